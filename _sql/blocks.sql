@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS `blocks`;
+CREATE TABLE `blocks` (
+  `user_id` varchar(200) NOT NULL,
+  `blocked_id` varchar(200) NOT NULL,
+  PRIMARY KEY (`user_id`, `blocked_id`),
+  KEY `blocked_id` (`blocked_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
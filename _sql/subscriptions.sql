@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS `subscriptions`;
+CREATE TABLE `subscriptions` (
+  `user_id` varchar(200) NOT NULL,
+  `category` varchar(200) NOT NULL,
+  PRIMARY KEY (`user_id`, `category`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS `user_preferences`;
+CREATE TABLE `user_preferences` (
+  `user_id` varchar(200) NOT NULL,
+  `show_nsfw` tinyint(1) NOT NULL DEFAULT 0,
+  `blur_nsfw` tinyint(1) NOT NULL DEFAULT 1,
+  `blur_spoilers` tinyint(1) NOT NULL DEFAULT 1,
+  PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS `user_stats`;
+CREATE TABLE `user_stats` (
+  `user_id` varchar(200) NOT NULL,
+  `post_karma` int NOT NULL DEFAULT 0,
+  `comment_karma` int NOT NULL DEFAULT 0,
+  `post_count` int NOT NULL DEFAULT 0,
+  `comment_count` int NOT NULL DEFAULT 0,
+  PRIMARY KEY (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
@@ -2,9 +2,12 @@ DROP TABLE IF EXISTS `users`;
 CREATE TABLE `users` (
   `id` varchar(200) NOT NULL,
   `username` varchar(200) NOT NULL,
-  `password` varchar(200) NOT NULL
+  `password` varchar(200) NOT NULL,
+  `moderator` tinyint(1) NOT NULL DEFAULT 0,
+  `suspended_until` datetime DEFAULT NULL,
+  `created` datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8;
 
-INSERT INTO `users` (`id`, `username`, `password`) VALUES
-('1',	'tayler',	'password');
+INSERT INTO `users` (`id`, `username`, `password`, `moderator`) VALUES
+('1',	'tayler',	'password',	1);
 
@@ -3,8 +3,13 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
+	"io/fs"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
@@ -13,87 +18,360 @@ import (
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
 
+	"github.com/KonstantinGalanin/redditclone/internal/blobs"
+	blobsHandlers "github.com/KonstantinGalanin/redditclone/internal/blobs/handlers"
+	"github.com/KonstantinGalanin/redditclone/internal/blobs/local"
+	"github.com/KonstantinGalanin/redditclone/internal/blobs/s3"
+	blocksHandlers "github.com/KonstantinGalanin/redditclone/internal/blocks/handlers"
+	blocksRepository "github.com/KonstantinGalanin/redditclone/internal/blocks/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/config"
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/feeds"
+	feedsHandlers "github.com/KonstantinGalanin/redditclone/internal/feeds/handlers"
+	"github.com/KonstantinGalanin/redditclone/internal/filter"
+	"github.com/KonstantinGalanin/redditclone/internal/health"
+	healthHandlers "github.com/KonstantinGalanin/redditclone/internal/health/handlers"
+	"github.com/KonstantinGalanin/redditclone/internal/images"
+	"github.com/KonstantinGalanin/redditclone/internal/lifecycle"
+	"github.com/KonstantinGalanin/redditclone/internal/live"
+	liveHandlers "github.com/KonstantinGalanin/redditclone/internal/live/handlers"
+	messagesHandlers "github.com/KonstantinGalanin/redditclone/internal/messages/handlers"
+	messagesRepository "github.com/KonstantinGalanin/redditclone/internal/messages/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/metrics"
+	modlogHandlers "github.com/KonstantinGalanin/redditclone/internal/modlog/handlers"
+	modlogRepository "github.com/KonstantinGalanin/redditclone/internal/modlog/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/notifications"
+	notificationsHandlers "github.com/KonstantinGalanin/redditclone/internal/notifications/handlers"
+	notificationsRepository "github.com/KonstantinGalanin/redditclone/internal/notifications/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
 	postsHandlers "github.com/KonstantinGalanin/redditclone/internal/posts/handlers"
 	postsRepository "github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	preferencesHandlers "github.com/KonstantinGalanin/redditclone/internal/preferences/handlers"
+	preferencesRepository "github.com/KonstantinGalanin/redditclone/internal/preferences/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/profiles"
+	profilesHandlers "github.com/KonstantinGalanin/redditclone/internal/profiles/handlers"
+	profilesRepository "github.com/KonstantinGalanin/redditclone/internal/profiles/repository"
+	reportsHandlers "github.com/KonstantinGalanin/redditclone/internal/reports/handlers"
+	reportsRepository "github.com/KonstantinGalanin/redditclone/internal/reports/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/requestid"
+	"github.com/KonstantinGalanin/redditclone/internal/retention"
 	"github.com/KonstantinGalanin/redditclone/internal/router"
+	savedRepository "github.com/KonstantinGalanin/redditclone/internal/saved/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/scheduler"
 	sessionRepository "github.com/KonstantinGalanin/redditclone/internal/session/redis"
+	shellHandlers "github.com/KonstantinGalanin/redditclone/internal/shell/handlers"
+	subscriptionsHandlers "github.com/KonstantinGalanin/redditclone/internal/subscriptions/handlers"
+	subscriptionsRepository "github.com/KonstantinGalanin/redditclone/internal/subscriptions/repository"
 	"github.com/KonstantinGalanin/redditclone/internal/token_manager/jwt"
+	"github.com/KonstantinGalanin/redditclone/internal/tracing"
 	userHandlers "github.com/KonstantinGalanin/redditclone/internal/user/handlers"
 	userRepository "github.com/KonstantinGalanin/redditclone/internal/user/repository"
 )
 
-var (
-	ADDR = os.Getenv("ADDR")
+const (
+	liveCleanupInterval = time.Minute
+	liveIdleTopic       = 10 * time.Minute
 )
 
 func main() {
-	err := godotenv.Load()
+	if err := godotenv.Load(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logrus.WithError(err).Fatal("Load .env error")
+	}
 
-	redisURL := "redis://"
-	if redisUser := os.Getenv("REDIS_USER"); redisUser != "" {
-		redisURL += redisUser + ":@"
+	cfg, printOnly, err := config.Load(os.Args[1:], os.LookupEnv)
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		logrus.WithError(err).Fatal("Load config error")
+	}
+	if printOnly {
+		if err = cfg.Print(os.Stdout); err != nil {
+			logrus.WithError(err).Fatal("Print config error")
+		}
+		return
+	}
+	if cfg.Log.Format == config.LogFormatJSON {
+		logrus.SetFormatter(&logrus.JSONFormatter{})
+	} else {
+		logrus.SetFormatter(&logrus.TextFormatter{DisableColors: true})
 	}
-	redisURL += "localhost:6379/0"
-	redisConn, err := redis.DialURL(redisURL)
+	logrus.AddHook(requestid.LogHook{})
+	jwt.TokenSecret = []byte(cfg.Auth.TokenSecret)
 
+	stopTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    cfg.Tracing.Exporter,
+		Endpoint:    cfg.Tracing.Endpoint,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		logrus.WithError(err).Fatal("Conn redis error")
+		logrus.WithError(err).Fatal("Setup tracing error")
 	}
-	redisManager := sessionRepository.NewSessionManagerRedis(redisConn)
+	logrus.AddHook(tracing.LogHook{})
 
+	redisURL := cfg.Redis.URL()
+	sessionPool := sessionRepository.NewPool(redisURL, sessionRepository.PoolOptions{
+		Size:         cfg.Redis.PoolSize,
+		IdleTimeout:  cfg.Redis.IdleTimeout,
+		DialTimeout:  cfg.Redis.DialTimeout,
+		ReadTimeout:  cfg.Redis.ReadTimeout,
+		WriteTimeout: cfg.Redis.WriteTimeout,
+		HealthCheck:  cfg.Redis.HealthCheck,
+	})
+	if err = sessionRepository.Ping(context.Background(), sessionPool); err != nil {
+		logrus.WithError(err).Fatal("Conn redis error")
+	}
+	redisManager := sessionRepository.NewSessionManagerRedis(sessionPool)
 
-	dbUser := os.Getenv("DB_USER")
-	dbPass := os.Getenv("DB_PASS")
-	dbHost := os.Getenv("DB_HOST")
-	dbPort := os.Getenv("DB_PORT")
-	dbName := os.Getenv("DB_NAME")
+	// Live updates need their own connections: a subscribed connection
+	// cannot be used for anything else.
+	livePool := &redis.Pool{
+		MaxIdle:     4,
+		IdleTimeout: 4 * time.Minute,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(redisURL)
+		},
+	}
 
-	dsn := dbUser + ":" + dbPass + "@tcp(" + dbHost + ":" + dbPort + ")/" + dbName + "?"
-	dsn += "&charset=utf8&interpolateParams=true"
-	db, err := sql.Open("mysql", dsn)
+	db, err := sql.Open("mysql", cfg.MySQL.DSN())
 	if err != nil {
 		logrus.WithError(err).Fatal("Open mysql error")
 	}
-	defer db.Close()
 	err = db.Ping()
 	if err != nil {
 		logrus.WithError(err).Fatal("Connect mysql error")
 	}
 
 	userHandler := userHandlers.UserHandler{
-		UserRepo:       userRepository.NewUserPostgresRepo(db),
+		UserRepo:       userRepository.NewInstrumentedUserPostgresRepo(userRepository.NewUserPostgresRepo(db)),
 		SessionManager: redisManager,
 		JwtService:     jwt.NewJwtService(),
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Mongo.ConnectTimeout)
 	defer cancel()
-	sessMongo, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv("MONGO_URI")))
+	sessMongo, err := mongo.Connect(ctx, options.Client().ApplyURI(string(cfg.Mongo.URI)))
 	if err != nil {
 		logrus.WithError(err).Fatal("Open mongodb error")
 	}
-	collection := sessMongo.Database("reddit").Collection("posts")
+	mongoDB := sessMongo.Database(cfg.Mongo.Database)
+	collection := mongoDB.Collection(cfg.Mongo.Collections.Posts)
+	savedCollection := mongoDB.Collection(cfg.Mongo.Collections.Saved)
+	reportsCollection := mongoDB.Collection(cfg.Mongo.Collections.Reports)
+	notificationsCollection := mongoDB.Collection(cfg.Mongo.Collections.Notifications)
+	modlogCollection := mongoDB.Collection(cfg.Mongo.Collections.Modlog)
+	messagesCollection := mongoDB.Collection(cfg.Mongo.Collections.Messages)
+
+	subscriptionRepo := subscriptionsRepository.NewSubscriptionMySQLRepo(db)
+	preferencesRepo := preferencesRepository.NewPreferencesMySQLRepo(db)
+	blockRepo := blocksRepository.NewBlockMySQLRepo(db)
+
+	postsRepo := postsRepository.NewInstrumentedPostMongoDB(postsRepository.NewPostMongoDB(collection))
+	reportRepo := reportsRepository.NewReportMongoDB(reportsCollection)
+	notificationRepo := notificationsRepository.NewNotificationMongoDB(notificationsCollection)
+	statsRepo := profilesRepository.NewStatsMySQLRepo(db)
+
+	bus := events.NewBus()
+	bus.Subscribe(&notifications.Notifier{
+		Repo:     notificationRepo,
+		UserRepo: userHandler.UserRepo,
+	})
+
+	bus.Subscribe(&profiles.StatsListener{Repo: statsRepo})
+	bus.Subscribe(metrics.Listener{})
+
+	liveHub := live.NewHub(live.DefaultHistory, live.DefaultBuffer)
+	liveBroker := live.NewRedisBroker(livePool, liveHub)
+	bus.Subscribe(&live.Listener{Publisher: liveBroker})
+	workers := lifecycle.NewWorkers()
+	workers.Go(liveBroker.Run)
+	workers.Go(func(ctx context.Context) {
+		liveHub.RunCleanup(ctx, liveCleanupInterval, liveIdleTopic)
+	})
+
+	var blobStore blobs.BlobStore
+	switch cfg.Blobs.Backend {
+	case config.BlobBackendS3:
+		blobStore = s3.NewStore(s3.Config{
+			Endpoint:  cfg.Blobs.S3.Endpoint,
+			Region:    cfg.Blobs.S3.Region,
+			Bucket:    cfg.Blobs.S3.Bucket,
+			AccessKey: cfg.Blobs.S3.AccessKey,
+			SecretKey: string(cfg.Blobs.S3.SecretKey),
+		}, nil)
+	case config.BlobBackendLocal:
+		if blobStore, err = local.NewFileStore(cfg.Blobs.UploadDir); err != nil {
+			logrus.WithError(err).Fatal("Open upload dir error")
+		}
+	}
+
+	var contentFilter filter.Checker
+	if cfg.Filter.Config != "" {
+		fileFilter, err := filter.NewFileFilter(cfg.Filter.Config, postsRepo)
+		if err != nil {
+			logrus.WithError(err).Fatal("Load filter config error")
+		}
+		workers.Go(func(ctx context.Context) {
+			fileFilter.Watch(ctx, cfg.Filter.ReloadInterval)
+		})
+		contentFilter = fileFilter
+	}
 
 	postsHandler := postsHandlers.PostsHandler{
-		PostsRepo:      postsRepository.NewPostMongoDB(collection),
-		UserRepo:       userHandler.UserRepo,
-		SessionManager: redisManager,
+		PostsRepo:        postsRepo,
+		UserRepo:         userHandler.UserRepo,
+		SessionManager:   redisManager,
+		SubscriptionRepo: subscriptionRepo,
+		SavedRepo:        savedRepository.NewSavedMongoDB(savedCollection),
+		PreferencesRepo:  preferencesRepo,
+		DefaultFeed:      cfg.Feed.DefaultCategories,
+		Events:           bus,
+		Blobs:            blobStore,
+		ImageLimits:      images.DefaultLimits,
+		Filter:           contentFilter,
+		ReportRepo:       reportRepo,
+		BlockRepo:        blockRepo,
+	}
+
+	subscriptionsHandler := subscriptionsHandlers.SubscriptionsHandler{
+		SubscriptionRepo: subscriptionRepo,
+		UserRepo:         userHandler.UserRepo,
+	}
+
+	reportsHandler := reportsHandlers.ReportsHandler{
+		ReportRepo: reportRepo,
+		PostsRepo:  postsRepo,
+		UserRepo:   userHandler.UserRepo,
+		Events:     bus,
+	}
+
+	notificationsHandler := notificationsHandlers.NotificationsHandler{
+		NotificationRepo: notificationRepo,
+		UserRepo:         userHandler.UserRepo,
+	}
+
+	liveHandler := liveHandlers.LiveHandler{
+		Hub:       liveHub,
+		Heartbeat: liveHandlers.DefaultHeartbeat,
 	}
 
-	r := router.NewRouter(userHandler, postsHandler, redisManager)
+	profilesHandler := profilesHandlers.ProfilesHandler{
+		UserRepo:  userHandler.UserRepo,
+		StatsRepo: statsRepo,
+		PostsRepo: postsRepo,
+		BlockRepo: blockRepo,
+	}
+
+	mediaHandler := blobsHandlers.MediaHandler{Blobs: blobStore}
+
+	moderationHandler := modlogHandlers.ModerationHandler{
+		PostsRepo: postsRepo,
+		LogRepo:   modlogRepository.NewLogMongoDB(modlogCollection),
+		UserRepo:  userHandler.UserRepo,
+		Events:    bus,
+		PinLimit:  posts.DefaultPinLimit,
+	}
+
+	preferencesHandler := preferencesHandlers.PreferencesHandler{
+		PreferencesRepo: preferencesRepo,
+		UserRepo:        userHandler.UserRepo,
+	}
+
+	blocksHandler := blocksHandlers.BlocksHandler{
+		BlockRepo: blockRepo,
+		UserRepo:  userHandler.UserRepo,
+	}
+
+	messageRepo := messagesRepository.NewMessageMongoDB(messagesCollection)
+	if err = messageRepo.EnsureIndexes(context.Background()); err != nil {
+		logrus.WithError(err).Fatal("Create message indexes error")
+	}
+	messagesHandler := messagesHandlers.MessagesHandler{
+		MessageRepo: messageRepo,
+		UserRepo:    userHandler.UserRepo,
+		BlockRepo:   blockRepo,
+	}
+
+	feedsHandler := feedsHandlers.FeedsHandler{
+		PostsRepo: postsRepo,
+		BaseURL:   cfg.HTTP.BaseURL(),
+		Size:      feeds.DefaultSize,
+	}
+
+	shellHandler := shellHandlers.ShellHandler{
+		PostsRepo: postsRepo,
+		BaseURL:   cfg.HTTP.BaseURL(),
+	}
+
+	healthHandler := &healthHandlers.HealthHandler{
+		Checks: []health.Check{
+			{Name: "mysql", Required: true, Ping: db.PingContext},
+			{Name: "mongodb", Required: true, Ping: func(ctx context.Context) error {
+				return sessMongo.Ping(ctx, readpref.Primary())
+			}},
+			{Name: "redis", Required: true, Ping: func(ctx context.Context) error {
+				return sessionRepository.Ping(ctx, sessionPool)
+			}},
+		},
+		Timeout: cfg.Health.Timeout,
+	}
+
+	workers.Go(retention.NewJob(postsRepo, cfg.Retention.Period, cfg.Retention.Interval).Run)
+	workers.Go(scheduler.NewJob(postsRepo, bus, cfg.Scheduler.Interval).Run)
+
+	r := router.NewRouter(userHandler, postsHandler, subscriptionsHandler, reportsHandler, notificationsHandler, liveHandler, profilesHandler, mediaHandler, moderationHandler, preferencesHandler, blocksHandler, messagesHandler, feedsHandler, shellHandler, healthHandler, redisManager)
 
-	logrus.SetFormatter(&logrus.TextFormatter{DisableColors: true})
 	logrus.WithFields(logrus.Fields{
 		"type": "START",
 	}).Info("starting server")
 
-	addr := ADDR
-	if addr == "" {
-		addr = ":8000"
+	srv := &http.Server{
+		Addr:         cfg.HTTP.Addr,
+		Handler:      r,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
 	}
-	err = http.ListenAndServe(addr, r)
-	if err != nil {
+	// Open live streams never go idle, so end them as soon as shutdown
+	// starts; clients reconnect to another instance.
+	srv.RegisterOnShutdown(liveHub.Close)
+
+	stop, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err = <-serveErr:
 		logrus.WithError(err).Fatal("Starting server error")
+	case <-stop.Done():
+	}
+	// A second signal kills the process without waiting.
+	stopSignals()
+	logrus.WithField("type", "STOP").Info("shutting down")
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
+	err = lifecycle.Shutdown(shutdownCtx,
+		lifecycle.Step{Name: "readiness", Stop: func(ctx context.Context) error {
+			healthHandler.ShuttingDown()
+			return lifecycle.Sleep(ctx, cfg.HTTP.ShutdownDelay)
+		}},
+		lifecycle.Step{Name: "http server", Stop: srv.Shutdown},
+		lifecycle.Step{Name: "background workers", Stop: workers.Stop},
+		lifecycle.Step{Name: "mysql", Stop: lifecycle.Closer(db.Close)},
+		lifecycle.Step{Name: "mongodb", Stop: sessMongo.Disconnect},
+		lifecycle.Step{Name: "redis live pool", Stop: lifecycle.Closer(livePool.Close)},
+		lifecycle.Step{Name: "redis sessions", Stop: lifecycle.Closer(sessionPool.Close)},
+		lifecycle.Step{Name: "tracing", Stop: stopTracing},
+	)
+	cancelShutdown()
+	if err != nil {
+		logrus.WithError(err).Error("shutdown finished with errors")
+		os.Exit(1)
 	}
+	logrus.WithField("type", "STOP").Info("server stopped")
 }
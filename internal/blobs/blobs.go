@@ -0,0 +1,38 @@
+package blobs
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var keyPattern = regexp.MustCompile(`^[a-zA-Z0-9_-][a-zA-Z0-9._/-]*$`)
+
+type Info struct {
+	ContentType string
+	Size        int64
+	Modified    time.Time
+}
+
+//go:generate mockgen -source=blobs.go -destination=mock/blobs_mock.go -package=mock BlobStore
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *Info, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidKey reports whether key is safe to use as a path on every backend:
+// relative, without empty or dot segments and limited to a plain charset.
+func ValidKey(key string) bool {
+	if !keyPattern.MatchString(key) {
+		return false
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
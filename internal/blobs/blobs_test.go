@@ -0,0 +1,19 @@
+package blobs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidKey(t *testing.T) {
+	valid := []string{"a", "images/1.jpg", "images/a_b-c.thumb.png"}
+	invalid := []string{"", "/etc/passwd", "../x", "images/../x", "images//x", "images/./x", "a b", "images/", ".hidden"}
+
+	for _, key := range valid {
+		assert.True(t, ValidKey(key), key)
+	}
+	for _, key := range invalid {
+		assert.False(t, ValidKey(key), key)
+	}
+}
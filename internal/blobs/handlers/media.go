@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/blobs"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+)
+
+const (
+	fieldKey = "key"
+
+	// Blob keys are never reused, so the content behind a URL never changes.
+	cacheControl = "public, max-age=31536000, immutable"
+)
+
+type MediaHandler struct {
+	Blobs blobs.BlobStore
+}
+
+// Serve streams a stored blob with long-lived caching headers.
+func (h *MediaHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)[fieldKey]
+	if !blobs.ValidKey(key) {
+		http.NotFound(w, r)
+		return
+	}
+
+	etag := strconv.Quote(key)
+	if r.Header.Get("If-None-Match") == etag {
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("ETag", etag)
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	body, info, err := h.Blobs.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, myerrors.ErrNoBlob) {
+			http.NotFound(w, r)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer body.Close()
+
+	header := w.Header()
+	header.Set("Cache-Control", cacheControl)
+	header.Set("ETag", etag)
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", "default-src 'none'")
+	if info.ContentType != "" {
+		header.Set("Content-Type", info.ContentType)
+	}
+	if info.Size > 0 {
+		header.Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	}
+	if !info.Modified.IsZero() {
+		header.Set("Last-Modified", info.Modified.UTC().Format(http.TimeFormat))
+	}
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err = io.Copy(w, body); err != nil {
+		logrus.WithContext(r.Context()).WithError(err).WithField("key", key).Error("write blob failed")
+	}
+}
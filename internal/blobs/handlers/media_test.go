@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/blobs"
+	"github.com/KonstantinGalanin/redditclone/internal/blobs/mock"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+)
+
+const key = "images/1.png"
+
+func newRequest(method, key string, header http.Header) *http.Request {
+	req := httptest.NewRequest(method, "/media/"+key, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	return mux.SetURLVars(req, map[string]string{fieldKey: key})
+}
+
+func TestServe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mock.NewMockBlobStore(ctrl)
+	handler := &MediaHandler{Blobs: store}
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	blob := func() {
+		store.EXPECT().Get(gomock.Any(), key).Return(io.NopCloser(strings.NewReader("png")),
+			&blobs.Info{ContentType: "image/png", Size: 3, Modified: modified}, nil)
+	}
+
+	cases := []struct {
+		name       string
+		req        *http.Request
+		expect     func()
+		statusCode int
+		body       string
+	}{
+		{
+			name:       "bad key",
+			req:        newRequest(http.MethodGet, "../secret", nil),
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "not found",
+			req:  newRequest(http.MethodGet, key, nil),
+			expect: func() {
+				store.EXPECT().Get(gomock.Any(), key).Return(nil, nil, myerrors.ErrNoBlob)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "store error",
+			req:  newRequest(http.MethodGet, key, nil),
+			expect: func() {
+				store.EXPECT().Get(gomock.Any(), key).Return(nil, nil, errors.New("some error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "not modified",
+			req:        newRequest(http.MethodGet, key, http.Header{"If-None-Match": {`"` + key + `"`}}),
+			statusCode: http.StatusNotModified,
+		},
+		{
+			name:       "head",
+			req:        newRequest(http.MethodHead, key, nil),
+			expect:     blob,
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "success",
+			req:        newRequest(http.MethodGet, key, nil),
+			expect:     blob,
+			statusCode: http.StatusOK,
+			body:       "png",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			handler.Serve(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+
+			if c.statusCode == http.StatusOK {
+				assert.Equal(t, c.body, recorder.Body.String())
+				assert.Equal(t, cacheControl, recorder.Header().Get("Cache-Control"))
+				assert.Equal(t, `"`+key+`"`, recorder.Header().Get("ETag"))
+				assert.Equal(t, "image/png", recorder.Header().Get("Content-Type"))
+				assert.Equal(t, "3", recorder.Header().Get("Content-Length"))
+				assert.Equal(t, "nosniff", recorder.Header().Get("X-Content-Type-Options"))
+				assert.Equal(t, modified.Format(http.TimeFormat), recorder.Header().Get("Last-Modified"))
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package local
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+
+	"github.com/KonstantinGalanin/redditclone/internal/blobs"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+)
+
+const (
+	dirPerm  = 0o755
+	filePerm = 0o644
+)
+
+// FileStore keeps blobs as files under Dir. The content type is derived from
+// the key's extension, so keys should carry one.
+type FileStore struct {
+	Dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, dirPerm); err != nil {
+		return nil, fmt.Errorf("local blob store: %w", err)
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+func (s *FileStore) path(key string) (string, error) {
+	if !blobs.ValidKey(key) {
+		return "", myerrors.ErrBadBlobKey
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+// Put writes to a temporary file first so readers never see a partial blob.
+func (s *FileStore) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return fmt.Errorf("local put blob: %w", err)
+	}
+	if err = os.MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return fmt.Errorf("local put blob: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("local put blob: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return fmt.Errorf("local put blob: %w", err)
+	}
+	if err = tmp.Close(); err != nil {
+		return fmt.Errorf("local put blob: %w", err)
+	}
+	if err = os.Chmod(tmp.Name(), filePerm); err != nil {
+		return fmt.Errorf("local put blob: %w", err)
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("local put blob: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) Get(ctx context.Context, key string) (io.ReadCloser, *blobs.Info, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("local get blob: %w", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("local get blob: %w", myerrors.ErrNoBlob)
+		}
+		return nil, nil, fmt.Errorf("local get blob: %w", err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, fmt.Errorf("local get blob: %w", err)
+	}
+	if stat.IsDir() {
+		file.Close()
+		return nil, nil, fmt.Errorf("local get blob: %w", myerrors.ErrNoBlob)
+	}
+
+	info := &blobs.Info{
+		ContentType: mime.TypeByExtension(filepath.Ext(path)),
+		Size:        stat.Size(),
+		Modified:    stat.ModTime(),
+	}
+	return file, info, nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return fmt.Errorf("local delete blob: %w", err)
+	}
+	if err = os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("local delete blob: %w", err)
+	}
+	return nil
+}
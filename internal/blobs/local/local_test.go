@@ -0,0 +1,63 @@
+package local
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+)
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "blobs"))
+	require.NoError(t, err)
+
+	data := []byte("image data")
+	require.NoError(t, store.Put(ctx, "images/1.png", bytes.NewReader(data), int64(len(data)), "image/png"))
+
+	body, info, err := store.Get(ctx, "images/1.png")
+	require.NoError(t, err)
+	got, err := io.ReadAll(body)
+	body.Close()
+	assert.NoError(t, err)
+	assert.Equal(t, data, got)
+	assert.Equal(t, "image/png", info.ContentType)
+	assert.Equal(t, int64(len(data)), info.Size)
+	assert.False(t, info.Modified.IsZero())
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(filepath.Join(dir, "blobs", "images"))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, store.Delete(ctx, "images/1.png"))
+	_, _, err = store.Get(ctx, "images/1.png")
+	assert.ErrorIs(t, err, myerrors.ErrNoBlob)
+	assert.NoError(t, store.Delete(ctx, "images/1.png"))
+}
+
+func TestFileStoreErrors(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, store.Put(ctx, "../escape", bytes.NewReader(nil), 0, ""), myerrors.ErrBadBlobKey)
+	_, _, err = store.Get(ctx, "../escape")
+	assert.ErrorIs(t, err, myerrors.ErrBadBlobKey)
+	assert.ErrorIs(t, store.Delete(ctx, "a//b"), myerrors.ErrBadBlobKey)
+
+	require.NoError(t, os.Mkdir(filepath.Join(store.Dir, "dir"), dirPerm))
+	_, _, err = store.Get(ctx, "dir")
+	assert.ErrorIs(t, err, myerrors.ErrNoBlob)
+
+	_, err = NewFileStore(filepath.Join(store.Dir, "dir", "\x00"))
+	assert.Error(t, err)
+}
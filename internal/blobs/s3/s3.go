@@ -0,0 +1,252 @@
+package s3
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/KonstantinGalanin/redditclone/internal/blobs"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+)
+
+const (
+	DefaultRegion = "us-east-1"
+
+	service        = "s3"
+	algorithm      = "AWS4-HMAC-SHA256"
+	amzDateFormat  = "20060102T150405Z"
+	dateFormat     = "20060102"
+	unsignedBody   = "UNSIGNED-PAYLOAD"
+	headerDate     = "X-Amz-Date"
+	headerSHA256   = "X-Amz-Content-Sha256"
+	requestTimeout = 30 * time.Second
+)
+
+type Config struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+// Store talks to any S3-compatible service with path-style URLs and
+// Signature Version 4, which MinIO and the other stand-ins support too.
+type Store struct {
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewStore(cfg Config, client *http.Client) *Store {
+	if cfg.Region == "" {
+		cfg.Region = DefaultRegion
+	}
+	cfg.Endpoint = strings.TrimRight(cfg.Endpoint, "/")
+	if client == nil {
+		client = &http.Client{Timeout: requestTimeout}
+	}
+	return &Store{
+		cfg:    cfg,
+		client: client,
+		now:    time.Now,
+	}
+}
+
+func (s *Store) objectURL(key string) (string, error) {
+	if !blobs.ValidKey(key) {
+		return "", myerrors.ErrBadBlobKey
+	}
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.cfg.Endpoint + "/" + url.PathEscape(s.cfg.Bucket) + "/" + strings.Join(segments, "/"), nil
+}
+
+func (s *Store) do(req *http.Request) (*http.Response, error) {
+	sign(req, s.cfg, s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, myerrors.ErrNoBlob
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d: %s", resp.StatusCode, body)
+	}
+	return resp, nil
+}
+
+func (s *Store) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return fmt.Errorf("s3 put blob: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, objectURL, body)
+	if err != nil {
+		return fmt.Errorf("s3 put blob: %w", err)
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("s3 put blob: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, *blobs.Info, error) {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s3 get blob: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, objectURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s3 get blob: %w", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("s3 get blob: %w", err)
+	}
+	info := &blobs.Info{
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.Modified = modified
+	}
+	return resp.Body, info, nil
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	objectURL, err := s.objectURL(key)
+	if err != nil {
+		return fmt.Errorf("s3 delete blob: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, objectURL, nil)
+	if err != nil {
+		return fmt.Errorf("s3 delete blob: %w", err)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return fmt.Errorf("s3 delete blob: %w", err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// sign adds a Signature Version 4 Authorization header covering every header
+// set so far. Unless the caller hashed the body, it is left unsigned so
+// uploads can be streamed.
+func sign(req *http.Request, cfg Config, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	req.Header.Set(headerDate, amzDate)
+	payloadHash := req.Header.Get(headerSHA256)
+	if payloadHash == "" {
+		payloadHash = unsignedBody
+		req.Header.Set(headerSHA256, payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(dateFormat), cfg.Region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{algorithm, amzDate, scope, hashHex(canonicalRequest)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretKey), now.Format(dateFormat))
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, cfg.AccessKey, scope, signedHeaders, signature))
+}
+
+func canonicalHeaders(req *http.Request) (string, string) {
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		if strings.EqualFold(name, "Authorization") {
+			continue
+		}
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	if req.ContentLength > 0 {
+		headers["content-length"] = strconv.FormatInt(req.ContentLength, 10)
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, queryEscape(key)+"="+queryEscape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// queryEscape encodes like url.QueryEscape but with spaces as %20, which is
+// what the signature expects.
+func queryEscape(value string) string {
+	return strings.ReplaceAll(url.QueryEscape(value), "+", "%20")
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
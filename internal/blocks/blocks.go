@@ -0,0 +1,22 @@
+package blocks
+
+import "github.com/KonstantinGalanin/redditclone/internal/user"
+
+//go:generate mockgen -source=blocks.go -destination=repository/repo_mock.go -package=repository BlockRepo
+type BlockRepo interface {
+	Block(userID, blockedID string) error
+	Unblock(userID, blockedID string) error
+	// GetBlocked returns the users userID has blocked.
+	GetBlocked(userID string) ([]*user.User, error)
+	// GetBlockers returns the users who have blocked userID.
+	GetBlockers(userID string) ([]*user.User, error)
+}
+
+// IDs returns the IDs of users, ready to be passed to repository filters.
+func IDs(users []*user.User) []string {
+	var ids []string
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	return ids
+}
@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/blocks"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	unauthorizedMsg = "unauthorized"
+	fieldUsername   = "username"
+)
+
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
+
+func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
+	newError := &ErrorMessage{
+		Message: errBody,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errStatus)
+	if err := json.NewEncoder(w).Encode(newError); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func WriteResponseUsers(w http.ResponseWriter, users []*user.User, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(users); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type BlocksHandler struct {
+	BlockRepo blocks.BlockRepo
+	UserRepo  user.UserRepo
+}
+
+func (b *BlocksHandler) getUserFromCtx(r *http.Request) (*user.User, error) {
+	ctx := r.Context()
+	sess, ok := ctx.Value("session").(*session.Session)
+	if !ok || sess == nil {
+		return nil, myerrors.ErrNoAuth
+	}
+
+	user, err := b.UserRepo.GetUserByUsername(r.Context(), sess.Username)
+	if err != nil {
+		return nil, fmt.Errorf("get user from sess: %w", err)
+	}
+	return user, nil
+}
+
+// List returns the users the current user has blocked.
+func (b *BlocksHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, err := b.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("list blocked %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	blocked, err := b.BlockRepo.GetBlocked(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	WriteResponseUsers(w, blocked, http.StatusOK)
+}
+
+func (b *BlocksHandler) Block(w http.ResponseWriter, r *http.Request) {
+	b.change(w, r, "block", b.BlockRepo.Block)
+}
+
+func (b *BlocksHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	b.change(w, r, "unblock", b.BlockRepo.Unblock)
+}
+
+// change applies apply to the current user and the user named in the url
+// and responds with the updated block list.
+func (b *BlocksHandler) change(w http.ResponseWriter, r *http.Request, action string, apply func(userID, blockedID string) error) {
+	username := mux.Vars(r)[fieldUsername]
+	if username == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyUsername.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current, err := b.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("%s %s", action, unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+	if current.Username == username {
+		WriteErrorMsg(w, myerrors.ErrBlockSelf.Error(), http.StatusBadRequest)
+		return
+	}
+
+	target, err := b.UserRepo.GetUserByUsername(r.Context(), username)
+	if errors.Is(err, myerrors.ErrNoUser) {
+		WriteErrorMsg(w, myerrors.ErrNoUser.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = apply(current.ID, target.ID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	blocked, err := b.BlockRepo.GetBlocked(current.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	WriteResponseUsers(w, blocked, http.StatusOK)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+
+	repositoryBlocks "github.com/KonstantinGalanin/redditclone/internal/blocks/repository"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+const username = "User"
+
+var (
+	expectedUser = &user.User{
+		Username: username,
+		Password: "password",
+		ID:       "1",
+	}
+	otherUser = &user.User{
+		Username: "Other",
+		ID:       "2",
+	}
+)
+
+func newRequest(withSession bool, vars map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	if withSession {
+		req = sessiontest.SignIn(req, username)
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+func TestList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockRepo := repositoryBlocks.NewMockBlockRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &BlocksHandler{
+		BlockRepo: blockRepo,
+		UserRepo:  userRepo,
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, nil),
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{otherUser}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			service.List(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestBlockUnblock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	blockRepo := repositoryBlocks.NewMockBlockRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &BlocksHandler{
+		BlockRepo: blockRepo,
+		UserRepo:  userRepo,
+	}
+	vars := map[string]string{fieldUsername: otherUser.Username}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+		handler    http.HandlerFunc
+	}{
+		{
+			name:       "block empty username",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(true, nil),
+			handler:    service.Block,
+		},
+		{
+			name:       "block no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, vars),
+			handler:    service.Block,
+		},
+		{
+			name:       "block self",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(true, map[string]string{fieldUsername: username}),
+			handler:    service.Block,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+			},
+		},
+		{
+			name:       "block unknown user",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(true, vars),
+			handler:    service.Block,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(nil, fmt.Errorf("get user: %w", myerrors.ErrNoUser))
+			},
+		},
+		{
+			name:       "block user lookup error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, vars),
+			handler:    service.Block,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "block repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, vars),
+			handler:    service.Block,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+				blockRepo.EXPECT().Block(expectedUser.ID, otherUser.ID).Return(errors.New("some error"))
+			},
+		},
+		{
+			name:       "block list error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, vars),
+			handler:    service.Block,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+				blockRepo.EXPECT().Block(expectedUser.ID, otherUser.ID).Return(nil)
+				blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "block success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, vars),
+			handler:    service.Block,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+				blockRepo.EXPECT().Block(expectedUser.ID, otherUser.ID).Return(nil)
+				blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{otherUser}, nil)
+			},
+		},
+		{
+			name:       "unblock success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, vars),
+			handler:    service.Unblock,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+				blockRepo.EXPECT().Unblock(expectedUser.ID, otherUser.ID).Return(nil)
+				blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			c.handler(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+type BlockMySQLRepo struct {
+	DB *sql.DB
+}
+
+func NewBlockMySQLRepo(db *sql.DB) *BlockMySQLRepo {
+	return &BlockMySQLRepo{
+		DB: db,
+	}
+}
+
+func (b *BlockMySQLRepo) Block(userID, blockedID string) error {
+	if _, err := b.DB.Exec(Block, userID, blockedID); err != nil {
+		return fmt.Errorf("mysql block: %w", err)
+	}
+	return nil
+}
+
+func (b *BlockMySQLRepo) Unblock(userID, blockedID string) error {
+	if _, err := b.DB.Exec(Unblock, userID, blockedID); err != nil {
+		return fmt.Errorf("mysql unblock: %w", err)
+	}
+	return nil
+}
+
+func (b *BlockMySQLRepo) GetBlocked(userID string) ([]*user.User, error) {
+	users, err := b.users(GetBlocked, userID)
+	if err != nil {
+		return nil, fmt.Errorf("mysql get blocked: %w", err)
+	}
+	return users, nil
+}
+
+func (b *BlockMySQLRepo) GetBlockers(userID string) ([]*user.User, error) {
+	users, err := b.users(GetBlockers, userID)
+	if err != nil {
+		return nil, fmt.Errorf("mysql get blockers: %w", err)
+	}
+	return users, nil
+}
+
+func (b *BlockMySQLRepo) users(query, userID string) ([]*user.User, error) {
+	rows, err := b.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*user.User{}
+	for rows.Next() {
+		u := &user.User{}
+		if err = rows.Scan(&u.ID, &u.Username); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	userID    = "1"
+	blockedID = "2"
+)
+
+func TestNewBlockMySQLRepo(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBlockMySQLRepo(db)
+
+	assert.NotNil(t, repo)
+	assert.Equal(t, db, repo.DB)
+}
+
+func TestBlock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBlockMySQLRepo(db)
+
+	mock.
+		ExpectExec("INSERT IGNORE INTO blocks").
+		WithArgs(userID, blockedID).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.Block(userID, blockedID))
+
+	mock.
+		ExpectExec("INSERT IGNORE INTO blocks").
+		WithArgs(userID, blockedID).
+		WillReturnError(fmt.Errorf("exec error"))
+	assert.EqualError(t, repo.Block(userID, blockedID), "mysql block: exec error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnblock(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBlockMySQLRepo(db)
+
+	mock.
+		ExpectExec("DELETE FROM blocks").
+		WithArgs(userID, blockedID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Unblock(userID, blockedID))
+
+	mock.
+		ExpectExec("DELETE FROM blocks").
+		WithArgs(userID, blockedID).
+		WillReturnError(fmt.Errorf("exec error"))
+	assert.EqualError(t, repo.Unblock(userID, blockedID), "mysql unblock: exec error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlocked(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBlockMySQLRepo(db)
+
+	rows := sqlmock.NewRows([]string{"id", "username"}).AddRow("2", "bob").AddRow("3", "eve")
+	mock.
+		ExpectQuery("SELECT u.id, u.username FROM blocks b JOIN users u ON u.id = b.blocked_id WHERE b.user_id = (.+)").
+		WithArgs(userID).
+		WillReturnRows(rows)
+	users, err := repo.GetBlocked(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*user.User{{ID: "2", Username: "bob"}, {ID: "3", Username: "eve"}}, users)
+
+	mock.
+		ExpectQuery("SELECT (.+) FROM blocks").
+		WithArgs(userID).
+		WillReturnError(fmt.Errorf("query error"))
+	users, err = repo.GetBlocked(userID)
+	assert.EqualError(t, err, "mysql get blocked: query error")
+	assert.Nil(t, users)
+
+	rows = sqlmock.NewRows([]string{"id"}).AddRow("2")
+	mock.
+		ExpectQuery("SELECT (.+) FROM blocks").
+		WithArgs(userID).
+		WillReturnRows(rows)
+	users, err = repo.GetBlocked(userID)
+	assert.Error(t, err)
+	assert.Nil(t, users)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBlockers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewBlockMySQLRepo(db)
+
+	rows := sqlmock.NewRows([]string{"id", "username"}).AddRow("2", "bob")
+	mock.
+		ExpectQuery("SELECT u.id, u.username FROM blocks b JOIN users u ON u.id = b.user_id WHERE b.blocked_id = (.+)").
+		WithArgs(userID).
+		WillReturnRows(rows)
+	users, err := repo.GetBlockers(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*user.User{{ID: "2", Username: "bob"}}, users)
+
+	mock.
+		ExpectQuery("SELECT (.+) FROM blocks").
+		WithArgs(userID).
+		WillReturnError(fmt.Errorf("query error"))
+	_, err = repo.GetBlockers(userID)
+	assert.EqualError(t, err, "mysql get blockers: query error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,8 @@
+package repository
+
+var (
+	Block       = "INSERT IGNORE INTO blocks (user_id, blocked_id) VALUES (?, ?);"
+	Unblock     = "DELETE FROM blocks WHERE user_id = ? AND blocked_id = ?;"
+	GetBlocked  = "SELECT u.id, u.username FROM blocks b JOIN users u ON u.id = b.blocked_id WHERE b.user_id = ? ORDER BY u.username;"
+	GetBlockers = "SELECT u.id, u.username FROM blocks b JOIN users u ON u.id = b.user_id WHERE b.blocked_id = ? ORDER BY u.username;"
+)
@@ -0,0 +1,93 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: blocks.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	reflect "reflect"
+
+	user "github.com/KonstantinGalanin/redditclone/internal/user"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockBlockRepo is a mock of BlockRepo interface.
+type MockBlockRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockBlockRepoMockRecorder
+}
+
+// MockBlockRepoMockRecorder is the mock recorder for MockBlockRepo.
+type MockBlockRepoMockRecorder struct {
+	mock *MockBlockRepo
+}
+
+// NewMockBlockRepo creates a new mock instance.
+func NewMockBlockRepo(ctrl *gomock.Controller) *MockBlockRepo {
+	mock := &MockBlockRepo{ctrl: ctrl}
+	mock.recorder = &MockBlockRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBlockRepo) EXPECT() *MockBlockRepoMockRecorder {
+	return m.recorder
+}
+
+// Block mocks base method.
+func (m *MockBlockRepo) Block(userID, blockedID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Block", userID, blockedID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Block indicates an expected call of Block.
+func (mr *MockBlockRepoMockRecorder) Block(userID, blockedID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Block", reflect.TypeOf((*MockBlockRepo)(nil).Block), userID, blockedID)
+}
+
+// GetBlocked mocks base method.
+func (m *MockBlockRepo) GetBlocked(userID string) ([]*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlocked", userID)
+	ret0, _ := ret[0].([]*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlocked indicates an expected call of GetBlocked.
+func (mr *MockBlockRepoMockRecorder) GetBlocked(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlocked", reflect.TypeOf((*MockBlockRepo)(nil).GetBlocked), userID)
+}
+
+// GetBlockers mocks base method.
+func (m *MockBlockRepo) GetBlockers(userID string) ([]*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBlockers", userID)
+	ret0, _ := ret[0].([]*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBlockers indicates an expected call of GetBlockers.
+func (mr *MockBlockRepoMockRecorder) GetBlockers(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlockers", reflect.TypeOf((*MockBlockRepo)(nil).GetBlockers), userID)
+}
+
+// Unblock mocks base method.
+func (m *MockBlockRepo) Unblock(userID, blockedID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unblock", userID, blockedID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unblock indicates an expected call of Unblock.
+func (mr *MockBlockRepoMockRecorder) Unblock(userID, blockedID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unblock", reflect.TypeOf((*MockBlockRepo)(nil).Unblock), userID, blockedID)
+}
@@ -0,0 +1,252 @@
+// Package config holds the server settings. Load reads them from defaults,
+// a YAML file, environment variables and command line flags, later sources
+// overriding earlier ones.
+package config
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// Every setting is a leaf field tagged with its YAML key and environment
+// variable. The flag name is the dotted YAML path, e.g. -mysql.host.
+// Secrets have no flag, since flags are visible in the process list.
+type Config struct {
+	HTTP      HTTPConfig      `yaml:"http"`
+	MySQL     MySQLConfig     `yaml:"mysql"`
+	Mongo     MongoConfig     `yaml:"mongo"`
+	Redis     RedisConfig     `yaml:"redis"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Feed      FeedConfig      `yaml:"feed"`
+	Retention RetentionConfig `yaml:"retention"`
+	Scheduler SchedulerConfig `yaml:"scheduler"`
+	Blobs     BlobsConfig     `yaml:"blobs"`
+	Filter    FilterConfig    `yaml:"filter"`
+	Health    HealthConfig    `yaml:"health"`
+	Tracing   TracingConfig   `yaml:"tracing"`
+	Log       LogConfig       `yaml:"log"`
+}
+
+type HTTPConfig struct {
+	Addr string `yaml:"addr" env:"ADDR"`
+	// PublicURL is the address the site is reached at, used for absolute
+	// links in feeds and page previews. Empty makes those links relative:
+	// the Host and X-Forwarded-Proto headers are not trusted to build them.
+	PublicURL string `yaml:"publicUrl" env:"PUBLIC_URL"`
+	// Zero read, write and idle timeouts mean no limit. Live streams clear
+	// their write deadline.
+	ReadTimeout  time.Duration `yaml:"readTimeout" env:"HTTP_READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"writeTimeout" env:"HTTP_WRITE_TIMEOUT"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout" env:"HTTP_IDLE_TIMEOUT"`
+	// ShutdownTimeout bounds the whole shutdown: draining requests,
+	// stopping workers and closing connections.
+	ShutdownTimeout time.Duration `yaml:"shutdownTimeout" env:"SHUTDOWN_TIMEOUT"`
+	// ShutdownDelay keeps serving for a while after /readyz turns
+	// unavailable, so the load balancer stops sending traffic first.
+	ShutdownDelay time.Duration `yaml:"shutdownDelay" env:"SHUTDOWN_DELAY"`
+}
+
+type MySQLConfig struct {
+	User     string `yaml:"user" env:"DB_USER"`
+	Password Secret `yaml:"password" env:"DB_PASS"`
+	Host     string `yaml:"host" env:"DB_HOST"`
+	Port     int    `yaml:"port" env:"DB_PORT"`
+	Database string `yaml:"database" env:"DB_NAME"`
+}
+
+// BaseURL is PublicURL without a trailing slash, ready to prefix paths.
+func (c HTTPConfig) BaseURL() string {
+	return strings.TrimSuffix(c.PublicURL, "/")
+}
+
+// DSN is the data source name for the mysql driver.
+func (c MySQLConfig) DSN() string {
+	dsn := mysql.NewConfig()
+	dsn.User = c.User
+	dsn.Passwd = string(c.Password)
+	dsn.Net = "tcp"
+	dsn.Addr = c.Host + ":" + strconv.Itoa(c.Port)
+	dsn.DBName = c.Database
+	dsn.Params = map[string]string{"charset": "utf8"}
+	dsn.InterpolateParams = true
+	dsn.ParseTime = true
+	return dsn.FormatDSN()
+}
+
+type MongoConfig struct {
+	URI            Secret           `yaml:"uri" env:"MONGO_URI"`
+	Database       string           `yaml:"database" env:"MONGO_DATABASE"`
+	ConnectTimeout time.Duration    `yaml:"connectTimeout" env:"MONGO_CONNECT_TIMEOUT"`
+	Collections    MongoCollections `yaml:"collections"`
+}
+
+type MongoCollections struct {
+	Posts         string `yaml:"posts" env:"MONGO_COLLECTION_POSTS"`
+	Saved         string `yaml:"saved" env:"MONGO_COLLECTION_SAVED"`
+	Reports       string `yaml:"reports" env:"MONGO_COLLECTION_REPORTS"`
+	Notifications string `yaml:"notifications" env:"MONGO_COLLECTION_NOTIFICATIONS"`
+	Modlog        string `yaml:"modlog" env:"MONGO_COLLECTION_MODLOG"`
+	Messages      string `yaml:"messages" env:"MONGO_COLLECTION_MESSAGES"`
+}
+
+type RedisConfig struct {
+	Addr     string `yaml:"addr" env:"REDIS_ADDR"`
+	User     string `yaml:"user" env:"REDIS_USER"`
+	Password Secret `yaml:"password" env:"REDIS_PASSWORD"`
+	DB       int    `yaml:"db" env:"REDIS_DB"`
+	// Settings of the session store pool, see session/redis.PoolOptions.
+	PoolSize     int           `yaml:"poolSize" env:"REDIS_POOL_SIZE"`
+	IdleTimeout  time.Duration `yaml:"idleTimeout" env:"REDIS_IDLE_TIMEOUT"`
+	DialTimeout  time.Duration `yaml:"dialTimeout" env:"REDIS_DIAL_TIMEOUT"`
+	ReadTimeout  time.Duration `yaml:"readTimeout" env:"REDIS_READ_TIMEOUT"`
+	WriteTimeout time.Duration `yaml:"writeTimeout" env:"REDIS_WRITE_TIMEOUT"`
+	HealthCheck  time.Duration `yaml:"healthCheck" env:"REDIS_HEALTH_CHECK"`
+}
+
+// URL is the redis:// address for redigo's DialURL.
+func (c RedisConfig) URL() string {
+	u := &url.URL{
+		Scheme: "redis",
+		Host:   c.Addr,
+		Path:   "/" + strconv.Itoa(c.DB),
+	}
+	if c.User != "" || c.Password != "" {
+		u.User = url.UserPassword(c.User, string(c.Password))
+	}
+	return u.String()
+}
+
+type AuthConfig struct {
+	TokenSecret Secret `yaml:"tokenSecret" env:"TOKEN_SECRET"`
+}
+
+type FeedConfig struct {
+	// DefaultCategories make up the feed of users without subscriptions.
+	DefaultCategories []string `yaml:"defaultCategories" env:"FEED_DEFAULT_CATEGORIES"`
+}
+
+type RetentionConfig struct {
+	Period   time.Duration `yaml:"period" env:"RETENTION_PERIOD"`
+	Interval time.Duration `yaml:"interval" env:"RETENTION_INTERVAL"`
+}
+
+type SchedulerConfig struct {
+	Interval time.Duration `yaml:"interval" env:"SCHEDULER_INTERVAL"`
+}
+
+const (
+	BlobBackendLocal = "local"
+	BlobBackendS3    = "s3"
+)
+
+type BlobsConfig struct {
+	Backend   string   `yaml:"backend" env:"BLOB_BACKEND"`
+	UploadDir string   `yaml:"uploadDir" env:"UPLOAD_DIR"`
+	S3        S3Config `yaml:"s3"`
+}
+
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" env:"S3_ENDPOINT"`
+	Region    string `yaml:"region" env:"S3_REGION"`
+	Bucket    string `yaml:"bucket" env:"S3_BUCKET"`
+	AccessKey string `yaml:"accessKey" env:"S3_ACCESS_KEY"`
+	SecretKey Secret `yaml:"secretKey" env:"S3_SECRET_KEY"`
+}
+
+type FilterConfig struct {
+	// Config is the path of the content filter rules. Empty disables the
+	// filter.
+	Config         string        `yaml:"config" env:"FILTER_CONFIG"`
+	ReloadInterval time.Duration `yaml:"reloadInterval" env:"FILTER_RELOAD_INTERVAL"`
+}
+
+type HealthConfig struct {
+	// Timeout bounds each backend ping of the readiness check.
+	Timeout time.Duration `yaml:"timeout" env:"HEALTH_TIMEOUT"`
+}
+
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+type TracingConfig struct {
+	// Exporter is where spans go: none keeps them in the process, which
+	// still puts trace IDs in the logs.
+	Exporter string `yaml:"exporter" env:"TRACING_EXPORTER"`
+	// Endpoint is the OTLP/HTTP address of the collector.
+	Endpoint string `yaml:"endpoint" env:"TRACING_ENDPOINT"`
+	// SampleRatio is the share of new traces recorded, from 0 to 1.
+	SampleRatio float64 `yaml:"sampleRatio" env:"TRACING_SAMPLE_RATIO"`
+}
+
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+type LogConfig struct {
+	Format string `yaml:"format" env:"LOG_FORMAT"`
+}
+
+// Default returns the settings used when nothing overrides them. They match
+// the services in docker-compose.yml. Zero intervals leave the choice to
+// the job that uses them.
+func Default() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Addr:            ":8000",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    30 * time.Second,
+			IdleTimeout:     2 * time.Minute,
+			ShutdownTimeout: 20 * time.Second,
+		},
+		MySQL: MySQLConfig{
+			User:     "root",
+			Host:     "localhost",
+			Port:     3306,
+			Database: "users",
+		},
+		Mongo: MongoConfig{
+			URI:            "mongodb://localhost:27017",
+			Database:       "reddit",
+			ConnectTimeout: 2 * time.Second,
+			Collections: MongoCollections{
+				Posts:         "posts",
+				Saved:         "saved",
+				Reports:       "reports",
+				Notifications: "notifications",
+				Modlog:        "modlog",
+				Messages:      "messages",
+			},
+		},
+		Redis: RedisConfig{
+			Addr:         "localhost:6379",
+			PoolSize:     16,
+			IdleTimeout:  4 * time.Minute,
+			DialTimeout:  2 * time.Second,
+			ReadTimeout:  2 * time.Second,
+			WriteTimeout: 2 * time.Second,
+			HealthCheck:  10 * time.Second,
+		},
+		Feed: FeedConfig{
+			DefaultCategories: []string{"music", "funny", "videos", "programming", "news", "fashion"},
+		},
+		Blobs: BlobsConfig{
+			Backend:   BlobBackendLocal,
+			UploadDir: "./uploads",
+		},
+		Health: HealthConfig{Timeout: time.Second},
+		Tracing: TracingConfig{
+			Exporter:    TracingExporterNone,
+			Endpoint:    "http://localhost:4318",
+			SampleRatio: 1,
+		},
+		Log: LogConfig{Format: LogFormatText},
+	}
+}
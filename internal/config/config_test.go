@@ -0,0 +1,225 @@
+package config
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(vars map[string]string) LookupFunc {
+	return func(key string) (string, bool) {
+		value, ok := vars[key]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, printOnly, err := Load(nil, env(map[string]string{"TOKEN_SECRET": "secret"}))
+	require.NoError(t, err)
+	assert.False(t, printOnly)
+
+	expect := Default()
+	expect.Auth.TokenSecret = "secret"
+	assert.Equal(t, expect, cfg)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+http:
+  addr: ":9000"
+mysql:
+  host: db.local
+  port: 3307
+mongo:
+  database: fromfile
+retention:
+  period: 24h
+feed:
+  defaultCategories: [news]
+auth:
+  tokenSecret: fromfile
+`)
+
+	cfg, _, err := Load(
+		[]string{"-mysql.host", "flag.local", "-retention.period=1h"},
+		env(map[string]string{
+			FileEnv:                   file,
+			"DB_HOST":                 "env.local",
+			"DB_PORT":                 "3308",
+			"FEED_DEFAULT_CATEGORIES": "music, ,funny",
+			"MONGO_DATABASE":          "",
+			"TRACING_SAMPLE_RATIO":    "0.25",
+		}),
+	)
+	require.NoError(t, err)
+
+	assert.Equal(t, ":9000", cfg.HTTP.Addr)
+	assert.Equal(t, "flag.local", cfg.MySQL.Host)
+	assert.Equal(t, 3308, cfg.MySQL.Port)
+	assert.Equal(t, "fromfile", cfg.Mongo.Database)
+	assert.Equal(t, time.Hour, cfg.Retention.Period)
+	assert.Equal(t, []string{"music", "funny"}, cfg.Feed.DefaultCategories)
+	assert.Equal(t, Secret("fromfile"), cfg.Auth.TokenSecret)
+	assert.Equal(t, "posts", cfg.Mongo.Collections.Posts)
+	assert.Equal(t, 0.25, cfg.Tracing.SampleRatio)
+}
+
+func TestLoadSecretFiles(t *testing.T) {
+	token := writeFile(t, "token", "from-env-file\n")
+	password := writeFile(t, "password", "from-config-file")
+	file := writeFile(t, "config.yaml", "mysql:\n  password:\n    file: "+password+"\n")
+
+	cfg, _, err := Load([]string{"-config", file}, env(map[string]string{"TOKEN_SECRET_FILE": token}))
+	require.NoError(t, err)
+	assert.Equal(t, Secret("from-env-file"), cfg.Auth.TokenSecret)
+	assert.Equal(t, Secret("from-config-file"), cfg.MySQL.Password)
+
+	_, _, err = Load(nil, env(map[string]string{"TOKEN_SECRET_FILE": filepath.Join(t.TempDir(), "missing")}))
+	assert.ErrorContains(t, err, "env TOKEN_SECRET_FILE")
+}
+
+func TestLoadErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		file   string
+		errors []string
+	}{
+		{
+			name:   "missing secret",
+			errors: []string{"auth.tokenSecret: required"},
+		},
+		{
+			name: "bad values",
+			args: []string{"-mysql.port", "abc", "-scheduler.interval", "soon"},
+			env:  map[string]string{"TOKEN_SECRET": "s", "REDIS_DB": "x"},
+			errors: []string{
+				`flag -mysql.port: "abc" is not a number`,
+				"flag -scheduler.interval:",
+				`env REDIS_DB: "x" is not a number`,
+			},
+		},
+		{
+			name: "invalid settings",
+			env: map[string]string{
+				"TOKEN_SECRET": "s",
+				"ADDR":         "8000",
+				"PUBLIC_URL":   "example.com",
+				"MONGO_URI":    "localhost:27017",
+				"BLOB_BACKEND": "s3",
+				"S3_BUCKET":    "media",
+			},
+			errors: []string{
+				"http.addr:",
+				"http.publicUrl: must be an http or https URL",
+				"mongo.uri: must start with mongodb://",
+				"blobs.s3.endpoint: required",
+				"blobs.s3.accessKey: required",
+				"blobs.s3.secretKey: required",
+			},
+		},
+		{
+			name:   "unknown backend",
+			env:    map[string]string{"TOKEN_SECRET": "s", "BLOB_BACKEND": "ftp"},
+			errors: []string{`blobs.backend: unknown backend "ftp"`},
+		},
+		{
+			name: "tracing",
+			env: map[string]string{
+				"TOKEN_SECRET":         "s",
+				"TRACING_EXPORTER":     "jaeger",
+				"TRACING_SAMPLE_RATIO": "2",
+			},
+			errors: []string{
+				`tracing.exporter: unknown exporter "jaeger"`,
+				"tracing.sampleRatio: must be between 0 and 1",
+			},
+		},
+		{
+			name:   "unknown log format",
+			env:    map[string]string{"TOKEN_SECRET": "s", "LOG_FORMAT": "xml"},
+			errors: []string{`log.format: unknown format "xml"`},
+		},
+		{
+			name:   "unknown key in file",
+			file:   "mysql:\n  hots: db\n",
+			errors: []string{"field hots not found"},
+		},
+		{
+			name:   "unknown flag",
+			args:   []string{"-mysql.password", "secret"},
+			errors: []string{"flag provided but not defined: -mysql.password"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			vars := tc.env
+			if vars == nil {
+				vars = map[string]string{}
+			}
+			if tc.file != "" {
+				vars[FileEnv] = writeFile(t, "config.yaml", tc.file)
+			}
+			_, _, err := Load(tc.args, env(vars))
+
+			require.Error(t, err)
+			for _, msg := range tc.errors {
+				assert.Contains(t, err.Error(), msg)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	cfg, printOnly, err := Load([]string{"-print-config"}, env(map[string]string{
+		"TOKEN_SECRET": "token",
+		"DB_PASS":      "password",
+	}))
+	require.NoError(t, err)
+	assert.True(t, printOnly)
+
+	var out bytes.Buffer
+	require.NoError(t, cfg.Print(&out))
+	assert.NotContains(t, out.String(), "token\n")
+	assert.NotContains(t, out.String(), "password\n")
+	assert.Contains(t, out.String(), "tokenSecret: '[redacted]'")
+	assert.Contains(t, out.String(), "connectTimeout: 2s")
+	assert.Contains(t, out.String(), "uri: '[redacted]'")
+}
+
+func TestBaseURL(t *testing.T) {
+	assert.Equal(t, "https://example.com", HTTPConfig{PublicURL: "https://example.com/"}.BaseURL())
+	assert.Equal(t, "https://example.com/forum", HTTPConfig{PublicURL: "https://example.com/forum"}.BaseURL())
+	assert.Empty(t, HTTPConfig{}.BaseURL())
+}
+
+func TestDSN(t *testing.T) {
+	cfg := MySQLConfig{User: "root", Password: "p@ss:word", Host: "db", Port: 3306, Database: "users"}
+	parsed, err := mysql.ParseDSN(cfg.DSN())
+	require.NoError(t, err)
+	assert.Equal(t, "p@ss:word", parsed.Passwd)
+	assert.Equal(t, "db:3306", parsed.Addr)
+	assert.Equal(t, "users", parsed.DBName)
+	assert.Equal(t, "utf8", parsed.Params["charset"])
+	assert.True(t, parsed.ParseTime)
+	assert.True(t, parsed.InterpolateParams)
+}
+
+func TestRedisURL(t *testing.T) {
+	assert.Equal(t, "redis://localhost:6379/0", RedisConfig{Addr: "localhost:6379"}.URL())
+	assert.Equal(t, "redis://user:@localhost:6379/2", RedisConfig{Addr: "localhost:6379", User: "user", DB: 2}.URL())
+	assert.Equal(t, "redis://:p%40ss@localhost:6379/0", RedisConfig{Addr: "localhost:6379", Password: "p@ss"}.URL())
+}
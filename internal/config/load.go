@@ -0,0 +1,193 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the config file when the -config flag is not given.
+const FileEnv = "CONFIG_FILE"
+
+var (
+	secretType   = reflect.TypeOf(Secret(""))
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// LookupFunc reads an environment variable, like os.LookupEnv.
+type LookupFunc func(key string) (string, bool)
+
+// Load builds the config from the defaults, the YAML file given by -config
+// or CONFIG_FILE, the environment and the flags in args, and validates it.
+// Empty environment variables count as unset. printOnly is true when the
+// caller should print the config with Print and exit.
+func Load(args []string, lookupEnv LookupFunc) (cfg *Config, printOnly bool, err error) {
+	cfg = Default()
+	all := settings(cfg)
+
+	flags := flag.NewFlagSet("redditclone", flag.ContinueOnError)
+	file := flags.String("config", "", "path of the YAML config file, also $"+FileEnv)
+	flags.BoolVar(&printOnly, "print-config", false, "print the effective config with secrets redacted and exit")
+	fromFlags := make(map[string]string)
+	for _, s := range all {
+		if s.secret() {
+			continue
+		}
+		path := s.path
+		flags.Func(path, "also $"+s.env, func(raw string) error {
+			fromFlags[path] = raw
+			return nil
+		})
+	}
+	if err = flags.Parse(args); err != nil {
+		return nil, false, err
+	}
+
+	if *file == "" {
+		*file, _ = lookupEnv(FileEnv)
+	}
+	if *file != "" {
+		if err = loadFile(cfg, *file); err != nil {
+			return nil, false, fmt.Errorf("config file %s: %w", *file, err)
+		}
+	}
+
+	var errs []error
+	for _, s := range all {
+		if err = s.fromEnv(lookupEnv); err != nil {
+			errs = append(errs, err)
+		}
+		if raw, ok := fromFlags[s.path]; ok {
+			if err = s.set(raw); err != nil {
+				errs = append(errs, fmt.Errorf("flag -%s: %w", s.path, err))
+			}
+		}
+	}
+	if err = errors.Join(errs...); err != nil {
+		return nil, false, err
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return nil, false, err
+	}
+	return cfg, printOnly, nil
+}
+
+// Print writes the config as YAML with the secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(c); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err = dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// setting is a leaf of Config.
+type setting struct {
+	path  string
+	env   string
+	value reflect.Value
+}
+
+func settings(cfg *Config) []setting {
+	var all []setting
+	collect(reflect.ValueOf(cfg).Elem(), "", &all)
+	return all
+}
+
+func collect(v reflect.Value, prefix string, all *[]setting) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		path := prefix + field.Tag.Get("yaml")
+		if field.Type.Kind() == reflect.Struct {
+			collect(v.Field(i), path+".", all)
+			continue
+		}
+		*all = append(*all, setting{path: path, env: field.Tag.Get("env"), value: v.Field(i)})
+	}
+}
+
+func (s setting) secret() bool {
+	return s.value.Type() == secretType
+}
+
+// fromEnv applies the environment variable of the setting. A secret can
+// also come from the file named by the variable with a _FILE suffix.
+func (s setting) fromEnv(lookupEnv LookupFunc) error {
+	if raw, ok := lookupEnv(s.env); ok && raw != "" {
+		if err := s.set(raw); err != nil {
+			return fmt.Errorf("env %s: %w", s.env, err)
+		}
+		return nil
+	}
+	if !s.secret() {
+		return nil
+	}
+	if path, ok := lookupEnv(s.env + "_FILE"); ok && path != "" {
+		secret, err := readSecret(path)
+		if err != nil {
+			return fmt.Errorf("env %s_FILE: %w", s.env, err)
+		}
+		s.value.SetString(string(secret))
+	}
+	return nil
+}
+
+func (s setting) set(raw string) error {
+	switch {
+	case s.value.Type() == durationType:
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		s.value.SetInt(int64(d))
+	case s.value.Kind() == reflect.Int:
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		s.value.SetInt(int64(n))
+	case s.value.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		s.value.SetFloat(f)
+	case s.value.Kind() == reflect.String:
+		s.value.SetString(raw)
+	case s.value.Kind() == reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		s.value.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported setting type %s", s.value.Type())
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "[redacted]"
+
+// Secret is a setting that must not be printed. Besides a plain value it
+// can be read from a file: NAME_FILE in the environment, or {file: path}
+// in the config file, which suits Docker and Kubernetes secrets.
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+func (s *Secret) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		var value string
+		if err := node.Decode(&value); err != nil {
+			return err
+		}
+		*s = Secret(value)
+		return nil
+	}
+
+	var ref struct {
+		File string `yaml:"file"`
+	}
+	if err := node.Decode(&ref); err != nil {
+		return err
+	}
+	value, err := readSecret(ref.File)
+	if err != nil {
+		return err
+	}
+	*s = value
+	return nil
+}
+
+func readSecret(path string) (Secret, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read secret: %w", err)
+	}
+	return Secret(strings.TrimRight(string(data), "\r\n")), nil
+}
@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validate reports every invalid setting at once, each prefixed with its
+// path in the config file.
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(path string, err error) {
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+
+	check("http.addr", hostPort(c.HTTP.Addr, true))
+	check("http.publicUrl", publicURL(c.HTTP.PublicURL))
+	check("http.readTimeout", notNegative(c.HTTP.ReadTimeout))
+	check("http.writeTimeout", notNegative(c.HTTP.WriteTimeout))
+	check("http.idleTimeout", notNegative(c.HTTP.IdleTimeout))
+	check("http.shutdownTimeout", positive(c.HTTP.ShutdownTimeout))
+	check("http.shutdownDelay", notNegative(c.HTTP.ShutdownDelay))
+
+	check("mysql.user", required(c.MySQL.User))
+	check("mysql.host", required(c.MySQL.Host))
+	check("mysql.port", port(c.MySQL.Port))
+	check("mysql.database", required(c.MySQL.Database))
+
+	check("mongo.uri", mongoURI(string(c.Mongo.URI)))
+	check("mongo.database", required(c.Mongo.Database))
+	check("mongo.connectTimeout", positive(c.Mongo.ConnectTimeout))
+	collections := map[string]string{
+		"posts":         c.Mongo.Collections.Posts,
+		"saved":         c.Mongo.Collections.Saved,
+		"reports":       c.Mongo.Collections.Reports,
+		"notifications": c.Mongo.Collections.Notifications,
+		"modlog":        c.Mongo.Collections.Modlog,
+		"messages":      c.Mongo.Collections.Messages,
+	}
+	for _, name := range []string{"posts", "saved", "reports", "notifications", "modlog", "messages"} {
+		check("mongo.collections."+name, required(collections[name]))
+	}
+
+	check("redis.addr", hostPort(c.Redis.Addr, false))
+	if c.Redis.DB < 0 {
+		check("redis.db", errors.New("must not be negative"))
+	}
+	if c.Redis.PoolSize < 1 {
+		check("redis.poolSize", errors.New("must be at least 1"))
+	}
+	check("redis.idleTimeout", positive(c.Redis.IdleTimeout))
+	check("redis.dialTimeout", positive(c.Redis.DialTimeout))
+	check("redis.readTimeout", positive(c.Redis.ReadTimeout))
+	check("redis.writeTimeout", positive(c.Redis.WriteTimeout))
+	check("redis.healthCheck", positive(c.Redis.HealthCheck))
+
+	check("auth.tokenSecret", required(string(c.Auth.TokenSecret)))
+
+	for _, category := range c.Feed.DefaultCategories {
+		if strings.TrimSpace(category) == "" {
+			check("feed.defaultCategories", errors.New("must not contain empty names"))
+			break
+		}
+	}
+
+	check("retention.period", notNegative(c.Retention.Period))
+	check("retention.interval", notNegative(c.Retention.Interval))
+	check("scheduler.interval", notNegative(c.Scheduler.Interval))
+	check("filter.reloadInterval", notNegative(c.Filter.ReloadInterval))
+	check("health.timeout", positive(c.Health.Timeout))
+
+	switch c.Tracing.Exporter {
+	case TracingExporterNone, TracingExporterStdout:
+	case TracingExporterOTLP:
+		check("tracing.endpoint", required(c.Tracing.Endpoint))
+		check("tracing.endpoint", publicURL(c.Tracing.Endpoint))
+	default:
+		check("tracing.exporter", fmt.Errorf("unknown exporter %q, want %s, %s or %s",
+			c.Tracing.Exporter, TracingExporterNone, TracingExporterStdout, TracingExporterOTLP))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		check("tracing.sampleRatio", errors.New("must be between 0 and 1"))
+	}
+
+	if c.Log.Format != LogFormatText && c.Log.Format != LogFormatJSON {
+		check("log.format", fmt.Errorf("unknown format %q, want %s or %s", c.Log.Format, LogFormatText, LogFormatJSON))
+	}
+
+	switch c.Blobs.Backend {
+	case BlobBackendLocal:
+		check("blobs.uploadDir", required(c.Blobs.UploadDir))
+	case BlobBackendS3:
+		check("blobs.s3.endpoint", required(c.Blobs.S3.Endpoint))
+		check("blobs.s3.endpoint", publicURL(c.Blobs.S3.Endpoint))
+		check("blobs.s3.bucket", required(c.Blobs.S3.Bucket))
+		check("blobs.s3.accessKey", required(c.Blobs.S3.AccessKey))
+		check("blobs.s3.secretKey", required(string(c.Blobs.S3.SecretKey)))
+	default:
+		check("blobs.backend", fmt.Errorf("unknown backend %q, want %s or %s", c.Blobs.Backend, BlobBackendLocal, BlobBackendS3))
+	}
+
+	return errors.Join(errs...)
+}
+
+func required(value string) error {
+	if value == "" {
+		return errors.New("required")
+	}
+	return nil
+}
+
+func port(n int) error {
+	if n < 1 || n > 65535 {
+		return fmt.Errorf("port %d out of range", n)
+	}
+	return nil
+}
+
+func positive(d time.Duration) error {
+	if d <= 0 {
+		return errors.New("must be positive")
+	}
+	return nil
+}
+
+func notNegative(d time.Duration) error {
+	if d < 0 {
+		return errors.New("must not be negative")
+	}
+	return nil
+}
+
+// hostPort checks a host:port address. The host may be left out of a
+// listen address.
+func hostPort(addr string, listen bool) error {
+	host, p, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	if host == "" && !listen {
+		return errors.New("host is required")
+	}
+	n, err := strconv.Atoi(p)
+	if err != nil {
+		return fmt.Errorf("invalid port %q", p)
+	}
+	if listen && n == 0 {
+		return nil
+	}
+	return port(n)
+}
+
+// publicURL checks an optional absolute http(s) URL.
+func publicURL(raw string) error {
+	if raw == "" {
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errors.New("must be an http or https URL")
+	}
+	if u.Host == "" {
+		return errors.New("host is required")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return errors.New("must not have a query or fragment")
+	}
+	return nil
+}
+
+func mongoURI(raw string) error {
+	if raw == "" {
+		return errors.New("required")
+	}
+	if !strings.HasPrefix(raw, "mongodb://") && !strings.HasPrefix(raw, "mongodb+srv://") {
+		// The URI may hold a password, so it is not quoted.
+		return errors.New("must start with mongodb:// or mongodb+srv://")
+	}
+	return nil
+}
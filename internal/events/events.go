@@ -0,0 +1,82 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	PostCreated     = "post-created"
+	PostDeleted     = "post-deleted"
+	CommentCreated  = "comment-created"
+	CommentDeleted  = "comment-deleted"
+	ScoreChanged    = "score-changed"
+	ModeratorAction = "moderator-action"
+)
+
+// Event describes something that happened to a post. Handlers publish events
+// and do not know who consumes them.
+type Event struct {
+	Type      string
+	PostID    string
+	CommentID string
+	Actor     *user.User
+	Post      *posts.Post
+	Comment   *posts.Comment
+
+	// ScoreDelta is how much a vote moved the score of the post, or of the
+	// comment when CommentID is set.
+	ScoreDelta int
+
+	// Action and Target are set for moderator actions: what was done and to
+	// whose content. Target is also set when a moderator deletes a comment.
+	Action string
+	Target *user.User
+}
+
+// Publishers pass the context of the request that caused the event, so
+// listeners log and trace as part of it.
+type Publisher interface {
+	Publish(ctx context.Context, event *Event)
+}
+
+type Listener interface {
+	Handle(ctx context.Context, event *Event)
+}
+
+type ListenerFunc func(ctx context.Context, event *Event)
+
+func (f ListenerFunc) Handle(ctx context.Context, event *Event) {
+	f(ctx, event)
+}
+
+// Bus delivers every published event to all subscribed listeners in the
+// order they subscribed. Listeners run on the publishing goroutine and are
+// expected to handle their own errors.
+type Bus struct {
+	mu        sync.RWMutex
+	listeners []Listener
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+func (b *Bus) Subscribe(listener Listener) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+func (b *Bus) Publish(ctx context.Context, event *Event) {
+	b.mu.RLock()
+	listeners := b.listeners
+	b.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener.Handle(ctx, event)
+	}
+}
@@ -0,0 +1,24 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusPublish(t *testing.T) {
+	bus := NewBus()
+	bus.Publish(context.Background(), &Event{Type: PostCreated})
+
+	var got []string
+	bus.Subscribe(ListenerFunc(func(_ context.Context, event *Event) {
+		got = append(got, "first:"+event.Type)
+	}))
+	bus.Subscribe(ListenerFunc(func(_ context.Context, event *Event) {
+		got = append(got, "second:"+event.Type)
+	}))
+
+	bus.Publish(context.Background(), &Event{Type: CommentCreated})
+	assert.Equal(t, []string{"first:comment-created", "second:comment-created"}, got)
+}
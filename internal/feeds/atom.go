@@ -0,0 +1,96 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+const atomNamespace = "http://www.w3.org/2005/Atom"
+
+type atomFeed struct {
+	XMLName xml.Name     `xml:"feed"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	Title   string       `xml:"title"`
+	ID      string       `xml:"id"`
+	Updated string       `xml:"updated"`
+	Links   []atomLink   `xml:"link"`
+	Entries []*atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomEntry struct {
+	Title     string        `xml:"title"`
+	ID        string        `xml:"id"`
+	Link      atomLink      `xml:"link"`
+	Published string        `xml:"published"`
+	Updated   string        `xml:"updated"`
+	Author    *atomAuthor   `xml:"author,omitempty"`
+	Category  *atomCategory `xml:"category,omitempty"`
+	Content   *atomContent  `xml:"content,omitempty"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+// Atom encodes the feed as Atom 1.0. Posts cannot be edited once published,
+// so an entry's updated time is its publication time.
+func (f *Feed) Atom() ([]byte, error) {
+	feed := &atomFeed{
+		Xmlns:   atomNamespace,
+		Title:   f.Title,
+		ID:      f.Self,
+		Updated: atomTime(f.Updated),
+		Links: []atomLink{
+			{Href: f.Self, Rel: "self", Type: "application/atom+xml"},
+			{Href: f.Link, Rel: "alternate", Type: "text/html"},
+		},
+		Entries: make([]*atomEntry, 0, len(f.Entries)),
+	}
+	for _, entry := range f.Entries {
+		item := &atomEntry{
+			Title:     entry.Title,
+			ID:        entry.ID,
+			Link:      atomLink{Href: entry.Link, Rel: "alternate", Type: "text/html"},
+			Published: atomTime(entry.Published),
+			Updated:   atomTime(entry.Published),
+		}
+		if entry.Author != "" {
+			item.Author = &atomAuthor{Name: entry.Author}
+		}
+		if entry.Category != "" {
+			item.Category = &atomCategory{Term: entry.Category}
+		}
+		if entry.Content != "" {
+			item.Content = &atomContent{Type: "html", Body: entry.Content}
+		}
+		feed.Entries = append(feed.Entries, item)
+	}
+	return encode(feed)
+}
+
+func atomTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func encode(v interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
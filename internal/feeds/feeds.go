@@ -0,0 +1,136 @@
+package feeds
+
+import (
+	"bytes"
+	"html/template"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KonstantinGalanin/redditclone/internal/markdown"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+const DefaultSize = 50
+
+// Feed is what both the Atom and the RSS encoders render. URLs are
+// absolute.
+type Feed struct {
+	Title string
+	// Link is the page of the site the feed mirrors, Self the feed itself.
+	Link    string
+	Self    string
+	Updated time.Time
+	Entries []*Entry
+}
+
+type Entry struct {
+	ID        string
+	Title     string
+	Link      string
+	Author    string
+	Category  string
+	Published time.Time
+	// Content is HTML. The encoders escape it.
+	Content string
+}
+
+var contentTemplate = template.Must(template.New("content").Parse(
+	`{{if .Image}}<p><img src="{{.Image}}" alt="{{.Title}}"></p>{{end}}` +
+		`{{if .URL}}<p><a href="{{.URL}}">{{.URL}}</a></p>{{end}}` +
+		`{{.Text}}` +
+		`{{if .Options}}<ul>{{range .Options}}<li>{{.}}</li>{{end}}</ul>{{end}}`,
+))
+
+// New builds a feed of the newest posts, at most size of them. NSFW posts
+// are left out and spoilers carry no content, as for anonymous visitors of
+// the site. baseURL has no trailing slash.
+func New(title, link, self, baseURL string, items []*posts.Post, size int) (*Feed, error) {
+	if size <= 0 {
+		size = DefaultSize
+	}
+	items = newest(items, size)
+
+	feed := &Feed{
+		Title:   title,
+		Link:    link,
+		Self:    self,
+		Entries: make([]*Entry, 0, len(items)),
+	}
+	for _, post := range items {
+		entry, err := newEntry(post, baseURL)
+		if err != nil {
+			return nil, err
+		}
+		if post.Created.After(feed.Updated) {
+			feed.Updated = post.Created
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed, nil
+}
+
+// Permalink is the address of the post page in the web client.
+func Permalink(baseURL string, post *posts.Post) string {
+	return baseURL + "/a/" + post.Category + "/" + post.ID
+}
+
+func newest(items []*posts.Post, size int) []*posts.Post {
+	kept := make([]*posts.Post, 0, len(items))
+	for _, post := range items {
+		if !post.NSFW {
+			kept = append(kept, post)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].Created.After(kept[j].Created)
+	})
+	if len(kept) > size {
+		kept = kept[:size]
+	}
+	return kept
+}
+
+func newEntry(post *posts.Post, baseURL string) (*Entry, error) {
+	entry := &Entry{
+		ID:        Permalink(baseURL, post),
+		Title:     post.Title,
+		Link:      Permalink(baseURL, post),
+		Category:  post.Category,
+		Published: post.Created,
+	}
+	if post.Author != nil {
+		entry.Author = post.Author.Username
+	}
+	if post.Spoiler {
+		return entry, nil
+	}
+
+	data := struct {
+		Title   string
+		Image   string
+		URL     string
+		Text    template.HTML
+		Options []string
+	}{
+		Title: post.Title,
+		URL:   post.URL,
+		// The renderer sanitizes its output.
+		Text: template.HTML(markdown.Render(post.Text)),
+	}
+	if post.Image != nil {
+		data.Image = baseURL + posts.MediaPath + post.Image.Key
+	}
+	if post.Poll != nil {
+		for _, option := range post.Poll.Options {
+			data.Options = append(data.Options, option.Text)
+		}
+	}
+
+	var content bytes.Buffer
+	if err := contentTemplate.Execute(&content, data); err != nil {
+		return nil, err
+	}
+	entry.Content = strings.TrimSpace(content.String())
+	return entry, nil
+}
@@ -0,0 +1,145 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const base = "https://example.com"
+
+var (
+	older = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newer = time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+)
+
+func testPosts() []*posts.Post {
+	author := &user.User{ID: "1", Username: "alice"}
+	return []*posts.Post{
+		{ID: "p1", Title: "Fish & <chips>", Type: posts.TypeText, Category: "funny", Author: author,
+			Text: "**bold** <script>alert(1)</script>", Created: older},
+		{ID: "p2", Title: "Link", Type: posts.TypeLink, Category: "news", Author: author,
+			URL: "javascript:alert(1)", Created: newer},
+		{ID: "p3", Title: "Hidden", Type: posts.TypeText, Category: "funny", Author: author,
+			Text: "nsfw", NSFW: true, Created: newer.Add(time.Hour)},
+	}
+}
+
+func TestNew(t *testing.T) {
+	feed, err := New("title", base+"/", base+"/feeds/all.atom", base, testPosts(), 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, newer, feed.Updated)
+	require.Len(t, feed.Entries, 2)
+	assert.Equal(t, base+"/a/news/p2", feed.Entries[0].Link)
+	assert.Equal(t, base+"/a/funny/p1", feed.Entries[1].Link)
+	assert.Equal(t, "alice", feed.Entries[1].Author)
+	assert.Contains(t, feed.Entries[1].Content, "<strong>bold</strong>")
+	assert.NotContains(t, feed.Entries[1].Content, "<script>")
+	assert.NotContains(t, feed.Entries[0].Content, `href="javascript:`)
+}
+
+func TestNewContent(t *testing.T) {
+	cases := []struct {
+		name     string
+		post     *posts.Post
+		contains string
+		empty    bool
+	}{
+		{
+			name:     "link",
+			post:     &posts.Post{ID: "1", Type: posts.TypeLink, URL: "https://go.dev/?a=1&b=2"},
+			contains: `<a href="https://go.dev/?a=1&amp;b=2">`,
+		},
+		{
+			name:     "image",
+			post:     &posts.Post{ID: "1", Type: posts.TypeImage, Title: `"quoted"`, Image: &posts.Image{Key: "images/1.png"}},
+			contains: `<img src="` + base + `/media/images/1.png" alt="&#34;quoted&#34;">`,
+		},
+		{
+			name: "poll",
+			post: &posts.Post{ID: "1", Type: posts.TypePoll, Poll: &posts.Poll{
+				Options: []*posts.PollOption{{ID: "a", Text: "<yes>"}, {ID: "b", Text: "no"}},
+			}},
+			contains: "<ul><li>&lt;yes&gt;</li><li>no</li></ul>",
+		},
+		{
+			name:  "spoiler",
+			post:  &posts.Post{ID: "1", Type: posts.TypeText, Text: "the butler did it", Spoiler: true},
+			empty: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			feed, err := New("title", base, base, base, []*posts.Post{tc.post}, 0)
+			require.NoError(t, err)
+			require.Len(t, feed.Entries, 1)
+			if tc.empty {
+				assert.Empty(t, feed.Entries[0].Content)
+				return
+			}
+			assert.Contains(t, feed.Entries[0].Content, tc.contains)
+		})
+	}
+}
+
+func TestNewSize(t *testing.T) {
+	feed, err := New("title", base, base, base, testPosts(), 1)
+	require.NoError(t, err)
+	require.Len(t, feed.Entries, 1)
+	assert.Equal(t, base+"/a/news/p2", feed.Entries[0].ID)
+}
+
+func TestAtom(t *testing.T) {
+	feed, err := New("title", base+"/", base+"/feeds/all.atom", base, testPosts(), 0)
+	require.NoError(t, err)
+
+	body, err := feed.Atom()
+	require.NoError(t, err)
+	out := string(body)
+
+	assert.True(t, strings.HasPrefix(out, xml.Header))
+	assert.Contains(t, out, `<feed xmlns="http://www.w3.org/2005/Atom">`)
+	assert.Contains(t, out, `<link href="https://example.com/feeds/all.atom" rel="self" type="application/atom+xml"></link>`)
+	assert.Contains(t, out, "<updated>2024-05-02T12:00:00Z</updated>")
+	assert.Contains(t, out, "<title>Fish &amp; &lt;chips&gt;</title>")
+	assert.Contains(t, out, `<content type="html">&lt;p&gt;&lt;strong&gt;bold&lt;/strong&gt;`)
+	assert.Contains(t, out, "<author>\n      <name>alice</name>")
+
+	var decoded atomFeed
+	require.NoError(t, xml.Unmarshal(body, &decoded))
+	assert.Len(t, decoded.Entries, 2)
+}
+
+func TestRSS(t *testing.T) {
+	feed, err := New("title", base+"/", base+"/feeds/all.rss", base, testPosts(), 0)
+	require.NoError(t, err)
+
+	body, err := feed.RSS()
+	require.NoError(t, err)
+	out := string(body)
+
+	assert.Contains(t, out, `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:dc="http://purl.org/dc/elements/1.1/">`)
+	assert.Contains(t, out, `<atom:link href="https://example.com/feeds/all.rss" rel="self" type="application/rss+xml"></atom:link>`)
+	assert.Contains(t, out, "<lastBuildDate>Thu, 02 May 2024 12:00:00 +0000</lastBuildDate>")
+	assert.Contains(t, out, `<guid isPermaLink="true">https://example.com/a/funny/p1</guid>`)
+	assert.Contains(t, out, "<dc:creator>alice</dc:creator>")
+	assert.Contains(t, out, "<title>Fish &amp; &lt;chips&gt;</title>")
+	assert.NotContains(t, out, "<script>")
+}
+
+func TestRSSEmpty(t *testing.T) {
+	feed, err := New("title", base, base, base, nil, 0)
+	require.NoError(t, err)
+
+	body, err := feed.RSS()
+	require.NoError(t, err)
+	assert.NotContains(t, string(body), "lastBuildDate")
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/feeds"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+const (
+	fieldFormat   = "format"
+	fieldCategory = "category"
+	fieldUsername = "username"
+
+	formatAtom = "atom"
+	formatRSS  = "rss"
+
+	siteTitle = "redditclone"
+)
+
+var contentTypes = map[string]string{
+	formatAtom: "application/atom+xml; charset=utf-8",
+	formatRSS:  "application/rss+xml; charset=utf-8",
+}
+
+type FeedsHandler struct {
+	PostsRepo posts.PostRepo
+	// BaseURL is the public address of the site without a trailing slash,
+	// e.g. https://example.com. When empty, links are relative to the site.
+	BaseURL string
+	Size    int
+}
+
+func (h *FeedsHandler) All(w http.ResponseWriter, r *http.Request) {
+	items, err := h.PostsRepo.GetAllPosts(r.Context(), nil, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.serve(w, r, siteTitle, "/", "/feeds/all", items)
+}
+
+func (h *FeedsHandler) Category(w http.ResponseWriter, r *http.Request) {
+	category := mux.Vars(r)[fieldCategory]
+	items, err := h.PostsRepo.GetPostsByCategory(r.Context(), category, nil, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.serve(w, r, siteTitle+": "+category, "/a/"+category, "/feeds/category/"+category, items)
+}
+
+func (h *FeedsHandler) User(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)[fieldUsername]
+	items, err := h.PostsRepo.GetPostsByUser(r.Context(), username, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	h.serve(w, r, siteTitle+": posts by "+username, "/u/"+username, "/feeds/user/"+username, items)
+}
+
+// serve encodes the feed in the format asked for by the URL. The ETag is a
+// digest of the body and Last-Modified the newest entry, so readers polling
+// the feed get 304 until something is posted.
+func (h *FeedsHandler) serve(w http.ResponseWriter, r *http.Request, title, page, self string, items []*posts.Post) {
+	format := mux.Vars(r)[fieldFormat]
+	contentType, ok := contentTypes[format]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	base := h.BaseURL
+	feed, err := feeds.New(title, base+page, base+self+"."+format, base, items, h.Size)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var body []byte
+	if format == formatAtom {
+		body, err = feed.Atom()
+	} else {
+		body, err = feed.RSS()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	sum := sha256.Sum256(body)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	http.ServeContent(w, r, "", feed.Updated, bytes.NewReader(body))
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+var created = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func testPosts() []*posts.Post {
+	return []*posts.Post{{
+		ID:       "p1",
+		Title:    "Hello",
+		Type:     posts.TypeText,
+		Category: "music",
+		Text:     "text",
+		Author:   &user.User{ID: "1", Username: "alice"},
+		Created:  created,
+	}}
+}
+
+func newRequest(path string, vars map[string]string, header http.Header) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+func TestFeeds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockPostRepo(ctrl)
+	handler := &FeedsHandler{PostsRepo: repo, BaseURL: "https://example.com"}
+
+	cases := []struct {
+		name        string
+		call        http.HandlerFunc
+		req         *http.Request
+		expect      func()
+		statusCode  int
+		contentType string
+		contains    []string
+	}{
+		{
+			name: "all atom",
+			call: handler.All,
+			req:  newRequest("/feeds/all.atom", map[string]string{fieldFormat: "atom"}, nil),
+			expect: func() {
+				repo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), gomock.Nil()).Return(testPosts(), nil)
+			},
+			statusCode:  http.StatusOK,
+			contentType: "application/atom+xml; charset=utf-8",
+			contains: []string{
+				`<link href="https://example.com/feeds/all.atom" rel="self"`,
+				`<link href="https://example.com/a/music/p1" rel="alternate"`,
+			},
+		},
+		{
+			name: "category rss",
+			call: handler.Category,
+			req:  newRequest("/feeds/category/music.rss", map[string]string{fieldFormat: "rss", fieldCategory: "music"}, nil),
+			expect: func() {
+				repo.EXPECT().GetPostsByCategory(gomock.Any(), "music", gomock.Nil(), gomock.Nil()).Return(testPosts(), nil)
+			},
+			statusCode:  http.StatusOK,
+			contentType: "application/rss+xml; charset=utf-8",
+			contains: []string{
+				"<title>redditclone: music</title>",
+				"<link>https://example.com/a/music</link>",
+				`<atom:link href="https://example.com/feeds/category/music.rss"`,
+			},
+		},
+		{
+			name: "user",
+			call: handler.User,
+			req:  newRequest("/feeds/user/alice.atom", map[string]string{fieldFormat: "atom", fieldUsername: "alice"}, nil),
+			expect: func() {
+				repo.EXPECT().GetPostsByUser(gomock.Any(), "alice", nil).Return(testPosts(), nil)
+			},
+			statusCode:  http.StatusOK,
+			contentType: "application/atom+xml; charset=utf-8",
+			contains:    []string{`<link href="https://example.com/u/alice" rel="alternate"`},
+		},
+		{
+			name: "not modified",
+			call: handler.All,
+			req: newRequest("/feeds/all.atom", map[string]string{fieldFormat: "atom"},
+				http.Header{"If-Modified-Since": {created.Format(http.TimeFormat)}}),
+			expect: func() {
+				repo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), gomock.Nil()).Return(testPosts(), nil)
+			},
+			statusCode: http.StatusNotModified,
+		},
+		{
+			name: "unknown format",
+			call: handler.All,
+			req:  newRequest("/feeds/all.json", map[string]string{fieldFormat: "json"}, nil),
+			expect: func() {
+				repo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), gomock.Nil()).Return(testPosts(), nil)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "repo error",
+			call: handler.All,
+			req:  newRequest("/feeds/all.rss", map[string]string{fieldFormat: "rss"}, nil),
+			expect: func() {
+				repo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), gomock.Nil()).Return(nil, errors.New("db error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect()
+			w := httptest.NewRecorder()
+			tc.call(w, tc.req)
+
+			assert.Equal(t, tc.statusCode, w.Code)
+			if tc.statusCode != http.StatusOK {
+				return
+			}
+			assert.Equal(t, tc.contentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, created.Format(http.TimeFormat), w.Header().Get("Last-Modified"))
+			assert.NotEmpty(t, w.Header().Get("ETag"))
+			for _, s := range tc.contains {
+				assert.Contains(t, w.Body.String(), s)
+			}
+		})
+	}
+}
+
+func TestFeedsETag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockPostRepo(ctrl)
+	handler := &FeedsHandler{PostsRepo: repo}
+	repo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), gomock.Nil()).Return(testPosts(), nil).Times(2)
+
+	vars := map[string]string{fieldFormat: "atom"}
+	w := httptest.NewRecorder()
+	req := newRequest("/feeds/all.atom", vars, http.Header{"X-Forwarded-Proto": {"https"}})
+	req.Host = "evil.example"
+	handler.All(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `<link href="/feeds/all.atom" rel="self"`)
+	assert.NotContains(t, w.Body.String(), "evil.example")
+
+	etag := w.Header().Get("ETag")
+	w = httptest.NewRecorder()
+	handler.All(w, newRequest("/feeds/all.atom", vars, http.Header{"X-Forwarded-Proto": {"https"}, "If-None-Match": {etag}}))
+	assert.Equal(t, http.StatusNotModified, w.Code)
+}
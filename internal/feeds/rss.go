@@ -0,0 +1,74 @@
+package feeds
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Atom    string     `xml:"xmlns:atom,attr"`
+	DC      string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string     `xml:"title"`
+	Link          string     `xml:"link"`
+	Description   string     `xml:"description"`
+	Self          atomLink   `xml:"atom:link"`
+	LastBuildDate string     `xml:"lastBuildDate,omitempty"`
+	Items         []*rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Creator     string  `xml:"dc:creator,omitempty"`
+	Category    string  `xml:"category,omitempty"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// RSS encodes the feed as RSS 2.0. RSS wants an e-mail address as author,
+// so the username goes into dc:creator instead.
+func (f *Feed) RSS() ([]byte, error) {
+	feed := &rssFeed{
+		Version: "2.0",
+		Atom:    atomNamespace,
+		DC:      "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       f.Title,
+			Link:        f.Link,
+			Description: f.Title,
+			Self:        atomLink{Href: f.Self, Rel: "self", Type: "application/rss+xml"},
+			Items:       make([]*rssItem, 0, len(f.Entries)),
+		},
+	}
+	if !f.Updated.IsZero() {
+		feed.Channel.LastBuildDate = rssTime(f.Updated)
+	}
+	for _, entry := range f.Entries {
+		feed.Channel.Items = append(feed.Channel.Items, &rssItem{
+			Title:       entry.Title,
+			Link:        entry.Link,
+			GUID:        rssGUID{IsPermaLink: true, Value: entry.ID},
+			PubDate:     rssTime(entry.Published),
+			Creator:     entry.Author,
+			Category:    entry.Category,
+			Description: entry.Content,
+		})
+	}
+	return encode(feed)
+}
+
+func rssTime(t time.Time) string {
+	return t.UTC().Format(time.RFC1123Z)
+}
@@ -0,0 +1,249 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const DefaultReloadInterval = 30 * time.Second
+
+// Config is the JSON rule file. Every rule group names the outcome it
+// produces; groups left out are disabled.
+type Config struct {
+	Words          []ListConfig      `json:"words"`
+	Patterns       []ListConfig      `json:"patterns"`
+	BlockedDomains []ListConfig      `json:"blockedDomains"`
+	DuplicateLinks Outcome           `json:"duplicateLinks"`
+	Caps           *CapsConfig       `json:"caps"`
+	Repetition     *RepetitionConfig `json:"repetition"`
+}
+
+// ListConfig is a list of words, regular expressions or domains sharing an
+// outcome. Patterns may carry a reason; it defaults to "blocked pattern".
+type ListConfig struct {
+	Entries []string `json:"entries"`
+	Outcome Outcome  `json:"outcome"`
+	Reason  string   `json:"reason,omitempty"`
+}
+
+type CapsConfig struct {
+	MinLetters int     `json:"minLetters"`
+	MaxRatio   float64 `json:"maxRatio"`
+	Outcome    Outcome `json:"outcome"`
+}
+
+type RepetitionConfig struct {
+	MaxCharRun int     `json:"maxCharRun"`
+	MaxWordRun int     `json:"maxWordRun"`
+	Outcome    Outcome `json:"outcome"`
+}
+
+// Build compiles the config into a pipeline. links backs the duplicate link
+// rule and may be nil when that rule is disabled.
+func Build(cfg *Config, links LinkIndex) (Pipeline, error) {
+	pipeline := Pipeline{}
+
+	for _, list := range cfg.Words {
+		if err := checkOutcome(list.Outcome); err != nil {
+			return nil, err
+		}
+		if words := nonEmpty(list.Entries); len(words) != 0 {
+			pipeline = append(pipeline, NewWordRule(words, list.Outcome))
+		}
+	}
+
+	for _, list := range cfg.Patterns {
+		if err := checkOutcome(list.Outcome); err != nil {
+			return nil, err
+		}
+		reason := list.Reason
+		if reason == "" {
+			reason = "blocked pattern"
+		}
+		for _, entry := range nonEmpty(list.Entries) {
+			pattern, err := regexp.Compile(entry)
+			if err != nil {
+				return nil, fmt.Errorf("filter pattern %q: %w", entry, err)
+			}
+			pipeline = append(pipeline, &PatternRule{Pattern: pattern, Outcome: list.Outcome, Reason: reason})
+		}
+	}
+
+	for _, list := range cfg.BlockedDomains {
+		if err := checkOutcome(list.Outcome); err != nil {
+			return nil, err
+		}
+		domains := make(map[string]bool, len(list.Entries))
+		for _, domain := range nonEmpty(list.Entries) {
+			domains[strings.TrimSuffix(strings.ToLower(domain), ".")] = true
+		}
+		pipeline = append(pipeline, &DomainRule{Domains: domains, Outcome: list.Outcome})
+	}
+
+	if cfg.DuplicateLinks != "" {
+		if err := checkOutcome(cfg.DuplicateLinks); err != nil {
+			return nil, err
+		}
+		if links == nil {
+			return nil, fmt.Errorf("filter duplicate links: no link index")
+		}
+		pipeline = append(pipeline, &DuplicateLinkRule{Links: links, Outcome: cfg.DuplicateLinks})
+	}
+
+	if cfg.Caps != nil {
+		if err := checkOutcome(cfg.Caps.Outcome); err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, &CapsRule{
+			MinLetters: cfg.Caps.MinLetters,
+			MaxRatio:   cfg.Caps.MaxRatio,
+			Outcome:    cfg.Caps.Outcome,
+		})
+	}
+
+	if cfg.Repetition != nil {
+		if err := checkOutcome(cfg.Repetition.Outcome); err != nil {
+			return nil, err
+		}
+		pipeline = append(pipeline, &RepetitionRule{
+			MaxCharRun: cfg.Repetition.MaxCharRun,
+			MaxWordRun: cfg.Repetition.MaxWordRun,
+			Outcome:    cfg.Repetition.Outcome,
+		})
+	}
+
+	return pipeline, nil
+}
+
+func nonEmpty(entries []string) []string {
+	kept := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			kept = append(kept, entry)
+		}
+	}
+	return kept
+}
+
+func checkOutcome(outcome Outcome) error {
+	if !outcome.valid() {
+		return fmt.Errorf("filter: unknown outcome %q", outcome)
+	}
+	return nil
+}
+
+// FileFilter is a pipeline loaded from a rule file. Reload swaps in the new
+// rules only when the whole file is valid, so a broken edit keeps the old
+// rules running.
+type FileFilter struct {
+	Path  string
+	Links LinkIndex
+
+	mu       sync.RWMutex
+	pipeline Pipeline
+	modTime  time.Time
+}
+
+func NewFileFilter(path string, links LinkIndex) (*FileFilter, error) {
+	f := &FileFilter{
+		Path:  path,
+		Links: links,
+	}
+	if err := f.Reload(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileFilter) Check(ctx context.Context, content *Content) (*Verdict, error) {
+	f.mu.RLock()
+	pipeline := f.pipeline
+	f.mu.RUnlock()
+	return pipeline.Check(ctx, content)
+}
+
+// Reload reads the rule file again. The modification time is recorded even
+// when the file is invalid, so Watch reports a broken file once instead of on
+// every tick.
+func (f *FileFilter) Reload() error {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return fmt.Errorf("filter reload: %w", err)
+	}
+	f.mu.Lock()
+	f.modTime = info.ModTime()
+	f.mu.Unlock()
+
+	data, err := os.ReadFile(f.Path)
+	if err != nil {
+		return fmt.Errorf("filter reload: %w", err)
+	}
+
+	cfg := &Config{}
+	if err = json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("filter reload: %w", err)
+	}
+	pipeline, err := Build(cfg, f.Links)
+	if err != nil {
+		return fmt.Errorf("filter reload: %w", err)
+	}
+
+	f.mu.Lock()
+	f.pipeline = pipeline
+	f.mu.Unlock()
+	return nil
+}
+
+// changed reports whether the file was modified since the last load.
+func (f *FileFilter) changed() (bool, error) {
+	info, err := os.Stat(f.Path)
+	if err != nil {
+		return false, err
+	}
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return !info.ModTime().Equal(f.modTime), nil
+}
+
+// Watch reloads the rules every interval when the file changed, until ctx
+// is done.
+func (f *FileFilter) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReloadInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := f.changed()
+		if err != nil {
+			logrus.WithError(err).Error("filter rules stat failed")
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err = f.Reload(); err != nil {
+			logrus.WithError(err).Error("filter rules reload failed, keeping the old rules")
+			continue
+		}
+		logrus.WithFields(logrus.Fields{
+			"type": "FILTER",
+			"path": f.Path,
+		}).Info("reloaded filter rules")
+	}
+}
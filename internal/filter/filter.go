@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"context"
+	"fmt"
+)
+
+type Outcome string
+
+const (
+	OutcomeAllow  Outcome = "allow"
+	OutcomeHold   Outcome = "hold"
+	OutcomeReject Outcome = "reject"
+)
+
+var severity = map[Outcome]int{
+	OutcomeAllow:  0,
+	OutcomeHold:   1,
+	OutcomeReject: 2,
+}
+
+func (o Outcome) valid() bool {
+	_, ok := severity[o]
+	return ok
+}
+
+// Content is what the filter inspects. Comments only carry Text; Category
+// is the category of the post they belong to.
+type Content struct {
+	Category string
+	Title    string
+	Text     string
+	URL      string
+}
+
+// Verdict is the combined result of every rule. Reasons are meant to be
+// shown to the author and to moderators, so they name the rule that fired
+// but not the list entry that matched.
+type Verdict struct {
+	Outcome Outcome  `json:"outcome"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// Rule inspects content and returns OutcomeAllow when it has nothing to say.
+type Rule interface {
+	Apply(ctx context.Context, content *Content) (Outcome, string, error)
+}
+
+// Checker is what handlers depend on. Both Pipeline and FileFilter
+// implement it.
+type Checker interface {
+	Check(ctx context.Context, content *Content) (*Verdict, error)
+}
+
+// Pipeline runs every rule and keeps the most severe outcome, so a reject
+// always wins over a hold.
+type Pipeline []Rule
+
+func (p Pipeline) Check(ctx context.Context, content *Content) (*Verdict, error) {
+	verdict := &Verdict{Outcome: OutcomeAllow}
+	for _, rule := range p {
+		outcome, reason, err := rule.Apply(ctx, content)
+		if err != nil {
+			return nil, fmt.Errorf("filter check: %w", err)
+		}
+		if outcome == OutcomeAllow {
+			continue
+		}
+		verdict.Reasons = append(verdict.Reasons, reason)
+		if severity[outcome] > severity[verdict.Outcome] {
+			verdict.Outcome = outcome
+		}
+	}
+	return verdict, nil
+}
@@ -0,0 +1,205 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLinks map[string]bool
+
+func (f fakeLinks) LinkPosted(ctx context.Context, category, url string) (bool, error) {
+	if category == "broken" {
+		return false, errors.New("some error")
+	}
+	return f[category+" "+url], nil
+}
+
+func TestPipeline(t *testing.T) {
+	pipeline := Pipeline{
+		NewWordRule([]string{"spam"}, OutcomeHold),
+		&PatternRule{Pattern: regexp.MustCompile(`(?i)buy now`), Outcome: OutcomeReject, Reason: "advertising"},
+	}
+
+	cases := []struct {
+		name     string
+		content  *Content
+		expected *Verdict
+	}{
+		{
+			name:     "clean",
+			content:  &Content{Title: "hello", Text: "spammy is not a whole word"},
+			expected: &Verdict{Outcome: OutcomeAllow},
+		},
+		{
+			name:     "hold",
+			content:  &Content{Title: "SPAM here"},
+			expected: &Verdict{Outcome: OutcomeHold, Reasons: []string{"blocked word"}},
+		},
+		{
+			name:     "reject wins",
+			content:  &Content{Title: "spam", Text: "Buy now!"},
+			expected: &Verdict{Outcome: OutcomeReject, Reasons: []string{"blocked word", "advertising"}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			verdict, err := pipeline.Check(context.Background(), c.content)
+			assert.NoError(t, err)
+			assert.Equal(t, c.expected, verdict)
+		})
+	}
+}
+
+func TestPipelineError(t *testing.T) {
+	pipeline := Pipeline{&DuplicateLinkRule{Links: fakeLinks{}, Outcome: OutcomeHold}}
+	_, err := pipeline.Check(context.Background(), &Content{Category: "broken", URL: "https://example.com"})
+	assert.Error(t, err)
+}
+
+func TestDomainRule(t *testing.T) {
+	rule := &DomainRule{Domains: map[string]bool{"spam.example": true}, Outcome: OutcomeReject}
+
+	cases := []struct {
+		content *Content
+		outcome Outcome
+	}{
+		{content: &Content{URL: "https://spam.example/offer"}, outcome: OutcomeReject},
+		{content: &Content{URL: "https://www.Spam.Example./offer"}, outcome: OutcomeReject},
+		{content: &Content{Text: "see http://cdn.spam.example/x for more"}, outcome: OutcomeReject},
+		{content: &Content{URL: "https://notspam.example/"}, outcome: OutcomeAllow},
+		{content: &Content{Text: "spam.example without a scheme"}, outcome: OutcomeAllow},
+	}
+
+	for _, c := range cases {
+		outcome, _, err := rule.Apply(context.Background(), c.content)
+		assert.NoError(t, err)
+		assert.Equal(t, c.outcome, outcome, c.content)
+	}
+}
+
+func TestDuplicateLinkRule(t *testing.T) {
+	rule := &DuplicateLinkRule{Links: fakeLinks{"music https://example.com": true}, Outcome: OutcomeHold}
+
+	outcome, reason, err := rule.Apply(context.Background(), &Content{Category: "music", URL: "https://example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeHold, outcome)
+	assert.Equal(t, "duplicate link", reason)
+
+	outcome, _, err = rule.Apply(context.Background(), &Content{Category: "news", URL: "https://example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeAllow, outcome)
+
+	outcome, _, err = rule.Apply(context.Background(), &Content{Category: "music", Text: "no url"})
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeAllow, outcome)
+}
+
+func TestCapsRule(t *testing.T) {
+	rule := &CapsRule{MinLetters: 10, MaxRatio: 0.7, Outcome: OutcomeHold}
+
+	outcome, _, _ := rule.Apply(context.Background(), &Content{Title: "TIL"})
+	assert.Equal(t, OutcomeAllow, outcome)
+
+	outcome, _, _ = rule.Apply(context.Background(), &Content{Title: "THIS IS VERY IMPORTANT", Text: "READ IT"})
+	assert.Equal(t, OutcomeHold, outcome)
+
+	outcome, _, _ = rule.Apply(context.Background(), &Content{Title: "NASA launches a new rocket today"})
+	assert.Equal(t, OutcomeAllow, outcome)
+}
+
+func TestRepetitionRule(t *testing.T) {
+	rule := &RepetitionRule{MaxCharRun: 5, MaxWordRun: 3, Outcome: OutcomeHold}
+
+	cases := []struct {
+		text    string
+		outcome Outcome
+	}{
+		{text: "wow!!!!!", outcome: OutcomeAllow},
+		{text: "wow!!!!!!", outcome: OutcomeHold},
+		{text: "buy buy buy", outcome: OutcomeAllow},
+		{text: "buy, buy, BUY buy!", outcome: OutcomeHold},
+		{text: "a      lot of spaces", outcome: OutcomeAllow},
+	}
+
+	for _, c := range cases {
+		outcome, _, err := rule.Apply(context.Background(), &Content{Text: c.text})
+		assert.NoError(t, err)
+		assert.Equal(t, c.outcome, outcome, c.text)
+	}
+}
+
+func TestBuild(t *testing.T) {
+	cfg := &Config{
+		Words:          []ListConfig{{Entries: []string{"spam", " "}, Outcome: OutcomeHold}},
+		Patterns:       []ListConfig{{Entries: []string{"(?i)buy now"}, Outcome: OutcomeReject}},
+		BlockedDomains: []ListConfig{{Entries: []string{"Spam.Example"}, Outcome: OutcomeReject}},
+		DuplicateLinks: OutcomeHold,
+		Caps:           &CapsConfig{MinLetters: 10, MaxRatio: 0.7, Outcome: OutcomeHold},
+		Repetition:     &RepetitionConfig{MaxCharRun: 10, Outcome: OutcomeHold},
+	}
+	pipeline, err := Build(cfg, fakeLinks{})
+	assert.NoError(t, err)
+	assert.Len(t, pipeline, 6)
+
+	verdict, err := pipeline.Check(context.Background(), &Content{URL: "https://spam.example"})
+	assert.NoError(t, err)
+	assert.Equal(t, &Verdict{Outcome: OutcomeReject, Reasons: []string{"blocked domain spam.example"}}, verdict)
+
+	_, err = Build(&Config{Words: []ListConfig{{Entries: []string{"x"}, Outcome: "delete"}}}, nil)
+	assert.Error(t, err)
+
+	_, err = Build(&Config{Patterns: []ListConfig{{Entries: []string{"("}, Outcome: OutcomeHold}}}, nil)
+	assert.Error(t, err)
+
+	_, err = Build(&Config{DuplicateLinks: OutcomeHold}, nil)
+	assert.Error(t, err)
+}
+
+func TestFileFilterReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "filter.json")
+	write := func(data string, modTime time.Time) {
+		assert.NoError(t, os.WriteFile(path, []byte(data), 0o600))
+		assert.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+	start := time.Now().Add(-time.Hour)
+
+	_, err := NewFileFilter(path, nil)
+	assert.Error(t, err)
+
+	write(`{"words":[{"entries":["spam"],"outcome":"reject"}]}`, start)
+	f, err := NewFileFilter(path, nil)
+	assert.NoError(t, err)
+	verdict, err := f.Check(context.Background(), &Content{Text: "spam"})
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeReject, verdict.Outcome)
+
+	changed, err := f.changed()
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	write(`{"words":[{"entries":["spam"],"outcome":"hold"}]}`, start.Add(time.Minute))
+	changed, err = f.changed()
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, f.Reload())
+	verdict, err = f.Check(context.Background(), &Content{Text: "spam"})
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeHold, verdict.Outcome)
+
+	write(`{"words":`, start.Add(2*time.Minute))
+	assert.Error(t, f.Reload())
+	changed, err = f.changed()
+	assert.NoError(t, err)
+	assert.False(t, changed, "a broken file is only reported once")
+	verdict, err = f.Check(context.Background(), &Content{Text: "spam"})
+	assert.NoError(t, err)
+	assert.Equal(t, OutcomeHold, verdict.Outcome, "a broken file keeps the old rules")
+}
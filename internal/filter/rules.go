@@ -0,0 +1,188 @@
+package filter
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// linkPattern finds links inside free text. It only has to be good enough
+// to extract the host.
+var linkPattern = regexp.MustCompile(`(?i)\bhttps?://[^\s<>"'()]+`)
+
+// PatternRule fires when the title or the text matches.
+type PatternRule struct {
+	Pattern *regexp.Regexp
+	Outcome Outcome
+	Reason  string
+}
+
+func (r *PatternRule) Apply(ctx context.Context, content *Content) (Outcome, string, error) {
+	if r.Pattern.MatchString(content.Title) || r.Pattern.MatchString(content.Text) {
+		return r.Outcome, r.Reason, nil
+	}
+	return OutcomeAllow, "", nil
+}
+
+// NewWordRule matches any of the words as a whole word, ignoring case.
+func NewWordRule(words []string, outcome Outcome) *PatternRule {
+	quoted := make([]string, 0, len(words))
+	for _, word := range words {
+		quoted = append(quoted, regexp.QuoteMeta(word))
+	}
+	return &PatternRule{
+		Pattern: regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`),
+		Outcome: outcome,
+		Reason:  "blocked word",
+	}
+}
+
+// DomainRule fires on links to a listed domain or any of its subdomains,
+// both in the post URL and inside the text.
+type DomainRule struct {
+	Domains map[string]bool
+	Outcome Outcome
+}
+
+func (r *DomainRule) Apply(ctx context.Context, content *Content) (Outcome, string, error) {
+	links := linkPattern.FindAllString(content.Text, -1)
+	if content.URL != "" {
+		links = append(links, content.URL)
+	}
+	for _, link := range links {
+		if domain := r.match(link); domain != "" {
+			return r.Outcome, "blocked domain " + domain, nil
+		}
+	}
+	return OutcomeAllow, "", nil
+}
+
+func (r *DomainRule) match(link string) string {
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	for host != "" {
+		if r.Domains[host] {
+			return host
+		}
+		_, parent, found := strings.Cut(host, ".")
+		if !found {
+			break
+		}
+		host = parent
+	}
+	return ""
+}
+
+// LinkIndex tells whether a link was already posted to a category.
+type LinkIndex interface {
+	LinkPosted(ctx context.Context, category, url string) (bool, error)
+}
+
+// DuplicateLinkRule fires when the post URL was already submitted to the
+// same category.
+type DuplicateLinkRule struct {
+	Links   LinkIndex
+	Outcome Outcome
+}
+
+func (r *DuplicateLinkRule) Apply(ctx context.Context, content *Content) (Outcome, string, error) {
+	if content.URL == "" || content.Category == "" {
+		return OutcomeAllow, "", nil
+	}
+	posted, err := r.Links.LinkPosted(ctx, content.Category, content.URL)
+	if err != nil {
+		return "", "", err
+	}
+	if posted {
+		return r.Outcome, "duplicate link", nil
+	}
+	return OutcomeAllow, "", nil
+}
+
+// CapsRule fires when more than MaxRatio of the letters are upper case.
+// Content with fewer than MinLetters letters is ignored, so short shouts
+// like "TIL" pass.
+type CapsRule struct {
+	MinLetters int
+	MaxRatio   float64
+	Outcome    Outcome
+}
+
+func (r *CapsRule) Apply(ctx context.Context, content *Content) (Outcome, string, error) {
+	letters, upper := 0, 0
+	for _, text := range []string{content.Title, content.Text} {
+		for _, c := range text {
+			if !unicode.IsLetter(c) {
+				continue
+			}
+			letters++
+			if unicode.IsUpper(c) {
+				upper++
+			}
+		}
+	}
+	if letters < r.MinLetters || letters == 0 {
+		return OutcomeAllow, "", nil
+	}
+	if float64(upper)/float64(letters) > r.MaxRatio {
+		return r.Outcome, "excessive capitals", nil
+	}
+	return OutcomeAllow, "", nil
+}
+
+// RepetitionRule fires on a character repeated more than MaxCharRun times
+// in a row or a word repeated more than MaxWordRun times in a row. A zero
+// limit disables that check.
+type RepetitionRule struct {
+	MaxCharRun int
+	MaxWordRun int
+	Outcome    Outcome
+}
+
+func (r *RepetitionRule) Apply(ctx context.Context, content *Content) (Outcome, string, error) {
+	for _, text := range []string{content.Title, content.Text} {
+		if r.MaxCharRun > 0 && longestCharRun(text) > r.MaxCharRun {
+			return r.Outcome, "repeated characters", nil
+		}
+		if r.MaxWordRun > 0 && longestWordRun(text) > r.MaxWordRun {
+			return r.Outcome, "repeated words", nil
+		}
+	}
+	return OutcomeAllow, "", nil
+}
+
+func longestCharRun(text string) int {
+	longest, run := 0, 0
+	var prev rune
+	for i, c := range text {
+		if i > 0 && c == prev && !unicode.IsSpace(c) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = c
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+func longestWordRun(text string) int {
+	longest, run := 0, 0
+	prev := ""
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		word = strings.TrimFunc(word, unicode.IsPunct)
+		if word != "" && word == prev {
+			run++
+		} else {
+			run = 1
+		}
+		prev = word
+		longest = max(longest, run)
+	}
+	return longest
+}
@@ -0,0 +1,73 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/health"
+)
+
+const (
+	statusOK           = "ok"
+	statusUnavailable  = "unavailable"
+	statusShuttingDown = "shutting down"
+)
+
+type Report struct {
+	Status string                    `json:"status"`
+	Checks map[string]*health.Result `json:"checks,omitempty"`
+}
+
+type HealthHandler struct {
+	Checks  []health.Check
+	Timeout time.Duration
+
+	shuttingDown atomic.Bool
+}
+
+// ShuttingDown makes the server report not ready from now on, so the
+// orchestrator stops routing to it while it drains.
+func (h *HealthHandler) ShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Live reports that the process is up and serving. It checks nothing else,
+// so a broken backend does not get the process restarted.
+func (h *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	WriteReport(r.Context(), w, &Report{Status: statusOK}, http.StatusOK)
+}
+
+// Ready reports whether the server can handle requests: every required
+// backend answers and shutdown has not started.
+func (h *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		WriteReport(r.Context(), w, &Report{Status: statusShuttingDown}, http.StatusServiceUnavailable)
+		return
+	}
+
+	results, ok := health.Run(r.Context(), h.Checks, h.Timeout)
+	if !ok {
+		WriteReport(r.Context(), w, &Report{Status: statusUnavailable, Checks: results}, http.StatusServiceUnavailable)
+		return
+	}
+	WriteReport(r.Context(), w, &Report{Status: statusOK, Checks: results}, http.StatusOK)
+}
+
+func WriteReport(ctx context.Context, w http.ResponseWriter, report *Report, status int) {
+	resp, err := json.Marshal(report)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if _, err = w.Write(resp); err != nil {
+		logrus.WithContext(ctx).WithError(err).Error("write health report failed")
+	}
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonstantinGalanin/redditclone/internal/health"
+)
+
+func ping(err error) func(context.Context) error {
+	return func(context.Context) error {
+		return err
+	}
+}
+
+func decode(t *testing.T, w *httptest.ResponseRecorder) *Report {
+	report := &Report{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), report))
+	return report
+}
+
+func TestLive(t *testing.T) {
+	handler := &HealthHandler{Checks: []health.Check{{Name: "mysql", Required: true, Ping: ping(errors.New("down"))}}}
+	w := httptest.NewRecorder()
+	handler.Live(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, statusOK, decode(t, w).Status)
+}
+
+func TestReady(t *testing.T) {
+	cases := []struct {
+		name       string
+		checks     []health.Check
+		statusCode int
+		status     string
+	}{
+		{
+			name: "ready",
+			checks: []health.Check{
+				{Name: "mysql", Required: true, Ping: ping(nil)},
+				{Name: "redis", Required: true, Ping: ping(nil)},
+			},
+			statusCode: http.StatusOK,
+			status:     statusOK,
+		},
+		{
+			name: "dependency down",
+			checks: []health.Check{
+				{Name: "mysql", Required: true, Ping: ping(nil)},
+				{Name: "redis", Required: true, Ping: ping(errors.New("connection refused"))},
+			},
+			statusCode: http.StatusServiceUnavailable,
+			status:     statusUnavailable,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := &HealthHandler{Checks: tc.checks}
+			w := httptest.NewRecorder()
+			handler.Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			assert.Equal(t, tc.statusCode, w.Code)
+			report := decode(t, w)
+			assert.Equal(t, tc.status, report.Status)
+			assert.Len(t, report.Checks, len(tc.checks))
+		})
+	}
+}
+
+func TestReadyReport(t *testing.T) {
+	handler := &HealthHandler{Checks: []health.Check{
+		{Name: "redis", Required: true, Ping: ping(errors.New("connection refused"))},
+	}}
+	w := httptest.NewRecorder()
+	handler.Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	redis := body["checks"].(map[string]interface{})["redis"].(map[string]interface{})
+	assert.Equal(t, "down", redis["status"])
+	assert.Equal(t, "connection refused", redis["error"])
+	assert.Equal(t, true, redis["required"])
+	assert.Contains(t, redis, "latencyMs")
+}
+
+func TestReadyShuttingDown(t *testing.T) {
+	handler := &HealthHandler{Checks: []health.Check{{Name: "mysql", Required: true, Ping: ping(nil)}}}
+	handler.ShuttingDown()
+
+	w := httptest.NewRecorder()
+	handler.Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, statusShuttingDown, decode(t, w).Status)
+}
@@ -0,0 +1,82 @@
+// Package health checks the backends the server depends on.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	DefaultTimeout = time.Second
+)
+
+// Check pings one dependency. A failing required check makes the server
+// not ready; an optional one is only reported.
+type Check struct {
+	Name     string
+	Required bool
+	Ping     func(ctx context.Context) error
+}
+
+type Result struct {
+	Status string `json:"status"`
+	// Latency is in milliseconds.
+	Latency  float64 `json:"latencyMs"`
+	Required bool    `json:"required"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// Run pings every dependency at once, each bounded by timeout, and reports
+// whether all required ones are up.
+func Run(ctx context.Context, checks []Check, timeout time.Duration) (map[string]*Result, bool) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	results := make(map[string]*Result, len(checks))
+	var (
+		mu sync.Mutex
+		wg sync.WaitGroup
+	)
+	for _, check := range checks {
+		wg.Add(1)
+		go func(check Check) {
+			defer wg.Done()
+			result := run(ctx, check, timeout)
+			mu.Lock()
+			results[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+	wg.Wait()
+
+	ok := true
+	for _, check := range checks {
+		if check.Required && results[check.Name].Status != StatusUp {
+			ok = false
+		}
+	}
+	return results, ok
+}
+
+func run(ctx context.Context, check Check, timeout time.Duration) *Result {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.Ping(ctx)
+	result := &Result{
+		Status:   StatusUp,
+		Latency:  float64(time.Since(start).Microseconds()) / 1000,
+		Required: check.Required,
+	}
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func ping(err error) func(context.Context) error {
+	return func(context.Context) error {
+		return err
+	}
+}
+
+func TestRun(t *testing.T) {
+	hang := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	cases := []struct {
+		name   string
+		checks []Check
+		ok     bool
+		status map[string]string
+	}{
+		{
+			name: "all up",
+			checks: []Check{
+				{Name: "mysql", Required: true, Ping: ping(nil)},
+				{Name: "redis", Required: true, Ping: ping(nil)},
+			},
+			ok:     true,
+			status: map[string]string{"mysql": StatusUp, "redis": StatusUp},
+		},
+		{
+			name: "required down",
+			checks: []Check{
+				{Name: "mysql", Required: true, Ping: ping(nil)},
+				{Name: "redis", Required: true, Ping: ping(errors.New("connection refused"))},
+			},
+			status: map[string]string{"mysql": StatusUp, "redis": StatusDown},
+		},
+		{
+			name: "optional down",
+			checks: []Check{
+				{Name: "mysql", Required: true, Ping: ping(nil)},
+				{Name: "s3", Ping: ping(errors.New("timeout"))},
+			},
+			ok:     true,
+			status: map[string]string{"mysql": StatusUp, "s3": StatusDown},
+		},
+		{
+			name:   "timeout",
+			checks: []Check{{Name: "mongodb", Required: true, Ping: hang}},
+			status: map[string]string{"mongodb": StatusDown},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			results, ok := Run(context.Background(), tc.checks, 20*time.Millisecond)
+			assert.Equal(t, tc.ok, ok)
+			assert.Len(t, results, len(tc.status))
+			for name, status := range tc.status {
+				assert.Equal(t, status, results[name].Status, name)
+				if status == StatusDown {
+					assert.NotEmpty(t, results[name].Error)
+				}
+			}
+		})
+	}
+}
+
+// Each ping waits for all of them to start, which only happens when they
+// run at the same time.
+func TestRunConcurrent(t *testing.T) {
+	var started sync.WaitGroup
+	started.Add(3)
+	barrier := func(ctx context.Context) error {
+		started.Done()
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	checks := []Check{
+		{Name: "a", Required: true, Ping: barrier},
+		{Name: "b", Required: true, Ping: barrier},
+		{Name: "c", Required: true, Ping: barrier},
+	}
+
+	_, ok := Run(context.Background(), checks, time.Second)
+	assert.True(t, ok)
+}
@@ -0,0 +1,95 @@
+package images
+
+import (
+	"encoding/binary"
+	"errors"
+	"image"
+)
+
+var errBadGIF = errors.New("malformed gif")
+
+// gifFrames walks the blocks of a GIF without decompressing any pixel data
+// and returns the bounds of every frame, so an animation can be checked
+// before gif.DecodeAll allocates all of its frames.
+func gifFrames(data []byte) ([]image.Rectangle, error) {
+	s := &gifScanner{data: data}
+	header := s.next(13)
+	if header == nil {
+		return nil, errBadGIF
+	}
+	if header[10]&0x80 != 0 {
+		s.next(colorTableSize(header[10]))
+	}
+
+	var frames []image.Rectangle
+	for {
+		block := s.next(1)
+		if block == nil {
+			return nil, errBadGIF
+		}
+		switch block[0] {
+		case 0x21: // extension: a label and data sub-blocks
+			if s.next(1) == nil || !s.skipSubBlocks() {
+				return nil, errBadGIF
+			}
+		case 0x2C: // image descriptor, color table, LZW code size, sub-blocks
+			desc := s.next(9)
+			if desc == nil {
+				return nil, errBadGIF
+			}
+			left := int(binary.LittleEndian.Uint16(desc[0:]))
+			top := int(binary.LittleEndian.Uint16(desc[2:]))
+			width := int(binary.LittleEndian.Uint16(desc[4:]))
+			height := int(binary.LittleEndian.Uint16(desc[6:]))
+			frames = append(frames, image.Rect(left, top, left+width, top+height))
+			if desc[8]&0x80 != 0 {
+				s.next(colorTableSize(desc[8]))
+			}
+			if s.next(1) == nil || !s.skipSubBlocks() {
+				return nil, errBadGIF
+			}
+		case 0x3B: // trailer
+			return frames, nil
+		default:
+			return nil, errBadGIF
+		}
+	}
+}
+
+// colorTableSize is the size in bytes of the color table announced by the
+// packed field of a screen or image descriptor.
+func colorTableSize(packed byte) int {
+	return 3 << ((packed & 0x07) + 1)
+}
+
+type gifScanner struct {
+	data []byte
+	pos  int
+}
+
+// next returns the following n bytes, or nil if the data ends first.
+func (s *gifScanner) next(n int) []byte {
+	if n > len(s.data)-s.pos {
+		s.pos = len(s.data)
+		return nil
+	}
+	b := s.data[s.pos : s.pos+n]
+	s.pos += n
+	return b
+}
+
+// skipSubBlocks skips length-prefixed sub-blocks up to the empty terminator.
+func (s *gifScanner) skipSubBlocks() bool {
+	for {
+		size := s.next(1)
+		if size == nil {
+			return false
+		}
+		if size[0] == 0 {
+			return true
+		}
+		if s.next(int(size[0])) == nil {
+			return false
+		}
+	}
+}
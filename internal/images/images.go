@@ -0,0 +1,241 @@
+package images
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"golang.org/x/image/draw"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+)
+
+const (
+	TypeJPEG = "image/jpeg"
+	TypePNG  = "image/png"
+	TypeGIF  = "image/gif"
+
+	jpegQuality = 90
+	sniffLen    = 512
+)
+
+var extensions = map[string]string{
+	TypeJPEG: ".jpg",
+	TypePNG:  ".png",
+	TypeGIF:  ".gif",
+}
+
+// Limits bound an upload. MaxFrames and MaxPixels cap what decoding an
+// animated GIF may allocate, summed over its frames; zero uses the default.
+type Limits struct {
+	MaxBytes      int64
+	MaxWidth      int
+	MaxHeight     int
+	MaxFrames     int
+	MaxPixels     int
+	ThumbnailSize int
+}
+
+var DefaultLimits = Limits{
+	MaxBytes:      10 << 20,
+	MaxWidth:      8000,
+	MaxHeight:     8000,
+	MaxFrames:     500,
+	MaxPixels:     100_000_000,
+	ThumbnailSize: 320,
+}
+
+// Encoded is an image re-encoded from its decoded pixels, which drops EXIF
+// and every other metadata block the upload carried.
+type Encoded struct {
+	Data        []byte
+	ContentType string
+	Width       int
+	Height      int
+}
+
+func (e *Encoded) Ext() string {
+	return extensions[e.ContentType]
+}
+
+// Process validates an upload and returns the cleaned image together with a
+// thumbnail that fits in a ThumbnailSize square.
+func Process(r io.Reader, limits Limits) (*Encoded, *Encoded, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limits.MaxBytes+1))
+	if err != nil {
+		return nil, nil, fmt.Errorf("process image: %w", err)
+	}
+	if int64(len(data)) > limits.MaxBytes {
+		return nil, nil, myerrors.ErrImageTooLarge
+	}
+
+	contentType := http.DetectContentType(data[:min(len(data), sniffLen)])
+	if _, ok := extensions[contentType]; !ok {
+		return nil, nil, myerrors.ErrBadImageType
+	}
+
+	// Check the header before decoding so a small file cannot claim a huge
+	// canvas and exhaust memory.
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, myerrors.ErrBadImageType
+	}
+	if config.Width <= 0 || config.Height <= 0 || config.Width > limits.MaxWidth || config.Height > limits.MaxHeight {
+		return nil, nil, myerrors.ErrImageTooLarge
+	}
+
+	var original *Encoded
+	var first image.Image
+	if contentType == TypeGIF {
+		original, first, err = reencodeGIF(data, limits)
+	} else {
+		original, first, err = reencode(data, contentType)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	thumbnail, err := encode(thumbnail(first, limits.ThumbnailSize), thumbnailType(contentType))
+	if err != nil {
+		return nil, nil, err
+	}
+	return original, thumbnail, nil
+}
+
+func reencode(data []byte, contentType string) (*Encoded, image.Image, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, myerrors.ErrBadImageType
+	}
+	encoded, err := encode(img, contentType)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encoded, img, nil
+}
+
+// reencodeGIF keeps every frame of an animation. The frames are checked
+// against limits before any of them is decoded, since a few megabytes of
+// compressed GIF can expand to gigabytes of frames.
+func reencodeGIF(data []byte, limits Limits) (*Encoded, image.Image, error) {
+	if err := checkGIF(data, limits); err != nil {
+		return nil, nil, err
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil || len(anim.Image) == 0 {
+		return nil, nil, myerrors.ErrBadImageType
+	}
+	var buf bytes.Buffer
+	if err = gif.EncodeAll(&buf, anim); err != nil {
+		return nil, nil, fmt.Errorf("encode gif: %w", err)
+	}
+	encoded := &Encoded{
+		Data:        buf.Bytes(),
+		ContentType: TypeGIF,
+		Width:       anim.Config.Width,
+		Height:      anim.Config.Height,
+	}
+	return encoded, firstFrame(anim), nil
+}
+
+// checkGIF rejects animations with too many frames, too many pixels in total
+// or frames outside the canvas.
+func checkGIF(data []byte, limits Limits) error {
+	maxFrames, maxPixels := limits.MaxFrames, limits.MaxPixels
+	if maxFrames <= 0 {
+		maxFrames = DefaultLimits.MaxFrames
+	}
+	if maxPixels <= 0 {
+		maxPixels = DefaultLimits.MaxPixels
+	}
+
+	config, err := gif.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return myerrors.ErrBadImageType
+	}
+	frames, err := gifFrames(data)
+	if err != nil || len(frames) == 0 {
+		return myerrors.ErrBadImageType
+	}
+	if len(frames) > maxFrames {
+		return myerrors.ErrImageTooLarge
+	}
+	canvas := image.Rect(0, 0, config.Width, config.Height)
+	pixels := 0
+	for _, frame := range frames {
+		if !frame.In(canvas) {
+			return myerrors.ErrBadImageType
+		}
+		pixels += frame.Dx() * frame.Dy()
+		if pixels > maxPixels {
+			return myerrors.ErrImageTooLarge
+		}
+	}
+	return nil
+}
+
+// firstFrame draws the first frame of anim onto the full canvas; the frame
+// itself may cover only part of it.
+func firstFrame(anim *gif.GIF) image.Image {
+	frame := anim.Image[0]
+	canvas := image.NewRGBA(image.Rect(0, 0, anim.Config.Width, anim.Config.Height))
+	draw.Draw(canvas, frame.Bounds(), frame, frame.Bounds().Min, draw.Src)
+	return canvas
+}
+
+func encode(img image.Image, contentType string) (*Encoded, error) {
+	var buf bytes.Buffer
+	var err error
+	switch contentType {
+	case TypeJPEG:
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case TypePNG:
+		err = png.Encode(&buf, img)
+	default:
+		return nil, myerrors.ErrBadImageType
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode image: %w", err)
+	}
+	bounds := img.Bounds()
+	return &Encoded{
+		Data:        buf.Bytes(),
+		ContentType: contentType,
+		Width:       bounds.Dx(),
+		Height:      bounds.Dy(),
+	}, nil
+}
+
+// thumbnailType keeps transparency for formats that may have it.
+func thumbnailType(contentType string) string {
+	if contentType == TypeJPEG {
+		return TypeJPEG
+	}
+	return TypePNG
+}
+
+// thumbnail scales img down to fit in a size x size square, keeping the
+// aspect ratio. Smaller images are returned unchanged.
+func thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width <= size && height <= size {
+		return img
+	}
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
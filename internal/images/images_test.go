@@ -0,0 +1,217 @@
+package images
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+)
+
+func newImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 100, A: 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+// withEXIF inserts an APP1 segment carrying EXIF data right after the SOI
+// marker, the way cameras write it.
+func withEXIF(data []byte) []byte {
+	payload := append([]byte("Exif\x00\x00"), []byte("GPS 55.75N 37.61E")...)
+	segment := []byte{0xFF, 0xE1, byte((len(payload) + 2) >> 8), byte(len(payload) + 2)}
+	segment = append(segment, payload...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, segment...)
+	return append(out, data[2:]...)
+}
+
+func TestProcessJPEG(t *testing.T) {
+	data := withEXIF(encodeJPEG(t, newImage(640, 480)))
+	require.True(t, bytes.Contains(data, []byte("Exif")))
+
+	original, thumb, err := Process(bytes.NewReader(data), DefaultLimits)
+	require.NoError(t, err)
+
+	assert.Equal(t, TypeJPEG, original.ContentType)
+	assert.Equal(t, ".jpg", original.Ext())
+	assert.Equal(t, 640, original.Width)
+	assert.Equal(t, 480, original.Height)
+	assert.False(t, bytes.Contains(original.Data, []byte("Exif")))
+	assert.False(t, bytes.Contains(original.Data, []byte("GPS")))
+
+	assert.Equal(t, TypeJPEG, thumb.ContentType)
+	assert.Equal(t, 320, thumb.Width)
+	assert.Equal(t, 240, thumb.Height)
+	decoded, err := jpeg.Decode(bytes.NewReader(thumb.Data))
+	require.NoError(t, err)
+	assert.Equal(t, image.Rect(0, 0, 320, 240), decoded.Bounds())
+}
+
+func TestProcessPNG(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, newImage(100, 400)))
+
+	original, thumb, err := Process(&buf, DefaultLimits)
+	require.NoError(t, err)
+	assert.Equal(t, TypePNG, original.ContentType)
+	assert.Equal(t, TypePNG, thumb.ContentType)
+	assert.Equal(t, 80, thumb.Width)
+	assert.Equal(t, 320, thumb.Height)
+}
+
+func TestProcessGIF(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{
+		Image: []*image.Paletted{
+			image.NewPaletted(image.Rect(0, 0, 10, 10), palette),
+			image.NewPaletted(image.Rect(0, 0, 10, 10), palette),
+		},
+		Delay: []int{10, 10},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+
+	original, thumb, err := Process(&buf, DefaultLimits)
+	require.NoError(t, err)
+	assert.Equal(t, TypeGIF, original.ContentType)
+	decoded, err := gif.DecodeAll(bytes.NewReader(original.Data))
+	require.NoError(t, err)
+	assert.Len(t, decoded.Image, 2)
+
+	// Small images are not scaled up.
+	assert.Equal(t, TypePNG, thumb.ContentType)
+	assert.Equal(t, 10, thumb.Width)
+}
+
+func TestProcessErrors(t *testing.T) {
+	jpegData := encodeJPEG(t, newImage(64, 64))
+
+	cases := []struct {
+		name        string
+		data        []byte
+		limits      Limits
+		expectedErr error
+	}{
+		{
+			name:        "too many bytes",
+			data:        jpegData,
+			limits:      Limits{MaxBytes: 10, MaxWidth: 100, MaxHeight: 100, ThumbnailSize: 10},
+			expectedErr: myerrors.ErrImageTooLarge,
+		},
+		{
+			name:        "not an image",
+			data:        []byte("<html><script>alert(1)</script></html>"),
+			limits:      DefaultLimits,
+			expectedErr: myerrors.ErrBadImageType,
+		},
+		{
+			name:        "truncated",
+			data:        jpegData[:20],
+			limits:      DefaultLimits,
+			expectedErr: myerrors.ErrBadImageType,
+		},
+		{
+			name:        "too wide",
+			data:        jpegData,
+			limits:      Limits{MaxBytes: DefaultLimits.MaxBytes, MaxWidth: 32, MaxHeight: 100, ThumbnailSize: 10},
+			expectedErr: myerrors.ErrImageTooLarge,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			original, thumb, err := Process(bytes.NewReader(c.data), c.limits)
+			assert.ErrorIs(t, err, c.expectedErr)
+			assert.Nil(t, original)
+			assert.Nil(t, thumb)
+		})
+	}
+}
+
+func encodeGIF(t *testing.T, frames ...image.Rectangle) []byte {
+	palette := color.Palette{color.Black, color.White}
+	anim := &gif.GIF{Config: image.Config{Width: 20, Height: 20, ColorModel: palette}}
+	for _, frame := range frames {
+		anim.Image = append(anim.Image, image.NewPaletted(frame, palette))
+		anim.Delay = append(anim.Delay, 10)
+	}
+	var buf bytes.Buffer
+	require.NoError(t, gif.EncodeAll(&buf, anim))
+	return buf.Bytes()
+}
+
+func TestProcessGIFLimits(t *testing.T) {
+	full := image.Rect(0, 0, 20, 20)
+	// Shrink the canvas in the screen descriptor below the frame bounds.
+	outside := encodeGIF(t, full)
+	outside[6] = 10
+
+	cases := []struct {
+		name        string
+		data        []byte
+		limits      Limits
+		expectedErr error
+	}{
+		{
+			name:        "too many frames",
+			data:        encodeGIF(t, full, full, full),
+			limits:      Limits{MaxBytes: DefaultLimits.MaxBytes, MaxWidth: 100, MaxHeight: 100, MaxFrames: 2, ThumbnailSize: 10},
+			expectedErr: myerrors.ErrImageTooLarge,
+		},
+		{
+			name:        "too many pixels",
+			data:        encodeGIF(t, full, full),
+			limits:      Limits{MaxBytes: DefaultLimits.MaxBytes, MaxWidth: 100, MaxHeight: 100, MaxPixels: 600, ThumbnailSize: 10},
+			expectedErr: myerrors.ErrImageTooLarge,
+		},
+		{
+			name:        "frame outside the canvas",
+			data:        outside,
+			limits:      DefaultLimits,
+			expectedErr: myerrors.ErrBadImageType,
+		},
+		{
+			name:        "truncated",
+			data:        encodeGIF(t, full)[:30],
+			limits:      DefaultLimits,
+			expectedErr: myerrors.ErrBadImageType,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			original, thumb, err := Process(bytes.NewReader(c.data), c.limits)
+			assert.ErrorIs(t, err, c.expectedErr)
+			assert.Nil(t, original)
+			assert.Nil(t, thumb)
+		})
+	}
+}
+
+func TestProcessGIFPartialFrame(t *testing.T) {
+	data := encodeGIF(t, image.Rect(5, 5, 10, 10), image.Rect(0, 0, 20, 20))
+
+	original, thumb, err := Process(bytes.NewReader(data), DefaultLimits)
+	require.NoError(t, err)
+	assert.Equal(t, 20, original.Width)
+	assert.Equal(t, 20, thumb.Width)
+	assert.Equal(t, 20, thumb.Height)
+}
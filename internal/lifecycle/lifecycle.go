@@ -0,0 +1,98 @@
+// Package lifecycle runs the background workers of the server and shuts
+// everything down in order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Workers runs background jobs that stop when their context is done.
+type Workers struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorkers() *Workers {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Workers{ctx: ctx, cancel: cancel}
+}
+
+// Go starts run in its own goroutine.
+func (w *Workers) Go(run func(ctx context.Context)) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		run(w.ctx)
+	}()
+}
+
+// Stop cancels the workers and waits for them to return, or for ctx to be
+// done.
+func (w *Workers) Stop(ctx context.Context) error {
+	w.cancel()
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Sleep waits for d or until ctx is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Step is one stage of the shutdown.
+type Step struct {
+	Name string
+	Stop func(ctx context.Context) error
+}
+
+// Closer adapts a Close method to a Step.
+func Closer(close func() error) func(context.Context) error {
+	return func(context.Context) error {
+		return close()
+	}
+}
+
+// Shutdown runs the steps in order, all sharing the deadline of ctx. A
+// failed step is logged and the rest still run, so connections get closed
+// even when draining took too long.
+func Shutdown(ctx context.Context, steps ...Step) error {
+	var errs []error
+	for _, step := range steps {
+		log := logrus.WithFields(logrus.Fields{"type": "STOP", "step": step.Name})
+		log.Info("stopping")
+		start := time.Now()
+		if err := step.Stop(ctx); err != nil {
+			log.WithError(err).Error("stop failed")
+			errs = append(errs, fmt.Errorf("%s: %w", step.Name, err))
+			continue
+		}
+		log.WithField("work_time", time.Since(start)).Info("stopped")
+	}
+	return errors.Join(errs...)
+}
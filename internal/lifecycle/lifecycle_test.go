@@ -0,0 +1,72 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkersStop(t *testing.T) {
+	workers := NewWorkers()
+	stopped := make(chan struct{})
+	workers.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	assert.NoError(t, workers.Stop(context.Background()))
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("worker still running after Stop")
+	}
+}
+
+func TestWorkersStopDeadline(t *testing.T) {
+	workers := NewWorkers()
+	release := make(chan struct{})
+	defer close(release)
+	workers.Go(func(context.Context) {
+		<-release
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, workers.Stop(ctx), context.DeadlineExceeded)
+}
+
+func TestSleep(t *testing.T) {
+	assert.NoError(t, Sleep(context.Background(), 0))
+	assert.NoError(t, Sleep(context.Background(), time.Millisecond))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, Sleep(ctx, time.Hour), context.Canceled)
+}
+
+func TestShutdown(t *testing.T) {
+	var order []string
+	step := func(name string, err error) Step {
+		return Step{Name: name, Stop: func(context.Context) error {
+			order = append(order, name)
+			return err
+		}}
+	}
+	errClose := errors.New("close failed")
+
+	err := Shutdown(context.Background(),
+		step("http", nil),
+		step("mysql", errClose),
+		Step{Name: "redis", Stop: Closer(func() error {
+			order = append(order, "redis")
+			return nil
+		})},
+	)
+
+	assert.Equal(t, []string{"http", "mysql", "redis"}, order)
+	assert.ErrorIs(t, err, errClose)
+	assert.ErrorContains(t, err, "mysql: close failed")
+}
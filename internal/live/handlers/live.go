@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/live"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+)
+
+const (
+	fieldPostID      = "id"
+	fieldCategory    = "category"
+	headerLastEvent  = "Last-Event-ID"
+	queryLastEvent   = "lastEventId"
+	DefaultHeartbeat = 15 * time.Second
+	retryMillis      = 3000
+)
+
+type LiveHandler struct {
+	Hub       *live.Hub
+	Heartbeat time.Duration
+}
+
+// PostStream streams comment and score updates for one post.
+func (h *LiveHandler) PostStream(w http.ResponseWriter, r *http.Request) {
+	postID := mux.Vars(r)[fieldPostID]
+	if postID == "" {
+		http.Error(w, myerrors.ErrEmptyPostID.Error(), http.StatusBadRequest)
+		return
+	}
+	h.stream(w, r, live.PostTopic(postID))
+}
+
+// CategoryStream streams updates for every post in a category.
+func (h *LiveHandler) CategoryStream(w http.ResponseWriter, r *http.Request) {
+	category := mux.Vars(r)[fieldCategory]
+	if category == "" {
+		http.Error(w, myerrors.ErrEmptyCategory.Error(), http.StatusBadRequest)
+		return
+	}
+	h.stream(w, r, live.CategoryTopic(category))
+}
+
+func (h *LiveHandler) stream(w http.ResponseWriter, r *http.Request, topic string) {
+	rc := http.NewResponseController(w)
+	// Streams outlive the server's write timeout; errors mean the writer
+	// has no deadline to clear.
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	lastEventID := r.Header.Get(headerLastEvent)
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get(queryLastEvent)
+	}
+	sub := h.Hub.Subscribe(topic, lastEventID)
+	defer h.Hub.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", retryMillis); err != nil {
+		return
+	}
+	for _, msg := range sub.Replay {
+		if err := writeMessage(w, msg); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := h.Heartbeat
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+	ticker := time.NewTicker(heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case msg, ok := <-sub.C:
+			if !ok {
+				// Dropped for falling behind or by shutdown; the client
+				// reconnects with Last-Event-ID and catches up from the
+				// history.
+				return
+			}
+			if err := writeMessage(w, msg); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func writeMessage(w http.ResponseWriter, msg *live.Message) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID, msg.Type, msg.Data)
+	return err
+}
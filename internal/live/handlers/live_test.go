@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/live"
+)
+
+func newServer(hub *live.Hub) *httptest.Server {
+	handler := &LiveHandler{Hub: hub, Heartbeat: 20 * time.Millisecond}
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{id}/stream", handler.PostStream)
+	router.HandleFunc("/api/posts/{category}/stream", handler.CategoryStream)
+	return httptest.NewServer(router)
+}
+
+func readLines(t *testing.T, reader *bufio.Reader, n int) []string {
+	lines := make([]string, 0, n)
+	for len(lines) < n {
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		lines = append(lines, strings.TrimSuffix(line, "\n"))
+	}
+	return lines
+}
+
+func TestPostStream(t *testing.T) {
+	hub := live.NewHub(0, 0)
+	server := newServer(hub)
+	defer server.Close()
+
+	topic := live.PostTopic("1")
+	hub.Dispatch(&live.Message{ID: "a", Type: events.CommentCreated, Topic: topic, Data: json.RawMessage(`{"n":1}`)})
+	hub.Dispatch(&live.Message{ID: "b", Type: events.ScoreChanged, Topic: topic, Data: json.RawMessage(`{"n":2}`)})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/post/1/stream", nil)
+	assert.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "a")
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(res.Body)
+	assert.Equal(t, []string{"retry: 3000", "", "id: b", "event: score-changed", `data: {"n":2}`, ""}, readLines(t, reader, 6))
+
+	hub.Dispatch(&live.Message{ID: "c", Type: events.CommentDeleted, Topic: topic, Data: json.RawMessage(`{"n":3}`)})
+	lines := readLines(t, reader, 2)
+	for lines[0] == ": heartbeat" {
+		lines = readLines(t, reader, 2)
+	}
+	assert.Equal(t, []string{"id: c", "event: comment-deleted"}, lines)
+
+	cancel()
+	assert.Eventually(t, func() bool { return hub.Subscribers(topic) == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestCategoryStreamHeartbeat(t *testing.T) {
+	hub := live.NewHub(0, 0)
+	server := newServer(hub)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/api/posts/music/stream?lastEventId=unknown")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	reader := bufio.NewReader(res.Body)
+	assert.Equal(t, []string{"retry: 3000", "", ": heartbeat", ""}, readLines(t, reader, 4))
+	assert.Equal(t, 1, hub.Subscribers(live.CategoryTopic("music")))
+}
+
+func TestStreamEmptyVars(t *testing.T) {
+	handler := &LiveHandler{Hub: live.NewHub(0, 0)}
+
+	recorder := httptest.NewRecorder()
+	handler.PostStream(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.CategoryStream(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
@@ -0,0 +1,94 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+type commentCreated struct {
+	PostID  string         `json:"postId"`
+	Comment *posts.Comment `json:"comment"`
+}
+
+type commentDeleted struct {
+	PostID    string `json:"postId"`
+	CommentID string `json:"commentId"`
+}
+
+type commentScoreChanged struct {
+	PostID    string `json:"postId"`
+	CommentID string `json:"commentId"`
+	Score     int    `json:"score"`
+}
+
+type scoreChanged struct {
+	PostID           string `json:"postId"`
+	Score            int    `json:"score"`
+	UpvotePercentage int    `json:"upvotePercentage"`
+}
+
+// Listener turns post events into live messages for the post's own stream
+// and for its category feed.
+type Listener struct {
+	Publisher Publisher
+}
+
+func (l *Listener) Handle(ctx context.Context, event *events.Event) {
+	var data interface{}
+	switch event.Type {
+	case events.CommentCreated:
+		if event.Comment != nil {
+			event.Comment.Render()
+		}
+		data = &commentCreated{PostID: event.PostID, Comment: event.Comment}
+	case events.CommentDeleted:
+		data = &commentDeleted{PostID: event.PostID, CommentID: event.CommentID}
+	case events.ScoreChanged:
+		if event.CommentID != "" && event.Comment != nil {
+			data = &commentScoreChanged{
+				PostID:    event.PostID,
+				CommentID: event.CommentID,
+				Score:     event.Comment.Score,
+			}
+			break
+		}
+		if event.Post == nil {
+			return
+		}
+		data = &scoreChanged{
+			PostID:           event.PostID,
+			Score:            event.Post.Score,
+			UpvotePercentage: event.Post.UpvotePercentage,
+		}
+	default:
+		return
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Error("marshal live message failed")
+		return
+	}
+
+	topics := []string{PostTopic(event.PostID)}
+	if event.Post != nil && event.Post.Category != "" {
+		topics = append(topics, CategoryTopic(event.Post.Category))
+	}
+	for _, topic := range topics {
+		msg := &Message{
+			ID:    uuid.New().String(),
+			Type:  event.Type,
+			Topic: topic,
+			Data:  payload,
+		}
+		if err = l.Publisher.Publish(msg); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("topic", topic).Error("publish live message failed")
+		}
+	}
+}
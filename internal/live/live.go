@@ -0,0 +1,212 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultHistory = 100
+	DefaultBuffer  = 16
+)
+
+type Message struct {
+	ID    string          `json:"id"`
+	Type  string          `json:"type"`
+	Topic string          `json:"topic"`
+	Data  json.RawMessage `json:"data"`
+}
+
+func PostTopic(postID string) string {
+	return "post:" + postID
+}
+
+func CategoryTopic(category string) string {
+	return "category:" + category
+}
+
+// Publisher sends a message to every subscriber of its topic, possibly on
+// other server instances.
+type Publisher interface {
+	Publish(msg *Message) error
+}
+
+// Subscription receives the messages of one topic. C is closed when the
+// subscriber falls too far behind and is dropped by the hub, or when the
+// hub is closed.
+type Subscription struct {
+	Topic  string
+	Replay []*Message
+	C      <-chan *Message
+
+	c chan *Message
+}
+
+type topic struct {
+	subscribers map[*Subscription]struct{}
+	history     []*Message
+	updated     time.Time
+}
+
+// Hub fans messages out to the subscribers connected to this instance and
+// keeps a short per-topic history so reconnecting clients can catch up.
+type Hub struct {
+	mu      sync.Mutex
+	topics  map[string]*topic
+	history int
+	buffer  int
+	closed  bool
+}
+
+func NewHub(history, buffer int) *Hub {
+	if history <= 0 {
+		history = DefaultHistory
+	}
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{
+		topics:  make(map[string]*topic),
+		history: history,
+		buffer:  buffer,
+	}
+}
+
+func (h *Hub) getTopic(name string) *topic {
+	t, ok := h.topics[name]
+	if !ok {
+		t = &topic{
+			subscribers: make(map[*Subscription]struct{}),
+			updated:     time.Now(),
+		}
+		h.topics[name] = t
+	}
+	return t
+}
+
+// Subscribe registers a subscriber. When lastEventID is found in the topic
+// history, the messages after it are returned in Replay.
+func (h *Hub) Subscribe(topicName, lastEventID string) *Subscription {
+	c := make(chan *Message, h.buffer)
+	sub := &Subscription{
+		Topic: topicName,
+		C:     c,
+		c:     c,
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(c)
+		return sub
+	}
+	t := h.getTopic(topicName)
+	if lastEventID != "" {
+		for i, msg := range t.history {
+			if msg.ID == lastEventID {
+				sub.Replay = append(sub.Replay, t.history[i+1:]...)
+				break
+			}
+		}
+	}
+	t.subscribers[sub] = struct{}{}
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t, ok := h.topics[sub.Topic]
+	if !ok {
+		return
+	}
+	if _, ok = t.subscribers[sub]; ok {
+		delete(t.subscribers, sub)
+		close(sub.c)
+	}
+	t.updated = time.Now()
+}
+
+// Close drops every subscriber, which ends their streams, and refuses new
+// ones. It is called on shutdown so open streams do not hold up draining.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, t := range h.topics {
+		for sub := range t.subscribers {
+			delete(t.subscribers, sub)
+			close(sub.c)
+		}
+	}
+}
+
+// Dispatch delivers msg to local subscribers without blocking. Subscribers
+// whose buffer is full are dropped so one slow client cannot stall the rest.
+func (h *Hub) Dispatch(msg *Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	t := h.getTopic(msg.Topic)
+	t.history = append(t.history, msg)
+	if len(t.history) > h.history {
+		t.history = t.history[len(t.history)-h.history:]
+	}
+	t.updated = time.Now()
+
+	for sub := range t.subscribers {
+		select {
+		case sub.c <- msg:
+		default:
+			delete(t.subscribers, sub)
+			close(sub.c)
+		}
+	}
+}
+
+// Publish delivers msg to this instance only. It lets the hub act as a
+// Publisher when there is a single server.
+func (h *Hub) Publish(msg *Message) error {
+	h.Dispatch(msg)
+	return nil
+}
+
+// Cleanup forgets topics that have had no subscribers and no messages for
+// longer than idle.
+func (h *Hub) Cleanup(idle time.Duration, now time.Time) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	removed := 0
+	for name, t := range h.topics {
+		if len(t.subscribers) == 0 && now.Sub(t.updated) > idle {
+			delete(h.topics, name)
+			removed++
+		}
+	}
+	return removed
+}
+
+func (h *Hub) Subscribers(topicName string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if t, ok := h.topics[topicName]; ok {
+		return len(t.subscribers)
+	}
+	return 0
+}
+
+// RunCleanup calls Cleanup every interval until ctx is done.
+func (h *Hub) RunCleanup(ctx context.Context, interval, idle time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			h.Cleanup(idle, now)
+		}
+	}
+}
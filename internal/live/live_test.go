@@ -0,0 +1,123 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+func message(id, topic string) *Message {
+	return &Message{ID: id, Type: events.ScoreChanged, Topic: topic, Data: json.RawMessage(`{}`)}
+}
+
+func TestHubDispatch(t *testing.T) {
+	hub := NewHub(0, 0)
+	topic := PostTopic("1")
+	sub := hub.Subscribe(topic, "")
+	other := hub.Subscribe(PostTopic("2"), "")
+
+	hub.Dispatch(message("a", topic))
+	assert.Equal(t, "a", (<-sub.C).ID)
+	assert.Len(t, other.C, 0)
+
+	hub.Unsubscribe(sub)
+	_, ok := <-sub.C
+	assert.False(t, ok)
+	assert.Equal(t, 0, hub.Subscribers(topic))
+	hub.Unsubscribe(sub)
+}
+
+func TestHubReplay(t *testing.T) {
+	hub := NewHub(2, 1)
+	topic := CategoryTopic("music")
+	for _, id := range []string{"a", "b", "c"} {
+		assert.NoError(t, hub.Publish(message(id, topic)))
+	}
+
+	sub := hub.Subscribe(topic, "b")
+	assert.Len(t, sub.Replay, 1)
+	assert.Equal(t, "c", sub.Replay[0].ID)
+
+	// "a" fell out of the history, so there is nothing to resume from.
+	assert.Empty(t, hub.Subscribe(topic, "a").Replay)
+	assert.Empty(t, hub.Subscribe(topic, "").Replay)
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	hub := NewHub(10, 1)
+	topic := PostTopic("1")
+	sub := hub.Subscribe(topic, "")
+
+	hub.Dispatch(message("a", topic))
+	hub.Dispatch(message("b", topic))
+
+	assert.Equal(t, "a", (<-sub.C).ID)
+	_, ok := <-sub.C
+	assert.False(t, ok)
+	assert.Equal(t, 0, hub.Subscribers(topic))
+}
+
+func TestHubClose(t *testing.T) {
+	hub := NewHub(0, 0)
+	topic := PostTopic("1")
+	sub := hub.Subscribe(topic, "")
+
+	hub.Close()
+	_, ok := <-sub.C
+	assert.False(t, ok)
+	assert.Equal(t, 0, hub.Subscribers(topic))
+	hub.Unsubscribe(sub)
+
+	late := hub.Subscribe(topic, "")
+	_, ok = <-late.C
+	assert.False(t, ok)
+	hub.Unsubscribe(late)
+}
+
+func TestHubCleanup(t *testing.T) {
+	hub := NewHub(0, 0)
+	hub.Dispatch(message("a", PostTopic("1")))
+	sub := hub.Subscribe(PostTopic("2"), "")
+
+	now := time.Now().Add(time.Hour)
+	assert.Equal(t, 1, hub.Cleanup(time.Minute, now))
+	assert.Empty(t, hub.Subscribe(PostTopic("1"), "a").Replay)
+	assert.Equal(t, 1, hub.Subscribers(sub.Topic))
+}
+
+func TestListener(t *testing.T) {
+	hub := NewHub(0, 0)
+	listener := &Listener{Publisher: hub}
+	post := &posts.Post{ID: "1", Category: "music", Score: 3, UpvotePercentage: 100}
+	postSub := hub.Subscribe(PostTopic("1"), "")
+	categorySub := hub.Subscribe(CategoryTopic("music"), "")
+
+	listener.Handle(context.Background(), &events.Event{Type: events.ScoreChanged, PostID: "1", Post: post})
+	listener.Handle(context.Background(), &events.Event{Type: events.CommentDeleted, PostID: "1", CommentID: "2", Post: post})
+	listener.Handle(context.Background(), &events.Event{Type: events.CommentCreated, PostID: "1", Post: post, Comment: &posts.Comment{ID: "3", Body: "hi"}})
+	listener.Handle(context.Background(), &events.Event{Type: events.ScoreChanged, PostID: "1", CommentID: "3", Post: post, Comment: &posts.Comment{ID: "3", Score: -1}})
+	listener.Handle(context.Background(), &events.Event{Type: events.PostCreated, PostID: "1", Post: post})
+	listener.Handle(context.Background(), &events.Event{Type: events.ScoreChanged, PostID: "1"})
+
+	msg := <-postSub.C
+	assert.Equal(t, events.ScoreChanged, msg.Type)
+	assert.JSONEq(t, `{"postId":"1","score":3,"upvotePercentage":100}`, string(msg.Data))
+	msg = <-postSub.C
+	assert.Equal(t, events.CommentDeleted, msg.Type)
+	assert.JSONEq(t, `{"postId":"1","commentId":"2"}`, string(msg.Data))
+	msg = <-postSub.C
+	assert.Equal(t, events.CommentCreated, msg.Type)
+	assert.Contains(t, string(msg.Data), `"bodyHtml":"\u003cp\u003ehi\u003c/p\u003e\n"`)
+	msg = <-postSub.C
+	assert.JSONEq(t, `{"postId":"1","commentId":"3","score":-1}`, string(msg.Data))
+	assert.Len(t, postSub.C, 0)
+
+	assert.Len(t, categorySub.C, 4)
+	assert.NotEqual(t, msg.ID, (<-categorySub.C).ID)
+}
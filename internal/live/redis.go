@@ -0,0 +1,119 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gomodule/redigo/redis"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultChannel = "live"
+
+	reconnectMin = 100 * time.Millisecond
+	reconnectMax = 10 * time.Second
+)
+
+// RedisBroker publishes messages through a Redis channel and dispatches what
+// it receives on that channel to the local hub, so every server instance
+// sees every message in the same order.
+type RedisBroker struct {
+	Pool    *redis.Pool
+	Hub     *Hub
+	Channel string
+}
+
+func NewRedisBroker(pool *redis.Pool, hub *Hub) *RedisBroker {
+	return &RedisBroker{
+		Pool:    pool,
+		Hub:     hub,
+		Channel: DefaultChannel,
+	}
+}
+
+func (b *RedisBroker) Publish(msg *Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("redis publish live message: %w", err)
+	}
+
+	conn := b.Pool.Get()
+	defer conn.Close()
+	if _, err = conn.Do("PUBLISH", b.Channel, payload); err != nil {
+		return fmt.Errorf("redis publish live message: %w", err)
+	}
+	return nil
+}
+
+// Run receives messages until ctx is done, resubscribing with backoff when
+// the connection to Redis is lost.
+func (b *RedisBroker) Run(ctx context.Context) {
+	wait := reconnectMin
+	for {
+		start := time.Now()
+		err := b.receive(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		logrus.WithError(err).Error("live subscription lost, reconnecting")
+		if time.Since(start) > reconnectMax {
+			wait = reconnectMin
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		wait *= 2
+		if wait > reconnectMax {
+			wait = reconnectMax
+		}
+	}
+}
+
+func (b *RedisBroker) receive(ctx context.Context) error {
+	psc := redis.PubSubConn{Conn: b.Pool.Get()}
+	defer psc.Close()
+
+	if err := psc.Subscribe(b.Channel); err != nil {
+		return fmt.Errorf("redis subscribe: %w", err)
+	}
+
+	// Receive blocks, so unsubscribing is the way to wake it up on shutdown.
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			psc.Unsubscribe()
+		case <-done:
+		}
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	for {
+		switch v := psc.Receive().(type) {
+		case redis.Message:
+			msg := &Message{}
+			if err := json.Unmarshal(v.Data, msg); err != nil {
+				logrus.WithError(err).Error("decode live message failed")
+				continue
+			}
+			b.Hub.Dispatch(msg)
+		case redis.Subscription:
+			if v.Count == 0 {
+				return ctx.Err()
+			}
+		case error:
+			return fmt.Errorf("redis receive: %w", v)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package live
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPool(t *testing.T, addr string) *redis.Pool {
+	pool := &redis.Pool{
+		Dial: func() (redis.Conn, error) {
+			return redis.Dial("tcp", addr)
+		},
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+func waitSubscribed(t *testing.T, server *miniredis.Miniredis, count int) {
+	assert.Eventually(t, func() bool {
+		return server.PubSubNumSub(DefaultChannel)[DefaultChannel] == count
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRedisBrokerFanOut(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Two brokers stand in for two server instances.
+	first := NewRedisBroker(newPool(t, server.Addr()), NewHub(0, 0))
+	second := NewRedisBroker(newPool(t, server.Addr()), NewHub(0, 0))
+	go first.Run(ctx)
+	go second.Run(ctx)
+	waitSubscribed(t, server, 2)
+
+	topic := PostTopic("1")
+	firstSub := first.Hub.Subscribe(topic, "")
+	secondSub := second.Hub.Subscribe(topic, "")
+
+	assert.NoError(t, first.Publish(message("a", topic)))
+	for _, sub := range []*Subscription{firstSub, secondSub} {
+		select {
+		case msg := <-sub.C:
+			assert.Equal(t, "a", msg.ID)
+		case <-time.After(time.Second):
+			t.Fatal("message was not delivered")
+		}
+	}
+
+	cancel()
+	waitSubscribed(t, server, 0)
+}
+
+func TestRedisBrokerReconnect(t *testing.T) {
+	server := miniredis.RunT(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	broker := NewRedisBroker(newPool(t, server.Addr()), NewHub(0, 0))
+	go broker.Run(ctx)
+	waitSubscribed(t, server, 1)
+
+	server.Restart()
+	waitSubscribed(t, server, 1)
+
+	sub := broker.Hub.Subscribe(PostTopic("1"), "")
+	assert.NoError(t, broker.Publish(message("a", PostTopic("1"))))
+	select {
+	case msg := <-sub.C:
+		assert.Equal(t, "a", msg.ID)
+	case <-time.After(time.Second):
+		t.Fatal("message was not delivered after reconnect")
+	}
+}
+
+func TestRedisBrokerPublishError(t *testing.T) {
+	server := miniredis.RunT(t)
+	broker := NewRedisBroker(newPool(t, server.Addr()), NewHub(0, 0))
+	server.Close()
+	assert.Error(t, broker.Publish(message("a", PostTopic("1"))))
+}
@@ -0,0 +1,194 @@
+package markdown
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"regexp"
+	"sync"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/sirupsen/logrus"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+const (
+	DefaultCacheSize = 4096
+
+	linkRel = "nofollow ugc"
+)
+
+var (
+	codeClass = regexp.MustCompile(`^language-[\w+-]+$`)
+	relValue  = regexp.MustCompile(`^` + linkRel + `$`)
+)
+
+// Default is the renderer shared by posts and comments.
+var Default = NewRenderer(DefaultCacheSize)
+
+func Render(source string) string {
+	return Default.Render(source)
+}
+
+// Renderer converts the supported Markdown subset (links, emphasis, code,
+// lists and quotes) to HTML and passes the result through an allowlist
+// sanitizer. Results are cached by a hash of the source, so every revision
+// of a text is rendered once.
+type Renderer struct {
+	md     goldmark.Markdown
+	policy *bluemonday.Policy
+
+	mu      sync.Mutex
+	size    int
+	order   *list.List
+	entries map[[sha256.Size]byte]*list.Element
+}
+
+type entry struct {
+	key  [sha256.Size]byte
+	html string
+}
+
+func NewRenderer(cacheSize int) *Renderer {
+	if cacheSize <= 0 {
+		cacheSize = DefaultCacheSize
+	}
+	return &Renderer{
+		md:      newMarkdown(),
+		policy:  newPolicy(),
+		size:    cacheSize,
+		order:   list.New(),
+		entries: make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// newMarkdown builds a parser without headings, thematic breaks or raw HTML.
+// Raw HTML is therefore kept as escaped text instead of being passed through.
+func newMarkdown() goldmark.Markdown {
+	p := parser.NewParser(
+		parser.WithBlockParsers(
+			util.Prioritized(parser.NewListParser(), 300),
+			util.Prioritized(parser.NewListItemParser(), 400),
+			util.Prioritized(parser.NewCodeBlockParser(), 500),
+			util.Prioritized(parser.NewFencedCodeBlockParser(), 700),
+			util.Prioritized(parser.NewBlockquoteParser(), 800),
+			util.Prioritized(parser.NewParagraphParser(), 1000),
+		),
+		parser.WithInlineParsers(
+			util.Prioritized(parser.NewCodeSpanParser(), 100),
+			util.Prioritized(parser.NewLinkParser(), 200),
+			util.Prioritized(parser.NewAutoLinkParser(), 300),
+			util.Prioritized(parser.NewEmphasisParser(), 500),
+		),
+		parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+		parser.WithASTTransformers(util.Prioritized(linkTransformer{}, 100)),
+	)
+	return goldmark.New(goldmark.WithParser(p))
+}
+
+// newPolicy allows only the elements the Markdown subset produces. It is the
+// last line of defence, so it does not trust the renderer to be safe.
+func newPolicy() *bluemonday.Policy {
+	policy := bluemonday.NewPolicy()
+	policy.AllowElements("p", "br", "em", "strong", "code", "pre", "ul", "ol", "li", "blockquote")
+	policy.AllowAttrs("start").Matching(bluemonday.Integer).OnElements("ol")
+	policy.AllowAttrs("class").Matching(codeClass).OnElements("code")
+	policy.AllowAttrs("href").OnElements("a")
+	policy.AllowAttrs("rel").Matching(relValue).OnElements("a")
+	policy.AllowURLSchemes("http", "https", "mailto")
+	policy.RequireParseableURLs(true)
+	policy.RequireNoFollowOnLinks(true)
+	return policy
+}
+
+// linkTransformer marks every link as user-generated so search engines do
+// not pass ranking through it.
+type linkTransformer struct{}
+
+func (linkTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var images []ast.Node
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindLink, ast.KindAutoLink:
+			n.SetAttributeString("rel", []byte(linkRel))
+		case ast.KindImage:
+			images = append(images, n)
+		}
+		return ast.WalkContinue, nil
+	})
+
+	// Images are not part of the subset; only their alt text is kept.
+	for _, image := range images {
+		parent := image.Parent()
+		for child := image.FirstChild(); child != nil; {
+			next := child.NextSibling()
+			parent.InsertBefore(parent, image, child)
+			child = next
+		}
+		parent.RemoveChild(parent, image)
+	}
+}
+
+// Render returns the sanitized HTML for source.
+func (r *Renderer) Render(source string) string {
+	if source == "" {
+		return ""
+	}
+	key := sum(source)
+	if html, ok := r.get(key); ok {
+		return html
+	}
+
+	var buf bytes.Buffer
+	if err := r.md.Convert([]byte(source), &buf); err != nil {
+		logrus.WithError(err).Error("render markdown failed")
+		return r.policy.Sanitize(source)
+	}
+	html := r.policy.SanitizeBytes(buf.Bytes())
+	r.put(key, string(html))
+	return string(html)
+}
+
+func sum(source string) [sha256.Size]byte {
+	return sha256.Sum256([]byte(source))
+}
+
+func (r *Renderer) get(key [sha256.Size]byte) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	elem, ok := r.entries[key]
+	if !ok {
+		return "", false
+	}
+	r.order.MoveToFront(elem)
+	return elem.Value.(*entry).html, true
+}
+
+func (r *Renderer) put(key [sha256.Size]byte, html string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if elem, ok := r.entries[key]; ok {
+		r.order.MoveToFront(elem)
+		return
+	}
+	r.entries[key] = r.order.PushFront(&entry{key: key, html: html})
+	for r.order.Len() > r.size {
+		oldest := r.order.Back()
+		r.order.Remove(oldest)
+		delete(r.entries, oldest.Value.(*entry).key)
+	}
+}
+
+// Cached reports how many rendered revisions are currently kept.
+func (r *Renderer) Cached() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.order.Len()
+}
@@ -0,0 +1,154 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "empty",
+			source:   "",
+			expected: "",
+		},
+		{
+			name:     "emphasis and code",
+			source:   "*em* **strong** `code`",
+			expected: "<p><em>em</em> <strong>strong</strong> <code>code</code></p>\n",
+		},
+		{
+			name:     "link",
+			source:   "[site](https://example.com)",
+			expected: "<p><a href=\"https://example.com\" rel=\"nofollow ugc\">site</a></p>\n",
+		},
+		{
+			name:     "autolink",
+			source:   "<https://example.com>",
+			expected: "<p><a href=\"https://example.com\" rel=\"nofollow ugc\">https://example.com</a></p>\n",
+		},
+		{
+			name:     "lists and quotes",
+			source:   "> quote\n\n- a\n\n2. b",
+			expected: "<blockquote>\n<p>quote</p>\n</blockquote>\n<ul>\n<li>a</li>\n</ul>\n<ol start=\"2\">\n<li>b</li>\n</ol>\n",
+		},
+		{
+			name:     "fenced code",
+			source:   "```go\nx := 1\n```",
+			expected: "<pre><code class=\"language-go\">x := 1\n</code></pre>\n",
+		},
+		{
+			name:     "headings are not supported",
+			source:   "# title",
+			expected: "<p># title</p>\n",
+		},
+		{
+			name:     "images keep alt text",
+			source:   "![alt](https://example.com/a.png)",
+			expected: "<p>alt</p>\n",
+		},
+		{
+			name:     "raw html is escaped",
+			source:   "<script>alert(1)</script>",
+			expected: "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n",
+		},
+		{
+			name:     "javascript link",
+			source:   "[x](javascript:alert(1))",
+			expected: "<p><a rel=\"nofollow ugc\">x</a></p>\n",
+		},
+	}
+
+	renderer := NewRenderer(10)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, renderer.Render(c.source))
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	policy := newPolicy()
+
+	cases := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "event handler",
+			html:     `<p onclick="alert(1)">x</p>`,
+			expected: `<p>x</p>`,
+		},
+		{
+			name:     "rel is forced",
+			html:     `<a href="https://example.com" rel="author">x</a>`,
+			expected: `<a href="https://example.com" rel="nofollow">x</a>`,
+		},
+		{
+			name:     "data url",
+			html:     `<a href="data:text/html;base64,PHNjcmlwdD4=">x</a>`,
+			expected: `x`,
+		},
+		{
+			name:     "unknown element",
+			html:     `<iframe src="https://example.com"></iframe><img src="x">`,
+			expected: ``,
+		},
+		{
+			name:     "code class",
+			html:     `<code class="evil">x</code>`,
+			expected: `<code>x</code>`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.expected, policy.Sanitize(c.html))
+		})
+	}
+}
+
+func TestRenderCache(t *testing.T) {
+	renderer := NewRenderer(2)
+
+	first := renderer.Render("*a*")
+	assert.Equal(t, first, renderer.Render("*a*"))
+	assert.Equal(t, 1, renderer.Cached())
+
+	renderer.Render("*b*")
+	renderer.Render("*a*")
+	renderer.Render("*c*")
+	assert.Equal(t, 2, renderer.Cached())
+
+	// *b* was the least recently used revision.
+	_, ok := renderer.get(sum("*b*"))
+	assert.False(t, ok)
+	_, ok = renderer.get(sum("*a*"))
+	assert.True(t, ok)
+}
+
+func TestRenderConcurrent(t *testing.T) {
+	renderer := NewRenderer(8)
+	done := make(chan struct{})
+	for i := 0; i < 8; i++ {
+		go func(i int) {
+			defer func() { done <- struct{}{} }()
+			for j := 0; j < 50; j++ {
+				source := fmt.Sprintf("*%d*", (i+j)%16)
+				assert.True(t, strings.HasPrefix(renderer.Render(source), "<p><em>"))
+			}
+		}(i)
+	}
+	for i := 0; i < 8; i++ {
+		<-done
+	}
+	assert.LessOrEqual(t, renderer.Cached(), 8)
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/blocks"
+	"github.com/KonstantinGalanin/redditclone/internal/messages"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	successMsg      = "success"
+	unauthorizedMsg = "unauthorized"
+	fieldUsername   = "username"
+	queryLimit      = "limit"
+	queryOffset     = "offset"
+)
+
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
+
+func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
+	newError := &ErrorMessage{
+		Message: errBody,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errStatus)
+	if err := json.NewEncoder(w).Encode(newError); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func WriteErrorMessages(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, myerrors.ErrNoUser):
+		WriteErrorMsg(w, myerrors.ErrNoUser.Error(), http.StatusNotFound)
+	case errors.Is(err, myerrors.ErrBlocked):
+		WriteErrorMsg(w, myerrors.ErrBlocked.Error(), http.StatusForbidden)
+	case errors.Is(err, myerrors.ErrBlockedUser):
+		WriteErrorMsg(w, myerrors.ErrBlockedUser.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func WriteResponse(w http.ResponseWriter, data interface{}, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type Inbox struct {
+	Unread        int64                    `json:"unread"`
+	Conversations []*messages.Conversation `json:"conversations"`
+}
+
+type MessagesHandler struct {
+	MessageRepo messages.MessageRepo
+	UserRepo    user.UserRepo
+	BlockRepo   blocks.BlockRepo
+}
+
+func (h *MessagesHandler) getUserFromCtx(r *http.Request) (*user.User, error) {
+	ctx := r.Context()
+	sess, ok := ctx.Value("session").(*session.Session)
+	if !ok || sess == nil {
+		return nil, myerrors.ErrNoAuth
+	}
+
+	user, err := h.UserRepo.GetUserByUsername(r.Context(), sess.Username)
+	if err != nil {
+		return nil, fmt.Errorf("get user from sess: %w", err)
+	}
+	return user, nil
+}
+
+// getOther resolves the other side of a conversation from the url. It
+// writes the error response and returns false when there is none.
+func (h *MessagesHandler) getOther(w http.ResponseWriter, r *http.Request, current *user.User) (*user.User, bool) {
+	username := mux.Vars(r)[fieldUsername]
+	if username == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyUsername.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if username == current.Username {
+		WriteErrorMsg(w, myerrors.ErrMessageSelf.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	other, err := h.UserRepo.GetUserByUsername(r.Context(), username)
+	if err != nil {
+		WriteErrorMessages(w, err)
+		return nil, false
+	}
+	return other, true
+}
+
+// List returns the caller's conversations, most recently active first,
+// together with the total number of unread messages. Conversations with
+// blocked users are left out.
+func (h *MessagesHandler) List(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("messages %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	blocked, err := h.BlockRepo.GetBlocked(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	hidden := blocks.IDs(blocked)
+
+	conversations, err := h.MessageRepo.GetConversations(r.Context(), user.ID, hidden, limit, offset)
+	if err != nil {
+		WriteErrorMessages(w, err)
+		return
+	}
+	unread, err := h.MessageRepo.CountUnread(r.Context(), user.ID, hidden)
+	if err != nil {
+		WriteErrorMessages(w, err)
+		return
+	}
+	for _, conversation := range conversations {
+		conversation.Last.Render()
+	}
+
+	WriteResponse(w, &Inbox{Unread: unread, Conversations: conversations}, http.StatusOK)
+}
+
+// Conversation returns the messages exchanged with the user in the url,
+// newest first.
+func (h *MessagesHandler) Conversation(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := getPagination(r)
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current, err := h.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("conversation %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+	other, ok := h.getOther(w, r, current)
+	if !ok {
+		return
+	}
+
+	items, err := h.MessageRepo.GetMessages(r.Context(), messages.ConversationID(current.ID, other.ID), limit, offset)
+	if err != nil {
+		WriteErrorMessages(w, err)
+		return
+	}
+	for _, message := range items {
+		message.Render()
+	}
+	WriteResponse(w, items, http.StatusOK)
+}
+
+// Send delivers a message to the user in the url. Users cannot message
+// someone who blocked them or whom they blocked.
+func (h *MessagesHandler) Send(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	body := strings.TrimSpace(data.Body)
+	if body == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyMessage.Error(), http.StatusBadRequest)
+		return
+	}
+	if utf8.RuneCountInString(body) > messages.MaxBodyLength {
+		WriteErrorMsg(w, myerrors.ErrMessageTooLong.Error(), http.StatusBadRequest)
+		return
+	}
+
+	current, err := h.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("send message %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+	if current.IsSuspended(time.Now()) {
+		WriteErrorMsg(w, myerrors.ErrSuspended.Error(), http.StatusForbidden)
+		return
+	}
+	other, ok := h.getOther(w, r, current)
+	if !ok {
+		return
+	}
+
+	if err = h.checkBlocks(current, other); err != nil {
+		WriteErrorMessages(w, err)
+		return
+	}
+
+	message, err := h.MessageRepo.Send(r.Context(), current, other, body)
+	if err != nil {
+		WriteErrorMessages(w, err)
+		return
+	}
+	message.Render()
+	WriteResponse(w, message, http.StatusCreated)
+}
+
+// MarkRead marks the messages received from the user in the url as read.
+func (h *MessagesHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	current, err := h.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("mark read %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+	other, ok := h.getOther(w, r, current)
+	if !ok {
+		return
+	}
+
+	if err = h.MessageRepo.MarkRead(r.Context(), messages.ConversationID(current.ID, other.ID), current.ID); err != nil {
+		WriteErrorMessages(w, err)
+		return
+	}
+	WriteResponse(w, &ErrorMessage{Message: successMsg}, http.StatusOK)
+}
+
+// checkBlocks refuses messages in either direction of a block.
+func (h *MessagesHandler) checkBlocks(current, other *user.User) error {
+	blockers, err := h.BlockRepo.GetBlockers(current.ID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(blocks.IDs(blockers), other.ID) {
+		return myerrors.ErrBlocked
+	}
+	blocked, err := h.BlockRepo.GetBlocked(current.ID)
+	if err != nil {
+		return err
+	}
+	if slices.Contains(blocks.IDs(blocked), other.ID) {
+		return myerrors.ErrBlockedUser
+	}
+	return nil
+}
+
+func getPagination(r *http.Request) (int, int, error) {
+	query := r.URL.Query()
+	limit, err := getNonNegative(query.Get(queryLimit))
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err := getNonNegative(query.Get(queryOffset))
+	if err != nil {
+		return 0, 0, err
+	}
+	return limit, offset, nil
+}
+
+func getNonNegative(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, myerrors.ErrBadPagination
+	}
+	return n, nil
+}
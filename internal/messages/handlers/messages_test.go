@@ -0,0 +1,380 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/messages"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+
+	repositoryBlocks "github.com/KonstantinGalanin/redditclone/internal/blocks/repository"
+	repositoryMessages "github.com/KonstantinGalanin/redditclone/internal/messages/repository"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+const username = "User"
+
+var (
+	expectedUser = &user.User{
+		Username: username,
+		Password: "password",
+		ID:       "1",
+	}
+	otherUser = &user.User{
+		Username: "Other",
+		ID:       "2",
+	}
+	conversationID = messages.ConversationID(expectedUser.ID, otherUser.ID)
+)
+
+type mocks struct {
+	messageRepo *repositoryMessages.MockMessageRepo
+	userRepo    *repositoryUser.MockUserRepo
+	blockRepo   *repositoryBlocks.MockBlockRepo
+}
+
+func newService(t *testing.T) (*MessagesHandler, *mocks) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	m := &mocks{
+		messageRepo: repositoryMessages.NewMockMessageRepo(ctrl),
+		userRepo:    repositoryUser.NewMockUserRepo(ctrl),
+		blockRepo:   repositoryBlocks.NewMockBlockRepo(ctrl),
+	}
+	return &MessagesHandler{
+		MessageRepo: m.messageRepo,
+		UserRepo:    m.userRepo,
+		BlockRepo:   m.blockRepo,
+	}, m
+}
+
+func newRequest(withSession bool, target, body string, vars map[string]string) *http.Request {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(http.MethodPost, target, reader)
+	if withSession {
+		req = sessiontest.SignIn(req, username)
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+func TestList(t *testing.T) {
+	service, m := newService(t)
+
+	cases := []struct {
+		name       string
+		statusCode int
+		target     string
+		session    bool
+		expect     func()
+	}{
+		{
+			name:       "bad pagination",
+			statusCode: http.StatusBadRequest,
+			target:     "/?limit=-1",
+			session:    true,
+		},
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			target:     "/",
+		},
+		{
+			name:       "blocked error",
+			statusCode: http.StatusInternalServerError,
+			target:     "/",
+			session:    true,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "conversations error",
+			statusCode: http.StatusInternalServerError,
+			target:     "/",
+			session:    true,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, nil)
+				m.messageRepo.EXPECT().GetConversations(gomock.Any(), expectedUser.ID, gomock.Nil(), 0, 0).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "count error",
+			statusCode: http.StatusInternalServerError,
+			target:     "/",
+			session:    true,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, nil)
+				m.messageRepo.EXPECT().GetConversations(gomock.Any(), expectedUser.ID, gomock.Nil(), 0, 0).Return([]*messages.Conversation{}, nil)
+				m.messageRepo.EXPECT().CountUnread(gomock.Any(), expectedUser.ID, gomock.Nil()).Return(int64(0), errors.New("some error"))
+			},
+		},
+		{
+			name:       "success hides blocked users",
+			statusCode: http.StatusOK,
+			target:     "/?limit=10&offset=20",
+			session:    true,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{otherUser}, nil)
+				m.messageRepo.EXPECT().GetConversations(gomock.Any(), expectedUser.ID, []string{otherUser.ID}, 10, 20).Return([]*messages.Conversation{
+					{ID: "1:3", With: "third", Last: &messages.Message{Body: "**hi**"}, Unread: 1},
+				}, nil)
+				m.messageRepo.EXPECT().CountUnread(gomock.Any(), expectedUser.ID, []string{otherUser.ID}).Return(int64(1), nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.List(recorder, newRequest(c.session, c.target, "", nil))
+			assert.Equal(t, c.statusCode, recorder.Code)
+			if c.statusCode == http.StatusOK {
+				assert.Contains(t, recorder.Body.String(), `"unread":1`)
+				assert.Contains(t, recorder.Body.String(), `\u003cstrong\u003ehi`)
+			}
+		})
+	}
+}
+
+func TestConversation(t *testing.T) {
+	service, m := newService(t)
+	vars := map[string]string{fieldUsername: otherUser.Username}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		session    bool
+		vars       map[string]string
+		expect     func()
+	}{
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			vars:       vars,
+		},
+		{
+			name:       "empty username",
+			statusCode: http.StatusBadRequest,
+			session:    true,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+			},
+		},
+		{
+			name:       "self",
+			statusCode: http.StatusBadRequest,
+			session:    true,
+			vars:       map[string]string{fieldUsername: username},
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+			},
+		},
+		{
+			name:       "unknown user",
+			statusCode: http.StatusNotFound,
+			session:    true,
+			vars:       vars,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(nil, fmt.Errorf("get user: %w", myerrors.ErrNoUser))
+			},
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			session:    true,
+			vars:       vars,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+				m.messageRepo.EXPECT().GetMessages(gomock.Any(), conversationID, 0, 0).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			session:    true,
+			vars:       vars,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+				m.messageRepo.EXPECT().GetMessages(gomock.Any(), conversationID, 0, 0).Return([]*messages.Message{{Body: "hi"}}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.Conversation(recorder, newRequest(c.session, "/", "", c.vars))
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestSend(t *testing.T) {
+	service, m := newService(t)
+	vars := map[string]string{fieldUsername: otherUser.Username}
+	suspended := &user.User{Username: username, ID: "1", SuspendedUntil: time.Now().Add(time.Hour)}
+
+	resolve := func() {
+		m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+		m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		session    bool
+		body       string
+		expect     func()
+	}{
+		{
+			name:       "bad body",
+			statusCode: http.StatusBadRequest,
+			session:    true,
+			body:       `{`,
+		},
+		{
+			name:       "empty",
+			statusCode: http.StatusBadRequest,
+			session:    true,
+			body:       `{"body":"  "}`,
+		},
+		{
+			name:       "too long",
+			statusCode: http.StatusBadRequest,
+			session:    true,
+			body:       `{"body":"` + strings.Repeat("a", messages.MaxBodyLength+1) + `"}`,
+		},
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"body":"hi"}`,
+		},
+		{
+			name:       "suspended",
+			statusCode: http.StatusForbidden,
+			session:    true,
+			body:       `{"body":"hi"}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(suspended, nil)
+			},
+		},
+		{
+			name:       "blocked by recipient",
+			statusCode: http.StatusForbidden,
+			session:    true,
+			body:       `{"body":"hi"}`,
+			expect: func() {
+				resolve()
+				m.blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return([]*user.User{otherUser}, nil)
+			},
+		},
+		{
+			name:       "recipient blocked",
+			statusCode: http.StatusForbidden,
+			session:    true,
+			body:       `{"body":"hi"}`,
+			expect: func() {
+				resolve()
+				m.blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return(nil, nil)
+				m.blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{otherUser}, nil)
+			},
+		},
+		{
+			name:       "blocks error",
+			statusCode: http.StatusInternalServerError,
+			session:    true,
+			body:       `{"body":"hi"}`,
+			expect: func() {
+				resolve()
+				m.blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			session:    true,
+			body:       `{"body":"hi"}`,
+			expect: func() {
+				resolve()
+				m.blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return(nil, nil)
+				m.blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, nil)
+				m.messageRepo.EXPECT().Send(gomock.Any(), expectedUser, otherUser, "hi").Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusCreated,
+			session:    true,
+			body:       `{"body":" hi "}`,
+			expect: func() {
+				resolve()
+				m.blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return(nil, nil)
+				m.blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, nil)
+				m.messageRepo.EXPECT().Send(gomock.Any(), expectedUser, otherUser, "hi").Return(&messages.Message{Body: "hi"}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.Send(recorder, newRequest(c.session, "/", c.body, vars))
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestMarkRead(t *testing.T) {
+	service, m := newService(t)
+	vars := map[string]string{fieldUsername: otherUser.Username}
+
+	recorder := httptest.NewRecorder()
+	service.MarkRead(recorder, newRequest(false, "/", "", vars))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+	m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+	m.messageRepo.EXPECT().MarkRead(gomock.Any(), conversationID, expectedUser.ID).Return(errors.New("some error"))
+	recorder = httptest.NewRecorder()
+	service.MarkRead(recorder, newRequest(true, "/", "", vars))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+	m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), otherUser.Username).Return(otherUser, nil)
+	m.messageRepo.EXPECT().MarkRead(gomock.Any(), conversationID, expectedUser.ID).Return(nil)
+	recorder = httptest.NewRecorder()
+	service.MarkRead(recorder, newRequest(true, "/", "", vars))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,60 @@
+package messages
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/KonstantinGalanin/redditclone/internal/markdown"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const MaxBodyLength = 10000
+
+// Message is a private message between two users. Both user IDs are kept
+// in Participants so one index serves the conversation list of either side.
+type Message struct {
+	ID           string    `json:"id" bson:"_id"`
+	Conversation string    `json:"conversation" bson:"conversation"`
+	Participants []string  `json:"-" bson:"participants"`
+	FromID       string    `json:"-" bson:"fromId"`
+	From         string    `json:"from" bson:"from"`
+	ToID         string    `json:"-" bson:"toId"`
+	To           string    `json:"to" bson:"to"`
+	Body         string    `json:"body" bson:"body"`
+	BodyHTML     string    `json:"bodyHtml,omitempty" bson:"-"`
+	Read         bool      `json:"read" bson:"read"`
+	Created      time.Time `json:"created" bson:"created"`
+}
+
+func (m *Message) Render() {
+	m.BodyHTML = markdown.Render(m.Body)
+}
+
+// Conversation summarizes the messages exchanged with one other user.
+// Unread counts the messages addressed to the viewer they have not read.
+type Conversation struct {
+	ID     string   `json:"id" bson:"_id"`
+	With   string   `json:"with" bson:"-"`
+	Last   *Message `json:"last" bson:"last"`
+	Unread int64    `json:"unread" bson:"unread"`
+}
+
+// ConversationID is the same for both directions of a conversation.
+func ConversationID(userID, otherID string) string {
+	ids := []string{userID, otherID}
+	sort.Strings(ids)
+	return strings.Join(ids, ":")
+}
+
+//go:generate mockgen -source=messages.go -destination=repository/repo_mock.go -package=repository MessageRepo
+type MessageRepo interface {
+	Send(ctx context.Context, from, to *user.User, body string) (*Message, error)
+	// GetConversations and CountUnread leave out conversations with the
+	// users in hidden, usually the users the viewer blocked.
+	GetConversations(ctx context.Context, userID string, hidden []string, limit, offset int) ([]*Conversation, error)
+	GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]*Message, error)
+	CountUnread(ctx context.Context, userID string, hidden []string) (int64, error)
+	MarkRead(ctx context.Context, conversationID, userID string) error
+}
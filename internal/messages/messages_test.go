@@ -0,0 +1,12 @@
+package messages
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConversationID(t *testing.T) {
+	assert.Equal(t, "1:2", ConversationID("1", "2"))
+	assert.Equal(t, ConversationID("1", "2"), ConversationID("2", "1"))
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KonstantinGalanin/redditclone/internal/messages"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	TimeoutVal = 10
+)
+
+// Indexes back the queries below: the conversation list of a user, the
+// messages of one conversation and the unread counter.
+var Indexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "participants", Value: 1}, {Key: "created", Value: -1}}},
+	{Keys: bson.D{{Key: "conversation", Value: 1}, {Key: "created", Value: -1}}},
+	{Keys: bson.D{{Key: "toId", Value: 1}, {Key: "read", Value: 1}}},
+}
+
+type MessageMongoDB struct {
+	db *mongo.Collection
+}
+
+func NewMessageMongoDB(db *mongo.Collection) *MessageMongoDB {
+	return &MessageMongoDB{
+		db: db,
+	}
+}
+
+func (m *MessageMongoDB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, TimeoutVal*time.Second)
+}
+
+// EnsureIndexes creates Indexes. Creating an index that already exists is a
+// no-op, so it is safe to call on every start.
+func (m *MessageMongoDB) EnsureIndexes(ctx context.Context) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	if _, err := m.db.Indexes().CreateMany(ctx, Indexes); err != nil {
+		return fmt.Errorf("mongodb create message indexes: %w", err)
+	}
+	return nil
+}
+
+func (m *MessageMongoDB) Send(ctx context.Context, from, to *user.User, body string) (*messages.Message, error) {
+	message := &messages.Message{
+		ID:           uuid.New().String(),
+		Conversation: messages.ConversationID(from.ID, to.ID),
+		Participants: []string{from.ID, to.ID},
+		FromID:       from.ID,
+		From:         from.Username,
+		ToID:         to.ID,
+		To:           to.Username,
+		Body:         body,
+		Created:      time.Now(),
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	if _, err := m.db.InsertOne(ctx, message); err != nil {
+		return nil, fmt.Errorf("mongodb send message: %w", err)
+	}
+	return message, nil
+}
+
+// GetConversations returns the user's conversations, the most recently
+// active first, each with its last message and unread count.
+func (m *MessageMongoDB) GetConversations(ctx context.Context, userID string, hidden []string, limit, offset int) ([]*messages.Conversation, error) {
+	participants := bson.M{"$eq": userID}
+	if len(hidden) != 0 {
+		participants["$nin"] = hidden
+	}
+	unread := bson.M{"$cond": bson.A{
+		bson.M{"$and": bson.A{
+			bson.M{"$eq": bson.A{"$toId", userID}},
+			bson.M{"$eq": bson.A{"$read", false}},
+		}},
+		1,
+		0,
+	}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"participants": participants}}},
+		{{Key: "$sort", Value: bson.M{"created": -1}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    "$conversation",
+			"last":   bson.M{"$first": "$$ROOT"},
+			"unread": bson.M{"$sum": unread},
+		}}},
+		{{Key: "$sort", Value: bson.M{"last.created": -1}}},
+		{{Key: "$skip", Value: offset}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	c, err := m.db.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get conversations: %w", err)
+	}
+	conversations := []*messages.Conversation{}
+	if err = c.All(ctx, &conversations); err != nil {
+		return nil, fmt.Errorf("mongodb get conversations: %w", err)
+	}
+	for _, conversation := range conversations {
+		if conversation.Last.FromID == userID {
+			conversation.With = conversation.Last.To
+		} else {
+			conversation.With = conversation.Last.From
+		}
+	}
+	return conversations, nil
+}
+
+// GetMessages returns the messages of a conversation, newest first.
+func (m *MessageMongoDB) GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]*messages.Message, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	c, err := m.db.Find(ctx, bson.M{"conversation": conversationID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get messages: %w", err)
+	}
+	items := []*messages.Message{}
+	if err = c.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("mongodb get messages: %w", err)
+	}
+	return items, nil
+}
+
+func (m *MessageMongoDB) CountUnread(ctx context.Context, userID string, hidden []string) (int64, error) {
+	filter := bson.M{"toId": userID, "read": false}
+	if len(hidden) != 0 {
+		filter["fromId"] = bson.M{"$nin": hidden}
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	count, err := m.db.CountDocuments(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("mongodb count unread messages: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks every message of the conversation addressed to userID as
+// read.
+func (m *MessageMongoDB) MarkRead(ctx context.Context, conversationID, userID string) error {
+	filter := bson.M{"conversation": conversationID, "toId": userID, "read": false}
+	update := bson.M{"$set": bson.M{"read": true}}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+	if _, err := m.db.UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("mongodb mark messages read: %w", err)
+	}
+	return nil
+}
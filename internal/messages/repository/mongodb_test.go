@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/KonstantinGalanin/redditclone/internal/messages"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	userID  = "1"
+	otherID = "2"
+)
+
+var (
+	sender    = &user.User{ID: userID, Username: "alice"}
+	recipient = &user.User{ID: otherID, Username: "bob"}
+)
+
+func messageData(fromID, from, toID, to string) bson.D {
+	return bson.D{
+		{Key: "_id", Value: "3"},
+		{Key: "conversation", Value: messages.ConversationID(fromID, toID)},
+		{Key: "participants", Value: bson.A{fromID, toID}},
+		{Key: "fromId", Value: fromID},
+		{Key: "from", Value: from},
+		{Key: "toId", Value: toID},
+		{Key: "to", Value: to},
+		{Key: "body", Value: "hi"},
+		{Key: "read", Value: false},
+		{Key: "created", Value: time.Now()},
+	}
+}
+
+func TestNewMessageMongoDB(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("test", func(mt *mtest.T) {
+		assert.NotNil(t, NewMessageMongoDB(mt.Coll))
+	})
+}
+
+func TestEnsureIndexes(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		assert.NoError(t, NewMessageMongoDB(mt.Coll).EnsureIndexes(context.Background()))
+		assert.Equal(t, "createIndexes", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		assert.Error(t, NewMessageMongoDB(mt.Coll).EnsureIndexes(context.Background()))
+	})
+}
+
+func TestSend(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		message, err := NewMessageMongoDB(mt.Coll).Send(context.Background(), sender, recipient, "hi")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, message.ID)
+		assert.Equal(t, "1:2", message.Conversation)
+		assert.Equal(t, []string{userID, otherID}, message.Participants)
+		assert.Equal(t, "alice", message.From)
+		assert.Equal(t, "bob", message.To)
+		assert.False(t, message.Read)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		message, err := NewMessageMongoDB(mt.Coll).Send(context.Background(), sender, recipient, "hi")
+		assert.Error(t, err)
+		assert.Nil(t, message)
+	})
+}
+
+func TestGetConversations(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	conversation := func(last bson.D) bson.D {
+		return bson.D{
+			{Key: "_id", Value: "1:2"},
+			{Key: "last", Value: last},
+			{Key: "unread", Value: int64(1)},
+		}
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "reddit.messages", mtest.FirstBatch,
+				conversation(messageData(otherID, "bob", userID, "alice")),
+				conversation(messageData(userID, "alice", otherID, "bob")),
+			),
+			mtest.CreateCursorResponse(0, "reddit.messages", mtest.NextBatch),
+		)
+		conversations, err := NewMessageMongoDB(mt.Coll).GetConversations(context.Background(), userID, []string{"9"}, 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, conversations, 2)
+		assert.Equal(t, "bob", conversations[0].With)
+		assert.Equal(t, "bob", conversations[1].With)
+		assert.Equal(t, int64(1), conversations[0].Unread)
+
+		pipeline := mt.GetStartedEvent().Command.Lookup("pipeline")
+		participants := pipeline.Array().Index(0).Value().Document().Lookup("$match", "participants")
+		assert.Equal(t, `{"$eq": "1","$nin": ["9"]}`, participants.String())
+		assert.Equal(t, int32(10), pipeline.Array().Index(5).Value().Document().Lookup("$limit").Int32())
+	})
+
+	mt.Run("aggregate error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		conversations, err := NewMessageMongoDB(mt.Coll).GetConversations(context.Background(), userID, nil, 0, 0)
+		assert.Error(t, err)
+		assert.Nil(t, conversations)
+	})
+
+	mt.Run("cursor error", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "reddit.messages", mtest.FirstBatch, bson.D{{Key: "unread", Value: "many"}}),
+			mtest.CreateCursorResponse(0, "reddit.messages", mtest.NextBatch),
+		)
+		conversations, err := NewMessageMongoDB(mt.Coll).GetConversations(context.Background(), userID, nil, 0, 0)
+		assert.Error(t, err)
+		assert.Nil(t, conversations)
+	})
+}
+
+func TestGetMessages(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "reddit.messages", mtest.FirstBatch, messageData(userID, "alice", otherID, "bob")),
+			mtest.CreateCursorResponse(0, "reddit.messages", mtest.NextBatch),
+		)
+		items, err := NewMessageMongoDB(mt.Coll).GetMessages(context.Background(), "1:2", 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, items, 1)
+		assert.Equal(t, "hi", items[0].Body)
+	})
+
+	mt.Run("find error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		items, err := NewMessageMongoDB(mt.Coll).GetMessages(context.Background(), "1:2", 0, 0)
+		assert.Error(t, err)
+		assert.Nil(t, items)
+	})
+
+	mt.Run("cursor error", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "reddit.messages", mtest.FirstBatch, bson.D{{Key: "read", Value: "yes"}}),
+			mtest.CreateCursorResponse(0, "reddit.messages", mtest.NextBatch),
+		)
+		items, err := NewMessageMongoDB(mt.Coll).GetMessages(context.Background(), "1:2", 0, 0)
+		assert.Error(t, err)
+		assert.Nil(t, items)
+	})
+}
+
+func TestCountUnread(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.messages", mtest.FirstBatch, bson.D{{Key: "n", Value: 4}}))
+		count, err := NewMessageMongoDB(mt.Coll).CountUnread(context.Background(), userID, []string{otherID})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(4), count)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		_, err := NewMessageMongoDB(mt.Coll).CountUnread(context.Background(), userID, nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestMarkRead(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 2}, {Key: "nModified", Value: 2}})
+		assert.NoError(t, NewMessageMongoDB(mt.Coll).MarkRead(context.Background(), "1:2", userID))
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		assert.Error(t, NewMessageMongoDB(mt.Coll).MarkRead(context.Background(), "1:2", userID))
+	})
+}
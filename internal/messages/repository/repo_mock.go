@@ -0,0 +1,111 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: messages.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	messages "github.com/KonstantinGalanin/redditclone/internal/messages"
+	user "github.com/KonstantinGalanin/redditclone/internal/user"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockMessageRepo is a mock of MessageRepo interface.
+type MockMessageRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockMessageRepoMockRecorder
+}
+
+// MockMessageRepoMockRecorder is the mock recorder for MockMessageRepo.
+type MockMessageRepoMockRecorder struct {
+	mock *MockMessageRepo
+}
+
+// NewMockMessageRepo creates a new mock instance.
+func NewMockMessageRepo(ctrl *gomock.Controller) *MockMessageRepo {
+	mock := &MockMessageRepo{ctrl: ctrl}
+	mock.recorder = &MockMessageRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMessageRepo) EXPECT() *MockMessageRepoMockRecorder {
+	return m.recorder
+}
+
+// CountUnread mocks base method.
+func (m *MockMessageRepo) CountUnread(ctx context.Context, userID string, hidden []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnread", ctx, userID, hidden)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnread indicates an expected call of CountUnread.
+func (mr *MockMessageRepoMockRecorder) CountUnread(ctx, userID, hidden interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnread", reflect.TypeOf((*MockMessageRepo)(nil).CountUnread), ctx, userID, hidden)
+}
+
+// GetConversations mocks base method.
+func (m *MockMessageRepo) GetConversations(ctx context.Context, userID string, hidden []string, limit, offset int) ([]*messages.Conversation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConversations", ctx, userID, hidden, limit, offset)
+	ret0, _ := ret[0].([]*messages.Conversation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConversations indicates an expected call of GetConversations.
+func (mr *MockMessageRepoMockRecorder) GetConversations(ctx, userID, hidden, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConversations", reflect.TypeOf((*MockMessageRepo)(nil).GetConversations), ctx, userID, hidden, limit, offset)
+}
+
+// GetMessages mocks base method.
+func (m *MockMessageRepo) GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]*messages.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMessages", ctx, conversationID, limit, offset)
+	ret0, _ := ret[0].([]*messages.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMessages indicates an expected call of GetMessages.
+func (mr *MockMessageRepoMockRecorder) GetMessages(ctx, conversationID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMessages", reflect.TypeOf((*MockMessageRepo)(nil).GetMessages), ctx, conversationID, limit, offset)
+}
+
+// MarkRead mocks base method.
+func (m *MockMessageRepo) MarkRead(ctx context.Context, conversationID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkRead", ctx, conversationID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkRead indicates an expected call of MarkRead.
+func (mr *MockMessageRepoMockRecorder) MarkRead(ctx, conversationID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkRead", reflect.TypeOf((*MockMessageRepo)(nil).MarkRead), ctx, conversationID, userID)
+}
+
+// Send mocks base method.
+func (m *MockMessageRepo) Send(ctx context.Context, from, to *user.User, body string) (*messages.Message, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Send", ctx, from, to, body)
+	ret0, _ := ret[0].(*messages.Message)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Send indicates an expected call of Send.
+func (mr *MockMessageRepoMockRecorder) Send(ctx, from, to, body interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockMessageRepo)(nil).Send), ctx, from, to, body)
+}
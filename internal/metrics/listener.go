@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+)
+
+const (
+	targetPost    = "post"
+	targetComment = "comment"
+)
+
+// Listener counts the business events published on the bus.
+type Listener struct{}
+
+func (Listener) Handle(ctx context.Context, event *events.Event) {
+	switch event.Type {
+	case events.PostCreated:
+		postType := ""
+		if event.Post != nil {
+			postType = event.Post.Type
+		}
+		PostsCreated.WithLabelValues(postType).Inc()
+	case events.CommentCreated:
+		CommentsCreated.Inc()
+	case events.ScoreChanged:
+		if event.ScoreDelta == 0 {
+			return
+		}
+		target := targetPost
+		if event.CommentID != "" {
+			target = targetComment
+		}
+		Votes.WithLabelValues(target).Inc()
+	}
+}
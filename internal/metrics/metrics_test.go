@@ -0,0 +1,79 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/metrics"
+	"github.com/KonstantinGalanin/redditclone/internal/middleware"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+func TestListener(t *testing.T) {
+	links := testutil.ToFloat64(metrics.PostsCreated.WithLabelValues("link"))
+	comments := testutil.ToFloat64(metrics.CommentsCreated)
+	postVotes := testutil.ToFloat64(metrics.Votes.WithLabelValues("post"))
+	commentVotes := testutil.ToFloat64(metrics.Votes.WithLabelValues("comment"))
+
+	listener := metrics.Listener{}
+	listener.Handle(context.Background(), &events.Event{Type: events.PostCreated, Post: &posts.Post{Type: "link"}})
+	listener.Handle(context.Background(), &events.Event{Type: events.CommentCreated})
+	listener.Handle(context.Background(), &events.Event{Type: events.ScoreChanged, ScoreDelta: 1})
+	listener.Handle(context.Background(), &events.Event{Type: events.ScoreChanged, ScoreDelta: -2, CommentID: "c"})
+	listener.Handle(context.Background(), &events.Event{Type: events.ScoreChanged})
+
+	assert.Equal(t, links+1, testutil.ToFloat64(metrics.PostsCreated.WithLabelValues("link")))
+	assert.Equal(t, comments+1, testutil.ToFloat64(metrics.CommentsCreated))
+	assert.Equal(t, postVotes+1, testutil.ToFloat64(metrics.Votes.WithLabelValues("post")))
+	assert.Equal(t, commentVotes+1, testutil.ToFloat64(metrics.Votes.WithLabelValues("comment")))
+}
+
+func TestObserveRepo(t *testing.T) {
+	errs := testutil.ToFloat64(metrics.RepoErrors.WithLabelValues("test", "Get"))
+
+	metrics.ObserveRepo("test", "Get", time.Now(), nil)
+	metrics.ObserveRepo("test", "Get", time.Now(), errors.New("boom"))
+
+	assert.Equal(t, errs+1, testutil.ToFloat64(metrics.RepoErrors.WithLabelValues("test", "Get")))
+	assert.Equal(t, 1, testutil.CollectAndCount(metrics.RepoDuration, "redditclone_repository_operation_duration_seconds"))
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{id}", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+	router.Use(middleware.Metrics)
+
+	route := metrics.HTTPRequests.WithLabelValues("/api/post/{id}", http.MethodGet, "404")
+	before := testutil.ToFloat64(route)
+	for _, path := range []string{"/api/post/1", "/api/post/2"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	assert.Equal(t, before+2, testutil.ToFloat64(route))
+
+	resp := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Contains(t, resp.Body.String(), `redditclone_http_requests_total{code="404",method="GET",route="/api/post/{id}"}`)
+	assert.NotContains(t, resp.Body.String(), `route="/api/post/1"`)
+
+	other := metrics.HTTPRequests.WithLabelValues("/api/post/{id}", "OTHER", "404")
+	before = testutil.ToFloat64(other)
+	for _, method := range []string{"BREW", "X-RANDOM-1"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, "/api/post/1", nil))
+	}
+	assert.Equal(t, before+2, testutil.ToFloat64(other))
+
+	resp = httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.NotContains(t, resp.Body.String(), `method="BREW"`)
+}
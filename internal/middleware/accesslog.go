@@ -1,20 +1,48 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
+type accessKey struct{}
+
+// access collects what inner middlewares learn about a request for its
+// access log line.
+type access struct {
+	username string
+}
+
+// setAccessUser records the user the request was made by, if AccessLog is
+// logging it.
+func setAccessUser(ctx context.Context, username string) {
+	if entry, ok := ctx.Value(accessKey{}).(*access); ok {
+		entry.username = username
+	}
+}
+
 func AccessLog(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
-		next.ServeHTTP(w, r)
-		logrus.WithFields(logrus.Fields{
+		entry := &access{}
+		rec := recordResponse(w)
+		ctx := context.WithValue(r.Context(), accessKey{}, entry)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		fields := logrus.Fields{
 			"method":      r.Method,
+			"route":       routeTemplate(r),
+			"status":      rec.status,
+			"bytes":       rec.bytes,
 			"remote_addr": r.RemoteAddr,
 			"work_time":   time.Since(start),
-		}).Info(r.URL.Path)
+		}
+		if entry.username != "" {
+			fields["user"] = entry.username
+		}
+		logrus.WithContext(r.Context()).WithFields(fields).Info(r.URL.Path)
 	})
 }
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonstantinGalanin/redditclone/internal/requestid"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/session/mock"
+)
+
+// captureLogs sends the JSON log lines of the test to the returned buffer.
+func captureLogs(t *testing.T) *bytes.Buffer {
+	logger := logrus.StandardLogger()
+	out, formatter, hooks := logger.Out, logger.Formatter, logger.ReplaceHooks(make(logrus.LevelHooks))
+	t.Cleanup(func() {
+		logger.SetOutput(out)
+		logger.SetFormatter(formatter)
+		logger.ReplaceHooks(hooks)
+	})
+
+	var buf bytes.Buffer
+	logger.SetOutput(&buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(requestid.LogHook{})
+	return &buf
+}
+
+func logLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var lines []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		entry := map[string]interface{}{}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		lines = append(lines, entry)
+	}
+	return lines
+}
+
+func newTestRouter(sm session.SessionManager) *mux.Router {
+	router := mux.NewRouter()
+	private := router.NewRoute().Subrouter()
+	private.HandleFunc("/api/post/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte("hello"))
+	})
+	router.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	router.Use(RequestID)
+	router.Use(AccessLog)
+	private.Use(Session(sm))
+	router.Use(Panic)
+	return router
+}
+
+func TestRequestID(t *testing.T) {
+	captureLogs(t)
+	router := newTestRouter(nil)
+
+	cases := []struct {
+		name     string
+		incoming string
+		kept     bool
+	}{
+		{name: "accepted", incoming: "abc-123", kept: true},
+		{name: "generated", incoming: ""},
+		{name: "unsafe", incoming: "bad\nid"},
+		{name: "too long", incoming: strings.Repeat("a", 129)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/post/1", nil)
+			if tc.incoming != "" {
+				req.Header.Set(requestid.Header, tc.incoming)
+			}
+			resp := httptest.NewRecorder()
+			router.ServeHTTP(resp, req)
+
+			id := resp.Header().Get(requestid.Header)
+			assert.True(t, requestid.Valid(id))
+			if tc.kept {
+				assert.Equal(t, tc.incoming, id)
+			} else {
+				assert.NotEqual(t, tc.incoming, id)
+			}
+		})
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	logs := captureLogs(t)
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	sm := mock.NewMockSessionManager(ctrl)
+	sm.EXPECT().Check(gomock.Any(), &session.SessionID{ID: "s1"}).Return(&session.Session{Username: "alice"}, nil)
+	sm.EXPECT().Create(gomock.Any(), &session.Session{Username: "alice"}).Return(&session.SessionID{ID: "s2"}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/post/42", nil)
+	req.Header.Set(requestid.Header, "req-1")
+	req.AddCookie(&http.Cookie{Name: "session_id", Value: "s1"})
+	newTestRouter(sm).ServeHTTP(httptest.NewRecorder(), req)
+
+	lines := logLines(t, logs)
+	require.Len(t, lines, 1)
+	entry := lines[0]
+	assert.Equal(t, "/api/post/42", entry["msg"])
+	assert.Equal(t, "/api/post/{id}", entry["route"])
+	assert.Equal(t, http.MethodGet, entry["method"])
+	assert.EqualValues(t, http.StatusCreated, entry["status"])
+	assert.EqualValues(t, 5, entry["bytes"])
+	assert.Equal(t, "alice", entry["user"])
+	assert.Equal(t, "req-1", entry["request_id"])
+}
+
+func TestPanicLog(t *testing.T) {
+	logs := captureLogs(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set(requestid.Header, "req-2")
+	resp := httptest.NewRecorder()
+	newTestRouter(nil).ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+
+	lines := logLines(t, logs)
+	require.Len(t, lines, 2)
+	recovered, access := lines[0], lines[1]
+	assert.Equal(t, "Recovered from error", recovered["msg"])
+	assert.Equal(t, "req-2", recovered["request_id"])
+	assert.Contains(t, recovered["stack"], "panic")
+	assert.Equal(t, "req-2", access["request_id"])
+	assert.EqualValues(t, http.StatusInternalServerError, access["status"])
+	assert.NotContains(t, access, "user")
+}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/metrics"
+)
+
+const (
+	unmatchedRoute = "unmatched"
+	otherMethod    = "OTHER"
+)
+
+// Metrics counts requests and their latency by route template, so that
+// /api/post/1 and /api/post/2 share a series.
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := recordResponse(w)
+		next.ServeHTTP(rec, r)
+
+		route := routeTemplate(r)
+		method := methodLabel(r.Method)
+		code := strconv.Itoa(rec.status)
+		metrics.HTTPRequests.WithLabelValues(route, method, code).Inc()
+		metrics.HTTPDuration.WithLabelValues(route, method, code).Observe(time.Since(start).Seconds())
+	})
+}
+
+func routeTemplate(r *http.Request) string {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return unmatchedRoute
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return unmatchedRoute
+	}
+	return template
+}
+
+// methodLabel keeps the method label bounded: clients choose the method, so
+// anything non-standard shares one series.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return otherMethod
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+// RequireModerator authorizes a moderator endpoint and writes the error
+// response itself when the caller is not allowed in. It is shared by the
+// moderation and report queue handlers.
+func RequireModerator(w http.ResponseWriter, r *http.Request, users user.UserRepo) (*user.User, bool) {
+	var moderator *user.User
+	sess, ok := r.Context().Value("session").(*session.Session)
+	if ok && sess != nil {
+		moderator, _ = users.GetUserByUsername(r.Context(), sess.Username)
+	}
+	if moderator == nil {
+		writeErrorMsg(w, "moderation unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	if !moderator.Moderator {
+		writeErrorMsg(w, myerrors.ErrNotModerator.Error(), http.StatusForbidden)
+		return nil, false
+	}
+	return moderator, true
+}
+
+// writeErrorMsg writes the JSON error body the handlers use.
+func writeErrorMsg(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(map[string]string{"message": message}); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"net/http"
+	"runtime/debug"
 
 	"github.com/sirupsen/logrus"
 )
@@ -10,8 +11,9 @@ func Panic(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				logrus.WithFields(logrus.Fields{
+				logrus.WithContext(r.Context()).WithFields(logrus.Fields{
 					"error": err,
+					"stack": string(debug.Stack()),
 				}).Error("Recovered from error")
 				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 			}
@@ -17,7 +17,7 @@ func checkSession(r *http.Request, sm session.SessionManager) (*session.Session,
 		return nil, err
 	}
 
-	sess, err := sm.Check(&session.SessionID{
+	sess, err := sm.Check(r.Context(), &session.SessionID{
 		ID: cookieSessionID.Value,
 	})
 	if err != nil {
@@ -27,8 +27,8 @@ func checkSession(r *http.Request, sm session.SessionManager) (*session.Session,
 	return sess, nil
 }
 
-func refreshSession(w http.ResponseWriter, sess *session.Session, sm session.SessionManager) {
-	sessID, err := sm.Create(sess)
+func refreshSession(w http.ResponseWriter, r *http.Request, sess *session.Session, sm session.SessionManager) {
+	sessID, err := sm.Create(r.Context(), sess)
 	if err != nil {
 		http.Error(w, "Failed to create session", http.StatusInternalServerError)
 		return
@@ -48,9 +48,24 @@ func Session(sm session.SessionManager) func(next http.Handler) http.Handler {
 			if err != nil && sess != nil {
 				http.Error(w, err.Error(), http.StatusUnauthorized)
 			}
-			refreshSession(w, sess, sm)
+			if sess != nil {
+				setAccessUser(r.Context(), sess.Username)
+				refreshSession(w, r, sess, sm)
+			}
 			ctx := context.WithValue(r.Context(), "session", sess)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// RequireSession rejects requests the Session middleware found no session
+// for. It runs after Session on routes that only make sense signed in.
+func RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sess, ok := r.Context().Value("session").(*session.Session); !ok || sess == nil {
+			writeErrorMsg(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/KonstantinGalanin/redditclone/internal/tracing"
+)
+
+// Tracing starts a server span per request, named by route template like
+// the metrics, so the repository and session spans of the request become
+// its children.
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, span := tracing.StartServer(r, routeTemplate(r))
+		rec := recordResponse(w)
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		tracing.EndServer(span, rec.status)
+	})
+}
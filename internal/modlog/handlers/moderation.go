@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/middleware"
+	"github.com/KonstantinGalanin/redditclone/internal/modlog"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	fieldPostID  = "id"
+	queryLimit   = "limit"
+	queryOffset  = "offset"
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
+
+func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
+	newError := &ErrorMessage{
+		Message: errBody,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errStatus)
+	if err := json.NewEncoder(w).Encode(newError); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func WriteErrorModeration(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, myerrors.ErrNoPost):
+		WriteErrorMsg(w, myerrors.ErrNoPost.Error(), http.StatusNotFound)
+	case errors.Is(err, myerrors.ErrPinLimit):
+		WriteErrorMsg(w, myerrors.ErrPinLimit.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func WriteResponse(w http.ResponseWriter, body interface{}, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type ModerationHandler struct {
+	PostsRepo posts.PostRepo
+	LogRepo   modlog.LogRepo
+	UserRepo  user.UserRepo
+	Events    events.Publisher
+	PinLimit  int
+}
+
+// act applies a moderator flag change to a post and records it in the log.
+func (h *ModerationHandler) act(w http.ResponseWriter, r *http.Request, action string) {
+	moderator, ok := middleware.RequireModerator(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+	postID := mux.Vars(r)[fieldPostID]
+	if postID == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyPostID.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data struct {
+		Note string `json:"note,omitempty"`
+	}
+	// The body is optional.
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var post *posts.Post
+	var err error
+	switch action {
+	case modlog.ActionLock:
+		post, err = h.PostsRepo.SetLocked(r.Context(), postID, true)
+	case modlog.ActionUnlock:
+		post, err = h.PostsRepo.SetLocked(r.Context(), postID, false)
+	case modlog.ActionPin:
+		limit := h.PinLimit
+		if limit <= 0 {
+			limit = posts.DefaultPinLimit
+		}
+		post, err = h.PostsRepo.SetPinned(r.Context(), postID, true, limit)
+	case modlog.ActionUnpin:
+		post, err = h.PostsRepo.SetPinned(r.Context(), postID, false, 0)
+	}
+	if err != nil {
+		WriteErrorModeration(w, err)
+		return
+	}
+
+	_, err = h.LogRepo.Add(r.Context(), &modlog.Entry{
+		Action:    action,
+		Moderator: moderator.Username,
+		PostID:    post.ID,
+		Category:  post.Category,
+		Note:      data.Note,
+		Created:   time.Now(),
+	})
+	if err != nil {
+		WriteErrorModeration(w, err)
+		return
+	}
+	if h.Events != nil {
+		h.Events.Publish(r.Context(), &events.Event{
+			Type:   events.ModeratorAction,
+			PostID: post.ID,
+			Actor:  moderator,
+			Post:   post,
+			Action: action,
+			Target: post.Author,
+		})
+	}
+
+	post.Redact()
+	post.Render()
+	WriteResponse(w, post, http.StatusOK)
+}
+
+// Lock closes a post for new comments.
+func (h *ModerationHandler) Lock(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, modlog.ActionLock)
+}
+
+func (h *ModerationHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, modlog.ActionUnlock)
+}
+
+// Pin keeps a post on top of its category listing.
+func (h *ModerationHandler) Pin(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, modlog.ActionPin)
+}
+
+func (h *ModerationHandler) Unpin(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, modlog.ActionUnpin)
+}
+
+// Log lists moderator actions, newest first.
+func (h *ModerationHandler) Log(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.RequireModerator(w, r, h.UserRepo); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := getNonNegative(query.Get(queryLimit))
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit == 0 {
+		limit = defaultLimit
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+	offset, err := getNonNegative(query.Get(queryOffset))
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := h.LogRepo.List(r.Context(), limit, offset)
+	if err != nil {
+		WriteErrorModeration(w, err)
+		return
+	}
+	WriteResponse(w, entries, http.StatusOK)
+}
+
+func getNonNegative(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, myerrors.ErrBadPagination
+	}
+	return n, nil
+}
@@ -0,0 +1,315 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/modlog"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+
+	repositoryModlog "github.com/KonstantinGalanin/redditclone/internal/modlog/repository"
+	repositoryPosts "github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+const (
+	username = "User"
+	postID   = "1"
+)
+
+var (
+	expectedUser = &user.User{
+		Username: username,
+		ID:       "1",
+	}
+	moderatorUser = &user.User{
+		Username:  username,
+		ID:        "1",
+		Moderator: true,
+	}
+	author = &user.User{
+		Username: "author",
+		ID:       "2",
+	}
+)
+
+func newRequest(body string, withSession bool, vars map[string]string) *http.Request {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	if withSession {
+		req = sessiontest.SignIn(req, username)
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+type mocks struct {
+	postsRepo *repositoryPosts.MockPostRepo
+	logRepo   *repositoryModlog.MockLogRepo
+	userRepo  *repositoryUser.MockUserRepo
+}
+
+func newMockService(ctrl *gomock.Controller) (*ModerationHandler, *mocks) {
+	m := &mocks{
+		postsRepo: repositoryPosts.NewMockPostRepo(ctrl),
+		logRepo:   repositoryModlog.NewMockLogRepo(ctrl),
+		userRepo:  repositoryUser.NewMockUserRepo(ctrl),
+	}
+	return &ModerationHandler{
+		PostsRepo: m.postsRepo,
+		LogRepo:   m.logRepo,
+		UserRepo:  m.userRepo,
+	}, m
+}
+
+func TestWriteErrorModeration(t *testing.T) {
+	cases := []struct {
+		err        error
+		statusCode int
+	}{
+		{myerrors.ErrNoPost, http.StatusNotFound},
+		{myerrors.ErrPinLimit, http.StatusConflict},
+		{errors.New("some error"), http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		WriteErrorModeration(recorder, c.err)
+		assert.Equal(t, c.statusCode, recorder.Code)
+	}
+}
+
+func TestAct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+	var published []*events.Event
+	bus := events.NewBus()
+	bus.Subscribe(events.ListenerFunc(func(_ context.Context, event *events.Event) {
+		published = append(published, event)
+	}))
+	service.Events = bus
+
+	vars := map[string]string{fieldPostID: postID}
+	post := func(locked, pinned bool) *posts.Post {
+		return &posts.Post{ID: postID, Category: "music", Author: author, Locked: locked, Pinned: pinned}
+	}
+	logged := func(action, note string) {
+		m.logRepo.EXPECT().Add(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, entry *modlog.Entry) (*modlog.Entry, error) {
+			assert.Equal(t, action, entry.Action)
+			assert.Equal(t, username, entry.Moderator)
+			assert.Equal(t, postID, entry.PostID)
+			assert.Equal(t, "music", entry.Category)
+			assert.Equal(t, note, entry.Note)
+			return entry, nil
+		})
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		req        *http.Request
+		handler    http.HandlerFunc
+		expect     func()
+		action     string
+	}{
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest("", false, vars),
+			handler:    service.Lock,
+		},
+		{
+			name:       "not moderator",
+			statusCode: http.StatusForbidden,
+			req:        newRequest("", true, vars),
+			handler:    service.Lock,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+			},
+		},
+		{
+			name:       "empty post id",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest("", true, nil),
+			handler:    service.Lock,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+			},
+		},
+		{
+			name:       "bad body",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest("{", true, vars),
+			handler:    service.Lock,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+			},
+		},
+		{
+			name:       "no post",
+			statusCode: http.StatusNotFound,
+			req:        newRequest("", true, vars),
+			handler:    service.Lock,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.postsRepo.EXPECT().SetLocked(gomock.Any(), postID, true).Return(nil, myerrors.ErrNoPost)
+			},
+		},
+		{
+			name:       "pin limit",
+			statusCode: http.StatusConflict,
+			req:        newRequest("", true, vars),
+			handler:    service.Pin,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.postsRepo.EXPECT().SetPinned(gomock.Any(), postID, true, posts.DefaultPinLimit).Return(nil, myerrors.ErrPinLimit)
+			},
+		},
+		{
+			name:       "log error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest("", true, vars),
+			handler:    service.Unlock,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.postsRepo.EXPECT().SetLocked(gomock.Any(), postID, false).Return(post(false, false), nil)
+				m.logRepo.EXPECT().Add(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "lock",
+			statusCode: http.StatusOK,
+			req:        newRequest(`{"note":"heated"}`, true, vars),
+			handler:    service.Lock,
+			action:     modlog.ActionLock,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.postsRepo.EXPECT().SetLocked(gomock.Any(), postID, true).Return(post(true, false), nil)
+				logged(modlog.ActionLock, "heated")
+			},
+		},
+		{
+			name:       "unlock",
+			statusCode: http.StatusOK,
+			req:        newRequest("", true, vars),
+			handler:    service.Unlock,
+			action:     modlog.ActionUnlock,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.postsRepo.EXPECT().SetLocked(gomock.Any(), postID, false).Return(post(false, false), nil)
+				logged(modlog.ActionUnlock, "")
+			},
+		},
+		{
+			name:       "pin",
+			statusCode: http.StatusOK,
+			req:        newRequest("", true, vars),
+			handler:    service.Pin,
+			action:     modlog.ActionPin,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.postsRepo.EXPECT().SetPinned(gomock.Any(), postID, true, posts.DefaultPinLimit).Return(post(false, true), nil)
+				logged(modlog.ActionPin, "")
+			},
+		},
+		{
+			name:       "unpin",
+			statusCode: http.StatusOK,
+			req:        newRequest("", true, vars),
+			handler:    service.Unpin,
+			action:     modlog.ActionUnpin,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.postsRepo.EXPECT().SetPinned(gomock.Any(), postID, false, 0).Return(post(false, false), nil)
+				logged(modlog.ActionUnpin, "")
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			published = nil
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			c.handler(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+
+			if c.action == "" {
+				assert.Empty(t, published)
+				return
+			}
+			assert.Len(t, published, 1)
+			assert.Equal(t, events.ModeratorAction, published[0].Type)
+			assert.Equal(t, c.action, published[0].Action)
+			assert.Equal(t, author, published[0].Target)
+		})
+	}
+}
+
+func TestLog(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+
+	cases := []struct {
+		name       string
+		statusCode int
+		query      string
+		expect     func()
+	}{
+		{
+			name:       "bad limit",
+			statusCode: http.StatusBadRequest,
+			query:      "?limit=-1",
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+			},
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.logRepo.EXPECT().List(gomock.Any(), defaultLimit, 0).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "capped limit",
+			statusCode: http.StatusOK,
+			query:      "?limit=1000&offset=5",
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.logRepo.EXPECT().List(gomock.Any(), maxLimit, 5).Return([]*modlog.Entry{{ID: "1"}}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.expect()
+			req := sessiontest.SignIn(httptest.NewRequest(http.MethodGet, "/"+c.query, nil), username)
+			recorder := httptest.NewRecorder()
+			service.Log(recorder, req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
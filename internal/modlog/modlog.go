@@ -0,0 +1,30 @@
+package modlog
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ActionLock   = "lock"
+	ActionUnlock = "unlock"
+	ActionPin    = "pin"
+	ActionUnpin  = "unpin"
+)
+
+// Entry records one moderator action on a post.
+type Entry struct {
+	ID        string    `json:"id" bson:"_id"`
+	Action    string    `json:"action" bson:"action"`
+	Moderator string    `json:"moderator" bson:"moderator"`
+	PostID    string    `json:"postId" bson:"post"`
+	Category  string    `json:"category" bson:"category"`
+	Note      string    `json:"note,omitempty" bson:"note,omitempty"`
+	Created   time.Time `json:"created" bson:"created"`
+}
+
+//go:generate mockgen -source=modlog.go -destination=repository/repo_mock.go -package=repository LogRepo
+type LogRepo interface {
+	Add(ctx context.Context, entry *Entry) (*Entry, error)
+	List(ctx context.Context, limit, offset int) ([]*Entry, error)
+}
@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KonstantinGalanin/redditclone/internal/modlog"
+)
+
+const (
+	TimeoutVal = 10
+)
+
+type LogMongoDB struct {
+	db *mongo.Collection
+}
+
+func NewLogMongoDB(db *mongo.Collection) *LogMongoDB {
+	return &LogMongoDB{
+		db: db,
+	}
+}
+
+func (l *LogMongoDB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, TimeoutVal*time.Second)
+}
+
+// Add appends an entry to the log. Entries are never changed afterwards.
+func (l *LogMongoDB) Add(ctx context.Context, entry *modlog.Entry) (*modlog.Entry, error) {
+	newEntry := *entry
+	newEntry.ID = uuid.New().String()
+	if newEntry.Created.IsZero() {
+		newEntry.Created = time.Now()
+	}
+
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	if _, err := l.db.InsertOne(ctx, &newEntry); err != nil {
+		return nil, fmt.Errorf("mongodb add log entry: %w", err)
+	}
+	return &newEntry, nil
+}
+
+// List returns the log newest first.
+func (l *LogMongoDB) List(ctx context.Context, limit, offset int) ([]*modlog.Entry, error) {
+	entries := []*modlog.Entry{}
+	opts := options.Find().
+		SetSort(bson.M{"created": -1}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	ctx, cancel := l.withTimeout(ctx)
+	defer cancel()
+	c, err := l.db.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb list log: %w", err)
+	}
+	if err = c.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("mongodb list log: %w", err)
+	}
+	return entries, nil
+}
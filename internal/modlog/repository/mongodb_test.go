@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/KonstantinGalanin/redditclone/internal/modlog"
+)
+
+var entryData = bson.D{
+	{Key: "_id", Value: "1"},
+	{Key: "action", Value: modlog.ActionLock},
+	{Key: "moderator", Value: "mod"},
+	{Key: "post", Value: "2"},
+	{Key: "category", Value: "music"},
+	{Key: "created", Value: time.Now()},
+}
+
+func TestNewLogMongoDB(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("test", func(mt *mtest.T) {
+		assert.NotNil(t, NewLogMongoDB(mt.Coll))
+	})
+}
+
+func TestAdd(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		entry, err := NewLogMongoDB(mt.Coll).Add(context.Background(), &modlog.Entry{Action: modlog.ActionPin, Moderator: "mod", PostID: "2"})
+		assert.NoError(t, err)
+		assert.NotEmpty(t, entry.ID)
+		assert.False(t, entry.Created.IsZero())
+		assert.Equal(t, modlog.ActionPin, entry.Action)
+	})
+
+	mt.Run("insert error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 1, Message: "some error"}))
+		entry, err := NewLogMongoDB(mt.Coll).Add(context.Background(), &modlog.Entry{Action: modlog.ActionPin})
+		assert.Error(t, err)
+		assert.Nil(t, entry)
+	})
+}
+
+func TestList(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "reddit.modlog", mtest.FirstBatch, entryData),
+			mtest.CreateCursorResponse(0, "reddit.modlog", mtest.NextBatch),
+		)
+		entries, err := NewLogMongoDB(mt.Coll).List(context.Background(), 10, 0)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, "2", entries[0].PostID)
+	})
+
+	mt.Run("find error", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		_, err := NewLogMongoDB(mt.Coll).List(context.Background(), 10, 0)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: modlog.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	modlog "github.com/KonstantinGalanin/redditclone/internal/modlog"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockLogRepo is a mock of LogRepo interface.
+type MockLogRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockLogRepoMockRecorder
+}
+
+// MockLogRepoMockRecorder is the mock recorder for MockLogRepo.
+type MockLogRepoMockRecorder struct {
+	mock *MockLogRepo
+}
+
+// NewMockLogRepo creates a new mock instance.
+func NewMockLogRepo(ctrl *gomock.Controller) *MockLogRepo {
+	mock := &MockLogRepo{ctrl: ctrl}
+	mock.recorder = &MockLogRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLogRepo) EXPECT() *MockLogRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockLogRepo) Add(ctx context.Context, entry *modlog.Entry) (*modlog.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", ctx, entry)
+	ret0, _ := ret[0].(*modlog.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockLogRepoMockRecorder) Add(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockLogRepo)(nil).Add), ctx, entry)
+}
+
+// List mocks base method.
+func (m *MockLogRepo) List(ctx context.Context, limit, offset int) ([]*modlog.Entry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, limit, offset)
+	ret0, _ := ret[0].([]*modlog.Entry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockLogRepoMockRecorder) List(ctx, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockLogRepo)(nil).List), ctx, limit, offset)
+}
@@ -5,6 +5,7 @@ import "errors"
 var (
 	ErrBadPass           = errors.New("invalid password")
 	ErrNoPost            = errors.New("no post with this id")
+	ErrNoComment         = errors.New("no comment with this id")
 	ErrNoUser            = errors.New("user not found")
 	ErrUserExist         = errors.New("user with this username already exist")
 	ErrInvalidChars      = errors.New("contains invalid characters")
@@ -15,4 +16,36 @@ var (
 	ErrEmptyUsername     = errors.New("empty username in url")
 	ErrRedisSetNotOk     = errors.New("redis set: result not OK")
 	ErrNoAuth            = errors.New("no session found")
+	ErrBadSort           = errors.New("unknown sort order")
+	ErrBadReason         = errors.New("unknown report reason")
+	ErrBadAction         = errors.New("unknown moderator action")
+	ErrNoReport          = errors.New("no report with this id")
+	ErrAlreadyReported   = errors.New("already reported")
+	ErrNotModerator      = errors.New("moderator rights required")
+	ErrSuspended         = errors.New("user is suspended")
+	ErrBadPagination     = errors.New("limit and offset must be non-negative integers")
+	ErrNoNotification    = errors.New("no notification with this id")
+	ErrBadPoll           = errors.New("poll needs 2 to 10 non-empty options and a closing time in the future")
+	ErrNotPoll           = errors.New("post is not a poll")
+	ErrPollClosed        = errors.New("poll is closed")
+	ErrNoPollOption      = errors.New("no poll option with this id")
+	ErrNoBlob            = errors.New("blob not found")
+	ErrBadBlobKey        = errors.New("invalid blob key")
+	ErrBadImageType      = errors.New("unsupported image type")
+	ErrImageTooLarge     = errors.New("image is too large")
+	ErrImageUpload       = errors.New("image posts must be uploaded as multipart form")
+	ErrNotDraft          = errors.New("post is already published")
+	ErrBadSchedule       = errors.New("publish time must be in the future")
+	ErrPostLocked        = errors.New("post is locked")
+	ErrPinLimit          = errors.New("category already has the maximum number of pinned posts")
+	ErrNotAuthor         = errors.New("only the author or a moderator can change this post")
+	ErrRejected          = errors.New("content was rejected by the filter")
+	ErrHeld              = errors.New("post is held for review")
+	ErrNotHeld           = errors.New("item is not held for review")
+	ErrBlockSelf         = errors.New("you cannot block yourself")
+	ErrBlocked           = errors.New("this user has blocked you")
+	ErrBlockedUser       = errors.New("you have blocked this user")
+	ErrMessageSelf       = errors.New("you cannot message yourself")
+	ErrEmptyMessage      = errors.New("message is empty")
+	ErrMessageTooLong    = errors.New("message is too long")
 )
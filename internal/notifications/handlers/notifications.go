@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/notifications"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	successMsg          = "success"
+	unauthorizedMsg     = "unauthorized"
+	fieldNotificationID = "notificationID"
+	queryUnread         = "unread"
+	queryLimit          = "limit"
+	queryOffset         = "offset"
+)
+
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
+
+func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
+	newError := &ErrorMessage{
+		Message: errBody,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errStatus)
+	if err := json.NewEncoder(w).Encode(newError); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func WriteErrorNotification(w http.ResponseWriter, err error) {
+	if errors.Is(err, myerrors.ErrNoNotification) {
+		WriteErrorMsg(w, myerrors.ErrNoNotification.Error(), http.StatusNotFound)
+	} else {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func WriteResponse(w http.ResponseWriter, data interface{}, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type Inbox struct {
+	Unread        int64                         `json:"unread"`
+	Notifications []*notifications.Notification `json:"notifications"`
+}
+
+type NotificationsHandler struct {
+	NotificationRepo notifications.NotificationRepo
+	UserRepo         user.UserRepo
+}
+
+func (h *NotificationsHandler) getUserFromCtx(r *http.Request) (*user.User, error) {
+	ctx := r.Context()
+	sess, ok := ctx.Value("session").(*session.Session)
+	if !ok || sess == nil {
+		return nil, myerrors.ErrNoAuth
+	}
+
+	user, err := h.UserRepo.GetUserByUsername(r.Context(), sess.Username)
+	if err != nil {
+		return nil, fmt.Errorf("get user from sess: %w", err)
+	}
+	return user, nil
+}
+
+// List returns the caller's newest notifications together with the total
+// number of unread ones. ?unread=true limits the page to unread entries.
+func (h *NotificationsHandler) List(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	unreadOnly := query.Get(queryUnread) == "true"
+	limit, err := getNonNegative(query.Get(queryLimit))
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := getNonNegative(query.Get(queryOffset))
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("notifications %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	items, err := h.NotificationRepo.GetByUser(r.Context(), user.ID, unreadOnly, limit, offset)
+	if err != nil {
+		WriteErrorNotification(w, err)
+		return
+	}
+	unread, err := h.NotificationRepo.CountUnread(r.Context(), user.ID)
+	if err != nil {
+		WriteErrorNotification(w, err)
+		return
+	}
+
+	WriteResponse(w, &Inbox{Unread: unread, Notifications: items}, http.StatusOK)
+}
+
+func (h *NotificationsHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	notificationID := mux.Vars(r)[fieldNotificationID]
+	if notificationID == "" {
+		WriteErrorMsg(w, myerrors.ErrNoNotification.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := h.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("mark read %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err = h.NotificationRepo.MarkRead(r.Context(), user.ID, notificationID); err != nil {
+		WriteErrorNotification(w, err)
+		return
+	}
+	WriteResponse(w, &ErrorMessage{Message: successMsg}, http.StatusOK)
+}
+
+func (h *NotificationsHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	user, err := h.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("mark all read %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err = h.NotificationRepo.MarkAllRead(r.Context(), user.ID); err != nil {
+		WriteErrorNotification(w, err)
+		return
+	}
+	WriteResponse(w, &ErrorMessage{Message: successMsg}, http.StatusOK)
+}
+
+func getNonNegative(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, myerrors.ErrBadPagination
+	}
+	return n, nil
+}
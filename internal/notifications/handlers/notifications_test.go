@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/notifications"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+
+	repositoryNotifications "github.com/KonstantinGalanin/redditclone/internal/notifications/repository"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+const (
+	username       = "User"
+	notificationID = "2"
+)
+
+var expectedUser = &user.User{
+	Username: username,
+	Password: "password",
+	ID:       "1",
+}
+
+func newRequest(withSession bool, target string, vars map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if withSession {
+		req = sessiontest.SignIn(req, username)
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+func TestList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := repositoryNotifications.NewMockNotificationRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &NotificationsHandler{
+		NotificationRepo: notificationRepo,
+		UserRepo:         userRepo,
+	}
+
+	items := []*notifications.Notification{{ID: notificationID, Type: notifications.TypeMention}}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "bad pagination",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(true, "/api/notifications?limit=-1", nil),
+		},
+		{
+			name:       "bad offset",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(true, "/api/notifications?offset=x", nil),
+		},
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, "/api/notifications", nil),
+		},
+		{
+			name:       "get error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, "/api/notifications", nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				notificationRepo.EXPECT().GetByUser(gomock.Any(), expectedUser.ID, false, 0, 0).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "count error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, "/api/notifications", nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				notificationRepo.EXPECT().GetByUser(gomock.Any(), expectedUser.ID, false, 0, 0).Return(items, nil)
+				notificationRepo.EXPECT().CountUnread(gomock.Any(), expectedUser.ID).Return(int64(0), errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, "/api/notifications?unread=true&limit=10&offset=5", nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				notificationRepo.EXPECT().GetByUser(gomock.Any(), expectedUser.ID, true, 10, 5).Return(items, nil)
+				notificationRepo.EXPECT().CountUnread(gomock.Any(), expectedUser.ID).Return(int64(7), nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.List(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+
+			if c.statusCode == http.StatusOK {
+				inbox := &Inbox{}
+				assert.NoError(t, json.NewDecoder(recorder.Body).Decode(inbox))
+				assert.Equal(t, int64(7), inbox.Unread)
+				assert.Len(t, inbox.Notifications, 1)
+			}
+		})
+	}
+}
+
+func TestMarkRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := repositoryNotifications.NewMockNotificationRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &NotificationsHandler{
+		NotificationRepo: notificationRepo,
+		UserRepo:         userRepo,
+	}
+	vars := map[string]string{fieldNotificationID: notificationID}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "no id",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(true, "/", nil),
+		},
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, "/", vars),
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(true, "/", vars),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				notificationRepo.EXPECT().MarkRead(gomock.Any(), expectedUser.ID, notificationID).
+					Return(fmt.Errorf("mongodb mark notification read: %w", myerrors.ErrNoNotification))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, "/", vars),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				notificationRepo.EXPECT().MarkRead(gomock.Any(), expectedUser.ID, notificationID).Return(nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.MarkRead(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestMarkAllRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	notificationRepo := repositoryNotifications.NewMockNotificationRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &NotificationsHandler{
+		NotificationRepo: notificationRepo,
+		UserRepo:         userRepo,
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, "/", nil),
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, "/", nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				notificationRepo.EXPECT().MarkAllRead(gomock.Any(), expectedUser.ID).Return(errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, "/", nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				notificationRepo.EXPECT().MarkAllRead(gomock.Any(), expectedUser.ID).Return(nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.MarkAllRead(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package notifications
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	TypePostComment     = "post-comment"
+	TypeCommentReply    = "comment-reply"
+	TypeMention         = "mention"
+	TypeModeratorAction = "moderator-action"
+)
+
+type Notification struct {
+	ID        string    `json:"id" bson:"_id"`
+	UserID    string    `json:"-" bson:"user"`
+	Type      string    `json:"type" bson:"type"`
+	Actor     string    `json:"actor,omitempty" bson:"actor,omitempty"`
+	PostID    string    `json:"postId" bson:"post"`
+	CommentID string    `json:"commentId,omitempty" bson:"comment,omitempty"`
+	Text      string    `json:"text,omitempty" bson:"text,omitempty"`
+	Read      bool      `json:"read" bson:"read"`
+	Created   time.Time `json:"created" bson:"created"`
+}
+
+//go:generate mockgen -source=notifications.go -destination=repository/repo_mock.go -package=repository NotificationRepo
+type NotificationRepo interface {
+	Create(ctx context.Context, notification *Notification) error
+	GetByUser(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*Notification, error)
+	CountUnread(ctx context.Context, userID string) (int64, error)
+	MarkRead(ctx context.Context, userID, notificationID string) error
+	MarkAllRead(ctx context.Context, userID string) error
+}
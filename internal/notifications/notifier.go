@@ -0,0 +1,132 @@
+package notifications
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	snippetLen = 140
+	// maxMentions caps how many users one post or comment can notify by
+	// mentioning them.
+	maxMentions = 10
+)
+
+// Notifier listens to post events and records notifications for the users
+// they concern. A user gets at most one notification per event.
+type Notifier struct {
+	Repo     NotificationRepo
+	UserRepo user.UserRepo
+}
+
+func (n *Notifier) Handle(ctx context.Context, event *events.Event) {
+	for _, notification := range n.build(ctx, event) {
+		if err := n.Repo.Create(ctx, notification); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("type", notification.Type).Error("create notification failed")
+		}
+	}
+}
+
+func (n *Notifier) build(ctx context.Context, event *events.Event) []*Notification {
+	var actor string
+	if event.Actor != nil {
+		actor = event.Actor.Username
+	}
+	base := Notification{
+		Actor:     actor,
+		PostID:    event.PostID,
+		CommentID: event.CommentID,
+		Created:   time.Now(),
+	}
+
+	notified := map[string]bool{}
+	if event.Actor != nil {
+		notified[event.Actor.ID] = true
+	}
+	var result []*Notification
+	add := func(recipient *user.User, kind, text string) {
+		if recipient == nil || recipient.ID == "" || notified[recipient.ID] {
+			return
+		}
+		notified[recipient.ID] = true
+		notification := base
+		notification.UserID = recipient.ID
+		notification.Type = kind
+		notification.Text = text
+		result = append(result, &notification)
+	}
+
+	switch event.Type {
+	case events.PostCreated:
+		if event.Post != nil {
+			text := event.Post.Title + " " + event.Post.Text
+			for _, mentioned := range n.mentions(ctx, text) {
+				add(mentioned, TypeMention, snippet(event.Post.Title))
+			}
+		}
+	case events.CommentCreated:
+		if event.Comment == nil {
+			return nil
+		}
+		text := snippet(event.Comment.Body)
+		if event.Post != nil && event.Comment.ParentID != "" {
+			for _, comment := range event.Post.Comments {
+				if comment.ID == event.Comment.ParentID && comment.Deleted == nil {
+					add(comment.Author, TypeCommentReply, text)
+				}
+			}
+		}
+		if event.Post != nil && event.Post.Deleted == nil {
+			add(event.Post.Author, TypePostComment, text)
+		}
+		for _, mentioned := range n.mentions(ctx, event.Comment.Body) {
+			add(mentioned, TypeMention, text)
+		}
+	case events.ModeratorAction:
+		add(event.Target, TypeModeratorAction, event.Action)
+	}
+	return result
+}
+
+// mentions resolves @username references to existing users in one query,
+// ignoring unknown names. Names are compared case-insensitively and only the
+// first maxMentions count.
+func (n *Notifier) mentions(ctx context.Context, text string) []*user.User {
+	var usernames []string
+	seen := map[string]bool{}
+	for _, username := range user.Mentions(text) {
+		key := strings.ToLower(username)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, username)
+		if len(usernames) == maxMentions {
+			break
+		}
+	}
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	users, err := n.UserRepo.GetUsersByUsernames(ctx, usernames)
+	if err != nil {
+		logrus.WithContext(ctx).WithError(err).Error("resolve mentions failed")
+		return nil
+	}
+	return users
+}
+
+func snippet(text string) string {
+	runes := []rune(text)
+	if len(runes) <= snippetLen {
+		return text
+	}
+	return string(runes[:snippetLen]) + "…"
+}
@@ -0,0 +1,197 @@
+package notifications_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/notifications"
+	notificationsRepository "github.com/KonstantinGalanin/redditclone/internal/notifications/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/requestid"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+	userRepository "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+var (
+	postAuthor    = &user.User{ID: "1", Username: "author"}
+	parentAuthor  = &user.User{ID: "2", Username: "parent"}
+	commenter     = &user.User{ID: "3", Username: "commenter"}
+	mentionedUser = &user.User{ID: "4", Username: "friend"}
+)
+
+func TestNotifierHandle(t *testing.T) {
+	parent := &posts.Comment{ID: "c1", Author: parentAuthor, Body: "first"}
+	post := &posts.Post{ID: "p1", Author: postAuthor, Title: "title", Comments: []*posts.Comment{parent}}
+
+	cases := []struct {
+		name       string
+		event      *events.Event
+		userExpect func(repo *userRepository.MockUserRepo)
+		expect     []*notifications.Notification
+	}{
+		{
+			name: "comment on post",
+			event: &events.Event{
+				Type: events.CommentCreated, PostID: "p1", CommentID: "c2", Actor: commenter, Post: post,
+				Comment: &posts.Comment{ID: "c2", Author: commenter, Body: "nice"},
+			},
+			userExpect: func(repo *userRepository.MockUserRepo) {},
+			expect: []*notifications.Notification{
+				{UserID: "1", Type: notifications.TypePostComment, Actor: "commenter", PostID: "p1", CommentID: "c2", Text: "nice"},
+			},
+		},
+		{
+			name: "reply with mentions",
+			event: &events.Event{
+				Type: events.CommentCreated, PostID: "p1", CommentID: "c2", Actor: commenter, Post: post,
+				Comment: &posts.Comment{ID: "c2", Author: commenter, ParentID: "c1", Body: "@friend @nobody @parent look, mail@example.com"},
+			},
+			userExpect: func(repo *userRepository.MockUserRepo) {
+				repo.EXPECT().GetUsersByUsernames(gomock.Any(), []string{"friend", "nobody", "parent"}).Return([]*user.User{mentionedUser, parentAuthor}, nil)
+			},
+			expect: []*notifications.Notification{
+				{UserID: "2", Type: notifications.TypeCommentReply, Actor: "commenter", PostID: "p1", CommentID: "c2", Text: "@friend @nobody @parent look, mail@example.com"},
+				{UserID: "1", Type: notifications.TypePostComment, Actor: "commenter", PostID: "p1", CommentID: "c2", Text: "@friend @nobody @parent look, mail@example.com"},
+				{UserID: "4", Type: notifications.TypeMention, Actor: "commenter", PostID: "p1", CommentID: "c2", Text: "@friend @nobody @parent look, mail@example.com"},
+			},
+		},
+		{
+			name: "mentions are deduplicated and capped",
+			event: &events.Event{
+				Type: events.CommentCreated, PostID: "p1", CommentID: "c2", Actor: postAuthor, Post: post,
+				Comment: &posts.Comment{ID: "c2", Author: postAuthor, Body: "@Friend @friend @a @b @c @d @e @f @g @h @i @j"},
+			},
+			userExpect: func(repo *userRepository.MockUserRepo) {
+				names := []string{"Friend", "a", "b", "c", "d", "e", "f", "g", "h", "i"}
+				repo.EXPECT().GetUsersByUsernames(gomock.Any(), names).Return([]*user.User{mentionedUser}, nil)
+			},
+			expect: []*notifications.Notification{
+				{UserID: "4", Type: notifications.TypeMention, Actor: "author", PostID: "p1", CommentID: "c2", Text: "@Friend @friend @a @b @c @d @e @f @g @h @i @j"},
+			},
+		},
+		{
+			name: "mention lookup error",
+			event: &events.Event{
+				Type: events.CommentCreated, PostID: "p1", CommentID: "c2", Actor: commenter, Post: post,
+				Comment: &posts.Comment{ID: "c2", Author: commenter, Body: "@friend"},
+			},
+			userExpect: func(repo *userRepository.MockUserRepo) {
+				repo.EXPECT().GetUsersByUsernames(gomock.Any(), []string{"friend"}).Return(nil, errors.New("down"))
+			},
+			expect: []*notifications.Notification{
+				{UserID: "1", Type: notifications.TypePostComment, Actor: "commenter", PostID: "p1", CommentID: "c2", Text: "@friend"},
+			},
+		},
+		{
+			name: "own post is skipped",
+			event: &events.Event{
+				Type: events.CommentCreated, PostID: "p1", CommentID: "c2", Actor: postAuthor, Post: post,
+				Comment: &posts.Comment{ID: "c2", Author: postAuthor, Body: "thanks"},
+			},
+			userExpect: func(repo *userRepository.MockUserRepo) {},
+			expect:     nil,
+		},
+		{
+			name: "mention in post",
+			event: &events.Event{
+				Type: events.PostCreated, PostID: "p2", Actor: postAuthor,
+				Post: &posts.Post{ID: "p2", Author: postAuthor, Title: "hello", Text: "cc @friend"},
+			},
+			userExpect: func(repo *userRepository.MockUserRepo) {
+				repo.EXPECT().GetUsersByUsernames(gomock.Any(), []string{"friend"}).Return([]*user.User{mentionedUser}, nil)
+			},
+			expect: []*notifications.Notification{
+				{UserID: "4", Type: notifications.TypeMention, Actor: "author", PostID: "p2", Text: "hello"},
+			},
+		},
+		{
+			name: "moderator action",
+			event: &events.Event{
+				Type: events.ModeratorAction, PostID: "p1", Actor: &user.User{ID: "9", Username: "mod"},
+				Action: "warn", Target: postAuthor,
+			},
+			userExpect: func(repo *userRepository.MockUserRepo) {},
+			expect: []*notifications.Notification{
+				{UserID: "1", Type: notifications.TypeModeratorAction, Actor: "mod", PostID: "p1", Text: "warn"},
+			},
+		},
+		{
+			name:       "other events",
+			event:      &events.Event{Type: events.ScoreChanged, PostID: "p1", Actor: commenter, Post: post},
+			userExpect: func(repo *userRepository.MockUserRepo) {},
+			expect:     nil,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := notificationsRepository.NewMockNotificationRepo(ctrl)
+			userRepo := userRepository.NewMockUserRepo(ctrl)
+			c.userExpect(userRepo)
+
+			var got []*notifications.Notification
+			repo.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, n *notifications.Notification) error {
+				got = append(got, n)
+				return nil
+			}).AnyTimes()
+
+			notifier := &notifications.Notifier{Repo: repo, UserRepo: userRepo}
+			notifier.Handle(context.Background(), c.event)
+
+			assert.Len(t, got, len(c.expect))
+			for i, expect := range c.expect {
+				assert.False(t, got[i].Created.IsZero())
+				got[i].Created = expect.Created
+				assert.Equal(t, expect, got[i])
+			}
+		})
+	}
+}
+
+func TestNotifierHandleError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := notificationsRepository.NewMockNotificationRepo(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("some error"))
+
+	notifier := &notifications.Notifier{Repo: repo, UserRepo: userRepository.NewMockUserRepo(ctrl)}
+	notifier.Handle(context.Background(), &events.Event{Type: events.ModeratorAction, Action: "remove", Target: postAuthor})
+}
+
+func TestNotifierLogsRequestID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var logs bytes.Buffer
+	logger := logrus.StandardLogger()
+	out, hooks := logger.Out, logger.ReplaceHooks(make(logrus.LevelHooks))
+	defer func() {
+		logger.SetOutput(out)
+		logger.ReplaceHooks(hooks)
+	}()
+	logger.SetOutput(&logs)
+	logger.AddHook(requestid.LogHook{})
+
+	repo := notificationsRepository.NewMockNotificationRepo(ctrl)
+	repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(errors.New("down"))
+	notifier := &notifications.Notifier{Repo: repo, UserRepo: userRepository.NewMockUserRepo(ctrl)}
+
+	ctx := requestid.NewContext(context.Background(), "req-7")
+	notifier.Handle(ctx, &events.Event{
+		Type: events.CommentCreated, PostID: "p1", CommentID: "c2", Actor: commenter,
+		Post:    &posts.Post{ID: "p1", Author: postAuthor},
+		Comment: &posts.Comment{ID: "c2", Author: commenter, Body: "nice"},
+	})
+	assert.Contains(t, logs.String(), "request_id=req-7")
+}
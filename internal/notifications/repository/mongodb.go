@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/notifications"
+)
+
+const (
+	TimeoutVal = 10
+)
+
+type NotificationMongoDB struct {
+	db *mongo.Collection
+}
+
+func NewNotificationMongoDB(db *mongo.Collection) *NotificationMongoDB {
+	return &NotificationMongoDB{
+		db: db,
+	}
+}
+
+func (n *NotificationMongoDB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, TimeoutVal*time.Second)
+}
+
+func (n *NotificationMongoDB) Create(ctx context.Context, notification *notifications.Notification) error {
+	if notification.ID == "" {
+		notification.ID = uuid.New().String()
+	}
+	if notification.Created.IsZero() {
+		notification.Created = time.Now()
+	}
+
+	ctx, cancel := n.withTimeout(ctx)
+	defer cancel()
+	if _, err := n.db.InsertOne(ctx, notification); err != nil {
+		return fmt.Errorf("mongodb create notification: %w", err)
+	}
+	return nil
+}
+
+func (n *NotificationMongoDB) GetByUser(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]*notifications.Notification, error) {
+	items := []*notifications.Notification{}
+	filter := bson.M{"user": userID}
+	if unreadOnly {
+		filter["read"] = false
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	ctx, cancel := n.withTimeout(ctx)
+	defer cancel()
+	c, err := n.db.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get notifications: %w", err)
+	}
+	if err = c.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("mongodb get notifications: %w", err)
+	}
+
+	return items, nil
+}
+
+func (n *NotificationMongoDB) CountUnread(ctx context.Context, userID string) (int64, error) {
+	ctx, cancel := n.withTimeout(ctx)
+	defer cancel()
+	count, err := n.db.CountDocuments(ctx, bson.M{"user": userID, "read": false})
+	if err != nil {
+		return 0, fmt.Errorf("mongodb count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+func (n *NotificationMongoDB) MarkRead(ctx context.Context, userID, notificationID string) error {
+	filter := bson.M{"_id": notificationID, "user": userID}
+	update := bson.M{"$set": bson.M{"read": true}}
+
+	ctx, cancel := n.withTimeout(ctx)
+	defer cancel()
+	res, err := n.db.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("mongodb mark notification read: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("mongodb mark notification read: %w", myerrors.ErrNoNotification)
+	}
+	return nil
+}
+
+func (n *NotificationMongoDB) MarkAllRead(ctx context.Context, userID string) error {
+	filter := bson.M{"user": userID, "read": false}
+	update := bson.M{"$set": bson.M{"read": true}}
+
+	ctx, cancel := n.withTimeout(ctx)
+	defer cancel()
+	if _, err := n.db.UpdateMany(ctx, filter, update); err != nil {
+		return fmt.Errorf("mongodb mark all notifications read: %w", err)
+	}
+	return nil
+}
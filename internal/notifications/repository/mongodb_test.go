@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/notifications"
+)
+
+const (
+	userID         = "1"
+	notificationID = "2"
+)
+
+var notificationData = bson.D{
+	{Key: "_id", Value: notificationID},
+	{Key: "user", Value: userID},
+	{Key: "type", Value: notifications.TypePostComment},
+	{Key: "actor", Value: "User"},
+	{Key: "post", Value: "3"},
+	{Key: "read", Value: false},
+	{Key: "created", Value: time.Now()},
+}
+
+func TestNewNotificationMongoDB(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("test", func(mt *mtest.T) {
+		assert.NotNil(t, NewNotificationMongoDB(mt.Coll))
+	})
+}
+
+func TestCreate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse())
+		notification := &notifications.Notification{UserID: userID, Type: notifications.TypeMention}
+		assert.NoError(t, NewNotificationMongoDB(mt.Coll).Create(context.Background(), notification))
+		assert.NotEmpty(t, notification.ID)
+		assert.False(t, notification.Created.IsZero())
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		assert.Error(t, NewNotificationMongoDB(mt.Coll).Create(context.Background(), &notifications.Notification{}))
+	})
+}
+
+func TestGetByUser(t *testing.T) {
+	cases := []struct {
+		name          string
+		resp          []bson.D
+		expectedError bool
+	}{
+		{
+			name:          "find error",
+			resp:          nil,
+			expectedError: true,
+		},
+		{
+			name: "cursor error",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "reddit.notifications", mtest.FirstBatch, bson.D{{Key: "read", Value: "yes"}}),
+				mtest.CreateCursorResponse(0, "reddit.notifications", mtest.NextBatch),
+			},
+			expectedError: true,
+		},
+		{
+			name: "success",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "reddit.notifications", mtest.FirstBatch, notificationData),
+				mtest.CreateCursorResponse(0, "reddit.notifications", mtest.NextBatch),
+			},
+			expectedError: false,
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			for _, response := range c.resp {
+				mt.AddMockResponses(response)
+			}
+			items, err := NewNotificationMongoDB(mt.Coll).GetByUser(context.Background(), userID, true, 10, 0)
+			if c.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, items)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, items, 1)
+				assert.Equal(t, notificationID, items[0].ID)
+			}
+		})
+	}
+}
+
+func TestCountUnread(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "reddit.notifications", mtest.FirstBatch, bson.D{{Key: "n", Value: 3}}))
+		count, err := NewNotificationMongoDB(mt.Coll).CountUnread(context.Background(), userID)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		_, err := NewNotificationMongoDB(mt.Coll).CountUnread(context.Background(), userID)
+		assert.Error(t, err)
+	})
+}
+
+func TestMarkRead(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}})
+		assert.NoError(t, NewNotificationMongoDB(mt.Coll).MarkRead(context.Background(), userID, notificationID))
+	})
+
+	mt.Run("not found", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 0}, {Key: "nModified", Value: 0}})
+		err := NewNotificationMongoDB(mt.Coll).MarkRead(context.Background(), userID, notificationID)
+		assert.ErrorIs(t, err, myerrors.ErrNoNotification)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		assert.Error(t, NewNotificationMongoDB(mt.Coll).MarkRead(context.Background(), userID, notificationID))
+	})
+}
+
+func TestMarkAllRead(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 4}, {Key: "nModified", Value: 4}})
+		assert.NoError(t, NewNotificationMongoDB(mt.Coll).MarkAllRead(context.Background(), userID))
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		assert.Error(t, NewNotificationMongoDB(mt.Coll).MarkAllRead(context.Background(), userID))
+	})
+}
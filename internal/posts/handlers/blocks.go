@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/KonstantinGalanin/redditclone/internal/blocks"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+// hiddenAuthors returns the IDs of the users the caller blocked. Their posts
+// and comments are left out of what the caller sees.
+func (p *PostsHandler) hiddenAuthors(user *user.User) ([]string, error) {
+	if user == nil || p.BlockRepo == nil {
+		return nil, nil
+	}
+	blocked, err := p.BlockRepo.GetBlocked(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get blocked users: %w", err)
+	}
+	return blocks.IDs(blocked), nil
+}
+
+// blockers returns the users who blocked the caller.
+func (p *PostsHandler) blockers(user *user.User) ([]*user.User, error) {
+	if p.BlockRepo == nil {
+		return nil, nil
+	}
+	blockers, err := p.BlockRepo.GetBlockers(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get blockers: %w", err)
+	}
+	return blockers, nil
+}
+
+// checkMentions writes a 403 and returns false when text mentions one of
+// blockers. Names compare case-insensitively, as the notifier resolves them.
+func checkMentions(w http.ResponseWriter, blockers []*user.User, text string) bool {
+	if len(blockers) == 0 {
+		return true
+	}
+	mentioned := user.Mentions(text)
+	for _, blocker := range blockers {
+		if slices.ContainsFunc(mentioned, func(name string) bool {
+			return strings.EqualFold(name, blocker.Username)
+		}) {
+			WriteErrorMsg(w, myerrors.ErrBlocked.Error(), http.StatusForbidden)
+			return false
+		}
+	}
+	return true
+}
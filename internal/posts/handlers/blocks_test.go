@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	repositoryBlocks "github.com/KonstantinGalanin/redditclone/internal/blocks/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/saved"
+	repositorySaved "github.com/KonstantinGalanin/redditclone/internal/saved/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+var blocker = &user.User{ID: "7", Username: "blocker"}
+
+func TestGetAllHidesBlocked(t *testing.T) {
+	service, postsRepo, userRepo, _ := newDraftsService(t)
+	blockRepo := repositoryBlocks.NewMockBlockRepo(gomock.NewController(t))
+	service.BlockRepo = blockRepo
+
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil).AnyTimes()
+
+	blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, errors.New("some error"))
+	recorder := httptest.NewRecorder()
+	service.GetAll(recorder, draftRequest(http.MethodGet, ""))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{blocker}, nil)
+	postsRepo.EXPECT().GetAllPosts(gomock.Any(), []string{blocker.ID}, gomock.Any()).Return([]*posts.Post{}, nil)
+	recorder = httptest.NewRecorder()
+	service.GetAll(recorder, draftRequest(http.MethodGet, ""))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestGetPostHidesBlocked(t *testing.T) {
+	service, postsRepo, userRepo, _ := newDraftsService(t)
+	blockRepo := repositoryBlocks.NewMockBlockRepo(gomock.NewController(t))
+	service.BlockRepo = blockRepo
+
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+	blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{blocker}, nil)
+	postsRepo.EXPECT().GetPostHiding(gomock.Any(), postID, []string{blocker.ID}).Return(nil, fmt.Errorf("get post: %w", myerrors.ErrNoPost))
+
+	recorder := httptest.NewRecorder()
+	service.GetPost(recorder, draftRequest(http.MethodGet, ""))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestCreateCommentBlocked(t *testing.T) {
+	service, postsRepo, userRepo, published := newDraftsService(t)
+	blockRepo := repositoryBlocks.NewMockBlockRepo(gomock.NewController(t))
+	service.BlockRepo = blockRepo
+
+	cases := []struct {
+		name       string
+		body       string
+		statusCode int
+		expect     func()
+	}{
+		{
+			name:       "blockers error",
+			body:       `{"comment":"hi"}`,
+			statusCode: http.StatusInternalServerError,
+			expect: func() {
+				blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "mentions a blocker",
+			body:       `{"comment":"hey @blocker"}`,
+			statusCode: http.StatusForbidden,
+			expect: func() {
+				blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return([]*user.User{blocker}, nil)
+			},
+		},
+		{
+			name:       "mentions a blocker in another case",
+			body:       `{"comment":"hey @BLOCKER"}`,
+			statusCode: http.StatusForbidden,
+			expect: func() {
+				blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return([]*user.User{blocker}, nil)
+			},
+		},
+		{
+			name:       "replies to a blocker",
+			body:       `{"comment":"hi"}`,
+			statusCode: http.StatusForbidden,
+			expect: func() {
+				blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return([]*user.User{blocker}, nil)
+				blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, nil)
+				postsRepo.EXPECT().CreateComment(gomock.Any(), postID, "", "hi", expectedUser, posts.CommentOptions{BlockedBy: []string{blocker.ID}}).
+					Return(nil, nil, fmt.Errorf("create comment: %w", myerrors.ErrBlocked))
+			},
+		},
+		{
+			name:       "success",
+			body:       `{"comment":"hi @someone"}`,
+			statusCode: http.StatusCreated,
+			expect: func() {
+				blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return([]*user.User{blocker}, nil)
+				blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{{ID: "8"}}, nil)
+				postsRepo.EXPECT().CreateComment(gomock.Any(), postID, "", "hi @someone", expectedUser, posts.CommentOptions{
+					BlockedBy: []string{blocker.ID},
+					Hidden:    []string{"8"},
+				}).Return(&posts.Post{ID: postID}, &posts.Comment{ID: "2"}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+			c.expect()
+			recorder := httptest.NewRecorder()
+			service.CreateComment(recorder, draftRequest(http.MethodPost, c.body))
+			assert.Equal(t, c.statusCode, recorder.Code)
+			if c.statusCode == http.StatusForbidden {
+				var msg ErrorMessage
+				assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&msg))
+				assert.Equal(t, myerrors.ErrBlocked.Error(), msg.Message)
+			}
+		})
+	}
+	assert.Len(t, *published, 1)
+}
+
+func TestCreatePostMentionsBlocker(t *testing.T) {
+	service, _, userRepo, _ := newDraftsService(t)
+	blockRepo := repositoryBlocks.NewMockBlockRepo(gomock.NewController(t))
+	service.BlockRepo = blockRepo
+
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+	blockRepo.EXPECT().GetBlockers(expectedUser.ID).Return([]*user.User{blocker}, nil)
+
+	recorder := httptest.NewRecorder()
+	service.CreatePost(recorder, draftRequest(http.MethodPost, `{"category":"music","title":"@blocker look","type":"text"}`))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+}
+
+func TestPostsByUserHidesBlocked(t *testing.T) {
+	service, postsRepo, userRepo, _ := newDraftsService(t)
+	blockRepo := repositoryBlocks.NewMockBlockRepo(gomock.NewController(t))
+	service.BlockRepo = blockRepo
+
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil).AnyTimes()
+	request := func() *http.Request {
+		return mux.SetURLVars(draftRequest(http.MethodGet, ""), map[string]string{fieldUsername: blocker.Username})
+	}
+
+	blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, errors.New("some error"))
+	recorder := httptest.NewRecorder()
+	service.PostsByUser(recorder, request())
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{blocker}, nil)
+	postsRepo.EXPECT().GetPostsByUser(gomock.Any(), blocker.Username, []string{blocker.ID}).Return([]*posts.Post{}, nil)
+	recorder = httptest.NewRecorder()
+	service.PostsByUser(recorder, request())
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestSavedHidesBlocked(t *testing.T) {
+	service, postsRepo, userRepo, _ := newDraftsService(t)
+	ctrl := gomock.NewController(t)
+	blockRepo := repositoryBlocks.NewMockBlockRepo(ctrl)
+	savedRepo := repositorySaved.NewMockSavedRepo(ctrl)
+	service.BlockRepo = blockRepo
+	service.SavedRepo = savedRepo
+
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil).AnyTimes()
+	savedRepo.EXPECT().GetSaved(gomock.Any(), expectedUser.ID, 0, 0).Return([]*saved.Item{{PostID: postID}}, nil).Times(2)
+
+	blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return(nil, errors.New("some error"))
+	recorder := httptest.NewRecorder()
+	service.Saved(recorder, draftRequest(http.MethodGet, ""))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	blockRepo.EXPECT().GetBlocked(expectedUser.ID).Return([]*user.User{blocker}, nil)
+	postsRepo.EXPECT().GetPostsByIDs(gomock.Any(), []string{postID}, []string{blocker.ID}).Return([]*posts.Post{}, nil)
+	recorder = httptest.NewRecorder()
+	service.Saved(recorder, draftRequest(http.MethodGet, ""))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var result []*saved.Item
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+	assert.Len(t, result, 1)
+	assert.True(t, result[0].Removed)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/filter"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+// Drafts lists the caller's unpublished posts, scheduled ones included.
+func (p *PostsHandler) Drafts(w http.ResponseWriter, r *http.Request) {
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("drafts %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	drafts, err := p.PostsRepo.GetDrafts(r.Context(), user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tallyPolls(user, drafts...)
+	WriteResponsePosts(w, drafts, http.StatusOK)
+}
+
+// UpdateDraft replaces the category, title, text and URL of a draft. Edits
+// go through the content filter like new posts do.
+func (p *PostsHandler) UpdateDraft(w http.ResponseWriter, r *http.Request) {
+	postID, err := getFieldFromURL(r, fieldPostID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data struct {
+		Category string `json:"category"`
+		Title    string `json:"title"`
+		URL      string `json:"url,omitempty"`
+		Text     string `json:"text,omitempty"`
+	}
+	if err = json.NewDecoder(r.Body).Decode(&data); err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("update draft %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	blockers, err := p.blockers(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !checkMentions(w, blockers, data.Title+" "+data.Text) {
+		return
+	}
+
+	content := &filter.Content{Category: data.Category, Title: data.Title, Text: data.Text, URL: data.URL}
+	verdict, ok := p.screen(w, r, content)
+	if !ok {
+		return
+	}
+	draft := &posts.Post{
+		Category: data.Category,
+		Title:    data.Title,
+		URL:      data.URL,
+		Text:     data.Text,
+	}
+	if verdict.Outcome == filter.OutcomeHold {
+		draft.Status = posts.StatusHeld
+	}
+
+	post, err := p.PostsRepo.UpdateDraft(r.Context(), postID, user.ID, draft)
+	if err != nil {
+		WriteErrorPost(w, err)
+		return
+	}
+	if post.Status == posts.StatusHeld {
+		p.hold(r.Context(), post.ID, "", user, verdict, postExcerpt(content))
+	}
+	tallyPolls(user, post)
+	WriteResponsePost(w, post, http.StatusOK)
+}
+
+// PublishPost publishes a draft right away, or schedules it when the body
+// carries a publishAt time.
+func (p *PostsHandler) PublishPost(w http.ResponseWriter, r *http.Request) {
+	postID, err := getFieldFromURL(r, fieldPostID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data struct {
+		PublishAt *time.Time `json:"publishAt,omitempty"`
+	}
+	// The body is optional.
+	if err = json.NewDecoder(r.Body).Decode(&data); err != nil && !errors.Is(err, io.EOF) {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	now := time.Now()
+	if data.PublishAt != nil && !data.PublishAt.After(now) {
+		WriteErrorMsg(w, myerrors.ErrBadSchedule.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("publish post %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+	if user.IsSuspended(now) {
+		WriteErrorMsg(w, myerrors.ErrSuspended.Error(), http.StatusForbidden)
+		return
+	}
+
+	var post *posts.Post
+	if data.PublishAt != nil {
+		post, err = p.PostsRepo.SchedulePost(r.Context(), postID, user.ID, data.PublishAt)
+	} else {
+		post, err = p.PostsRepo.PublishPost(r.Context(), postID, user.ID, now)
+	}
+	if err != nil {
+		WriteErrorPost(w, err)
+		return
+	}
+
+	if post.IsPublished() {
+		p.publish(r.Context(), &events.Event{Type: events.PostCreated, PostID: post.ID, Actor: user, Post: post})
+	}
+	tallyPolls(user, post)
+	WriteResponsePost(w, post, http.StatusOK)
+}
+
+// UnschedulePost turns a scheduled post back into a draft.
+func (p *PostsHandler) UnschedulePost(w http.ResponseWriter, r *http.Request) {
+	postID, err := getFieldFromURL(r, fieldPostID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("unschedule post %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	post, err := p.PostsRepo.SchedulePost(r.Context(), postID, user.ID, nil)
+	if err != nil {
+		WriteErrorPost(w, err)
+		return
+	}
+	tallyPolls(user, post)
+	WriteResponsePost(w, post, http.StatusOK)
+}
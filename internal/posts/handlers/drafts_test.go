@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	repositoryPosts "github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/session/mock"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+func newDraftsService(t *testing.T) (*PostsHandler, *repositoryPosts.MockPostRepo, *repositoryUser.MockUserRepo, *[]*events.Event) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := newMockService(postsRepo, userRepo, mock.NewMockSessionManager(ctrl))
+
+	bus := events.NewBus()
+	published := &[]*events.Event{}
+	bus.Subscribe(events.ListenerFunc(func(_ context.Context, event *events.Event) {
+		*published = append(*published, event)
+	}))
+	service.Events = bus
+	return service, postsRepo, userRepo, published
+}
+
+func draftRequest(method, body string) *http.Request {
+	req := sessiontest.SignIn(httptest.NewRequest(method, "/", bytes.NewBufferString(body)), expectedUser.Username)
+	return mux.SetURLVars(req, map[string]string{fieldPostID: postID})
+}
+
+func TestCreateDraft(t *testing.T) {
+	service, postsRepo, userRepo, published := newDraftsService(t)
+	publishAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+
+	cases := []struct {
+		name       string
+		body       string
+		statusCode int
+		status     string
+	}{
+		{
+			name:       "schedule in the past",
+			body:       `{"category":"music","title":"t","type":"text","publishAt":"2000-01-01T00:00:00Z"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "draft",
+			body:       `{"category":"music","title":"t","type":"text","draft":true}`,
+			statusCode: http.StatusCreated,
+			status:     posts.StatusDraft,
+		},
+		{
+			name:       "scheduled",
+			body:       `{"category":"music","title":"t","type":"text","publishAt":"` + publishAt + `"}`,
+			statusCode: http.StatusCreated,
+			status:     posts.StatusScheduled,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.statusCode == http.StatusCreated {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any(), expectedUser).
+					DoAndReturn(func(_ context.Context, draft *posts.Post, author *user.User) (*posts.Post, error) {
+						assert.Equal(t, c.status, draft.Status)
+						draft.Author = author
+						return draft, nil
+					})
+			}
+			recorder := httptest.NewRecorder()
+			service.CreatePost(recorder, draftRequest(http.MethodPost, c.body))
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+	assert.Empty(t, *published)
+}
+
+func TestDrafts(t *testing.T) {
+	service, postsRepo, userRepo, _ := newDraftsService(t)
+
+	recorder := httptest.NewRecorder()
+	service.Drafts(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+	postsRepo.EXPECT().GetDrafts(gomock.Any(), expectedUser.ID).Return(nil, errors.New("some error"))
+	recorder = httptest.NewRecorder()
+	service.Drafts(recorder, draftRequest(http.MethodGet, ""))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+	postsRepo.EXPECT().GetDrafts(gomock.Any(), expectedUser.ID).Return([]*posts.Post{{ID: postID, Status: posts.StatusDraft}}, nil)
+	recorder = httptest.NewRecorder()
+	service.Drafts(recorder, draftRequest(http.MethodGet, ""))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	var result []*posts.Post
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+	assert.Len(t, result, 1)
+	assert.Equal(t, posts.StatusDraft, result[0].Status)
+}
+
+func TestUpdateDraft(t *testing.T) {
+	service, postsRepo, userRepo, _ := newDraftsService(t)
+
+	cases := []struct {
+		name       string
+		body       string
+		statusCode int
+		expect     func()
+	}{
+		{
+			name:       "bad body",
+			body:       `{`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "published",
+			body:       `{"title":"new"}`,
+			statusCode: http.StatusConflict,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().UpdateDraft(gomock.Any(), postID, expectedUser.ID, gomock.Any()).Return(nil, myerrors.ErrNotDraft)
+			},
+		},
+		{
+			name:       "success",
+			body:       `{"category":"music","title":"new"}`,
+			statusCode: http.StatusOK,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().UpdateDraft(gomock.Any(), postID, expectedUser.ID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, postID, userID string, post *posts.Post) (*posts.Post, error) {
+						assert.Equal(t, "new", post.Title)
+						assert.Equal(t, category, post.Category)
+						return post, nil
+					})
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.UpdateDraft(recorder, draftRequest(http.MethodPut, c.body))
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestPublishPost(t *testing.T) {
+	service, postsRepo, userRepo, published := newDraftsService(t)
+	publishAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+
+	cases := []struct {
+		name       string
+		body       string
+		statusCode int
+		events     int
+		expect     func()
+	}{
+		{
+			name:       "in the past",
+			body:       `{"publishAt":"2000-01-01T00:00:00Z"}`,
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name:       "suspended",
+			statusCode: http.StatusForbidden,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(suspendedUser, nil)
+			},
+		},
+		{
+			name:       "not found",
+			statusCode: http.StatusNotFound,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().PublishPost(gomock.Any(), postID, expectedUser.ID, gomock.Any()).Return(nil, myerrors.ErrNoPost)
+			},
+		},
+		{
+			name:       "schedule",
+			body:       `{"publishAt":"` + publishAt.Format(time.RFC3339) + `"}`,
+			statusCode: http.StatusOK,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().SchedulePost(gomock.Any(), postID, expectedUser.ID, gomock.Any()).
+					DoAndReturn(func(_ context.Context, postID, userID string, at *time.Time) (*posts.Post, error) {
+						assert.True(t, publishAt.Equal(*at))
+						return &posts.Post{ID: postID, Status: posts.StatusScheduled, PublishAt: at}, nil
+					})
+			},
+		},
+		{
+			name:       "publish now",
+			statusCode: http.StatusOK,
+			events:     1,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().PublishPost(gomock.Any(), postID, expectedUser.ID, gomock.Any()).
+					Return(&posts.Post{ID: postID, Author: expectedUser}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			*published = nil
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.PublishPost(recorder, draftRequest(http.MethodPost, c.body))
+			assert.Equal(t, c.statusCode, recorder.Code)
+			assert.Len(t, *published, c.events)
+			if c.events > 0 {
+				assert.Equal(t, events.PostCreated, (*published)[0].Type)
+			}
+		})
+	}
+}
+
+func TestUnschedulePost(t *testing.T) {
+	service, postsRepo, userRepo, _ := newDraftsService(t)
+
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+	postsRepo.EXPECT().SchedulePost(gomock.Any(), postID, expectedUser.ID, nil).
+		Return(&posts.Post{ID: postID, Status: posts.StatusDraft}, nil)
+	recorder := httptest.NewRecorder()
+	service.UnschedulePost(recorder, draftRequest(http.MethodPost, ""))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/filter"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+// maxExcerpt caps the copy of held content stored with its report.
+const maxExcerpt = 500
+
+// screen runs new or edited content through the filter. It writes the
+// response itself and returns false when the content is rejected or the
+// filter fails. Without a filter everything is allowed.
+func (p *PostsHandler) screen(w http.ResponseWriter, r *http.Request, content *filter.Content) (*filter.Verdict, bool) {
+	if p.Filter == nil {
+		return &filter.Verdict{Outcome: filter.OutcomeAllow}, true
+	}
+	verdict, err := p.Filter.Check(r.Context(), content)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if verdict.Outcome == filter.OutcomeReject {
+		msg := fmt.Sprintf("%s: %s", myerrors.ErrRejected, strings.Join(verdict.Reasons, ", "))
+		WriteErrorMsg(w, msg, http.StatusUnprocessableEntity)
+		return nil, false
+	}
+	return verdict, true
+}
+
+// hold puts held content into the moderators' report queue. The content is
+// already stored by then, so a failure is logged rather than returned.
+func (p *PostsHandler) hold(ctx context.Context, postID, commentID string, author *user.User, verdict *filter.Verdict, excerpt string) {
+	if p.ReportRepo == nil {
+		return
+	}
+	if len([]rune(excerpt)) > maxExcerpt {
+		excerpt = string([]rune(excerpt)[:maxExcerpt])
+	}
+	if _, err := p.ReportRepo.Hold(ctx, postID, commentID, author, verdict.Reasons, excerpt); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithFields(logrus.Fields{
+			"post":    postID,
+			"comment": commentID,
+		}).Error("file held content for review")
+	}
+}
+
+// postExcerpt is what moderators see of a held post.
+func postExcerpt(content *filter.Content) string {
+	parts := make([]string, 0, 3)
+	for _, part := range []string{content.Title, content.URL, content.Text} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "\n")
+}
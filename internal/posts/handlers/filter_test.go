@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/filter"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	repositoryReports "github.com/KonstantinGalanin/redditclone/internal/reports/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+type fakeChecker struct {
+	verdict *filter.Verdict
+	err     error
+}
+
+func (f *fakeChecker) Check(ctx context.Context, content *filter.Content) (*filter.Verdict, error) {
+	return f.verdict, f.err
+}
+
+var (
+	allowVerdict  = &filter.Verdict{Outcome: filter.OutcomeAllow}
+	holdVerdict   = &filter.Verdict{Outcome: filter.OutcomeHold, Reasons: []string{"blocked word"}}
+	rejectVerdict = &filter.Verdict{Outcome: filter.OutcomeReject, Reasons: []string{"blocked domain spam.example"}}
+)
+
+func TestCreatePostFilter(t *testing.T) {
+	service, postsRepo, userRepo, published := newDraftsService(t)
+	reportRepo := repositoryReports.NewMockReportRepo(gomock.NewController(t))
+	service.ReportRepo = reportRepo
+	body := `{"category":"music","title":"t","type":"text","text":"spam"}`
+
+	cases := []struct {
+		name       string
+		checker    *fakeChecker
+		expect     func()
+		statusCode int
+		events     int
+	}{
+		{
+			name:       "filter error",
+			checker:    &fakeChecker{err: errors.New("some error")},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name:       "reject",
+			checker:    &fakeChecker{verdict: rejectVerdict},
+			statusCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:    "hold",
+			checker: &fakeChecker{verdict: holdVerdict},
+			expect: func() {
+				postsRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any(), expectedUser).DoAndReturn(func(_ context.Context, post *posts.Post, author *user.User) (*posts.Post, error) {
+					assert.Equal(t, posts.StatusHeld, post.Status)
+					post.ID = postID
+					return post, nil
+				})
+				reportRepo.EXPECT().Hold(gomock.Any(), postID, "", expectedUser, holdVerdict.Reasons, "t\nspam").Return(nil, nil)
+			},
+			statusCode: http.StatusCreated,
+		},
+		{
+			name:    "allow",
+			checker: &fakeChecker{verdict: allowVerdict},
+			expect: func() {
+				postsRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any(), expectedUser).DoAndReturn(func(_ context.Context, post *posts.Post, author *user.User) (*posts.Post, error) {
+					assert.True(t, post.IsPublished())
+					return post, nil
+				})
+			},
+			statusCode: http.StatusCreated,
+			events:     1,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			*published = nil
+			service.Filter = c.checker
+			userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.CreatePost(recorder, draftRequest(http.MethodPost, body))
+			assert.Equal(t, c.statusCode, recorder.Code)
+			assert.Len(t, *published, c.events)
+		})
+	}
+}
+
+func TestCreateCommentHeld(t *testing.T) {
+	service, postsRepo, userRepo, published := newDraftsService(t)
+	reportRepo := repositoryReports.NewMockReportRepo(gomock.NewController(t))
+	service.ReportRepo = reportRepo
+	service.Filter = &fakeChecker{verdict: holdVerdict}
+
+	comment := &posts.Comment{ID: commentID, Author: expectedUser, Body: "spam", Held: true}
+	post := &posts.Post{ID: postID, Author: expectedUser, Comments: []*posts.Comment{comment}}
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+	postsRepo.EXPECT().CreateComment(gomock.Any(), postID, "", "spam", expectedUser, posts.CommentOptions{Held: true}).Return(post, comment, nil)
+	reportRepo.EXPECT().Hold(gomock.Any(), postID, commentID, expectedUser, holdVerdict.Reasons, "spam").Return(nil, errors.New("some error"))
+
+	recorder := httptest.NewRecorder()
+	service.CreateComment(recorder, draftRequest(http.MethodPost, `{"comment":"spam"}`))
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+	assert.Empty(t, *published)
+	assert.Contains(t, recorder.Body.String(), posts.HeldPlaceholder)
+}
+
+func TestUpdateDraftHeld(t *testing.T) {
+	service, postsRepo, userRepo, _ := newDraftsService(t)
+	reportRepo := repositoryReports.NewMockReportRepo(gomock.NewController(t))
+	service.ReportRepo = reportRepo
+	service.Filter = &fakeChecker{verdict: holdVerdict}
+
+	held := &posts.Post{ID: postID, Author: expectedUser, Title: "t", Status: posts.StatusHeld}
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+	postsRepo.EXPECT().UpdateDraft(gomock.Any(), postID, expectedUser.ID, &posts.Post{Title: "t", Status: posts.StatusHeld}).Return(held, nil)
+	reportRepo.EXPECT().Hold(gomock.Any(), postID, "", expectedUser, holdVerdict.Reasons, "t").Return(nil, nil)
+
+	recorder := httptest.NewRecorder()
+	service.UpdateDraft(recorder, draftRequest(http.MethodPut, `{"title":"t"}`))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/preferences"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+// preferences returns the caller's viewing preferences. Anonymous callers,
+// and every caller when no store is configured, get the defaults.
+func (p *PostsHandler) preferences(user *user.User) (*preferences.Preferences, error) {
+	if user == nil || p.PreferencesRepo == nil {
+		defaults := preferences.Default
+		return &defaults, nil
+	}
+	prefs, err := p.PreferencesRepo.Get(user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+// withholdFlagged hides the content of flagged posts as the viewer's
+// preferences ask. Authors always see their own posts.
+func withholdFlagged(user *user.User, prefs *preferences.Preferences, items ...*posts.Post) {
+	viewerID := userID(user)
+	for _, post := range items {
+		if post == nil || (viewerID != "" && post.Author != nil && post.Author.ID == viewerID) {
+			continue
+		}
+		if (post.NSFW && prefs.BlurNSFW) || (post.Spoiler && prefs.BlurSpoilers) {
+			post.Withhold()
+		}
+	}
+}
+
+// SetFlags marks a post as nsfw or spoiler. Flags missing from the body are
+// left as they are. Only the author and moderators may change them.
+func (p *PostsHandler) SetFlags(w http.ResponseWriter, r *http.Request) {
+	postID, err := getFieldFromURL(r, fieldPostID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data struct {
+		NSFW    *bool `json:"nsfw"`
+		Spoiler *bool `json:"spoiler"`
+	}
+	if err = json.NewDecoder(r.Body).Decode(&data); err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("set flags %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	post, err := p.PostsRepo.GetPost(r.Context(), postID)
+	if err != nil {
+		WriteErrorPost(w, err)
+		return
+	}
+	if !post.VisibleTo(user.ID) {
+		WriteErrorPost(w, myerrors.ErrNoPost)
+		return
+	}
+	if !user.Moderator && (post.Author == nil || post.Author.ID != user.ID) {
+		WriteErrorMsg(w, myerrors.ErrNotAuthor.Error(), http.StatusForbidden)
+		return
+	}
+
+	post, err = p.PostsRepo.SetContentFlags(r.Context(), postID, data.NSFW, data.Spoiler)
+	if err != nil {
+		WriteErrorPost(w, err)
+		return
+	}
+	tallyPolls(user, post)
+	WriteResponsePost(w, post, http.StatusOK)
+}
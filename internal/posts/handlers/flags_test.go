@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	repositoryPosts "github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/preferences"
+	repositoryPreferences "github.com/KonstantinGalanin/redditclone/internal/preferences/repository"
+	repositorySaved "github.com/KonstantinGalanin/redditclone/internal/saved/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/session/mock"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+func flaggedPosts() []*posts.Post {
+	author := &user.User{ID: "2", Username: "author"}
+	return []*posts.Post{
+		{ID: "1", Author: author, Type: posts.TypeText, Text: "plain"},
+		{ID: "2", Author: author, Type: posts.TypeText, Text: "nsfw", NSFW: true},
+		{ID: "3", Author: author, Type: posts.TypeLink, URL: "https://example.com", Spoiler: true},
+	}
+}
+
+func newFlagsService(t *testing.T) (*PostsHandler, *repositoryPosts.MockPostRepo, *repositoryUser.MockUserRepo, *repositoryPreferences.MockPreferencesRepo) {
+	ctrl := gomock.NewController(t)
+	t.Cleanup(ctrl.Finish)
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	preferencesRepo := repositoryPreferences.NewMockPreferencesRepo(ctrl)
+	savedRepo := repositorySaved.NewMockSavedRepo(ctrl)
+	savedRepo.EXPECT().GetSavedPostIDs(gomock.Any(), gomock.Any(), gomock.Any()).Return(map[string]bool{}, nil).AnyTimes()
+
+	service := newMockService(postsRepo, userRepo, mock.NewMockSessionManager(ctrl))
+	service.PreferencesRepo = preferencesRepo
+	service.SavedRepo = savedRepo
+	return service, postsRepo, userRepo, preferencesRepo
+}
+
+func TestWithholdFlagged(t *testing.T) {
+	items := flaggedPosts()
+	withholdFlagged(nil, &preferences.Default, items...)
+	assert.False(t, items[0].Withheld)
+	assert.Equal(t, "plain", items[0].Text)
+	assert.True(t, items[1].Withheld)
+	assert.Empty(t, items[1].Text)
+	assert.True(t, items[2].Withheld)
+	assert.Empty(t, items[2].URL)
+
+	items = flaggedPosts()
+	withholdFlagged(expectedUser, &preferences.Preferences{BlurSpoilers: true}, items...)
+	assert.False(t, items[1].Withheld)
+	assert.True(t, items[2].Withheld)
+
+	items = flaggedPosts()
+	withholdFlagged(items[0].Author, &preferences.Default, items...)
+	for _, post := range items {
+		assert.False(t, post.Withheld, "authors see their own posts")
+	}
+}
+
+func TestGetAllNSFW(t *testing.T) {
+	service, postsRepo, userRepo, preferencesRepo := newFlagsService(t)
+
+	cases := []struct {
+		name       string
+		req        *http.Request
+		expect     func()
+		statusCode int
+		expected   []string
+		withheld   []bool
+	}{
+		{
+			name: "anonymous excludes nsfw",
+			req:  httptest.NewRequest(http.MethodGet, "/", nil),
+			expect: func() {
+				items := flaggedPosts()
+				postsRepo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), &posts.ListOptions{HideNSFW: true}).Return([]*posts.Post{items[0], items[2]}, nil)
+			},
+			statusCode: http.StatusOK,
+			expected:   []string{"1", "3"},
+			withheld:   []bool{false, true},
+		},
+		{
+			name: "preferences error",
+			req:  draftRequest(http.MethodGet, ""),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				preferencesRepo.EXPECT().Get(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+			statusCode: http.StatusInternalServerError,
+		},
+		{
+			name: "opted in without blur",
+			req:  draftRequest(http.MethodGet, ""),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				preferencesRepo.EXPECT().Get(expectedUser.ID).Return(&preferences.Preferences{ShowNSFW: true}, nil)
+				postsRepo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), &posts.ListOptions{}).Return(flaggedPosts(), nil)
+			},
+			statusCode: http.StatusOK,
+			expected:   []string{"1", "2", "3"},
+			withheld:   []bool{false, false, false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.expect()
+			recorder := httptest.NewRecorder()
+			service.GetAll(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+			if c.statusCode != http.StatusOK {
+				return
+			}
+
+			var got []*posts.Post
+			assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&got))
+			ids := make([]string, 0, len(got))
+			withheld := make([]bool, 0, len(got))
+			for _, post := range got {
+				ids = append(ids, post.ID)
+				withheld = append(withheld, post.Withheld)
+			}
+			assert.Equal(t, c.expected, ids)
+			assert.Equal(t, c.withheld, withheld)
+		})
+	}
+}
+
+func TestGetByCategoryNSFW(t *testing.T) {
+	service, postsRepo, _, _ := newFlagsService(t)
+	items := flaggedPosts()
+	opts := &posts.ListOptions{Sort: posts.SortTop, Limit: 2, PinnedFirst: true, HideNSFW: true}
+	postsRepo.EXPECT().GetPostsByCategory(gomock.Any(), "music", gomock.Nil(), opts).Return([]*posts.Post{items[0], items[2]}, nil)
+
+	req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/?sort=top&limit=2", nil), map[string]string{fieldCategory: "music"})
+	recorder := httptest.NewRecorder()
+	service.GetByCategory(recorder, req)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []*posts.Post
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&got))
+	for _, post := range got {
+		assert.False(t, post.NSFW)
+	}
+	assert.Len(t, got, 2)
+}
+
+func TestGetPostReveal(t *testing.T) {
+	service, postsRepo, _, _ := newFlagsService(t)
+
+	for _, c := range []struct {
+		target   string
+		withheld bool
+	}{
+		{target: "/", withheld: true},
+		{target: "/?reveal=true", withheld: false},
+	} {
+		postsRepo.EXPECT().GetPostHiding(gomock.Any(), postID, gomock.Nil()).Return(flaggedPosts()[1], nil)
+		req := mux.SetURLVars(httptest.NewRequest(http.MethodGet, c.target, nil), map[string]string{fieldPostID: postID})
+		recorder := httptest.NewRecorder()
+		service.GetPost(recorder, req)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var got posts.Post
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&got))
+		assert.Equal(t, c.withheld, got.Withheld, c.target)
+		assert.Equal(t, c.withheld, got.Text == "", c.target)
+	}
+}
+
+func TestSetFlags(t *testing.T) {
+	service, postsRepo, userRepo, _ := newFlagsService(t)
+	moderator := &user.User{ID: "3", Username: expectedUser.Username, Moderator: true}
+	nsfw := true
+
+	cases := []struct {
+		name       string
+		body       string
+		expect     func()
+		statusCode int
+	}{
+		{
+			name:       "bad body",
+			body:       "{",
+			statusCode: http.StatusBadRequest,
+		},
+		{
+			name: "no post",
+			body: `{"nsfw":true}`,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(nil, myerrors.ErrNoPost)
+			},
+			statusCode: http.StatusNotFound,
+		},
+		{
+			name: "not the author",
+			body: `{"nsfw":true}`,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(flaggedPosts()[0], nil)
+			},
+			statusCode: http.StatusForbidden,
+		},
+		{
+			name: "author",
+			body: `{"nsfw":true}`,
+			expect: func() {
+				post := flaggedPosts()[0]
+				post.Author = expectedUser
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(post, nil)
+				postsRepo.EXPECT().SetContentFlags(gomock.Any(), postID, &nsfw, nil).Return(post, nil)
+			},
+			statusCode: http.StatusOK,
+		},
+		{
+			name: "moderator",
+			body: `{"spoiler":false}`,
+			expect: func() {
+				spoiler := false
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(moderator, nil)
+				postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(flaggedPosts()[2], nil)
+				postsRepo.EXPECT().SetContentFlags(gomock.Any(), postID, nil, &spoiler).Return(flaggedPosts()[0], nil)
+			},
+			statusCode: http.StatusOK,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.SetFlags(recorder, draftRequest(http.MethodPost, c.body))
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
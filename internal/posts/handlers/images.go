@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/filter"
+	"github.com/KonstantinGalanin/redditclone/internal/images"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+const (
+	formCategory = "category"
+	formTitle    = "title"
+	formText     = "text"
+	formImage    = "image"
+	formNSFW     = "nsfw"
+	formSpoiler  = "spoiler"
+
+	imageKeyPrefix    = "images/"
+	thumbnailSuffix   = "_thumb"
+	multipartMemory   = 1 << 20
+	multipartOverhead = 1 << 20
+	blobTimeout       = 30 * time.Second
+)
+
+// CreateImagePost accepts a multipart form with category, title, an optional
+// text caption and the image file. The image is cleaned and thumbnailed
+// before anything is stored.
+func (p *PostsHandler) CreateImagePost(w http.ResponseWriter, r *http.Request) {
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("create post %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+	if user.IsSuspended(time.Now()) {
+		WriteErrorMsg(w, myerrors.ErrSuspended.Error(), http.StatusForbidden)
+		return
+	}
+
+	limits := p.ImageLimits
+	if limits.MaxBytes == 0 {
+		limits = images.DefaultLimits
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, limits.MaxBytes+multipartOverhead)
+	if err = r.ParseMultipartForm(multipartMemory); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			WriteErrorMsg(w, myerrors.ErrImageTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	content := &filter.Content{
+		Category: r.FormValue(formCategory),
+		Title:    r.FormValue(formTitle),
+		Text:     r.FormValue(formText),
+	}
+	blockers, err := p.blockers(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !checkMentions(w, blockers, content.Title+" "+content.Text) {
+		return
+	}
+	verdict, ok := p.screen(w, r, content)
+	if !ok {
+		return
+	}
+
+	file, _, err := r.FormFile(formImage)
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	original, thumbnail, err := images.Process(file, limits)
+	if err != nil {
+		writeErrorImage(w, err)
+		return
+	}
+
+	id := uuid.New().String()
+	image := &posts.Image{
+		Key:          imageKeyPrefix + id + original.Ext(),
+		ThumbnailKey: imageKeyPrefix + id + thumbnailSuffix + thumbnail.Ext(),
+		ContentType:  original.ContentType,
+		Width:        original.Width,
+		Height:       original.Height,
+		Size:         int64(len(original.Data)),
+	}
+	if err = p.storeImage(r.Context(), image, original, thumbnail); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	draft := &posts.Post{
+		Category: content.Category,
+		Title:    content.Title,
+		Type:     posts.TypeImage,
+		Text:     content.Text,
+		Image:    image,
+		NSFW:     formFlag(r, formNSFW),
+		Spoiler:  formFlag(r, formSpoiler),
+	}
+	if verdict.Outcome == filter.OutcomeHold {
+		draft.Status = posts.StatusHeld
+	}
+	post, err := p.PostsRepo.CreatePost(r.Context(), draft, user)
+	if err != nil {
+		p.deleteBlobs(r.Context(), image.Key, image.ThumbnailKey)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if post.Status == posts.StatusHeld {
+		p.hold(r.Context(), post.ID, "", user, verdict, postExcerpt(content))
+	} else {
+		p.publish(r.Context(), &events.Event{Type: events.PostCreated, PostID: post.ID, Actor: user, Post: post})
+	}
+	WriteResponsePost(w, post, http.StatusCreated)
+}
+
+// formFlag reads an optional boolean form field. Anything that does not parse
+// as true counts as false.
+func formFlag(r *http.Request, field string) bool {
+	value, err := strconv.ParseBool(r.FormValue(field))
+	return err == nil && value
+}
+
+func (p *PostsHandler) storeImage(ctx context.Context, image *posts.Image, original, thumbnail *images.Encoded) error {
+	ctx, cancel := context.WithTimeout(ctx, blobTimeout)
+	defer cancel()
+
+	err := p.Blobs.Put(ctx, image.Key, bytes.NewReader(original.Data), int64(len(original.Data)), original.ContentType)
+	if err != nil {
+		return fmt.Errorf("store image: %w", err)
+	}
+	err = p.Blobs.Put(ctx, image.ThumbnailKey, bytes.NewReader(thumbnail.Data), int64(len(thumbnail.Data)), thumbnail.ContentType)
+	if err != nil {
+		p.deleteBlobs(ctx, image.Key)
+		return fmt.Errorf("store thumbnail: %w", err)
+	}
+	return nil
+}
+
+// deleteBlobs cleans up after a failed upload. Failures are only logged; an
+// orphaned blob is harmless. It runs even if the request was cancelled.
+func (p *PostsHandler) deleteBlobs(ctx context.Context, keys ...string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), blobTimeout)
+	defer cancel()
+	for _, key := range keys {
+		if err := p.Blobs.Delete(ctx, key); err != nil {
+			logrus.WithContext(ctx).WithError(err).WithField("key", key).Error("delete blob failed")
+		}
+	}
+}
+
+func writeErrorImage(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, myerrors.ErrImageTooLarge):
+		WriteErrorMsg(w, myerrors.ErrImageTooLarge.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, myerrors.ErrBadImageType):
+		WriteErrorMsg(w, myerrors.ErrBadImageType.Error(), http.StatusUnsupportedMediaType)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
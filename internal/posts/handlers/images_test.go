@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	blobsMock "github.com/KonstantinGalanin/redditclone/internal/blobs/mock"
+	"github.com/KonstantinGalanin/redditclone/internal/images"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	repositoryPosts "github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/session/mock"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+func pngBytes(t *testing.T, width, height int) []byte {
+	buf := &bytes.Buffer{}
+	assert.NoError(t, png.Encode(buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func imageForm(t *testing.T, data []byte) (*bytes.Buffer, string) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	assert.NoError(t, form.WriteField(formCategory, category))
+	assert.NoError(t, form.WriteField(formTitle, "title"))
+	file, err := form.CreateFormFile(formImage, "image.png")
+	assert.NoError(t, err)
+	_, err = file.Write(data)
+	assert.NoError(t, err)
+	assert.NoError(t, form.Close())
+	return body, form.FormDataContentType()
+}
+
+func TestCreateImagePost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	sessionManager := mock.NewMockSessionManager(ctrl)
+	blobStore := blobsMock.NewMockBlobStore(ctrl)
+
+	service := newMockService(postsRepo, userRepo, sessionManager)
+	service.Blobs = blobStore
+	service.ImageLimits = images.Limits{MaxBytes: 4096, MaxWidth: 100, MaxHeight: 100, ThumbnailSize: 16}
+	sessCtx := context.WithValue(context.Background(), "session", &session.Session{
+		Username: expectedUser.Username,
+	})
+	validPNG := pngBytes(t, 40, 20)
+
+	cases := []struct {
+		name       string
+		ctx        context.Context
+		data       []byte
+		statusCode int
+		expect     func()
+	}{
+		{
+			name:       "unauthorized",
+			ctx:        context.Background(),
+			data:       validPNG,
+			statusCode: http.StatusUnauthorized,
+		},
+		{
+			name:       "suspended",
+			ctx:        sessCtx,
+			data:       validPNG,
+			statusCode: http.StatusForbidden,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(suspendedUser, nil)
+			},
+		},
+		{
+			name:       "bad type",
+			ctx:        sessCtx,
+			data:       []byte("not an image"),
+			statusCode: http.StatusUnsupportedMediaType,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+			},
+		},
+		{
+			name:       "too wide",
+			ctx:        sessCtx,
+			data:       pngBytes(t, 200, 10),
+			statusCode: http.StatusRequestEntityTooLarge,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+			},
+		},
+		{
+			name:       "store error",
+			ctx:        sessCtx,
+			data:       validPNG,
+			statusCode: http.StatusInternalServerError,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				blobStore.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "image/png").
+					Return(errors.New("some error"))
+			},
+		},
+		{
+			name:       "thumbnail store error",
+			ctx:        sessCtx,
+			data:       validPNG,
+			statusCode: http.StatusInternalServerError,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				var key string
+				gomock.InOrder(
+					blobStore.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "image/png").
+						DoAndReturn(func(_ context.Context, k string, _ interface{}, _ int64, _ string) error {
+							key = k
+							return nil
+						}),
+					blobStore.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "image/png").
+						Return(errors.New("some error")),
+					blobStore.EXPECT().Delete(gomock.Any(), gomock.Any()).
+						DoAndReturn(func(_ context.Context, k string) error {
+							assert.Equal(t, key, k)
+							return nil
+						}),
+				)
+			},
+		},
+		{
+			name:       "create error",
+			ctx:        sessCtx,
+			data:       validPNG,
+			statusCode: http.StatusInternalServerError,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				blobStore.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "image/png").
+					Return(nil).Times(2)
+				postsRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any(), expectedUser).Return(nil, errors.New("some error"))
+				blobStore.EXPECT().Delete(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+			},
+		},
+		{
+			name:       "success",
+			ctx:        sessCtx,
+			data:       validPNG,
+			statusCode: http.StatusCreated,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				blobStore.EXPECT().Put(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), "image/png").
+					Return(nil).Times(2)
+				postsRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any(), expectedUser).
+					DoAndReturn(func(_ context.Context, draft *posts.Post, author *user.User) (*posts.Post, error) {
+						assert.Equal(t, posts.TypeImage, draft.Type)
+						assert.Equal(t, category, draft.Category)
+						assert.Equal(t, 40, draft.Image.Width)
+						assert.Equal(t, 20, draft.Image.Height)
+						assert.True(t, strings.HasPrefix(draft.Image.Key, imageKeyPrefix))
+						assert.True(t, strings.HasSuffix(draft.Image.ThumbnailKey, thumbnailSuffix+".png"))
+						draft.ID = postID
+						return draft, nil
+					})
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			body, contentType := imageForm(t, c.data)
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/api/posts/image", body).WithContext(c.ctx)
+			req.Header.Set("Content-Type", contentType)
+			service.CreateImagePost(recorder, req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+
+			if c.statusCode == http.StatusCreated {
+				var result map[string]interface{}
+				assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+				img := result["image"].(map[string]interface{})
+				assert.True(t, strings.HasPrefix(img["url"].(string), posts.MediaPath+imageKeyPrefix))
+				assert.NotContains(t, img, "key")
+			}
+		})
+	}
+}
+
+func TestCreateImagePostTooLarge(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := newMockService(nil, userRepo, nil)
+	service.ImageLimits = images.Limits{MaxBytes: 16, MaxWidth: 100, MaxHeight: 100, ThumbnailSize: 16}
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+
+	body, contentType := imageForm(t, bytes.Repeat([]byte{0}, multipartOverhead+64))
+	recorder := httptest.NewRecorder()
+	req := sessiontest.SignIn(httptest.NewRequest(http.MethodPost, "/api/posts/image", body), expectedUser.Username)
+	req.Header.Set("Content-Type", contentType)
+	service.CreateImagePost(recorder, req)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+}
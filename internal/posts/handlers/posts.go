@@ -1,16 +1,28 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
-	
+
+	"github.com/KonstantinGalanin/redditclone/internal/blobs"
+	"github.com/KonstantinGalanin/redditclone/internal/blocks"
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/filter"
+	"github.com/KonstantinGalanin/redditclone/internal/images"
 	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
 	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/preferences"
+	"github.com/KonstantinGalanin/redditclone/internal/reports"
+	"github.com/KonstantinGalanin/redditclone/internal/saved"
 	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/subscriptions"
 	"github.com/KonstantinGalanin/redditclone/internal/user"
 )
 
@@ -21,6 +33,13 @@ const (
 	fieldCategory   = "category"
 	fieldCommentID  = "commentID"
 	fieldUsername   = "username"
+	querySort       = "sort"
+	queryLimit      = "limit"
+	queryOffset     = "offset"
+	queryReveal     = "reveal"
+	voteUp          = 1
+	voteNone        = 0
+	voteDown        = -1
 )
 
 type ErrorMessage struct {
@@ -43,12 +62,34 @@ func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
 func WriteErrorPost(w http.ResponseWriter, err error) {
 	if errors.Is(err, myerrors.ErrNoPost) {
 		WriteErrorMsg(w, myerrors.ErrNoPost.Error(), http.StatusNotFound)
+	} else if errors.Is(err, myerrors.ErrNoComment) {
+		WriteErrorMsg(w, myerrors.ErrNoComment.Error(), http.StatusNotFound)
+	} else if errors.Is(err, myerrors.ErrNotPoll) {
+		WriteErrorMsg(w, myerrors.ErrNotPoll.Error(), http.StatusBadRequest)
+	} else if errors.Is(err, myerrors.ErrNoPollOption) {
+		WriteErrorMsg(w, myerrors.ErrNoPollOption.Error(), http.StatusBadRequest)
+	} else if errors.Is(err, myerrors.ErrPollClosed) {
+		WriteErrorMsg(w, myerrors.ErrPollClosed.Error(), http.StatusConflict)
+	} else if errors.Is(err, myerrors.ErrPostLocked) {
+		WriteErrorMsg(w, myerrors.ErrPostLocked.Error(), http.StatusForbidden)
+	} else if errors.Is(err, myerrors.ErrBlocked) {
+		WriteErrorMsg(w, myerrors.ErrBlocked.Error(), http.StatusForbidden)
+	} else if errors.Is(err, myerrors.ErrPinLimit) {
+		WriteErrorMsg(w, myerrors.ErrPinLimit.Error(), http.StatusConflict)
+	} else if errors.Is(err, myerrors.ErrNotDraft) {
+		WriteErrorMsg(w, myerrors.ErrNotDraft.Error(), http.StatusConflict)
+	} else if errors.Is(err, myerrors.ErrBadSchedule) {
+		WriteErrorMsg(w, myerrors.ErrBadSchedule.Error(), http.StatusBadRequest)
 	} else {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
 func WriteResponsePost(w http.ResponseWriter, post *posts.Post, status int) {
+	if post != nil {
+		post.Redact()
+		post.Render()
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(post); err != nil {
@@ -57,12 +98,44 @@ func WriteResponsePost(w http.ResponseWriter, post *posts.Post, status int) {
 }
 
 func WriteResponsePosts(w http.ResponseWriter, posts []*posts.Post, status int) {
+	for _, post := range posts {
+		post.Redact()
+		post.Render()
+	}
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(posts); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
+func WriteResponseSaved(w http.ResponseWriter, items []*saved.Item, status int) {
+	for _, item := range items {
+		if item.Post != nil {
+			item.Post.Redact()
+			item.Post.Render()
+		}
+		if item.Comment != nil {
+			item.Comment.Redact()
+			item.Comment.Render()
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(items); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func WriteSuccess(w http.ResponseWriter) {
+	msg := &ErrorMessage{
+		Message: successMsg,
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(msg); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
 type PostParams struct {
 	PostID    string
 	Category  string
@@ -79,10 +152,53 @@ func getFieldFromURL(r *http.Request, field string) (string, error) {
 	return postID, nil
 }
 
+func getListParams(r *http.Request) (*posts.ListOptions, error) {
+	query := r.URL.Query()
+	params := &posts.ListOptions{
+		Sort: query.Get(querySort),
+	}
+	switch params.Sort {
+	case "", posts.SortNew, posts.SortTop:
+	default:
+		return nil, myerrors.ErrBadSort
+	}
+
+	var err error
+	if limit := query.Get(queryLimit); limit != "" {
+		if params.Limit, err = strconv.Atoi(limit); err != nil || params.Limit < 0 {
+			return nil, myerrors.ErrBadPagination
+		}
+	}
+	if offset := query.Get(queryOffset); offset != "" {
+		if params.Offset, err = strconv.Atoi(offset); err != nil || params.Offset < 0 {
+			return nil, myerrors.ErrBadPagination
+		}
+	}
+	return params, nil
+}
+
 type PostsHandler struct {
-	PostsRepo      posts.PostRepo
-	UserRepo       user.UserRepo
-	SessionManager session.SessionManager
+	PostsRepo        posts.PostRepo
+	UserRepo         user.UserRepo
+	SessionManager   session.SessionManager
+	SubscriptionRepo subscriptions.SubscriptionRepo
+	SavedRepo        saved.SavedRepo
+	PreferencesRepo  preferences.PreferencesRepo
+	DefaultFeed      []string
+	Events           events.Publisher
+	Blobs            blobs.BlobStore
+	ImageLimits      images.Limits
+	Filter           filter.Checker
+	ReportRepo       reports.ReportRepo
+	BlockRepo        blocks.BlockRepo
+}
+
+// publish hands an event to whoever listens (notifications, live updates).
+// Publishing is optional so the handler works without any listeners.
+func (p *PostsHandler) publish(ctx context.Context, event *events.Event) {
+	if p.Events != nil {
+		p.Events.Publish(ctx, event)
+	}
 }
 
 func (p *PostsHandler) getUserFromCtx(r *http.Request) (*user.User, error) {
@@ -92,20 +208,164 @@ func (p *PostsHandler) getUserFromCtx(r *http.Request) (*user.User, error) {
 		return nil, myerrors.ErrNoAuth
 	}
 
-	user, err := p.UserRepo.GetUserByUsername(sess.Username)
+	user, err := p.UserRepo.GetUserByUsername(r.Context(), sess.Username)
 	if err != nil {
 		return nil, fmt.Errorf("get user from sess: %w", err)
 	}
 	return user, nil
 }
 
+// getOptionalUser is getUserFromCtx for endpoints that also serve anonymous
+// callers: a missing session yields a nil user and no error.
+func (p *PostsHandler) getOptionalUser(r *http.Request) (*user.User, error) {
+	user, err := p.getUserFromCtx(r)
+	if errors.Is(err, myerrors.ErrNoAuth) {
+		return nil, nil
+	}
+	return user, err
+}
+
+// markSaved sets the saved flag on the posts the caller has bookmarked.
+func (p *PostsHandler) markSaved(ctx context.Context, user *user.User, items ...*posts.Post) error {
+	if user == nil || len(items) == 0 {
+		return nil
+	}
+
+	postIDs := make([]string, 0, len(items))
+	for _, post := range items {
+		postIDs = append(postIDs, post.ID)
+	}
+	savedIDs, err := p.SavedRepo.GetSavedPostIDs(ctx, user.ID, postIDs)
+	if err != nil {
+		return fmt.Errorf("mark saved: %w", err)
+	}
+	for _, post := range items {
+		post.Saved = savedIDs[post.ID]
+	}
+	return nil
+}
+
+// tallyPolls computes poll results as the caller is allowed to see them.
+// Polls that are not tallied are returned without results.
+func tallyPolls(user *user.User, items ...*posts.Post) {
+	viewerID := userID(user)
+	now := time.Now()
+	for _, post := range items {
+		if post != nil && post.Poll != nil {
+			post.Poll.Tally(viewerID, now)
+		}
+	}
+}
+
+func userID(user *user.User) string {
+	if user == nil {
+		return ""
+	}
+	return user.ID
+}
+
+func findComment(post *posts.Post, commentID string) *posts.Comment {
+	for _, comment := range post.Comments {
+		if comment.ID == commentID {
+			return comment
+		}
+	}
+	return nil
+}
+
 func (p *PostsHandler) GetAll(w http.ResponseWriter, r *http.Request) {
-	posts, err := p.PostsRepo.GetAllPosts()
+	params, err := getListParams(r)
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getOptionalUser(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	prefs, err := p.preferences(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hidden, err := p.hiddenAuthors(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	params.HideNSFW = !prefs.ShowNSFW
+	posts, err := p.PostsRepo.GetAllPosts(r.Context(), hidden, params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = p.markSaved(r.Context(), user, posts...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tallyPolls(user, posts...)
+	withholdFlagged(user, prefs, posts...)
+	WriteResponsePosts(w, posts, http.StatusOK)
+}
+
+// Feed merges the posts of every category the caller is subscribed to.
+// Anonymous users and users without subscriptions get DefaultFeed.
+func (p *PostsHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	params, err := getListParams(r)
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getOptionalUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	prefs, err := p.preferences(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	categories := p.DefaultFeed
+	if user != nil {
+		subscribed, err := p.SubscriptionRepo.GetCategories(user.ID)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if len(subscribed) != 0 {
+			categories = subscribed
+		}
+	}
+
+	hidden, err := p.hiddenAuthors(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	params.HideNSFW = !prefs.ShowNSFW
+	posts, err := p.PostsRepo.GetPostsByCategories(r.Context(), categories, hidden, params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = p.markSaved(r.Context(), user, posts...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tallyPolls(user, posts...)
+	withholdFlagged(user, prefs, posts...)
 	WriteResponsePosts(w, posts, http.StatusOK)
 }
 
@@ -116,6 +376,15 @@ func (p *PostsHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		Type     string `json:"type"`
 		URL      string `json:"url,omitempty"`
 		Text     string `json:"text,omitempty"`
+		Poll     *struct {
+			Options     []string   `json:"options"`
+			Closes      *time.Time `json:"closes,omitempty"`
+			HideResults bool       `json:"hideResults,omitempty"`
+		} `json:"poll,omitempty"`
+		Draft     bool       `json:"draft,omitempty"`
+		PublishAt *time.Time `json:"publishAt,omitempty"`
+		NSFW      bool       `json:"nsfw,omitempty"`
+		Spoiler   bool       `json:"spoiler,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
@@ -123,17 +392,85 @@ func (p *PostsHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	draft := &posts.Post{
+		Category: data.Category,
+		Title:    data.Title,
+		Type:     data.Type,
+		URL:      data.URL,
+		Text:     data.Text,
+		NSFW:     data.NSFW,
+		Spoiler:  data.Spoiler,
+	}
+	switch {
+	case data.PublishAt != nil:
+		if !data.PublishAt.After(time.Now()) {
+			WriteErrorMsg(w, myerrors.ErrBadSchedule.Error(), http.StatusBadRequest)
+			return
+		}
+		draft.Status = posts.StatusScheduled
+		draft.PublishAt = data.PublishAt
+	case data.Draft:
+		draft.Status = posts.StatusDraft
+	}
+	switch data.Type {
+	case posts.TypePoll:
+		if data.Poll == nil {
+			WriteErrorMsg(w, myerrors.ErrBadPoll.Error(), http.StatusBadRequest)
+			return
+		}
+		var err error
+		draft.Poll, err = posts.NewPoll(data.Poll.Options, data.Poll.Closes, data.Poll.HideResults, time.Now())
+		if err != nil {
+			WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	case posts.TypeImage:
+		WriteErrorMsg(w, myerrors.ErrImageUpload.Error(), http.StatusBadRequest)
+		return
+	}
+
 	user, err := p.getUserFromCtx(r)
 	if err != nil {
 		WriteErrorMsg(w, fmt.Errorf("create post %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
 		return
 	}
+	if user.IsSuspended(time.Now()) {
+		WriteErrorMsg(w, myerrors.ErrSuspended.Error(), http.StatusForbidden)
+		return
+	}
 
-	post, err := p.PostsRepo.CreatePost(data.Category, data.Title, data.Type, data.URL, data.Text, user)
+	blockers, err := p.blockers(user)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if !checkMentions(w, blockers, draft.Title+" "+draft.Text) {
+		return
+	}
+
+	content := &filter.Content{Category: draft.Category, Title: draft.Title, Text: draft.Text, URL: draft.URL}
+	verdict, ok := p.screen(w, r, content)
+	if !ok {
+		return
+	}
+	if verdict.Outcome == filter.OutcomeHold {
+		draft.Status = posts.StatusHeld
+		draft.PublishAt = nil
+	}
+
+	post, err := p.PostsRepo.CreatePost(r.Context(), draft, user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if post.Status == posts.StatusHeld {
+		p.hold(r.Context(), post.ID, "", user, verdict, postExcerpt(content))
+	}
+	// Drafts announce themselves when they are published.
+	if post.IsPublished() {
+		p.publish(r.Context(), &events.Event{Type: events.PostCreated, PostID: post.ID, Actor: user, Post: post})
+	}
+	tallyPolls(user, post)
 	WriteResponsePost(w, post, http.StatusCreated)
 }
 
@@ -144,12 +481,41 @@ func (p *PostsHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := p.PostsRepo.GetPost(postID)
+	user, err := p.getOptionalUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hidden, err := p.hiddenAuthors(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	post, err := p.PostsRepo.GetPostHiding(r.Context(), postID, hidden)
 	if err != nil {
 		WriteErrorPost(w, err)
 		return
 	}
+	if !post.VisibleTo(userID(user)) {
+		WriteErrorPost(w, myerrors.ErrNoPost)
+		return
+	}
 
+	if err = p.markSaved(r.Context(), user, post); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tallyPolls(user, post)
+	if r.URL.Query().Get(queryReveal) != "true" {
+		prefs, err := p.preferences(user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		withholdFlagged(user, prefs, post)
+	}
 	WriteResponsePost(w, post, http.StatusOK)
 }
 
@@ -165,10 +531,11 @@ func (p *PostsHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := p.PostsRepo.DeletePost(postID, user.ID); err != nil {
+	if err := p.PostsRepo.DeletePost(r.Context(), postID, user.ID); err != nil {
 		WriteErrorPost(w, err)
 		return
 	}
+	p.publish(r.Context(), &events.Event{Type: events.PostDeleted, PostID: postID, Actor: user})
 
 	msg := &ErrorMessage{
 		Message: successMsg,
@@ -186,12 +553,41 @@ func (p *PostsHandler) GetByCategory(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	posts, err := p.PostsRepo.GetPostsByCategory(category)
+	params, err := getListParams(r)
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params.PinnedFirst = true
+	user, err := p.getOptionalUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	prefs, err := p.preferences(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	hidden, err := p.hiddenAuthors(user)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	params.HideNSFW = !prefs.ShowNSFW
+	posts, err := p.PostsRepo.GetPostsByCategory(r.Context(), category, hidden, params)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if err = p.markSaved(r.Context(), user, posts...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tallyPolls(user, posts...)
+	withholdFlagged(user, prefs, posts...)
 	WriteResponsePosts(w, posts, http.StatusOK)
 }
 
@@ -204,6 +600,7 @@ func (p *PostsHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 
 	var data struct {
 		Comment string `json:"comment"`
+		Parent  string `json:"parent,omitempty"`
 	}
 	if err = json.NewDecoder(r.Body).Decode(&data); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -215,13 +612,54 @@ func (p *PostsHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 		WriteErrorMsg(w, fmt.Errorf("create comment %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
 		return
 	}
+	if user.IsSuspended(time.Now()) {
+		WriteErrorMsg(w, myerrors.ErrSuspended.Error(), http.StatusForbidden)
+		return
+	}
 
+	blockers, err := p.blockers(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	commentText := data.Comment
-	post, err := p.PostsRepo.CreateComment(postID, commentText, user)
+	if !checkMentions(w, blockers, commentText) {
+		return
+	}
+	hidden, err := p.hiddenAuthors(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	verdict, ok := p.screen(w, r, &filter.Content{Text: commentText})
+	if !ok {
+		return
+	}
+	opts := posts.CommentOptions{
+		Held:      verdict.Outcome == filter.OutcomeHold,
+		BlockedBy: blocks.IDs(blockers),
+		Hidden:    hidden,
+	}
+
+	post, comment, err := p.PostsRepo.CreateComment(r.Context(), postID, data.Parent, commentText, user, opts)
 	if err != nil {
 		WriteErrorPost(w, err)
 		return
 	}
+	if opts.Held {
+		p.hold(r.Context(), post.ID, comment.ID, user, verdict, commentText)
+		WriteResponsePost(w, post, http.StatusCreated)
+		return
+	}
+	p.publish(r.Context(), &events.Event{
+		Type:      events.CommentCreated,
+		PostID:    post.ID,
+		CommentID: comment.ID,
+		Actor:     user,
+		Post:      post,
+		Comment:   comment,
+	})
 
 	WriteResponsePost(w, post, http.StatusCreated)
 }
@@ -239,17 +677,25 @@ func (p *PostsHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	_, err = p.getUserFromCtx(r)
+	user, err := p.getUserFromCtx(r)
 	if err != nil {
 		WriteErrorMsg(w, fmt.Errorf("delete comment %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
 		return
 	}
 
-	post, err := p.PostsRepo.DeleteComment(postID, commentID)
+	post, err := p.PostsRepo.DeleteComment(r.Context(), postID, commentID, user.ID)
 	if err != nil {
 		WriteErrorPost(w, err)
 		return
 	}
+	p.publish(r.Context(), &events.Event{
+		Type:      events.CommentDeleted,
+		PostID:    post.ID,
+		CommentID: commentID,
+		Actor:     user,
+		Post:      post,
+		Comment:   findComment(post, commentID),
+	})
 
 	WriteResponsePost(w, post, http.StatusOK)
 }
@@ -267,11 +713,12 @@ func (p *PostsHandler) UpvotePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := p.PostsRepo.UpvotePost(postID, user.ID)
+	post, delta, err := p.PostsRepo.UpvotePost(r.Context(), postID, user.ID)
 	if err != nil {
 		WriteErrorPost(w, err)
 		return
 	}
+	p.publish(r.Context(), &events.Event{Type: events.ScoreChanged, PostID: post.ID, Actor: user, Post: post, ScoreDelta: delta})
 
 	WriteResponsePost(w, post, http.StatusOK)
 }
@@ -289,11 +736,12 @@ func (p *PostsHandler) UnvotePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := p.PostsRepo.UnvotePost(postID, user.ID)
+	post, delta, err := p.PostsRepo.UnvotePost(r.Context(), postID, user.ID)
 	if err != nil {
 		WriteErrorPost(w, err)
 		return
 	}
+	p.publish(r.Context(), &events.Event{Type: events.ScoreChanged, PostID: post.ID, Actor: user, Post: post, ScoreDelta: delta})
 
 	WriteResponsePost(w, post, http.StatusOK)
 }
@@ -311,15 +759,106 @@ func (p *PostsHandler) DownvotePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := p.PostsRepo.DownvotePost(postID, user.ID)
+	post, delta, err := p.PostsRepo.DownvotePost(r.Context(), postID, user.ID)
 	if err != nil {
 		WriteErrorPost(w, err)
 		return
 	}
+	p.publish(r.Context(), &events.Event{Type: events.ScoreChanged, PostID: post.ID, Actor: user, Post: post, ScoreDelta: delta})
 
 	WriteResponsePost(w, post, http.StatusOK)
 }
 
+func (p *PostsHandler) voteComment(w http.ResponseWriter, r *http.Request, vote int) {
+	postID, err := getFieldFromURL(r, fieldPostID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	commentID, err := getFieldFromURL(r, fieldCommentID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("vote comment %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	post, delta, err := p.PostsRepo.VoteComment(r.Context(), postID, commentID, user.ID, vote)
+	if err != nil {
+		WriteErrorPost(w, err)
+		return
+	}
+	p.publish(r.Context(), &events.Event{
+		Type:       events.ScoreChanged,
+		PostID:     post.ID,
+		CommentID:  commentID,
+		Actor:      user,
+		Post:       post,
+		Comment:    findComment(post, commentID),
+		ScoreDelta: delta,
+	})
+
+	WriteResponsePost(w, post, http.StatusOK)
+}
+
+func (p *PostsHandler) UpvoteComment(w http.ResponseWriter, r *http.Request) {
+	p.voteComment(w, r, voteUp)
+}
+
+func (p *PostsHandler) UnvoteComment(w http.ResponseWriter, r *http.Request) {
+	p.voteComment(w, r, voteNone)
+}
+
+func (p *PostsHandler) DownvoteComment(w http.ResponseWriter, r *http.Request) {
+	p.voteComment(w, r, voteDown)
+}
+
+// VotePoll records the caller's choice in a poll. The choice can be changed
+// until the poll closes.
+func (p *PostsHandler) VotePoll(w http.ResponseWriter, r *http.Request) {
+	postID, err := getFieldFromURL(r, fieldPostID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var data struct {
+		Option string `json:"option"`
+	}
+	if err = json.NewDecoder(r.Body).Decode(&data); err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if data.Option == "" {
+		WriteErrorMsg(w, myerrors.ErrNoPollOption.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("vote poll %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+	if user.IsSuspended(time.Now()) {
+		WriteErrorMsg(w, myerrors.ErrSuspended.Error(), http.StatusForbidden)
+		return
+	}
+
+	post, err := p.PostsRepo.VotePoll(r.Context(), postID, user.ID, data.Option, time.Now())
+	if err != nil {
+		WriteErrorPost(w, err)
+		return
+	}
+
+	tallyPolls(user, post)
+	WriteResponsePost(w, post, http.StatusOK)
+}
+
 func (p *PostsHandler) PostsByUser(w http.ResponseWriter, r *http.Request) {
 	username, err := getFieldFromURL(r, fieldUsername)
 	if err != nil {
@@ -327,11 +866,176 @@ func (p *PostsHandler) PostsByUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	posts, err := p.PostsRepo.GetPostsByUser(username)
+	user, err := p.getOptionalUser(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	prefs, err := p.preferences(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	hidden, err := p.hiddenAuthors(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	posts, err := p.PostsRepo.GetPostsByUser(r.Context(), username, hidden)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
+	if err = p.markSaved(r.Context(), user, posts...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tallyPolls(user, posts...)
+	withholdFlagged(user, prefs, posts...)
 	WriteResponsePosts(w, posts, http.StatusOK)
 }
+
+func (p *PostsHandler) save(w http.ResponseWriter, r *http.Request) {
+	postID, err := getFieldFromURL(r, fieldPostID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	commentID := mux.Vars(r)[fieldCommentID]
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("save %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	post, err := p.PostsRepo.GetPost(r.Context(), postID)
+	if err != nil {
+		WriteErrorPost(w, err)
+		return
+	}
+	if !post.VisibleTo(user.ID) {
+		WriteErrorPost(w, myerrors.ErrNoPost)
+		return
+	}
+	if commentID != "" && findComment(post, commentID) == nil {
+		WriteErrorPost(w, myerrors.ErrNoComment)
+		return
+	}
+
+	if err = p.SavedRepo.Save(r.Context(), user.ID, postID, commentID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// unsave does not require the target to exist, so that bookmarks of removed
+// content can still be dropped.
+func (p *PostsHandler) unsave(w http.ResponseWriter, r *http.Request) {
+	postID, err := getFieldFromURL(r, fieldPostID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	commentID := mux.Vars(r)[fieldCommentID]
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("unsave %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err = p.SavedRepo.Unsave(r.Context(), user.ID, postID, commentID); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+func (p *PostsHandler) SavePost(w http.ResponseWriter, r *http.Request) {
+	p.save(w, r)
+}
+
+func (p *PostsHandler) UnsavePost(w http.ResponseWriter, r *http.Request) {
+	p.unsave(w, r)
+}
+
+func (p *PostsHandler) SaveComment(w http.ResponseWriter, r *http.Request) {
+	p.save(w, r)
+}
+
+func (p *PostsHandler) UnsaveComment(w http.ResponseWriter, r *http.Request) {
+	p.unsave(w, r)
+}
+
+// Saved lists the caller's bookmarks, newest first. Targets that were deleted
+// or purged, or whose author the caller blocked, are returned with removed set
+// instead of being dropped silently.
+func (p *PostsHandler) Saved(w http.ResponseWriter, r *http.Request) {
+	params, err := getListParams(r)
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("saved %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	items, err := p.SavedRepo.GetSaved(r.Context(), user.ID, params.Limit, params.Offset)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	postIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		postIDs = append(postIDs, item.PostID)
+	}
+	hidden, err := p.hiddenAuthors(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	found, err := p.PostsRepo.GetPostsByIDs(r.Context(), postIDs, hidden)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	prefs, err := p.preferences(user)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tallyPolls(user, found...)
+	withholdFlagged(user, prefs, found...)
+	postsByID := make(map[string]*posts.Post, len(found))
+	for _, post := range found {
+		postsByID[post.ID] = post
+	}
+
+	for _, item := range items {
+		post, ok := postsByID[item.PostID]
+		if !ok {
+			item.Removed = true
+			continue
+		}
+		if item.CommentID == "" {
+			post.Saved = true
+			item.Post = post
+			item.Removed = post.Deleted != nil
+			continue
+		}
+		item.Comment = findComment(post, item.CommentID)
+		item.Removed = item.Comment == nil || item.Comment.Deleted != nil
+	}
+
+	WriteResponseSaved(w, items, http.StatusOK)
+}
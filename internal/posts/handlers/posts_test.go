@@ -9,17 +9,23 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
-	"github.com/KonstantinGalanin/redditclone/internal/session"
-	"github.com/KonstantinGalanin/redditclone/internal/session/mock"
-	"github.com/KonstantinGalanin/redditclone/internal/user"
 	"github.com/golang/mock/gomock"
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
 	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
 	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/saved"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/session/mock"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
 
 	repositoryPosts "github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	repositorySaved "github.com/KonstantinGalanin/redditclone/internal/saved/repository"
+	repositorySubscriptions "github.com/KonstantinGalanin/redditclone/internal/subscriptions/repository"
 	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
 )
 
@@ -38,6 +44,13 @@ var expectedUser = &user.User{
 	ID:       id,
 }
 
+var suspendedUser = &user.User{
+	Username:       username,
+	Password:       password,
+	ID:             id,
+	SuspendedUntil: time.Now().Add(time.Hour),
+}
+
 type mockResponseWriter struct {
 	HeaderMap http.Header
 	Code      int
@@ -105,6 +118,51 @@ func TestWriteResponsePost(t *testing.T) {
 	})
 }
 
+func TestWriteResponsePostRedacts(t *testing.T) {
+	post := &posts.Post{
+		Author:  expectedUser,
+		Text:    "secret",
+		Deleted: &posts.Tombstone{By: posts.RemovedByModerator},
+		Comments: []*posts.Comment{
+			{Author: expectedUser, Body: "kept"},
+			{Author: expectedUser, Body: "gone", Deleted: &posts.Tombstone{By: posts.DeletedByAuthor}},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	WriteResponsePost(recorder, post, http.StatusOK)
+
+	var result posts.Post
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+	assert.Equal(t, posts.RemovedPlaceholder, result.Author.Username)
+	assert.Equal(t, posts.RemovedPlaceholder, result.Text)
+	assert.Equal(t, "kept", result.Comments[0].Body)
+	assert.Equal(t, username, result.Comments[0].Author.Username)
+	assert.Equal(t, posts.DeletedPlaceholder, result.Comments[1].Body)
+	assert.Equal(t, posts.DeletedPlaceholder, result.Comments[1].Author.Username)
+}
+
+func TestWriteResponsePostRendersMarkdown(t *testing.T) {
+	post := &posts.Post{
+		Author: expectedUser,
+		Text:   "**bold** <script>alert(1)</script>",
+		Comments: []*posts.Comment{
+			{Author: expectedUser, Body: "[link](https://example.com)"},
+			{Author: expectedUser, Body: "*gone*", Deleted: &posts.Tombstone{By: posts.DeletedByAuthor}},
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	WriteResponsePost(recorder, post, http.StatusOK)
+
+	var result posts.Post
+	assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+	assert.Equal(t, "**bold** <script>alert(1)</script>", result.Text)
+	assert.Equal(t, "<p><strong>bold</strong> &lt;script&gt;alert(1)&lt;/script&gt;</p>\n", result.TextHTML)
+	assert.Equal(t, "<p><a href=\"https://example.com\" rel=\"nofollow ugc\">link</a></p>\n", result.Comments[0].BodyHTML)
+	assert.Equal(t, "<p>"+posts.DeletedPlaceholder+"</p>\n", result.Comments[1].BodyHTML)
+}
+
 func TestWriteResponsePosts(t *testing.T) {
 	posts := []*posts.Post{}
 	// success
@@ -197,7 +255,7 @@ func TestGetUserFromCtxNoUser(t *testing.T) {
 	sessionManager := mock.NewMockSessionManager(ctrl)
 
 	service := newMockService(postsRepo, userRepo, sessionManager)
-	userRepo.EXPECT().GetUserByUsername(username).Return(nil, errors.New("no user session error"))
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(nil, errors.New("no user session error"))
 
 	user, err := service.getUserFromCtx(req)
 
@@ -220,7 +278,7 @@ func TestGetUserFromCtxSuccess(t *testing.T) {
 	sessionManager := mock.NewMockSessionManager(ctrl)
 
 	service := newMockService(postsRepo, userRepo, sessionManager)
-	userRepo.EXPECT().GetUserByUsername(username).Return(expectedUser, nil)
+	userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
 
 	actualUser, err := service.getUserFromCtx(req)
 
@@ -250,14 +308,14 @@ func TestGetAllError(t *testing.T) {
 			name:       "get all posts error",
 			statusCode: http.StatusInternalServerError,
 			postExpect: func() {
-				postsRepo.EXPECT().GetAllPosts().Return(nil, errors.New("some error"))
+				postsRepo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), gomock.Any()).Return(nil, errors.New("some error"))
 			},
 		},
 		{
 			name:       "success",
 			statusCode: http.StatusOK,
 			postExpect: func() {
-				postsRepo.EXPECT().GetAllPosts().Return([]*posts.Post{}, nil)
+				postsRepo.EXPECT().GetAllPosts(gomock.Any(), gomock.Nil(), gomock.Any()).Return([]*posts.Post{}, nil)
 			},
 		},
 	}
@@ -330,10 +388,20 @@ func TestCreatePost(t *testing.T) {
 				Username: expectedUser.Username,
 			})),
 			postExpect: func() {
-				postsRepo.EXPECT().CreatePost(data.Category, data.Title, data.Type, data.URL, data.Text, expectedUser).Return(nil, errors.New("some error"))
+				postsRepo.EXPECT().CreatePost(gomock.Any(), &posts.Post{Category: data.Category, Title: data.Title, Type: data.Type, URL: data.URL, Text: data.Text}, expectedUser).Return(nil, errors.New("some error"))
 			},
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+			},
+		},
+		{
+			name:       "suspended user",
+			statusCode: http.StatusForbidden,
+			req: httptest.NewRequest(http.MethodGet, "/", bytes.NewReader(body)).WithContext(context.WithValue(context.Background(), "session", &session.Session{
+				Username: expectedUser.Username,
+			})),
+			userExpect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(suspendedUser, nil)
 			},
 		},
 		{
@@ -343,10 +411,10 @@ func TestCreatePost(t *testing.T) {
 				Username: expectedUser.Username,
 			})),
 			postExpect: func() {
-				postsRepo.EXPECT().CreatePost(data.Category, data.Title, data.Type, data.URL, data.Text, expectedUser).Return(&posts.Post{}, nil)
+				postsRepo.EXPECT().CreatePost(gomock.Any(), &posts.Post{Category: data.Category, Title: data.Title, Type: data.Type, URL: data.URL, Text: data.Text}, expectedUser).Return(&posts.Post{}, nil)
 			},
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 		},
 	}
@@ -366,6 +434,172 @@ func TestCreatePost(t *testing.T) {
 	}
 }
 
+func TestCreatePoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	sessionManager := mock.NewMockSessionManager(ctrl)
+
+	service := newMockService(postsRepo, userRepo, sessionManager)
+	sessCtx := context.WithValue(context.Background(), "session", &session.Session{
+		Username: expectedUser.Username,
+	})
+
+	cases := []struct {
+		name       string
+		statusCode int
+		body       string
+		expect     func()
+	}{
+		{
+			name:       "missing poll",
+			statusCode: http.StatusBadRequest,
+			body:       `{"category":"music","title":"t","type":"poll"}`,
+		},
+		{
+			name:       "one option",
+			statusCode: http.StatusBadRequest,
+			body:       `{"category":"music","title":"t","type":"poll","poll":{"options":["a"]}}`,
+		},
+		{
+			name:       "image without upload",
+			statusCode: http.StatusBadRequest,
+			body:       `{"category":"music","title":"t","type":"image"}`,
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusCreated,
+			body:       `{"category":"music","title":"t","type":"poll","poll":{"options":["a","b"],"hideResults":true}}`,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().CreatePost(gomock.Any(), gomock.Any(), expectedUser).
+					DoAndReturn(func(_ context.Context, draft *posts.Post, author *user.User) (*posts.Post, error) {
+						assert.Equal(t, posts.TypePoll, draft.Type)
+						assert.Len(t, draft.Poll.Options, 2)
+						assert.True(t, draft.Poll.HideResults)
+						return draft, nil
+					})
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(c.body)).WithContext(sessCtx)
+			service.CreatePost(recorder, req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+
+			if c.statusCode == http.StatusCreated {
+				var result posts.Post
+				assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+				assert.Nil(t, result.Poll.Results)
+			}
+		})
+	}
+}
+
+func TestVotePoll(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	sessionManager := mock.NewMockSessionManager(ctrl)
+
+	service := newMockService(postsRepo, userRepo, sessionManager)
+	sessCtx := context.WithValue(context.Background(), "session", &session.Session{
+		Username: expectedUser.Username,
+	})
+	vars := map[string]string{"id": postID}
+	poll := &posts.Poll{
+		Options:     []*posts.PollOption{{ID: "0", Text: "a"}, {ID: "1", Text: "b"}},
+		HideResults: true,
+		Votes:       []*posts.PollVote{{UserID: expectedUser.ID, OptionID: "1"}},
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		body       string
+		ctx        context.Context
+		expect     func()
+	}{
+		{
+			name:       "bad body",
+			statusCode: http.StatusBadRequest,
+			body:       `{`,
+			ctx:        sessCtx,
+		},
+		{
+			name:       "no option",
+			statusCode: http.StatusBadRequest,
+			body:       `{}`,
+			ctx:        sessCtx,
+		},
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			body:       `{"option":"1"}`,
+			ctx:        context.Background(),
+		},
+		{
+			name:       "not a poll",
+			statusCode: http.StatusBadRequest,
+			body:       `{"option":"1"}`,
+			ctx:        sessCtx,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().VotePoll(gomock.Any(), postID, expectedUser.ID, "1", gomock.Any()).Return(nil, fmt.Errorf("vote: %w", myerrors.ErrNotPoll))
+			},
+		},
+		{
+			name:       "closed",
+			statusCode: http.StatusConflict,
+			body:       `{"option":"1"}`,
+			ctx:        sessCtx,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().VotePoll(gomock.Any(), postID, expectedUser.ID, "1", gomock.Any()).Return(nil, fmt.Errorf("vote: %w", myerrors.ErrPollClosed))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			body:       `{"option":"1"}`,
+			ctx:        sessCtx,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().VotePoll(gomock.Any(), postID, expectedUser.ID, "1", gomock.Any()).Return(&posts.Post{ID: postID, Poll: poll}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			req := mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(c.body)).WithContext(c.ctx), vars)
+			service.VotePoll(recorder, req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+
+			if c.statusCode == http.StatusOK {
+				var result posts.Post
+				assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+				assert.Equal(t, "1", result.Poll.Choice)
+				assert.Equal(t, 1, result.Poll.Results.Counts["1"])
+			}
+		})
+	}
+}
+
 func TestGetPost(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -398,7 +632,18 @@ func TestGetPost(t *testing.T) {
 			statusCode: http.StatusInternalServerError,
 			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"id": postID}),
 			postExpect: func() {
-				postsRepo.EXPECT().GetPost(postID).Return(nil, errors.New("some error"))
+				postsRepo.EXPECT().GetPostHiding(gomock.Any(), postID, gomock.Nil()).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "draft of another user",
+			statusCode: http.StatusNotFound,
+			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"id": postID}),
+			postExpect: func() {
+				postsRepo.EXPECT().GetPostHiding(gomock.Any(), postID, gomock.Nil()).Return(&posts.Post{
+					Author: &user.User{ID: "2"},
+					Status: posts.StatusDraft,
+				}, nil)
 			},
 		},
 		{
@@ -406,7 +651,7 @@ func TestGetPost(t *testing.T) {
 			statusCode: http.StatusOK,
 			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"id": postID}),
 			postExpect: func() {
-				postsRepo.EXPECT().GetPost(postID).Return(&posts.Post{}, nil)
+				postsRepo.EXPECT().GetPostHiding(gomock.Any(), postID, gomock.Nil()).Return(&posts.Post{}, nil)
 			},
 		},
 	}
@@ -474,10 +719,10 @@ func TestDeletePost(t *testing.T) {
 				Username: expectedUser.Username,
 			})), map[string]string{"id": postID}),
 			postExpect: func() {
-				postsRepo.EXPECT().DeletePost(postID, expectedUser.ID).Return(errors.New("some error"))
+				postsRepo.EXPECT().DeletePost(gomock.Any(), postID, expectedUser.ID).Return(errors.New("some error"))
 			},
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 		},
 		{
@@ -487,10 +732,10 @@ func TestDeletePost(t *testing.T) {
 				Username: expectedUser.Username,
 			})), map[string]string{"id": postID}),
 			postExpect: func() {
-				postsRepo.EXPECT().DeletePost(postID, expectedUser.ID).Return(nil)
+				postsRepo.EXPECT().DeletePost(gomock.Any(), postID, expectedUser.ID).Return(nil)
 			},
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 			mockRecorder: true,
 		},
@@ -501,10 +746,10 @@ func TestDeletePost(t *testing.T) {
 				Username: expectedUser.Username,
 			})), map[string]string{"id": postID}),
 			postExpect: func() {
-				postsRepo.EXPECT().DeletePost(postID, expectedUser.ID).Return(nil)
+				postsRepo.EXPECT().DeletePost(gomock.Any(), postID, expectedUser.ID).Return(nil)
 			},
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 		},
 	}
@@ -565,7 +810,7 @@ func TestGetByCategory(t *testing.T) {
 			statusCode: http.StatusInternalServerError,
 			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"category": category}),
 			postExpect: func() {
-				postsRepo.EXPECT().GetPostsByCategory(category).Return([]*posts.Post{}, errors.New("some error"))
+				postsRepo.EXPECT().GetPostsByCategory(gomock.Any(), category, gomock.Nil(), gomock.Any()).Return([]*posts.Post{}, errors.New("some error"))
 			},
 			mockRecorder: false,
 		},
@@ -574,7 +819,7 @@ func TestGetByCategory(t *testing.T) {
 			statusCode: http.StatusOK,
 			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"category": category}),
 			postExpect: func() {
-				postsRepo.EXPECT().GetPostsByCategory(category).Return([]*posts.Post{}, nil)
+				postsRepo.EXPECT().GetPostsByCategory(gomock.Any(), category, gomock.Nil(), gomock.Any()).Return([]*posts.Post{}, nil)
 			},
 			mockRecorder: false,
 		},
@@ -620,6 +865,12 @@ func TestCreateComment(t *testing.T) {
 	sessionManager := mock.NewMockSessionManager(ctrl)
 
 	service := newMockService(postsRepo, userRepo, sessionManager)
+	bus := events.NewBus()
+	var published []*events.Event
+	bus.Subscribe(events.ListenerFunc(func(_ context.Context, event *events.Event) {
+		published = append(published, event)
+	}))
+	service.Events = bus
 
 	data := DataComment{
 		Comment: "comment",
@@ -662,13 +913,37 @@ func TestCreateComment(t *testing.T) {
 				Username: expectedUser.Username,
 			})), map[string]string{"id": postID}),
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 			postExpect: func() {
-				postsRepo.EXPECT().CreateComment(postID, data.Comment, expectedUser).Return(nil, errors.New("some error"))
+				postsRepo.EXPECT().CreateComment(gomock.Any(), postID, "", data.Comment, expectedUser, posts.CommentOptions{}).Return(nil, nil, errors.New("some error"))
 			},
 			mockRecorder: false,
 		},
+		{
+			name:       "suspended user",
+			statusCode: http.StatusForbidden,
+			req: mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", bytes.NewReader(body)).WithContext(context.WithValue(context.Background(), "session", &session.Session{
+				Username: expectedUser.Username,
+			})), map[string]string{"id": postID}),
+			userExpect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(suspendedUser, nil)
+			},
+		},
+		{
+			name:       "locked post",
+			statusCode: http.StatusForbidden,
+			req: mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", bytes.NewReader(body)).WithContext(context.WithValue(context.Background(), "session", &session.Session{
+				Username: expectedUser.Username,
+			})), map[string]string{"id": postID}),
+			userExpect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+			},
+			postExpect: func() {
+				postsRepo.EXPECT().CreateComment(gomock.Any(), postID, "", data.Comment, expectedUser, posts.CommentOptions{}).
+					Return(nil, nil, fmt.Errorf("mogngodb create comment: %w", myerrors.ErrPostLocked))
+			},
+		},
 		{
 			name:       "success",
 			statusCode: http.StatusCreated,
@@ -676,10 +951,11 @@ func TestCreateComment(t *testing.T) {
 				Username: expectedUser.Username,
 			})), map[string]string{"id": postID}),
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 			postExpect: func() {
-				postsRepo.EXPECT().CreateComment(postID, data.Comment, expectedUser).Return(&posts.Post{}, nil)
+				postsRepo.EXPECT().CreateComment(gomock.Any(), postID, "", data.Comment, expectedUser, posts.CommentOptions{}).
+					Return(&posts.Post{ID: postID}, &posts.Comment{ID: commentID, Body: data.Comment}, nil)
 			},
 			mockRecorder: false,
 		},
@@ -710,6 +986,11 @@ func TestCreateComment(t *testing.T) {
 			}
 		})
 	}
+
+	assert.Len(t, published, 1)
+	assert.Equal(t, events.CommentCreated, published[0].Type)
+	assert.Equal(t, commentID, published[0].CommentID)
+	assert.Equal(t, expectedUser, published[0].Actor)
 }
 
 func TestDeleteComment(t *testing.T) {
@@ -756,10 +1037,10 @@ func TestDeleteComment(t *testing.T) {
 			})), map[string]string{"id": postID, "commentID": commentID}),
 			mockRecorder: false,
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 			postExpect: func() {
-				postsRepo.EXPECT().DeleteComment(postID, commentID).Return(nil, errors.New("some error"))
+				postsRepo.EXPECT().DeleteComment(gomock.Any(), postID, commentID, expectedUser.ID).Return(nil, errors.New("some error"))
 			},
 		},
 		{
@@ -770,10 +1051,10 @@ func TestDeleteComment(t *testing.T) {
 			})), map[string]string{"id": postID, "commentID": commentID}),
 			mockRecorder: false,
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 			postExpect: func() {
-				postsRepo.EXPECT().DeleteComment(postID, commentID).Return(&posts.Post{}, nil)
+				postsRepo.EXPECT().DeleteComment(gomock.Any(), postID, commentID, expectedUser.ID).Return(&posts.Post{}, nil)
 			},
 		},
 	}
@@ -878,8 +1159,8 @@ func TestUpvoteUnvotePost(t *testing.T) {
 		})
 		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
 		req = mux.SetURLVars(req, map[string]string{"id": postID})
-		userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
-		postsRepo.EXPECT().UpvotePost(postID, expectedUser.ID).Return(nil, errors.New("some error"))
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+		postsRepo.EXPECT().UpvotePost(gomock.Any(), postID, expectedUser.ID).Return(nil, 0, errors.New("some error"))
 		service.UpvotePost(recorder, req)
 		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 	})
@@ -892,8 +1173,8 @@ func TestUpvoteUnvotePost(t *testing.T) {
 		})
 		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
 		req = mux.SetURLVars(req, map[string]string{"id": postID})
-		userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
-		postsRepo.EXPECT().UnvotePost(postID, expectedUser.ID).Return(nil, errors.New("some error"))
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+		postsRepo.EXPECT().UnvotePost(gomock.Any(), postID, expectedUser.ID).Return(nil, 0, errors.New("some error"))
 		service.UnvotePost(recorder, req)
 		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 	})
@@ -906,8 +1187,8 @@ func TestUpvoteUnvotePost(t *testing.T) {
 		})
 		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
 		req = mux.SetURLVars(req, map[string]string{"id": postID})
-		userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
-		postsRepo.EXPECT().UpvotePost(postID, expectedUser.ID).Return(&posts.Post{}, nil)
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+		postsRepo.EXPECT().UpvotePost(gomock.Any(), postID, expectedUser.ID).Return(&posts.Post{}, 1, nil)
 		service.UpvotePost(recorder, req)
 		assert.Equal(t, http.StatusOK, recorder.Code)
 	})
@@ -920,8 +1201,8 @@ func TestUpvoteUnvotePost(t *testing.T) {
 		})
 		req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
 		req = mux.SetURLVars(req, map[string]string{"id": postID})
-		userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
-		postsRepo.EXPECT().UnvotePost(postID, expectedUser.ID).Return(&posts.Post{}, nil)
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+		postsRepo.EXPECT().UnvotePost(gomock.Any(), postID, expectedUser.ID).Return(&posts.Post{}, 1, nil)
 		service.UnvotePost(recorder, req)
 		assert.Equal(t, http.StatusOK, recorder.Code)
 	})
@@ -965,10 +1246,10 @@ func TestDownvotePost(t *testing.T) {
 			})), map[string]string{"id": postID}),
 			mockRecorder: false,
 			postExpect: func() {
-				postsRepo.EXPECT().DownvotePost(postID, expectedUser.ID).Return(nil, errors.New("some error"))
+				postsRepo.EXPECT().DownvotePost(gomock.Any(), postID, expectedUser.ID).Return(nil, 0, errors.New("some error"))
 			},
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 		},
 		{
@@ -979,10 +1260,10 @@ func TestDownvotePost(t *testing.T) {
 			})), map[string]string{"id": postID}),
 			mockRecorder: false,
 			postExpect: func() {
-				postsRepo.EXPECT().DownvotePost(postID, expectedUser.ID).Return(&posts.Post{}, nil)
+				postsRepo.EXPECT().DownvotePost(gomock.Any(), postID, expectedUser.ID).Return(&posts.Post{}, 1, nil)
 			},
 			userExpect: func() {
-				userRepo.EXPECT().GetUserByUsername(expectedUser.Username).Return(expectedUser, nil)
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
 			},
 		},
 	}
@@ -1014,6 +1295,106 @@ func TestDownvotePost(t *testing.T) {
 	}
 }
 
+func TestVoteComment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	sessionManager := mock.NewMockSessionManager(ctrl)
+
+	service := newMockService(postsRepo, userRepo, sessionManager)
+	bus := events.NewBus()
+	var published []*events.Event
+	bus.Subscribe(events.ListenerFunc(func(_ context.Context, event *events.Event) {
+		published = append(published, event)
+	}))
+	service.Events = bus
+
+	sessCtx := context.WithValue(context.Background(), "session", &session.Session{
+		Username: expectedUser.Username,
+	})
+	vars := map[string]string{"id": postID, "commentID": commentID}
+	comment := &posts.Comment{ID: commentID, Author: expectedUser}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		handler    http.HandlerFunc
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "no post id",
+			statusCode: http.StatusBadRequest,
+			handler:    service.UpvoteComment,
+			req:        httptest.NewRequest(http.MethodGet, "/", nil),
+		},
+		{
+			name:       "no comment id",
+			statusCode: http.StatusBadRequest,
+			handler:    service.UpvoteComment,
+			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"id": postID}),
+		},
+		{
+			name:       "unauthorized",
+			statusCode: http.StatusUnauthorized,
+			handler:    service.UpvoteComment,
+			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), vars),
+		},
+		{
+			name:       "no comment",
+			statusCode: http.StatusNotFound,
+			handler:    service.DownvoteComment,
+			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(sessCtx), vars),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().VoteComment(gomock.Any(), postID, commentID, expectedUser.ID, voteDown).Return(nil, 0, myerrors.ErrNoComment)
+			},
+		},
+		{
+			name:       "upvote",
+			statusCode: http.StatusOK,
+			handler:    service.UpvoteComment,
+			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(sessCtx), vars),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().VoteComment(gomock.Any(), postID, commentID, expectedUser.ID, voteUp).
+					Return(&posts.Post{ID: postID, Comments: []*posts.Comment{comment}}, 1, nil)
+			},
+		},
+		{
+			name:       "unvote",
+			statusCode: http.StatusOK,
+			handler:    service.UnvoteComment,
+			req:        mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil).WithContext(sessCtx), vars),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().VoteComment(gomock.Any(), postID, commentID, expectedUser.ID, voteNone).
+					Return(&posts.Post{ID: postID, Comments: []*posts.Comment{comment}}, -1, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			c.handler(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+
+	assert.Len(t, published, 2)
+	assert.Equal(t, events.ScoreChanged, published[0].Type)
+	assert.Equal(t, commentID, published[0].CommentID)
+	assert.Equal(t, comment, published[0].Comment)
+	assert.Equal(t, 1, published[0].ScoreDelta)
+	assert.Equal(t, -1, published[1].ScoreDelta)
+}
+
 func TestPostsByUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -1044,7 +1425,7 @@ func TestPostsByUser(t *testing.T) {
 			req:          mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"username": username}),
 			mockRecorder: false,
 			postExpect: func() {
-				postsRepo.EXPECT().GetPostsByUser(expectedUser.Username).Return(nil, errors.New("some error"))
+				postsRepo.EXPECT().GetPostsByUser(gomock.Any(), expectedUser.Username, nil).Return(nil, errors.New("some error"))
 
 			},
 		},
@@ -1054,7 +1435,7 @@ func TestPostsByUser(t *testing.T) {
 			req:          mux.SetURLVars(httptest.NewRequest(http.MethodGet, "/", nil), map[string]string{"username": username}),
 			mockRecorder: false,
 			postExpect: func() {
-				postsRepo.EXPECT().GetPostsByUser(expectedUser.Username).Return([]*posts.Post{}, nil)
+				postsRepo.EXPECT().GetPostsByUser(gomock.Any(), expectedUser.Username, nil).Return([]*posts.Post{}, nil)
 			},
 		},
 	}
@@ -1085,3 +1466,380 @@ func TestPostsByUser(t *testing.T) {
 		})
 	}
 }
+
+func TestGetListParams(t *testing.T) {
+	cases := []struct {
+		name        string
+		query       string
+		expected    *posts.ListOptions
+		expectedErr error
+	}{
+		{
+			name:     "empty",
+			query:    "",
+			expected: &posts.ListOptions{},
+		},
+		{
+			name:     "all params",
+			query:    "?sort=top&limit=10&offset=20",
+			expected: &posts.ListOptions{Sort: posts.SortTop, Limit: 10, Offset: 20},
+		},
+		{
+			name:        "bad sort",
+			query:       "?sort=random",
+			expectedErr: myerrors.ErrBadSort,
+		},
+		{
+			name:        "bad limit",
+			query:       "?limit=abc",
+			expectedErr: myerrors.ErrBadPagination,
+		},
+		{
+			name:        "negative offset",
+			query:       "?offset=-1",
+			expectedErr: myerrors.ErrBadPagination,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			params, err := getListParams(httptest.NewRequest(http.MethodGet, "/"+c.query, nil))
+			assert.Equal(t, c.expectedErr, err)
+			assert.Equal(t, c.expected, params)
+		})
+	}
+}
+
+func TestFeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	sessionManager := mock.NewMockSessionManager(ctrl)
+	subscriptionRepo := repositorySubscriptions.NewMockSubscriptionRepo(ctrl)
+	savedRepo := repositorySaved.NewMockSavedRepo(ctrl)
+
+	service := newMockService(postsRepo, userRepo, sessionManager)
+	service.SubscriptionRepo = subscriptionRepo
+	service.SavedRepo = savedRepo
+	service.DefaultFeed = []string{"music", "news"}
+
+	withSession := func(target string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, target, nil).WithContext(context.WithValue(context.Background(), "session", &session.Session{
+			Username: expectedUser.Username,
+		}))
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		postExpect func()
+		userExpect func()
+		req        *http.Request
+	}{
+		{
+			name:       "bad params",
+			statusCode: http.StatusBadRequest,
+			req:        httptest.NewRequest(http.MethodGet, "/?sort=random", nil),
+		},
+		{
+			name:       "anonymous gets default categories",
+			statusCode: http.StatusOK,
+			req:        httptest.NewRequest(http.MethodGet, "/", nil),
+			postExpect: func() {
+				postsRepo.EXPECT().GetPostsByCategories(gomock.Any(), []string{"music", "news"}, gomock.Nil(), gomock.Any()).Return([]*posts.Post{}, nil)
+			},
+		},
+		{
+			name:       "user lookup error",
+			statusCode: http.StatusInternalServerError,
+			req:        withSession("/"),
+			userExpect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "subscriptions error",
+			statusCode: http.StatusInternalServerError,
+			req:        withSession("/"),
+			userExpect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "no subscriptions falls back to default",
+			statusCode: http.StatusOK,
+			req:        withSession("/"),
+			userExpect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return([]string{}, nil)
+			},
+			postExpect: func() {
+				postsRepo.EXPECT().GetPostsByCategories(gomock.Any(), []string{"music", "news"}, gomock.Nil(), gomock.Any()).Return([]*posts.Post{}, nil)
+			},
+		},
+		{
+			name:       "get posts error",
+			statusCode: http.StatusInternalServerError,
+			req:        withSession("/"),
+			userExpect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return([]string{category}, nil)
+			},
+			postExpect: func() {
+				postsRepo.EXPECT().GetPostsByCategories(gomock.Any(), []string{category}, gomock.Nil(), gomock.Any()).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        withSession("/?sort=new&limit=5"),
+			userExpect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return([]string{category}, nil)
+			},
+			postExpect: func() {
+				postsRepo.EXPECT().GetPostsByCategories(gomock.Any(), []string{category}, gomock.Nil(), gomock.Any()).Return([]*posts.Post{{ID: postID}}, nil)
+				savedRepo.EXPECT().GetSavedPostIDs(gomock.Any(), expectedUser.ID, []string{postID}).Return(map[string]bool{postID: true}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.userExpect != nil {
+				c.userExpect()
+			}
+			if c.postExpect != nil {
+				c.postExpect()
+			}
+			service.Feed(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestMarkSaved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	savedRepo := repositorySaved.NewMockSavedRepo(ctrl)
+	service := &PostsHandler{SavedRepo: savedRepo}
+
+	first := &posts.Post{ID: "1"}
+	second := &posts.Post{ID: "2"}
+
+	assert.NoError(t, service.markSaved(context.Background(), nil, first, second))
+	assert.False(t, first.Saved)
+
+	savedRepo.EXPECT().GetSavedPostIDs(gomock.Any(), expectedUser.ID, []string{"1", "2"}).Return(nil, errors.New("some error"))
+	assert.Error(t, service.markSaved(context.Background(), expectedUser, first, second))
+
+	savedRepo.EXPECT().GetSavedPostIDs(gomock.Any(), expectedUser.ID, []string{"1", "2"}).Return(map[string]bool{"2": true}, nil)
+	assert.NoError(t, service.markSaved(context.Background(), expectedUser, first, second))
+	assert.False(t, first.Saved)
+	assert.True(t, second.Saved)
+}
+
+func TestSaveUnsave(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	sessionManager := mock.NewMockSessionManager(ctrl)
+	savedRepo := repositorySaved.NewMockSavedRepo(ctrl)
+
+	service := newMockService(postsRepo, userRepo, sessionManager)
+	service.SavedRepo = savedRepo
+
+	withSession := func(vars map[string]string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/", nil).WithContext(context.WithValue(context.Background(), "session", &session.Session{
+			Username: expectedUser.Username,
+		}))
+		return mux.SetURLVars(req, vars)
+	}
+	postVars := map[string]string{"id": postID}
+	commentVars := map[string]string{"id": postID, "commentID": commentID}
+	post := &posts.Post{ID: postID, Comments: []*posts.Comment{{ID: commentID}}}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+		handler    http.HandlerFunc
+	}{
+		{
+			name:       "get field from url error",
+			statusCode: http.StatusBadRequest,
+			req:        httptest.NewRequest(http.MethodPost, "/", nil),
+			handler:    service.SavePost,
+		},
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/", nil), postVars),
+			handler:    service.SavePost,
+		},
+		{
+			name:       "no post",
+			statusCode: http.StatusNotFound,
+			req:        withSession(postVars),
+			handler:    service.SavePost,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(nil, myerrors.ErrNoPost)
+			},
+		},
+		{
+			name:       "no comment",
+			statusCode: http.StatusNotFound,
+			req:        withSession(map[string]string{"id": postID, "commentID": "missing"}),
+			handler:    service.SaveComment,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(post, nil)
+			},
+		},
+		{
+			name:       "save error",
+			statusCode: http.StatusInternalServerError,
+			req:        withSession(postVars),
+			handler:    service.SavePost,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(post, nil)
+				savedRepo.EXPECT().Save(gomock.Any(), expectedUser.ID, postID, "").Return(errors.New("some error"))
+			},
+		},
+		{
+			name:       "save comment success",
+			statusCode: http.StatusOK,
+			req:        withSession(commentVars),
+			handler:    service.SaveComment,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(post, nil)
+				savedRepo.EXPECT().Save(gomock.Any(), expectedUser.ID, postID, commentID).Return(nil)
+			},
+		},
+		{
+			name:       "unsave no session",
+			statusCode: http.StatusUnauthorized,
+			req:        mux.SetURLVars(httptest.NewRequest(http.MethodPost, "/", nil), postVars),
+			handler:    service.UnsavePost,
+		},
+		{
+			name:       "unsave error",
+			statusCode: http.StatusInternalServerError,
+			req:        withSession(postVars),
+			handler:    service.UnsavePost,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				savedRepo.EXPECT().Unsave(gomock.Any(), expectedUser.ID, postID, "").Return(errors.New("some error"))
+			},
+		},
+		{
+			name:       "unsave comment success",
+			statusCode: http.StatusOK,
+			req:        withSession(commentVars),
+			handler:    service.UnsaveComment,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+				savedRepo.EXPECT().Unsave(gomock.Any(), expectedUser.ID, postID, commentID).Return(nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			c.handler(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestSaved(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	postsRepo := repositoryPosts.NewMockPostRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	sessionManager := mock.NewMockSessionManager(ctrl)
+	savedRepo := repositorySaved.NewMockSavedRepo(ctrl)
+
+	service := newMockService(postsRepo, userRepo, sessionManager)
+	service.SavedRepo = savedRepo
+
+	withSession := func(target string) *http.Request {
+		return httptest.NewRequest(http.MethodGet, target, nil).WithContext(context.WithValue(context.Background(), "session", &session.Session{
+			Username: expectedUser.Username,
+		}))
+	}
+
+	t.Run("bad params", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		service.Saved(recorder, httptest.NewRequest(http.MethodGet, "/?limit=-1", nil))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	})
+
+	t.Run("no session", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		service.Saved(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	})
+
+	t.Run("get saved error", func(t *testing.T) {
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+		savedRepo.EXPECT().GetSaved(gomock.Any(), expectedUser.ID, 10, 0).Return(nil, errors.New("some error"))
+
+		recorder := httptest.NewRecorder()
+		service.Saved(recorder, withSession("/?limit=10"))
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+
+	t.Run("get posts error", func(t *testing.T) {
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+		savedRepo.EXPECT().GetSaved(gomock.Any(), expectedUser.ID, 0, 0).Return([]*saved.Item{{PostID: postID}}, nil)
+		postsRepo.EXPECT().GetPostsByIDs(gomock.Any(), []string{postID}, nil).Return(nil, errors.New("some error"))
+
+		recorder := httptest.NewRecorder()
+		service.Saved(recorder, withSession("/"))
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+	})
+
+	t.Run("success with removed targets", func(t *testing.T) {
+		items := []*saved.Item{
+			{PostID: postID},
+			{PostID: postID, CommentID: commentID},
+			{PostID: postID, CommentID: "deleted"},
+			{PostID: "deleted"},
+		}
+		userRepo.EXPECT().GetUserByUsername(gomock.Any(), expectedUser.Username).Return(expectedUser, nil)
+		savedRepo.EXPECT().GetSaved(gomock.Any(), expectedUser.ID, 0, 0).Return(items, nil)
+		postsRepo.EXPECT().GetPostsByIDs(gomock.Any(), []string{postID, postID, postID, "deleted"}, nil).Return([]*posts.Post{
+			{ID: postID, Comments: []*posts.Comment{{ID: commentID}}},
+		}, nil)
+
+		recorder := httptest.NewRecorder()
+		service.Saved(recorder, withSession("/"))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+
+		var result []*saved.Item
+		assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&result))
+		assert.Len(t, result, 4)
+		assert.True(t, result[0].Post.Saved)
+		assert.False(t, result[0].Removed)
+		assert.Equal(t, commentID, result[1].Comment.ID)
+		assert.True(t, result[2].Removed)
+		assert.True(t, result[3].Removed)
+	})
+}
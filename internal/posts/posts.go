@@ -1,11 +1,64 @@
 package posts
 
 import (
+	"context"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/KonstantinGalanin/redditclone/internal/markdown"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
 	"github.com/KonstantinGalanin/redditclone/internal/user"
 )
 
+const (
+	DeletedByAuthor    = "author"
+	RemovedByModerator = "moderator"
+
+	DeletedPlaceholder = "[deleted]"
+	RemovedPlaceholder = "[removed]"
+	HeldPlaceholder    = "[awaiting review]"
+
+	TypeLink  = "link"
+	TypeText  = "text"
+	TypePoll  = "poll"
+	TypeImage = "image"
+
+	// MediaPath is where the blobs of image posts are served from.
+	MediaPath = "/media/"
+
+	// Unpublished posts carry a status. Publishing removes it, so posts
+	// created before drafts existed count as published too.
+	StatusDraft     = "draft"
+	StatusScheduled = "scheduled"
+	// StatusHeld is set by the content filter. Only a moderator can publish
+	// a held post.
+	StatusHeld = "held"
+
+	MinPollOptions = 2
+	MaxPollOptions = 10
+
+	// DefaultPinLimit is how many posts a category keeps pinned at most.
+	DefaultPinLimit = 2
+
+	SortNew = "new"
+	SortTop = "top"
+)
+
+// Tombstone marks deleted content. The document is kept so that permalinks
+// and comment threads survive; the retention job purges it later.
+type Tombstone struct {
+	By string    `json:"by" bson:"by"`
+	At time.Time `json:"at" bson:"at"`
+}
+
+func (t *Tombstone) placeholder() string {
+	if t.By == RemovedByModerator {
+		return RemovedPlaceholder
+	}
+	return DeletedPlaceholder
+}
+
 type Post struct {
 	Author           *user.User `json:"author" bson:"author"`
 	Category         string     `json:"category" bson:"category"`
@@ -18,15 +71,218 @@ type Post struct {
 	UpvotePercentage int        `json:"upvotePercentage" bson:"upvotePercentage"`
 	URL              string     `json:"url,omitempty" bson:"url,omitempty"`
 	Text             string     `json:"text,omitempty" bson:"text,omitempty"`
+	TextHTML         string     `json:"textHtml,omitempty" bson:"-"`
+	Poll             *Poll      `json:"poll,omitempty" bson:"poll,omitempty"`
+	Image            *Image     `json:"image,omitempty" bson:"image,omitempty"`
 	Views            int        `json:"views" bson:"views"`
 	Votes            []*Vote    `json:"votes" bson:"votes"`
+	Saved            bool       `json:"saved" bson:"-"`
+	Deleted          *Tombstone `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	Locked           bool       `json:"locked" bson:"locked,omitempty"`
+	Pinned           bool       `json:"pinned" bson:"pinned,omitempty"`
+	NSFW             bool       `json:"nsfw" bson:"nsfw,omitempty"`
+	Spoiler          bool       `json:"spoiler" bson:"spoiler,omitempty"`
+	Withheld         bool       `json:"withheld,omitempty" bson:"-"`
+	Status           string     `json:"status,omitempty" bson:"status,omitempty"`
+	PublishAt        *time.Time `json:"publishAt,omitempty" bson:"publishAt,omitempty"`
+}
+
+// IsPublished reports whether the post is visible to everyone. Drafts and
+// scheduled posts are only visible to their author.
+func (p *Post) IsPublished() bool {
+	return p.Status == ""
+}
+
+// VisibleTo reports whether the user may see the post. userID is empty for
+// anonymous callers.
+func (p *Post) VisibleTo(userID string) bool {
+	if p.IsPublished() {
+		return true
+	}
+	return userID != "" && p.Author != nil && p.Author.ID == userID
+}
+
+// Redact hides the body and author of tombstoned content before it is
+// returned to clients.
+func (p *Post) Redact() {
+	if p.Deleted != nil {
+		placeholder := p.Deleted.placeholder()
+		p.Author = &user.User{Username: placeholder}
+		p.Text = placeholder
+		p.URL = ""
+		p.Poll = nil
+		p.Image = nil
+	}
+	for _, comment := range p.Comments {
+		comment.Redact()
+	}
+}
+
+// Withhold hides the content of a flagged post until the viewer asks to
+// reveal it. The title and flags stay so clients can render a warning.
+func (p *Post) Withhold() {
+	p.Withheld = true
+	p.Text = ""
+	p.URL = ""
+	p.Poll = nil
+	p.Image = nil
+}
+
+// Render fills in the fields derived for clients: the HTML of the post text
+// and its comments and the media URLs. It runs after Redact so tombstones
+// render their placeholder.
+func (p *Post) Render() {
+	p.TextHTML = markdown.Render(p.Text)
+	if p.Image != nil {
+		p.Image.URL = MediaPath + p.Image.Key
+		p.Image.ThumbnailURL = MediaPath + p.Image.ThumbnailKey
+	}
+	for _, comment := range p.Comments {
+		comment.Render()
+	}
+}
+
+// Image references the stored blobs of an image post.
+type Image struct {
+	Key          string `json:"-" bson:"key"`
+	ThumbnailKey string `json:"-" bson:"thumbnailKey"`
+	ContentType  string `json:"contentType" bson:"contentType"`
+	Width        int    `json:"width" bson:"width"`
+	Height       int    `json:"height" bson:"height"`
+	Size         int64  `json:"size" bson:"size"`
+	URL          string `json:"url" bson:"-"`
+	ThumbnailURL string `json:"thumbnailUrl" bson:"-"`
+}
+
+// Poll is attached to posts of TypePoll. Votes are stored with the post and
+// only leave the server aggregated by Tally.
+type Poll struct {
+	Options     []*PollOption `json:"options" bson:"options"`
+	Closes      *time.Time    `json:"closes,omitempty" bson:"closes,omitempty"`
+	HideResults bool          `json:"hideResults" bson:"hideResults"`
+	Votes       []*PollVote   `json:"-" bson:"votes"`
+	Closed      bool          `json:"closed" bson:"-"`
+	Choice      string        `json:"choice,omitempty" bson:"-"`
+	Results     *PollResults  `json:"results,omitempty" bson:"-"`
+}
+
+type PollOption struct {
+	ID   string `json:"id" bson:"_id"`
+	Text string `json:"text" bson:"text"`
+}
+
+type PollVote struct {
+	UserID   string `json:"user" bson:"user"`
+	OptionID string `json:"option" bson:"option"`
+}
+
+// PollResults maps option IDs to their vote counts.
+type PollResults struct {
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts"`
+}
+
+// NewPoll validates the options and numbers them in order.
+func NewPoll(options []string, closes *time.Time, hideResults bool, now time.Time) (*Poll, error) {
+	if len(options) < MinPollOptions || len(options) > MaxPollOptions {
+		return nil, myerrors.ErrBadPoll
+	}
+	if closes != nil && !closes.After(now) {
+		return nil, myerrors.ErrBadPoll
+	}
+
+	poll := &Poll{
+		Options:     make([]*PollOption, 0, len(options)),
+		Closes:      closes,
+		HideResults: hideResults,
+		Votes:       []*PollVote{},
+	}
+	for i, option := range options {
+		option = strings.TrimSpace(option)
+		if option == "" {
+			return nil, myerrors.ErrBadPoll
+		}
+		poll.Options = append(poll.Options, &PollOption{ID: strconv.Itoa(i), Text: option})
+	}
+	return poll, nil
+}
+
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.Closes != nil && !now.Before(*p.Closes)
+}
+
+func (p *Poll) HasOption(optionID string) bool {
+	for _, option := range p.Options {
+		if option.ID == optionID {
+			return true
+		}
+	}
+	return false
+}
+
+// Tally fills in the viewer's choice and the results. When the creator hid
+// the results they stay hidden until the viewer votes or the poll closes.
+func (p *Poll) Tally(userID string, now time.Time) {
+	p.Closed = p.IsClosed(now)
+	p.Choice = ""
+	p.Results = nil
+
+	counts := make(map[string]int, len(p.Options))
+	for _, option := range p.Options {
+		counts[option.ID] = 0
+	}
+	for _, vote := range p.Votes {
+		counts[vote.OptionID]++
+		if userID != "" && vote.UserID == userID {
+			p.Choice = vote.OptionID
+		}
+	}
+
+	if p.HideResults && !p.Closed && p.Choice == "" {
+		return
+	}
+	p.Results = &PollResults{Total: len(p.Votes), Counts: counts}
 }
 
 type Comment struct {
-	Author  *user.User `json:"author" bson:"author"`
-	Body    string     `json:"body" bson:"body"`
-	Created time.Time  `json:"created" bson:"created"`
-	ID      string     `json:"id" bson:"_id"`
+	Author   *user.User `json:"author" bson:"author"`
+	Body     string     `json:"body" bson:"body"`
+	BodyHTML string     `json:"bodyHtml,omitempty" bson:"-"`
+	Created  time.Time  `json:"created" bson:"created"`
+	ID       string     `json:"id" bson:"_id"`
+	ParentID string     `json:"parent,omitempty" bson:"parent,omitempty"`
+	Score    int        `json:"score" bson:"score"`
+	Votes    []*Vote    `json:"votes,omitempty" bson:"votes,omitempty"`
+	Deleted  *Tombstone `json:"deleted,omitempty" bson:"deleted,omitempty"`
+	Held     bool       `json:"held,omitempty" bson:"held,omitempty"`
+}
+
+// UserComment is a comment listed on its author's profile together with the
+// post it belongs to.
+type UserComment struct {
+	PostID    string   `json:"postId" bson:"postId"`
+	PostTitle string   `json:"title" bson:"title"`
+	Category  string   `json:"category" bson:"category"`
+	Comment   *Comment `json:"comment" bson:"comment"`
+}
+
+// Redact hides the body of held comments, and the body and author of
+// tombstoned ones. Held comments are hidden from their author too; the held
+// flag tells clients why.
+func (c *Comment) Redact() {
+	if c.Deleted == nil {
+		if c.Held {
+			c.Body = HeldPlaceholder
+		}
+		return
+	}
+	placeholder := c.Deleted.placeholder()
+	c.Author = &user.User{Username: placeholder}
+	c.Body = placeholder
+}
+
+func (c *Comment) Render() {
+	c.BodyHTML = markdown.Render(c.Body)
 }
 
 type Vote struct {
@@ -34,17 +290,60 @@ type Vote struct {
 	Vote   int    `json:"vote" bson:"vote"`
 }
 
+// CommentOptions control how a new comment is stored. Held comments wait
+// for moderator approval. BlockedBy lists the users who blocked the author:
+// their posts and comments cannot be replied to. Hidden lists the users the
+// author blocked, whose content is left out of the returned post.
+type CommentOptions struct {
+	Held      bool
+	BlockedBy []string
+	Hidden    []string
+}
+
+// ListOptions sort and page a listing in the database. Without a sort the
+// listing keeps the order of the store, which is what the SPA expects.
+// PinnedFirst moves pinned posts ahead of the rest; only category listings
+// honour pins.
+type ListOptions struct {
+	Sort        string
+	Limit       int
+	Offset      int
+	PinnedFirst bool
+	HideNSFW    bool
+}
+
 //go:generate mockgen -source=posts.go -destination=repository/repo_mock.go -package=repository PostRepo
 type PostRepo interface {
-	GetAllPosts() ([]*Post, error)
-	CreatePost(category, title, typePost, url, text string, author *user.User) (*Post, error)
-	GetPost(postID string) (*Post, error)
-	GetPostsByCategory(category string) ([]*Post, error)
-	CreateComment(postID, text string, author *user.User) (*Post, error)
-	DeleteComment(postID string, commentID string) (*Post, error)
-	UpvotePost(postID, userID string) (*Post, error)
-	UnvotePost(postID, userID string) (*Post, error)
-	DownvotePost(postID, userID string) (*Post, error)
-	DeletePost(postID, userID string) error
-	GetPostsByUser(username string) ([]*Post, error)
+	// Listings and GetPostHiding leave out posts and comments by the
+	// authors in hidden, usually the users the viewer blocked. A nil opts
+	// lists everything in store order.
+	GetAllPosts(ctx context.Context, hidden []string, opts *ListOptions) ([]*Post, error)
+	CreatePost(ctx context.Context, post *Post, author *user.User) (*Post, error)
+	GetPost(ctx context.Context, postID string) (*Post, error)
+	GetPostHiding(ctx context.Context, postID string, hidden []string) (*Post, error)
+	GetPostsByIDs(ctx context.Context, postIDs []string, hidden []string) ([]*Post, error)
+	GetPostsByCategory(ctx context.Context, category string, hidden []string, opts *ListOptions) ([]*Post, error)
+	GetPostsByCategories(ctx context.Context, categories []string, hidden []string, opts *ListOptions) ([]*Post, error)
+	CreateComment(ctx context.Context, postID, parentID, text string, author *user.User, opts CommentOptions) (*Post, *Comment, error)
+	DeleteComment(ctx context.Context, postID, commentID, userID string) (*Post, error)
+	RemoveComment(ctx context.Context, postID, commentID string) (*Post, error)
+	UpvotePost(ctx context.Context, postID, userID string) (*Post, int, error)
+	UnvotePost(ctx context.Context, postID, userID string) (*Post, int, error)
+	DownvotePost(ctx context.Context, postID, userID string) (*Post, int, error)
+	VoteComment(ctx context.Context, postID, commentID, userID string, vote int) (*Post, int, error)
+	VotePoll(ctx context.Context, postID, userID, optionID string, now time.Time) (*Post, error)
+	DeletePost(ctx context.Context, postID, userID string) error
+	RemovePost(ctx context.Context, postID string) error
+	GetPostsByUser(ctx context.Context, username string, hidden []string) ([]*Post, error)
+	GetCommentsByUser(ctx context.Context, username string, hidden []string, limit, offset int) ([]*UserComment, error)
+	GetDrafts(ctx context.Context, userID string) ([]*Post, error)
+	UpdateDraft(ctx context.Context, postID, userID string, post *Post) (*Post, error)
+	SchedulePost(ctx context.Context, postID, userID string, publishAt *time.Time) (*Post, error)
+	PublishPost(ctx context.Context, postID, userID string, now time.Time) (*Post, error)
+	PublishDue(ctx context.Context, now time.Time) (*Post, error)
+	SetLocked(ctx context.Context, postID string, locked bool) (*Post, error)
+	SetPinned(ctx context.Context, postID string, pinned bool, limit int) (*Post, error)
+	SetContentFlags(ctx context.Context, postID string, nsfw, spoiler *bool) (*Post, error)
+	ApprovePost(ctx context.Context, postID string, now time.Time) (*Post, error)
+	ApproveComment(ctx context.Context, postID, commentID string) (*Post, error)
 }
@@ -0,0 +1,147 @@
+package posts
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+func TestNewPoll(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	cases := []struct {
+		name        string
+		options     []string
+		closes      *time.Time
+		expectError bool
+	}{
+		{
+			name:        "too few options",
+			options:     []string{"a"},
+			expectError: true,
+		},
+		{
+			name:        "too many options",
+			options:     []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"},
+			expectError: true,
+		},
+		{
+			name:        "empty option",
+			options:     []string{"a", " "},
+			expectError: true,
+		},
+		{
+			name:        "closes in the past",
+			options:     []string{"a", "b"},
+			closes:      &past,
+			expectError: true,
+		},
+		{
+			name:    "success",
+			options: []string{" a ", "b"},
+			closes:  &future,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			poll, err := NewPoll(c.options, c.closes, false, now)
+			if c.expectError {
+				assert.ErrorIs(t, err, myerrors.ErrBadPoll)
+				assert.Nil(t, poll)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, []*PollOption{{ID: "0", Text: "a"}, {ID: "1", Text: "b"}}, poll.Options)
+			assert.Equal(t, c.closes, poll.Closes)
+			assert.True(t, poll.HasOption("1"))
+			assert.False(t, poll.HasOption("2"))
+		})
+	}
+}
+
+func TestPollTally(t *testing.T) {
+	now := time.Now()
+	closes := now.Add(time.Hour)
+	newPoll := func(hide bool) *Poll {
+		return &Poll{
+			Options:     []*PollOption{{ID: "0", Text: "a"}, {ID: "1", Text: "b"}, {ID: "2", Text: "c"}},
+			Closes:      &closes,
+			HideResults: hide,
+			Votes: []*PollVote{
+				{UserID: "1", OptionID: "0"},
+				{UserID: "2", OptionID: "0"},
+				{UserID: "3", OptionID: "1"},
+			},
+		}
+	}
+	expected := &PollResults{Total: 3, Counts: map[string]int{"0": 2, "1": 1, "2": 0}}
+
+	t.Run("visible results", func(t *testing.T) {
+		poll := newPoll(false)
+		poll.Tally("", now)
+		assert.False(t, poll.Closed)
+		assert.Empty(t, poll.Choice)
+		assert.Equal(t, expected, poll.Results)
+	})
+
+	t.Run("hidden before voting", func(t *testing.T) {
+		poll := newPoll(true)
+		poll.Tally("4", now)
+		assert.Nil(t, poll.Results)
+	})
+
+	t.Run("shown after voting", func(t *testing.T) {
+		poll := newPoll(true)
+		poll.Tally("3", now)
+		assert.Equal(t, "1", poll.Choice)
+		assert.Equal(t, expected, poll.Results)
+	})
+
+	t.Run("shown after closing", func(t *testing.T) {
+		poll := newPoll(true)
+		poll.Tally("4", closes)
+		assert.True(t, poll.Closed)
+		assert.Equal(t, expected, poll.Results)
+	})
+}
+
+func TestRedactHidesPoll(t *testing.T) {
+	post := &Post{
+		Poll:    &Poll{Options: []*PollOption{{ID: "0", Text: "a"}}},
+		Deleted: &Tombstone{By: DeletedByAuthor},
+	}
+	post.Redact()
+	assert.Nil(t, post.Poll)
+}
+
+func TestRedactHeldComment(t *testing.T) {
+	author := &user.User{ID: "1", Username: "author"}
+	comment := &Comment{Author: author, Body: "spam", Held: true}
+	comment.Redact()
+	assert.Equal(t, HeldPlaceholder, comment.Body)
+	assert.Equal(t, author, comment.Author)
+
+	comment = &Comment{Author: author, Body: "spam", Held: true, Deleted: &Tombstone{By: RemovedByModerator}}
+	comment.Redact()
+	assert.Equal(t, RemovedPlaceholder, comment.Body)
+}
+
+func TestVisibleTo(t *testing.T) {
+	post := &Post{Author: &user.User{ID: "1"}}
+	assert.True(t, post.IsPublished())
+	assert.True(t, post.VisibleTo(""))
+	assert.True(t, post.VisibleTo("2"))
+
+	post.Status = StatusDraft
+	assert.False(t, post.IsPublished())
+	assert.False(t, post.VisibleTo(""))
+	assert.False(t, post.VisibleTo("2"))
+	assert.True(t, post.VisibleTo("1"))
+}
@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/KonstantinGalanin/redditclone/internal/metrics"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/tracing"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	metricsName = "posts_mongodb"
+	dbSystem    = "mongodb"
+)
+
+// InstrumentedPostMongoDB records the latency and errors of every operation
+// of the wrapped repository and traces it in a child span of the caller.
+type InstrumentedPostMongoDB struct {
+	*PostMongoDB
+}
+
+func NewInstrumentedPostMongoDB(repo *PostMongoDB) *InstrumentedPostMongoDB {
+	return &InstrumentedPostMongoDB{PostMongoDB: repo}
+}
+
+// instrument starts the span of an operation. done records its latency and
+// error and ends the span.
+func instrument(ctx context.Context, operation string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracing.StartClient(ctx, dbSystem, operation)
+	return ctx, func(err error) {
+		metrics.ObserveRepo(metricsName, operation, start, err)
+		tracing.End(span, err)
+	}
+}
+
+func (m *InstrumentedPostMongoDB) GetAllPosts(ctx context.Context, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
+	ctx, done := instrument(ctx, "GetAllPosts")
+	items, err := m.PostMongoDB.GetAllPosts(ctx, hidden, opts)
+	done(err)
+	return items, err
+}
+
+func (m *InstrumentedPostMongoDB) CreatePost(ctx context.Context, post *posts.Post, author *user.User) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "CreatePost")
+	created, err := m.PostMongoDB.CreatePost(ctx, post, author)
+	done(err)
+	return created, err
+}
+
+func (m *InstrumentedPostMongoDB) GetPost(ctx context.Context, postID string) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "GetPost")
+	post, err := m.PostMongoDB.GetPost(ctx, postID)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) GetPostHiding(ctx context.Context, postID string, hidden []string) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "GetPostHiding")
+	post, err := m.PostMongoDB.GetPostHiding(ctx, postID, hidden)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) GetPostsByIDs(ctx context.Context, postIDs []string, hidden []string) ([]*posts.Post, error) {
+	ctx, done := instrument(ctx, "GetPostsByIDs")
+	items, err := m.PostMongoDB.GetPostsByIDs(ctx, postIDs, hidden)
+	done(err)
+	return items, err
+}
+
+func (m *InstrumentedPostMongoDB) DeletePost(ctx context.Context, postID, userID string) error {
+	ctx, done := instrument(ctx, "DeletePost")
+	err := m.PostMongoDB.DeletePost(ctx, postID, userID)
+	done(err)
+	return err
+}
+
+func (m *InstrumentedPostMongoDB) RemovePost(ctx context.Context, postID string) error {
+	ctx, done := instrument(ctx, "RemovePost")
+	err := m.PostMongoDB.RemovePost(ctx, postID)
+	done(err)
+	return err
+}
+
+func (m *InstrumentedPostMongoDB) GetPostsByCategory(ctx context.Context, category string, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
+	ctx, done := instrument(ctx, "GetPostsByCategory")
+	items, err := m.PostMongoDB.GetPostsByCategory(ctx, category, hidden, opts)
+	done(err)
+	return items, err
+}
+
+func (m *InstrumentedPostMongoDB) GetPostsByCategories(ctx context.Context, categories []string, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
+	ctx, done := instrument(ctx, "GetPostsByCategories")
+	items, err := m.PostMongoDB.GetPostsByCategories(ctx, categories, hidden, opts)
+	done(err)
+	return items, err
+}
+
+func (m *InstrumentedPostMongoDB) CreateComment(ctx context.Context, postID, parentID, text string, author *user.User, opts posts.CommentOptions) (*posts.Post, *posts.Comment, error) {
+	ctx, done := instrument(ctx, "CreateComment")
+	post, comment, err := m.PostMongoDB.CreateComment(ctx, postID, parentID, text, author, opts)
+	done(err)
+	return post, comment, err
+}
+
+func (m *InstrumentedPostMongoDB) DeleteComment(ctx context.Context, postID, commentID, userID string) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "DeleteComment")
+	post, err := m.PostMongoDB.DeleteComment(ctx, postID, commentID, userID)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) RemoveComment(ctx context.Context, postID, commentID string) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "RemoveComment")
+	post, err := m.PostMongoDB.RemoveComment(ctx, postID, commentID)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, done := instrument(ctx, "PurgeDeleted")
+	purged, err := m.PostMongoDB.PurgeDeleted(ctx, before)
+	done(err)
+	return purged, err
+}
+
+func (m *InstrumentedPostMongoDB) UpvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
+	ctx, done := instrument(ctx, "UpvotePost")
+	post, delta, err := m.PostMongoDB.UpvotePost(ctx, postID, userID)
+	done(err)
+	return post, delta, err
+}
+
+func (m *InstrumentedPostMongoDB) UnvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
+	ctx, done := instrument(ctx, "UnvotePost")
+	post, delta, err := m.PostMongoDB.UnvotePost(ctx, postID, userID)
+	done(err)
+	return post, delta, err
+}
+
+func (m *InstrumentedPostMongoDB) DownvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
+	ctx, done := instrument(ctx, "DownvotePost")
+	post, delta, err := m.PostMongoDB.DownvotePost(ctx, postID, userID)
+	done(err)
+	return post, delta, err
+}
+
+func (m *InstrumentedPostMongoDB) VoteComment(ctx context.Context, postID, commentID, userID string, vote int) (*posts.Post, int, error) {
+	ctx, done := instrument(ctx, "VoteComment")
+	post, delta, err := m.PostMongoDB.VoteComment(ctx, postID, commentID, userID, vote)
+	done(err)
+	return post, delta, err
+}
+
+func (m *InstrumentedPostMongoDB) VotePoll(ctx context.Context, postID, userID, optionID string, now time.Time) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "VotePoll")
+	post, err := m.PostMongoDB.VotePoll(ctx, postID, userID, optionID, now)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) GetPostsByUser(ctx context.Context, username string, hidden []string) ([]*posts.Post, error) {
+	ctx, done := instrument(ctx, "GetPostsByUser")
+	items, err := m.PostMongoDB.GetPostsByUser(ctx, username, hidden)
+	done(err)
+	return items, err
+}
+
+func (m *InstrumentedPostMongoDB) GetCommentsByUser(ctx context.Context, username string, hidden []string, limit, offset int) ([]*posts.UserComment, error) {
+	ctx, done := instrument(ctx, "GetCommentsByUser")
+	comments, err := m.PostMongoDB.GetCommentsByUser(ctx, username, hidden, limit, offset)
+	done(err)
+	return comments, err
+}
+
+func (m *InstrumentedPostMongoDB) GetDrafts(ctx context.Context, userID string) ([]*posts.Post, error) {
+	ctx, done := instrument(ctx, "GetDrafts")
+	items, err := m.PostMongoDB.GetDrafts(ctx, userID)
+	done(err)
+	return items, err
+}
+
+func (m *InstrumentedPostMongoDB) UpdateDraft(ctx context.Context, postID, userID string, post *posts.Post) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "UpdateDraft")
+	updated, err := m.PostMongoDB.UpdateDraft(ctx, postID, userID, post)
+	done(err)
+	return updated, err
+}
+
+func (m *InstrumentedPostMongoDB) SchedulePost(ctx context.Context, postID, userID string, publishAt *time.Time) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "SchedulePost")
+	post, err := m.PostMongoDB.SchedulePost(ctx, postID, userID, publishAt)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) PublishPost(ctx context.Context, postID, userID string, now time.Time) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "PublishPost")
+	post, err := m.PostMongoDB.PublishPost(ctx, postID, userID, now)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) PublishDue(ctx context.Context, now time.Time) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "PublishDue")
+	post, err := m.PostMongoDB.PublishDue(ctx, now)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) SetLocked(ctx context.Context, postID string, locked bool) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "SetLocked")
+	post, err := m.PostMongoDB.SetLocked(ctx, postID, locked)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) SetPinned(ctx context.Context, postID string, pinned bool, limit int) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "SetPinned")
+	post, err := m.PostMongoDB.SetPinned(ctx, postID, pinned, limit)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) SetContentFlags(ctx context.Context, postID string, nsfw, spoiler *bool) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "SetContentFlags")
+	post, err := m.PostMongoDB.SetContentFlags(ctx, postID, nsfw, spoiler)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) ApprovePost(ctx context.Context, postID string, now time.Time) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "ApprovePost")
+	post, err := m.PostMongoDB.ApprovePost(ctx, postID, now)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) ApproveComment(ctx context.Context, postID, commentID string) (*posts.Post, error) {
+	ctx, done := instrument(ctx, "ApproveComment")
+	post, err := m.PostMongoDB.ApproveComment(ctx, postID, commentID)
+	done(err)
+	return post, err
+}
+
+func (m *InstrumentedPostMongoDB) LinkPosted(ctx context.Context, category, url string) (bool, error) {
+	ctx, done := instrument(ctx, "LinkPosted")
+	posted, err := m.PostMongoDB.LinkPosted(ctx, category, url)
+	done(err)
+	return posted, err
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
 	"github.com/KonstantinGalanin/redditclone/internal/posts"
@@ -27,6 +29,20 @@ const (
 	NoCredentialsToDelete = "No cretdentials to delete post: "
 )
 
+// notDeleted hides tombstoned posts from listings. Permalinks still resolve
+// them through GetPost.
+var notDeleted = bson.M{"$exists": false}
+
+// published matches posts without a draft status. Drafts and scheduled posts
+// are left out of listings and cannot be commented on or voted on.
+var published = bson.M{"$exists": false}
+
+// unpublished matches drafts, scheduled posts and posts held for review.
+var unpublished = bson.M{"$exists": true}
+
+// editable matches the unpublished posts their author may still change.
+var editable = bson.M{"$in": []string{posts.StatusDraft, posts.StatusScheduled}}
+
 type PostMongoDB struct {
 	db *mongo.Collection
 }
@@ -37,40 +53,120 @@ func NewPostMongoDB(db *mongo.Collection) *PostMongoDB {
 	}
 }
 
-func (p *PostMongoDB) withTimeout() (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), TimeoutVal*time.Second)
+func (p *PostMongoDB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, TimeoutVal*time.Second)
 }
 
-func (p *PostMongoDB) GetAllPosts() ([]*posts.Post, error) {
-	posts := []*posts.Post{}
-	ctx, cancel := p.withTimeout()
-	defer cancel()
-	c, err := p.db.Find(ctx, bson.M{})
+func (p *PostMongoDB) GetAllPosts(ctx context.Context, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
+	filter := bson.M{"deleted": notDeleted, "status": published}
+	posts, err := p.findPosts(ctx, filter, hidden, opts)
 	if err != nil {
 		return nil, fmt.Errorf("mongodb get all posts: %w", err)
 	}
-	err = c.All(ctx, &posts)
+	return posts, nil
+}
+
+// findPosts runs a listing query. Without hidden authors it is a plain find;
+// otherwise their posts are excluded by the match and their comments are
+// dropped server side, so the handlers never see them. Sorting and paging
+// happen in the database either way.
+func (p *PostMongoDB) findPosts(ctx context.Context, filter bson.M, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
+	if opts == nil {
+		opts = &posts.ListOptions{}
+	}
+	if opts.HideNSFW {
+		filter["nsfw"] = bson.M{"$ne": true}
+	}
+	sort := listSort(opts)
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	var c *mongo.Cursor
+	var err error
+	if len(hidden) == 0 {
+		find := options.Find()
+		if len(sort) != 0 {
+			find.SetSort(sort)
+		}
+		if opts.Offset > 0 {
+			find.SetSkip(int64(opts.Offset))
+		}
+		if opts.Limit > 0 {
+			find.SetLimit(int64(opts.Limit))
+		}
+		c, err = p.db.Find(ctx, filter, find)
+	} else {
+		c, err = p.db.Aggregate(ctx, hidingPipeline(filter, hidden, sort, opts))
+	}
 	if err != nil {
-		return nil, fmt.Errorf("mongodb get all posts: %w", err)
+		return nil, err
 	}
+	found := []*posts.Post{}
+	if err = c.All(ctx, &found); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
 
-	return posts, nil
+// listSort is the sort document of a listing. Ties on score go to the newer
+// post; pinned posts come first when asked.
+func listSort(opts *posts.ListOptions) bson.D {
+	var sort bson.D
+	if opts.PinnedFirst {
+		sort = append(sort, bson.E{Key: "pinned", Value: -1})
+	}
+	switch opts.Sort {
+	case posts.SortNew:
+		sort = append(sort, bson.E{Key: "created", Value: -1})
+	case posts.SortTop:
+		sort = append(sort, bson.E{Key: "score", Value: -1}, bson.E{Key: "created", Value: -1})
+	}
+	return sort
+}
+
+// hidingPipeline matches filter, skipping posts by hidden authors, sorts and
+// pages the matches and removes the comments of hidden authors from them.
+func hidingPipeline(filter bson.M, hidden []string, sort bson.D, opts *posts.ListOptions) mongo.Pipeline {
+	match := bson.M{"author._id": bson.M{"$nin": hidden}}
+	for key, value := range filter {
+		match[key] = value
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: match}}}
+	if len(sort) != 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: sort}})
+	}
+	if opts.Offset > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$skip", Value: int64(opts.Offset)}})
+	}
+	if opts.Limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: int64(opts.Limit)}})
+	}
+	return append(pipeline, bson.D{{Key: "$set", Value: bson.M{"comments": bson.M{"$filter": bson.M{
+		"input": "$comments",
+		"cond":  bson.M{"$not": bson.A{bson.M{"$in": bson.A{"$$this.author._id", hidden}}}},
+	}}}}})
 }
 
-func (p *PostMongoDB) CreatePost(category, title, typePost, url, text string, author *user.User) (*posts.Post, error) {
+// CreatePost stores the content of post as a new post by author. Everything
+// else, such as the ID and the initial vote, is set here.
+func (p *PostMongoDB) CreatePost(ctx context.Context, post *posts.Post, author *user.User) (*posts.Post, error) {
 	newPost := &posts.Post{
 		Author:           author,
-		Category:         category,
+		Category:         post.Category,
 		Comments:         []*posts.Comment{},
 		Created:          time.Now(),
 		ID:               uuid.New().String(),
 		Score:            InitialScore,
-		Title:            title,
-		Type:             typePost,
-		Text:             text,
+		Title:            post.Title,
+		Type:             post.Type,
+		Text:             post.Text,
 		UpvotePercentage: FullPercent,
-		URL:              url,
+		URL:              post.URL,
+		Poll:             post.Poll,
+		Image:            post.Image,
 		Views:            ZeroViews,
+		Status:           post.Status,
+		PublishAt:        post.PublishAt,
 		Votes: []*posts.Vote{
 			{
 				UserID: author.ID,
@@ -79,7 +175,7 @@ func (p *PostMongoDB) CreatePost(category, title, typePost, url, text string, au
 		},
 	}
 
-	ctx, cancel := p.withTimeout()
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 	if _, err := p.db.InsertOne(ctx, newPost); err != nil {
 		return nil, fmt.Errorf("mongodb create post: %w", err)
@@ -87,10 +183,10 @@ func (p *PostMongoDB) CreatePost(category, title, typePost, url, text string, au
 	return newPost, nil
 }
 
-func (p *PostMongoDB) GetPost(postID string) (*posts.Post, error) {
+func (p *PostMongoDB) GetPost(ctx context.Context, postID string) (*posts.Post, error) {
 	var post *posts.Post
 	filter := bson.M{"_id": postID}
-	ctx, cancel := p.withTimeout()
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 	err := p.db.FindOne(ctx, filter).Decode(&post)
 	if err != nil {
@@ -102,86 +198,247 @@ func (p *PostMongoDB) GetPost(postID string) (*posts.Post, error) {
 	return post, nil
 }
 
-func (p *PostMongoDB) DeletePost(postID, userID string) error {
-	post, err := p.GetPost(postID)
+// GetPostHiding is GetPost for a viewer who blocked the authors in hidden.
+// A post by a hidden author is reported as missing.
+func (p *PostMongoDB) GetPostHiding(ctx context.Context, postID string, hidden []string) (*posts.Post, error) {
+	if len(hidden) == 0 {
+		return p.GetPost(ctx, postID)
+	}
+	found, err := p.findPosts(ctx, bson.M{"_id": postID}, hidden, nil)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get post: %w", err)
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("mongodb get post: %w", myerrors.ErrNoPost)
+	}
+	return found[0], nil
+}
+
+func (p *PostMongoDB) GetPostsByIDs(ctx context.Context, postIDs []string, hidden []string) ([]*posts.Post, error) {
+	posts, err := p.findPosts(ctx, bson.M{"_id": bson.M{"$in": postIDs}}, hidden, nil)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get posts by ids: %w", err)
+	}
+	return posts, nil
+}
+
+func (p *PostMongoDB) DeletePost(ctx context.Context, postID, userID string) error {
+	post, err := p.GetPost(ctx, postID)
 	if err != nil {
 		return fmt.Errorf("mongodb delete post: %w", err)
 	}
+	if post.Deleted != nil {
+		return fmt.Errorf("mongodb delete post: %w", myerrors.ErrNoPost)
+	}
 
 	if post.Author.ID != userID {
 		return fmt.Errorf("mongodb delete post: %s %s", NoCredentialsToDelete, postID)
 	}
 
-	filter := bson.M{"_id": postID}
-	ctx, cancel := p.withTimeout()
-	defer cancel()
-	if res, err := p.db.DeleteOne(ctx, filter); err != nil || res.DeletedCount == 0 {
+	if err = p.tombstonePost(ctx, postID, posts.DeletedByAuthor); err != nil {
 		return fmt.Errorf("mongodb delete post: %w", err)
 	}
+	return nil
+}
 
+// RemovePost tombstones a post regardless of its author. It is used for
+// moderator removals.
+func (p *PostMongoDB) RemovePost(ctx context.Context, postID string) error {
+	if err := p.tombstonePost(ctx, postID, posts.RemovedByModerator); err != nil {
+		return fmt.Errorf("mongodb remove post: %w", err)
+	}
 	return nil
 }
 
-func (p *PostMongoDB) GetPostsByCategory(category string) ([]*posts.Post, error) {
-	var posts []*posts.Post
-	filter := bson.M{"category": category}
-	ctx, cancel := p.withTimeout()
+func (p *PostMongoDB) tombstonePost(ctx context.Context, postID, by string) error {
+	filter := bson.M{"_id": postID, "deleted": notDeleted}
+	update := bson.M{
+		"$set": bson.M{
+			"deleted": &posts.Tombstone{By: by, At: time.Now()},
+		},
+	}
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
-	c, err := p.db.Find(ctx, filter)
+	res, err := p.db.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return nil, fmt.Errorf("mogngodb get posts by category: %w", err)
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return myerrors.ErrNoPost
 	}
-	err = c.All(ctx, &posts)
+	return nil
+}
+
+func (p *PostMongoDB) GetPostsByCategory(ctx context.Context, category string, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
+	filter := bson.M{"category": category, "deleted": notDeleted, "status": published}
+	posts, err := p.findPosts(ctx, filter, hidden, opts)
 	if err != nil {
 		return nil, fmt.Errorf("mogngodb get posts by category: %w", err)
 	}
+	return posts, nil
+}
 
+func (p *PostMongoDB) GetPostsByCategories(ctx context.Context, categories []string, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
+	filter := bson.M{"category": bson.M{"$in": categories}, "deleted": notDeleted, "status": published}
+	posts, err := p.findPosts(ctx, filter, hidden, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get posts by categories: %w", err)
+	}
 	return posts, nil
 }
 
-func (p *PostMongoDB) CreateComment(postID, text string, author *user.User) (*posts.Post, error) {
+// CreateComment adds a comment to a published post. Held comments are
+// stored but hidden until a moderator approves them. Posts and parent
+// comments by users in opts.BlockedBy do not match.
+func (p *PostMongoDB) CreateComment(ctx context.Context, postID, parentID, text string, author *user.User, opts posts.CommentOptions) (*posts.Post, *posts.Comment, error) {
 	comment := &posts.Comment{
-		Author:  author,
-		Body:    text,
-		Created: time.Now(),
-		ID:      uuid.New().String(),
+		Author:   author,
+		Body:     text,
+		Created:  time.Now(),
+		ID:       uuid.New().String(),
+		ParentID: parentID,
+		Held:     opts.Held,
 	}
 
-	filterPost := bson.M{"_id": postID}
+	filterPost := bson.M{"_id": postID, "deleted": notDeleted, "status": published, "locked": bson.M{"$ne": true}}
+	if len(opts.BlockedBy) != 0 {
+		filterPost["author._id"] = bson.M{"$nin": opts.BlockedBy}
+	}
+	switch {
+	case parentID != "" && len(opts.BlockedBy) != 0:
+		filterPost["comments"] = bson.M{"$elemMatch": bson.M{"_id": parentID, "author._id": bson.M{"$nin": opts.BlockedBy}}}
+	case parentID != "":
+		filterPost["comments._id"] = parentID
+	}
 	update := bson.M{
 		"$push": bson.M{"comments": comment},
 	}
 
-	ctx, cancel := p.withTimeout()
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
-	if _, err := p.db.UpdateOne(ctx, filterPost, update); err != nil {
-		return nil, fmt.Errorf("mogngodb create comment: %w", err)
+	res, err := p.db.UpdateOne(ctx, filterPost, update)
+	if err != nil {
+		return nil, nil, fmt.Errorf("mogngodb create comment: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		return nil, nil, fmt.Errorf("mogngodb create comment: %w", p.commentError(ctx, postID, parentID, opts.BlockedBy))
 	}
 
-	post, err := p.GetPost(postID)
+	post, err := p.GetPostHiding(ctx, postID, opts.Hidden)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	return post, comment, nil
+}
+
+// commentError explains why a new comment matched no post.
+func (p *PostMongoDB) commentError(ctx context.Context, postID, parentID string, blockedBy []string) error {
+	post, err := p.GetPost(ctx, postID)
+	if err != nil {
+		return err
+	}
+	switch {
+	case post.Deleted != nil || !post.IsPublished():
+		return myerrors.ErrNoPost
+	case post.Locked:
+		return myerrors.ErrPostLocked
+	case post.Author != nil && slices.Contains(blockedBy, post.Author.ID):
+		return myerrors.ErrBlocked
+	case parentID != "":
+		for _, comment := range post.Comments {
+			if comment.ID == parentID && comment.Author != nil && slices.Contains(blockedBy, comment.Author.ID) {
+				return myerrors.ErrBlocked
+			}
+		}
+		return myerrors.ErrNoComment
+	}
+	// The post changed between both queries.
+	return myerrors.ErrNoPost
+}
+
+func (p *PostMongoDB) DeleteComment(ctx context.Context, postID, commentID, userID string) (*posts.Post, error) {
+	post, err := p.GetPost(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("mogngodb delete comment: %w", err)
+	}
+
+	var comment *posts.Comment
+	for _, item := range post.Comments {
+		if item.ID == commentID {
+			comment = item
+		}
+	}
+	if comment == nil || comment.Deleted != nil {
+		return nil, fmt.Errorf("mogngodb delete comment: %w", myerrors.ErrNoComment)
+	}
+	if comment.Author == nil || comment.Author.ID != userID {
+		return nil, fmt.Errorf("mogngodb delete comment: %s %s", NoCredentialsToDelete, commentID)
+	}
+
+	post, err = p.tombstoneComment(ctx, postID, commentID, posts.DeletedByAuthor)
+	if err != nil {
+		return nil, fmt.Errorf("mogngodb delete comment: %w", err)
 	}
 	return post, nil
 }
 
-func (p *PostMongoDB) DeleteComment(postID string, commentID string) (*posts.Post, error) {
-	filterPost := bson.M{"_id": postID}
+// RemoveComment tombstones a comment regardless of its author. It is used for
+// moderator removals.
+func (p *PostMongoDB) RemoveComment(ctx context.Context, postID, commentID string) (*posts.Post, error) {
+	post, err := p.tombstoneComment(ctx, postID, commentID, posts.RemovedByModerator)
+	if err != nil {
+		return nil, fmt.Errorf("mogngodb remove comment: %w", err)
+	}
+	return post, nil
+}
+
+// tombstoneComment keeps the comment in place so the rest of the thread
+// stays intact.
+func (p *PostMongoDB) tombstoneComment(ctx context.Context, postID, commentID, by string) (*posts.Post, error) {
+	filter := bson.M{
+		"_id":      postID,
+		"comments": bson.M{"$elemMatch": bson.M{"_id": commentID, "deleted": notDeleted}},
+	}
 	update := bson.M{
-		"$pull": bson.M{"comments": bson.M{"_id": commentID}},
+		"$set": bson.M{
+			"comments.$.deleted": &posts.Tombstone{By: by, At: time.Now()},
+		},
 	}
 
-	ctx, cancel := p.withTimeout()
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
-	if _, err := p.db.UpdateOne(ctx, filterPost, update); err != nil {
-		return nil, fmt.Errorf("mogngodb delete comment: %w", err)
+	res, err := p.db.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, err
 	}
+	if res.MatchedCount == 0 {
+		return nil, myerrors.ErrNoComment
+	}
+
+	return p.GetPost(ctx, postID)
+}
+
+// PurgeDeleted hard-deletes content that was tombstoned before the given
+// time. Purged comments are pulled out of their posts.
+func (p *PostMongoDB) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
 
-	post, err := p.GetPost(postID)
+	res, err := p.db.DeleteMany(ctx, bson.M{"deleted.at": bson.M{"$lt": before}})
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("mongodb purge deleted posts: %w", err)
 	}
-	return post, nil
+	purged := res.DeletedCount
+
+	filter := bson.M{"comments.deleted.at": bson.M{"$lt": before}}
+	update := bson.M{
+		"$pull": bson.M{"comments": bson.M{"deleted.at": bson.M{"$lt": before}}},
+	}
+	updated, err := p.db.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return purged, fmt.Errorf("mongodb purge deleted comments: %w", err)
+	}
+	return purged + updated.ModifiedCount, nil
 }
 
 func (p *PostMongoDB) getUpvotePercentage(post *posts.Post) int {
@@ -199,8 +456,8 @@ func (p *PostMongoDB) getUpvotePercentage(post *posts.Post) int {
 	return voteCount * FullPercent / len(post.Votes)
 }
 
-func (p *PostMongoDB) updateUpvotePercentage(postID string) error {
-	post, err := p.GetPost(postID)
+func (p *PostMongoDB) updateUpvotePercentage(ctx context.Context, postID string) error {
+	post, err := p.GetPost(ctx, postID)
 	if err != nil {
 		return fmt.Errorf("mogngodb update upvote percentage: %w", err)
 	}
@@ -212,7 +469,7 @@ func (p *PostMongoDB) updateUpvotePercentage(postID string) error {
 	}
 
 	filter := bson.M{"_id": postID}
-	ctx, cancel := p.withTimeout()
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 	if _, err = p.db.UpdateOne(ctx, filter, update); err != nil {
 		return fmt.Errorf("mogngodb update upvote percentage: %w", err)
@@ -228,8 +485,8 @@ func (p *PostMongoDB) getScore(post *posts.Post) int {
 	return score
 }
 
-func (p *PostMongoDB) updateScore(postID string) error {
-	post, err := p.GetPost(postID)
+func (p *PostMongoDB) updateScore(ctx context.Context, postID string) error {
+	post, err := p.GetPost(ctx, postID)
 	if err != nil {
 		return fmt.Errorf("mogngodb update score: %w", err)
 	}
@@ -241,7 +498,7 @@ func (p *PostMongoDB) updateScore(postID string) error {
 	}
 
 	filter := bson.M{"_id": postID}
-	ctx, cancel := p.withTimeout()
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 	if _, err = p.db.UpdateOne(ctx, filter, update); err != nil {
 		return fmt.Errorf("mogngodb update score: %w", err)
@@ -249,33 +506,40 @@ func (p *PostMongoDB) updateScore(postID string) error {
 	return nil
 }
 
-func (p *PostMongoDB) updateMetrics(postID string) error {
-	if err := p.updateScore(postID); err != nil {
+func (p *PostMongoDB) updateMetrics(ctx context.Context, postID string) error {
+	if err := p.updateScore(ctx, postID); err != nil {
 		return fmt.Errorf("mogngodb update metrics: %w", err)
 	}
-	if err := p.updateUpvotePercentage(postID); err != nil {
+	if err := p.updateUpvotePercentage(ctx, postID); err != nil {
 		return fmt.Errorf("mogngodb update metrics: %w", err)
 	}
 	return nil
 }
 
-func (p *PostMongoDB) vote(postID string, userID string, vote int) error {
-	filter := bson.M{"_id": postID, "votes.user": userID}
+// vote records the user's vote and returns how much it moved the score.
+// Votes are +1 or -1, so a modified vote was flipped and counts twice.
+// Tombstoned posts cannot be voted on.
+func (p *PostMongoDB) vote(ctx context.Context, postID string, userID string, vote int) (int, error) {
+	filter := bson.M{"_id": postID, "deleted": notDeleted, "status": published, "votes.user": userID}
 	update := bson.M{
 		"$set": bson.M{
 			"votes.$.vote": vote,
 		},
 	}
-	ctx, cancel := p.withTimeout()
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
 
 	result, err := p.db.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return fmt.Errorf("mogngodb vote: %w", err)
+		return 0, fmt.Errorf("mogngodb vote: %w", err)
 	}
 
-	if result.ModifiedCount == 0 {
-		filter := bson.M{"_id": postID}
+	delta := 0
+	switch {
+	case result.ModifiedCount > 0:
+		delta = 2 * vote
+	case result.MatchedCount == 0:
+		filter := bson.M{"_id": postID, "deleted": notDeleted, "status": published, "votes.user": bson.M{"$ne": userID}}
 		update := bson.M{
 			"$push": bson.M{
 				"votes": bson.M{
@@ -284,90 +548,635 @@ func (p *PostMongoDB) vote(postID string, userID string, vote int) error {
 				},
 			},
 		}
+		res, err := p.db.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return 0, fmt.Errorf("mogngodb vote: %w", err)
+		}
+		if res.MatchedCount == 0 {
+			return 0, fmt.Errorf("mogngodb vote: %w", myerrors.ErrNoPost)
+		}
+		delta = vote
+	}
+
+	if err = p.updateMetrics(ctx, postID); err != nil {
+		return 0, fmt.Errorf("mogngodb vote: %w", err)
+	}
+
+	return delta, nil
+}
+
+func (p *PostMongoDB) UpvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
+	delta, err := p.vote(ctx, postID, userID, LIKE)
+	if err != nil {
+		return nil, 0, fmt.Errorf("mogngodb upvote post: %w", err)
+	}
+
+	post, err := p.GetPost(ctx, postID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("mogngodb upvote post: %w", err)
+	}
+
+	return post, delta, nil
+}
+
+func (p *PostMongoDB) UnvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
+	delta := 0
+	// Pulling by value tells us which vote was removed.
+	for _, vote := range []int{LIKE, DISLIKE} {
+		filter := bson.M{
+			"_id":     postID,
+			"deleted": notDeleted,
+			"votes":   bson.M{"$elemMatch": bson.M{"user": userID, "vote": vote}},
+		}
+		update := bson.M{
+			"$pull": bson.M{
+				"votes": bson.M{
+					"user": userID,
+				},
+			},
+		}
+		ctx, cancel := p.withTimeout(ctx)
+		res, err := p.db.UpdateOne(ctx, filter, update)
+		cancel()
+		if err != nil {
+			return nil, 0, fmt.Errorf("mogngodb unvote post: %w", err)
+		}
+		if res.ModifiedCount > 0 {
+			delta = -vote
+			break
+		}
+	}
+
+	if err := p.updateMetrics(ctx, postID); err != nil {
+		return nil, 0, fmt.Errorf("mogngodb vote: %w", err)
+	}
+
+	post, err := p.GetPost(ctx, postID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("mogngodb unvote post: %w", err)
+	}
+	if post.Deleted != nil {
+		return nil, 0, fmt.Errorf("mogngodb unvote post: %w", myerrors.ErrNoPost)
+	}
+
+	return post, delta, nil
+}
+
+func (p *PostMongoDB) DownvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
+	delta, err := p.vote(ctx, postID, userID, DISLIKE)
+	if err != nil {
+		return nil, 0, fmt.Errorf("mogngodb downvote post: %w", err)
+	}
+
+	var post *posts.Post
+	filter := bson.M{"_id": postID}
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	err = p.db.FindOne(ctx, filter).Decode(&post)
+	if err != nil {
+		return nil, 0, fmt.Errorf("mogngodb downvote post: %w", err)
+	}
+
+	return post, delta, nil
+}
+
+// VoteComment sets the user's vote on a comment; a zero vote removes it. The
+// comment score is adjusted by the returned delta.
+func (p *PostMongoDB) VoteComment(ctx context.Context, postID, commentID, userID string, vote int) (*posts.Post, int, error) {
+	var delta int
+	var err error
+	if vote == 0 {
+		delta, err = p.unvoteComment(ctx, postID, commentID, userID)
+	} else {
+		delta, err = p.voteComment(ctx, postID, commentID, userID, vote)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("mogngodb vote comment: %w", err)
+	}
+
+	if delta != 0 {
+		filter := bson.M{"_id": postID, "comments._id": commentID}
+		update := bson.M{"$inc": bson.M{"comments.$.score": delta}}
+		ctx, cancel := p.withTimeout(ctx)
+		defer cancel()
 		if _, err = p.db.UpdateOne(ctx, filter, update); err != nil {
-			return fmt.Errorf("mogngodb vote: %w", err)
+			return nil, 0, fmt.Errorf("mogngodb vote comment: %w", err)
 		}
 	}
 
-	if err = p.updateMetrics(postID); err != nil {
-		return fmt.Errorf("mogngodb vote: %w", err)
+	post, err := p.GetPost(ctx, postID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("mogngodb vote comment: %w", err)
+	}
+	if post.Deleted != nil {
+		return nil, 0, fmt.Errorf("mogngodb vote comment: %w", myerrors.ErrNoPost)
 	}
+	if comment := findComment(post, commentID); comment == nil || comment.Deleted != nil {
+		return nil, 0, fmt.Errorf("mogngodb vote comment: %w", myerrors.ErrNoComment)
+	}
+	return post, delta, nil
+}
 
+func findComment(post *posts.Post, commentID string) *posts.Comment {
+	for _, comment := range post.Comments {
+		if comment.ID == commentID {
+			return comment
+		}
+	}
 	return nil
 }
 
-func (p *PostMongoDB) UpvotePost(postID, userID string) (*posts.Post, error) {
-	err := p.vote(postID, userID, LIKE)
+func (p *PostMongoDB) voteComment(ctx context.Context, postID, commentID, userID string, vote int) (int, error) {
+	filter := bson.M{
+		"_id":      postID,
+		"deleted":  notDeleted,
+		"comments": bson.M{"$elemMatch": bson.M{"_id": commentID, "deleted": notDeleted, "votes.user": userID}},
+	}
+	update := bson.M{"$set": bson.M{"comments.$[c].votes.$[v].vote": vote}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"c._id": commentID}, bson.M{"v.user": userID}},
+	})
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	res, err := p.db.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		return nil, fmt.Errorf("mogngodb upvote post: %w", err)
+		return 0, err
+	}
+	if res.ModifiedCount > 0 {
+		return 2 * vote, nil
+	}
+	if res.MatchedCount > 0 {
+		return 0, nil
 	}
 
-	post, err := p.GetPost(postID)
+	filter = bson.M{
+		"_id":      postID,
+		"deleted":  notDeleted,
+		"comments": bson.M{"$elemMatch": bson.M{"_id": commentID, "deleted": notDeleted, "votes.user": bson.M{"$ne": userID}}},
+	}
+	update = bson.M{"$push": bson.M{"comments.$.votes": bson.M{"user": userID, "vote": vote}}}
+	res, err = p.db.UpdateOne(ctx, filter, update)
 	if err != nil {
-		return nil, fmt.Errorf("mogngodb upvote post: %w", err)
+		return 0, err
+	}
+	if res.MatchedCount == 0 {
+		return 0, myerrors.ErrNoComment
 	}
+	return vote, nil
+}
+
+func (p *PostMongoDB) unvoteComment(ctx context.Context, postID, commentID, userID string) (int, error) {
+	for _, vote := range []int{LIKE, DISLIKE} {
+		filter := bson.M{
+			"_id":     postID,
+			"deleted": notDeleted,
+			"comments": bson.M{"$elemMatch": bson.M{
+				"_id":     commentID,
+				"deleted": notDeleted,
+				"votes":   bson.M{"$elemMatch": bson.M{"user": userID, "vote": vote}},
+			}},
+		}
+		update := bson.M{"$pull": bson.M{"comments.$.votes": bson.M{"user": userID}}}
+		ctx, cancel := p.withTimeout(ctx)
+		res, err := p.db.UpdateOne(ctx, filter, update)
+		cancel()
+		if err != nil {
+			return 0, err
+		}
+		if res.ModifiedCount > 0 {
+			return -vote, nil
+		}
+	}
+	return 0, nil
+}
+
+// VotePoll records the user's choice, replacing an earlier one, as long as the
+// poll is open.
+func (p *PostMongoDB) VotePoll(ctx context.Context, postID, userID, optionID string, now time.Time) (*posts.Post, error) {
+	open := bson.M{
+		"_id":             postID,
+		"type":            posts.TypePoll,
+		"deleted":         notDeleted,
+		"status":          published,
+		"poll.options":    bson.M{"$elemMatch": bson.M{"_id": optionID}},
+		"$or":             bson.A{bson.M{"poll.closes": nil}, bson.M{"poll.closes": bson.M{"$gt": now}}},
+		"poll.votes.user": userID,
+	}
+	// The filter touches two arrays, so the vote is picked by an array
+	// filter rather than the ambiguous positional $.
+	update := bson.M{"$set": bson.M{"poll.votes.$[v].option": optionID}}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{
+		Filters: []interface{}{bson.M{"v.user": userID}},
+	})
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
 
+	res, err := p.db.UpdateOne(ctx, open, update, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mogngodb vote poll: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		open["poll.votes.user"] = bson.M{"$ne": userID}
+		update = bson.M{"$push": bson.M{"poll.votes": &posts.PollVote{UserID: userID, OptionID: optionID}}}
+		if res, err = p.db.UpdateOne(ctx, open, update); err != nil {
+			return nil, fmt.Errorf("mogngodb vote poll: %w", err)
+		}
+	}
+
+	post, err := p.GetPost(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("mogngodb vote poll: %w", err)
+	}
+	if res.MatchedCount == 0 {
+		if err = pollVoteError(post, optionID, now); err != nil {
+			return nil, fmt.Errorf("mogngodb vote poll: %w", err)
+		}
+	}
 	return post, nil
 }
 
-func (p *PostMongoDB) UnvotePost(postID, userID string) (*posts.Post, error) {
-	filter := bson.M{"_id": postID}
-	update := bson.M{
-		"$pull": bson.M{
-			"votes": bson.M{
-				"user": userID,
-			},
-		},
+// pollVoteError explains why a vote matched no open poll.
+func pollVoteError(post *posts.Post, optionID string, now time.Time) error {
+	switch {
+	case post.Deleted != nil || !post.IsPublished():
+		return myerrors.ErrNoPost
+	case post.Type != posts.TypePoll || post.Poll == nil:
+		return myerrors.ErrNotPoll
+	case post.Poll.IsClosed(now):
+		return myerrors.ErrPollClosed
+	case !post.Poll.HasOption(optionID):
+		return myerrors.ErrNoPollOption
+	}
+	// The vote was recorded concurrently between both updates.
+	return nil
+}
+
+func (p *PostMongoDB) GetPostsByUser(ctx context.Context, username string, hidden []string) ([]*posts.Post, error) {
+	filter := bson.M{"author.username": username, "deleted": notDeleted, "status": published}
+	posts, err := p.findPosts(ctx, filter, hidden, nil)
+	if err != nil {
+		return nil, fmt.Errorf("mogngodb get posts by id: %w", err)
 	}
-	ctx, cancel := p.withTimeout()
+	return posts, nil
+}
+
+// GetCommentsByUser returns the user's newest live comments across all posts.
+// Comments held for review are left out until a moderator approves them, and
+// so are comments by, or on posts by, the authors in hidden.
+func (p *PostMongoDB) GetCommentsByUser(ctx context.Context, username string, hidden []string, limit, offset int) ([]*posts.UserComment, error) {
+	match := bson.M{"comments.author.username": username, "deleted": notDeleted, "status": published}
+	commentMatch := bson.M{
+		"comments.author.username": username,
+		"comments.deleted":         notDeleted,
+		"comments.held":            bson.M{"$ne": true},
+	}
+	if len(hidden) != 0 {
+		match["author._id"] = bson.M{"$nin": hidden}
+		commentMatch["comments.author._id"] = bson.M{"$nin": hidden}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$unwind", Value: "$comments"}},
+		{{Key: "$match", Value: commentMatch}},
+		{{Key: "$sort", Value: bson.M{"comments.created": -1}}},
+		{{Key: "$skip", Value: offset}},
+	}
+	if limit > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: limit}})
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.M{
+		"_id":      0,
+		"postId":   "$_id",
+		"title":    "$title",
+		"category": "$category",
+		"comment":  "$comments",
+	}}})
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	c, err := p.db.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("mogngodb get comments by user: %w", err)
+	}
+	comments := []*posts.UserComment{}
+	if err = c.All(ctx, &comments); err != nil {
+		return nil, fmt.Errorf("mogngodb get comments by user: %w", err)
+	}
+	return comments, nil
+}
+
+// GetDrafts returns the author's unpublished posts, scheduled ones included.
+func (p *PostMongoDB) GetDrafts(ctx context.Context, userID string) ([]*posts.Post, error) {
+	drafts := []*posts.Post{}
+	filter := bson.M{"author._id": userID, "deleted": notDeleted, "status": unpublished}
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
-	if _, err := p.db.UpdateOne(ctx, filter, update); err != nil {
-		return nil, fmt.Errorf("mogngodb unvote post: %w", err)
+	c, err := p.db.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get drafts: %w", err)
 	}
+	if err = c.All(ctx, &drafts); err != nil {
+		return nil, fmt.Errorf("mongodb get drafts: %w", err)
+	}
+	return drafts, nil
+}
 
-	if err := p.updateMetrics(postID); err != nil {
-		return nil, fmt.Errorf("mogngodb vote: %w", err)
+// UpdateDraft replaces the content of an unpublished post. The type and
+// attachments of a post are fixed when it is created. When post carries
+// StatusHeld the draft is held for review instead.
+func (p *PostMongoDB) UpdateDraft(ctx context.Context, postID, userID string, post *posts.Post) (*posts.Post, error) {
+	set := bson.M{
+		"category": post.Category,
+		"title":    post.Title,
+		"text":     post.Text,
+		"url":      post.URL,
+	}
+	update := bson.M{"$set": set}
+	if post.Status == posts.StatusHeld {
+		set["status"] = posts.StatusHeld
+		update["$unset"] = bson.M{"publishAt": ""}
+	}
+	draft, err := p.updateDraft(ctx, postID, userID, update)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb update draft: %w", err)
 	}
+	return draft, nil
+}
 
-	post, err := p.GetPost(postID)
+// SchedulePost sets the time the scheduler publishes the post at. A nil time
+// turns a scheduled post back into a draft.
+func (p *PostMongoDB) SchedulePost(ctx context.Context, postID, userID string, publishAt *time.Time) (*posts.Post, error) {
+	update := bson.M{
+		"$set": bson.M{"status": posts.StatusScheduled, "publishAt": publishAt},
+	}
+	if publishAt == nil {
+		update = bson.M{
+			"$set":   bson.M{"status": posts.StatusDraft},
+			"$unset": bson.M{"publishAt": ""},
+		}
+	}
+	draft, err := p.updateDraft(ctx, postID, userID, update)
 	if err != nil {
-		return nil, fmt.Errorf("mogngodb unvote post: %w", err)
+		return nil, fmt.Errorf("mongodb schedule post: %w", err)
 	}
+	return draft, nil
+}
 
+// PublishPost publishes an unpublished post right away.
+func (p *PostMongoDB) PublishPost(ctx context.Context, postID, userID string, now time.Time) (*posts.Post, error) {
+	post, err := p.updateDraft(ctx, postID, userID, publishUpdate(now))
+	if err != nil {
+		return nil, fmt.Errorf("mongodb publish post: %w", err)
+	}
 	return post, nil
 }
 
-func (p *PostMongoDB) DownvotePost(postID, userID string) (*posts.Post, error) {
-	err := p.vote(postID, userID, DISLIKE)
+// PublishDue publishes the earliest scheduled post that is due and returns
+// it, or nil when nothing is due. Finding and publishing the post is a
+// single atomic update, so with several servers running each post is
+// published by exactly one of them.
+func (p *PostMongoDB) PublishDue(ctx context.Context, now time.Time) (*posts.Post, error) {
+	filter := bson.M{
+		"status":    posts.StatusScheduled,
+		"publishAt": bson.M{"$lte": now},
+		"deleted":   notDeleted,
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"publishAt": 1}).
+		SetReturnDocument(options.After)
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var post *posts.Post
+	err := p.db.FindOneAndUpdate(ctx, filter, publishUpdate(now), opts).Decode(&post)
 	if err != nil {
-		return nil, fmt.Errorf("mogngodb downvote post: %w", err)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("mongodb publish due: %w", err)
+	}
+	return post, nil
+}
+
+// publishUpdate clears the draft status and dates the post to its
+// publication, so it is listed as new.
+func publishUpdate(now time.Time) bson.M {
+	return bson.M{
+		"$set":   bson.M{"created": now},
+		"$unset": bson.M{"status": "", "publishAt": ""},
+	}
+}
+
+// updateDraft applies update to one of the author's drafts or scheduled
+// posts and returns the result.
+func (p *PostMongoDB) updateDraft(ctx context.Context, postID, userID string, update bson.M) (*posts.Post, error) {
+	filter := bson.M{
+		"_id":        postID,
+		"author._id": userID,
+		"deleted":    notDeleted,
+		"status":     editable,
 	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
 
 	var post *posts.Post
-	filter := bson.M{"_id": postID}
-	ctx, cancel := p.withTimeout()
+	err := p.db.FindOneAndUpdate(ctx, filter, update, opts).Decode(&post)
+	if err == nil {
+		return post, nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+
+	post, err = p.GetPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+	return nil, draftError(post, userID)
+}
+
+// draftError explains why a post did not match as the user's draft. Other
+// users' drafts are reported as missing so their existence is not leaked.
+func draftError(post *posts.Post, userID string) error {
+	switch {
+	case post.Deleted != nil || post.Author == nil || post.Author.ID != userID:
+		return myerrors.ErrNoPost
+	case post.IsPublished():
+		return myerrors.ErrNotDraft
+	case post.Status == posts.StatusHeld:
+		return myerrors.ErrHeld
+	}
+	// The post changed between both queries.
+	return myerrors.ErrNoPost
+}
+
+// SetLocked closes a post for new comments or opens it again.
+func (p *PostMongoDB) SetLocked(ctx context.Context, postID string, locked bool) (*posts.Post, error) {
+	post, err := p.setFlag(ctx, postID, "locked", locked)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb set locked: %w", err)
+	}
+	return post, nil
+}
+
+// SetPinned pins a post to the top of its category or unpins it. A category
+// keeps at most limit pinned posts. The pinned posts are counted again after
+// pinning and a pin over the limit is rolled back, so moderators pinning at
+// the same moment cannot leave the category above it.
+func (p *PostMongoDB) SetPinned(ctx context.Context, postID string, pinned bool, limit int) (*posts.Post, error) {
+	if !pinned {
+		post, err := p.setFlag(ctx, postID, "pinned", false)
+		if err != nil {
+			return nil, fmt.Errorf("mongodb set pinned: %w", err)
+		}
+		return post, nil
+	}
+
+	post, err := p.GetPost(ctx, postID)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb set pinned: %w", err)
+	}
+	if post.Deleted != nil || !post.IsPublished() {
+		return nil, fmt.Errorf("mongodb set pinned: %w", myerrors.ErrNoPost)
+	}
+	if post.Pinned {
+		return post, nil
+	}
+	count, err := p.countPinned(ctx, post.Category)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb set pinned: %w", err)
+	}
+	if count >= int64(limit) {
+		return nil, fmt.Errorf("mongodb set pinned: %w", myerrors.ErrPinLimit)
+	}
+
+	post, err = p.setFlag(ctx, postID, "pinned", true)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb set pinned: %w", err)
+	}
+	count, err = p.countPinned(ctx, post.Category)
+	if err == nil && count <= int64(limit) {
+		return post, nil
+	}
+	// Another pin won the race, or the count is unknown: back out so the
+	// limit holds. The rollback runs even if the request was cancelled.
+	if _, rollbackErr := p.setFlag(context.WithoutCancel(ctx), postID, "pinned", false); rollbackErr != nil {
+		return nil, fmt.Errorf("mongodb set pinned: roll back: %w", rollbackErr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mongodb set pinned: %w", err)
+	}
+	return nil, fmt.Errorf("mongodb set pinned: %w", myerrors.ErrPinLimit)
+}
+
+func (p *PostMongoDB) countPinned(ctx context.Context, category string) (int64, error) {
+	filter := bson.M{"category": category, "pinned": true, "deleted": notDeleted}
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
-	err = p.db.FindOne(ctx, filter).Decode(&post)
+	return p.db.CountDocuments(ctx, filter)
+}
+
+// SetContentFlags updates the nsfw and spoiler flags of a post. Nil flags are
+// left as they are. Drafts can be flagged too.
+func (p *PostMongoDB) SetContentFlags(ctx context.Context, postID string, nsfw, spoiler *bool) (*posts.Post, error) {
+	set := bson.M{}
+	if nsfw != nil {
+		set["nsfw"] = *nsfw
+	}
+	if spoiler != nil {
+		set["spoiler"] = *spoiler
+	}
+	if len(set) == 0 {
+		post, err := p.GetPost(ctx, postID)
+		if err != nil {
+			return nil, fmt.Errorf("mongodb set content flags: %w", err)
+		}
+		return post, nil
+	}
+
+	filter := bson.M{"_id": postID, "deleted": notDeleted}
+	post, err := p.setFields(ctx, filter, set)
 	if err != nil {
-		return nil, fmt.Errorf("mogngodb downvote post: %w", err)
+		return nil, fmt.Errorf("mongodb set content flags: %w", err)
 	}
+	return post, nil
+}
+
+// ApprovePost publishes a post the content filter held for review.
+func (p *PostMongoDB) ApprovePost(ctx context.Context, postID string, now time.Time) (*posts.Post, error) {
+	filter := bson.M{"_id": postID, "deleted": notDeleted, "status": posts.StatusHeld}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
 
+	var post *posts.Post
+	err := p.db.FindOneAndUpdate(ctx, filter, publishUpdate(now), opts).Decode(&post)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("mongodb approve post: %w", myerrors.ErrNotHeld)
+		}
+		return nil, fmt.Errorf("mongodb approve post: %w", err)
+	}
 	return post, nil
 }
 
-func (p *PostMongoDB) GetPostsByUser(username string) ([]*posts.Post, error) {
-	var posts []*posts.Post
-	filter := bson.M{"author.username": username}
-	ctx, cancel := p.withTimeout()
+// ApproveComment shows a comment the content filter held for review.
+func (p *PostMongoDB) ApproveComment(ctx context.Context, postID, commentID string) (*posts.Post, error) {
+	filter := bson.M{
+		"_id":      postID,
+		"comments": bson.M{"$elemMatch": bson.M{"_id": commentID, "held": true, "deleted": notDeleted}},
+	}
+	update := bson.M{"$unset": bson.M{"comments.$.held": ""}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	ctx, cancel := p.withTimeout(ctx)
 	defer cancel()
-	c, err := p.db.Find(ctx, filter)
+
+	var post *posts.Post
+	err := p.db.FindOneAndUpdate(ctx, filter, update, opts).Decode(&post)
 	if err != nil {
-		return nil, fmt.Errorf("mogngodb get posts by id: %w", err)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("mongodb approve comment: %w", myerrors.ErrNotHeld)
+		}
+		return nil, fmt.Errorf("mongodb approve comment: %w", err)
 	}
-	err = c.All(ctx, &posts)
+	return post, nil
+}
+
+// LinkPosted reports whether url was already submitted to category. Deleted
+// posts do not count, held ones do.
+func (p *PostMongoDB) LinkPosted(ctx context.Context, category, url string) (bool, error) {
+	filter := bson.M{"category": category, "url": url, "deleted": notDeleted}
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	count, err := p.db.CountDocuments(ctx, filter, options.Count().SetLimit(1))
 	if err != nil {
-		return nil, fmt.Errorf("mogngodb get posts by id: %w", err)
+		return false, fmt.Errorf("mongodb link posted: %w", err)
 	}
+	return count > 0, nil
+}
 
-	return posts, nil
+// setFlag sets a moderator flag on a published post and returns the result.
+func (p *PostMongoDB) setFlag(ctx context.Context, postID, flag string, value bool) (*posts.Post, error) {
+	filter := bson.M{"_id": postID, "deleted": notDeleted, "status": published}
+	return p.setFields(ctx, filter, bson.M{flag: value})
+}
+
+// setFields applies $set to the post matching filter and returns the result.
+func (p *PostMongoDB) setFields(ctx context.Context, filter, set bson.M) (*posts.Post, error) {
+	update := bson.M{"$set": set}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+
+	var post *posts.Post
+	err := p.db.FindOneAndUpdate(ctx, filter, update, opts).Decode(&post)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, myerrors.ErrNoPost
+		}
+		return nil, err
+	}
+	return post, nil
 }
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
 
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
 	"github.com/KonstantinGalanin/redditclone/internal/posts"
 	"github.com/KonstantinGalanin/redditclone/internal/user"
 )
@@ -32,7 +35,7 @@ func TestWithTimeout(t *testing.T) {
 	mockCollection := mt.Coll
 	mockDB := NewPostMongoDB(mockCollection)
 
-	ctx, cancel := mockDB.withTimeout()
+	ctx, cancel := mockDB.withTimeout(context.Background())
 
 	assert.NotNil(t, mockDB)
 	assert.NotNil(t, ctx)
@@ -66,7 +69,7 @@ func TestCreatePost(t *testing.T) {
 
 			mt.AddMockResponses(c.resp)
 
-			posts, err := mockDB.CreatePost("category", "title", "type", "url", "tet", &user.User{
+			posts, err := mockDB.CreatePost(context.Background(), &posts.Post{Category: "category", Title: "title", Type: "type", URL: "url", Text: "tet"}, &user.User{
 				Username: "username",
 				Password: "password",
 				ID:       "1",
@@ -121,7 +124,7 @@ func TestGetAllPosts(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.GetAllPosts()
+			posts, err := mockDB.GetAllPosts(context.Background(), nil, nil)
 			if c.expectedError {
 				assert.Error(t, err)
 				assert.Nil(t, posts)
@@ -172,7 +175,7 @@ func TestGetPost(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.GetPost(postID)
+			posts, err := mockDB.GetPost(context.Background(), postID)
 			if c.expectedError {
 				assert.Error(t, err)
 				assert.Nil(t, posts)
@@ -215,6 +218,54 @@ var postData bson.D = bson.D{
 	}},
 }
 
+func TestGetPostsByIDs(t *testing.T) {
+	cases := []struct {
+		name          string
+		resp          []bson.D
+		expectedError bool
+	}{
+		{
+			name:          "find error",
+			resp:          nil,
+			expectedError: true,
+		},
+		{
+			name: "cursor error",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, bson.D{{Key: "author", Value: 1}}),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectedError: true,
+		},
+		{
+			name: "success",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectedError: false,
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewPostMongoDB(mt.Coll)
+			for _, response := range c.resp {
+				mt.AddMockResponses(response)
+			}
+			posts, err := mockDB.GetPostsByIDs(context.Background(), []string{"1", "2"}, nil)
+			if c.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, posts)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, posts, 1)
+			}
+		})
+	}
+}
+
 func TestDeletePost(t *testing.T) {
 	cases := []struct {
 		name          string
@@ -272,7 +323,7 @@ func TestDeletePost(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			err := mockDB.DeletePost(c.postID, c.userID)
+			err := mockDB.DeletePost(context.Background(), c.postID, c.userID)
 			if c.expectedError {
 				assert.Error(t, err)
 			} else {
@@ -282,6 +333,25 @@ func TestDeletePost(t *testing.T) {
 	}
 }
 
+func TestRemovePost(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}})
+		assert.NoError(t, NewPostMongoDB(mt.Coll).RemovePost(context.Background(), "1"))
+	})
+
+	mt.Run("no post", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 0}})
+		assert.ErrorIs(t, NewPostMongoDB(mt.Coll).RemovePost(context.Background(), "1"), myerrors.ErrNoPost)
+	})
+
+	mt.Run("delete error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		assert.Error(t, NewPostMongoDB(mt.Coll).RemovePost(context.Background(), "1"))
+	})
+}
+
 func TestGetPostsByCategory(t *testing.T) {
 	cases := []struct {
 		name          string
@@ -322,7 +392,7 @@ func TestGetPostsByCategory(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.GetPostsByCategory(c.category)
+			posts, err := mockDB.GetPostsByCategory(context.Background(), c.category, nil, nil)
 			if c.expectedError {
 				assert.Error(t, err)
 				assert.Nil(t, posts)
@@ -334,6 +404,173 @@ func TestGetPostsByCategory(t *testing.T) {
 	}
 }
 
+func TestGetPostsByCategories(t *testing.T) {
+	cases := []struct {
+		name          string
+		resp          []bson.D
+		expectedError bool
+	}{
+		{
+			name:          "find error",
+			resp:          nil,
+			expectedError: true,
+		},
+		{
+			name: "cursor error",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, bson.D{{Key: "author", Value: 1}}),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectedError: true,
+		},
+		{
+			name: "success",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectedError: false,
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewPostMongoDB(mt.Coll)
+			for _, response := range c.resp {
+				mt.AddMockResponses(response)
+			}
+			posts, err := mockDB.GetPostsByCategories(context.Background(), []string{"music", "news"}, nil, nil)
+			if c.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, posts)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, posts, 1)
+			}
+		})
+	}
+}
+
+func TestGetAllPostsHiding(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("aggregates with hidden authors", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
+			mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+		)
+		posts, err := NewPostMongoDB(mt.Coll).GetAllPosts(context.Background(), []string{"3"}, nil)
+		assert.NoError(t, err)
+		assert.Len(t, posts, 1)
+
+		started := mt.GetStartedEvent()
+		assert.Equal(t, "aggregate", started.CommandName)
+		match := started.Command.Lookup("pipeline", "0", "$match", "author._id", "$nin")
+		assert.Equal(t, "[\"3\"]", match.String())
+	})
+
+	mt.Run("finds without hidden authors", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch),
+		)
+		posts, err := NewPostMongoDB(mt.Coll).GetAllPosts(context.Background(), nil, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, posts)
+		assert.Equal(t, "find", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("aggregate error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse())
+		posts, err := NewPostMongoDB(mt.Coll).GetAllPosts(context.Background(), []string{"3"}, nil)
+		assert.Error(t, err)
+		assert.Nil(t, posts)
+	})
+}
+
+func TestListOptions(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	opts := &posts.ListOptions{Sort: posts.SortTop, Limit: 10, Offset: 20, PinnedFirst: true, HideNSFW: true}
+	empty := mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch)
+
+	mt.Run("find", func(mt *mtest.T) {
+		mt.AddMockResponses(empty)
+		_, err := NewPostMongoDB(mt.Coll).GetPostsByCategory(context.Background(), "music", nil, opts)
+		assert.NoError(t, err)
+
+		command := mt.GetStartedEvent().Command
+		assert.Equal(t, `{"pinned": {"$numberInt":"-1"},"score": {"$numberInt":"-1"},"created": {"$numberInt":"-1"}}`, command.Lookup("sort").String())
+		assert.EqualValues(t, 20, command.Lookup("skip").AsInt64())
+		assert.EqualValues(t, 10, command.Lookup("limit").AsInt64())
+		assert.Equal(t, true, command.Lookup("filter", "nsfw", "$ne").Boolean())
+	})
+
+	mt.Run("aggregate", func(mt *mtest.T) {
+		mt.AddMockResponses(empty)
+		_, err := NewPostMongoDB(mt.Coll).GetAllPosts(context.Background(), []string{"3"}, &posts.ListOptions{Sort: posts.SortNew, Limit: 5})
+		assert.NoError(t, err)
+
+		pipeline := mt.GetStartedEvent().Command.Lookup("pipeline")
+		assert.Equal(t, `{"created": {"$numberInt":"-1"}}`, pipeline.Array().Index(1).Value().Document().Lookup("$sort").String())
+		assert.EqualValues(t, 5, pipeline.Array().Index(2).Value().Document().Lookup("$limit").AsInt64())
+		_, err = pipeline.Array().Index(3).Value().Document().LookupErr("$set")
+		assert.NoError(t, err)
+		_, err = pipeline.Array().Index(0).Value().Document().LookupErr("$match", "nsfw")
+		assert.Error(t, err)
+	})
+
+	mt.Run("store order", func(mt *mtest.T) {
+		mt.AddMockResponses(empty)
+		_, err := NewPostMongoDB(mt.Coll).GetAllPosts(context.Background(), nil, &posts.ListOptions{})
+		assert.NoError(t, err)
+
+		command := mt.GetStartedEvent().Command
+		for _, key := range []string{"sort", "skip", "limit"} {
+			_, err = command.LookupErr(key)
+			assert.Error(t, err, key)
+		}
+	})
+}
+
+func TestGetPostHiding(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("no hidden authors", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
+		)
+		post, err := NewPostMongoDB(mt.Coll).GetPostHiding(context.Background(), "1", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", post.ID)
+		assert.Equal(t, "find", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("hidden author", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch),
+		)
+		_, err := NewPostMongoDB(mt.Coll).GetPostHiding(context.Background(), "1", []string{"2"})
+		assert.ErrorIs(t, err, myerrors.ErrNoPost)
+		assert.Equal(t, "aggregate", mt.GetStartedEvent().CommandName)
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
+			mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+		)
+		post, err := NewPostMongoDB(mt.Coll).GetPostHiding(context.Background(), "1", []string{"3"})
+		assert.NoError(t, err)
+		assert.Equal(t, "1", post.ID)
+	})
+
+	mt.Run("aggregate error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse())
+		_, err := NewPostMongoDB(mt.Coll).GetPostHiding(context.Background(), "1", []string{"3"})
+		assert.Error(t, err)
+	})
+}
+
 func TestCreateComment(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
@@ -345,10 +582,15 @@ func TestCreateComment(t *testing.T) {
 		ID:       "1",
 	}
 
+	lockedPost := append(bson.D{{Key: "locked", Value: true}}, postData...)
+
 	cases := []struct {
-		name        string
-		resp        []bson.D
-		expectError bool
+		name          string
+		parentID      string
+		blockedBy     []string
+		resp          []bson.D
+		expectError   bool
+		expectedError error
 	}{
 		{
 			name:        "upddate error",
@@ -362,6 +604,59 @@ func TestCreateComment(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name:     "no parent comment",
+			parentID: "2",
+			resp: []bson.D{
+				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 0}},
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectError:   true,
+			expectedError: myerrors.ErrNoComment,
+		},
+		{
+			name: "no post",
+			resp: []bson.D{
+				updateNone,
+				mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch),
+			},
+			expectError:   true,
+			expectedError: myerrors.ErrNoPost,
+		},
+		{
+			name: "locked",
+			resp: []bson.D{
+				updateNone,
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, lockedPost),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectError:   true,
+			expectedError: myerrors.ErrPostLocked,
+		},
+		{
+			name:      "post author blocked the commenter",
+			blockedBy: []string{"2"},
+			resp: []bson.D{
+				updateNone,
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectError:   true,
+			expectedError: myerrors.ErrBlocked,
+		},
+		{
+			name:      "parent author blocked the commenter",
+			parentID:  "1",
+			blockedBy: []string{"1"},
+			resp: []bson.D{
+				updateNone,
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, commentPostData),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectError:   true,
+			expectedError: myerrors.ErrBlocked,
+		},
 		{
 			name: "success",
 			resp: []bson.D{
@@ -380,64 +675,122 @@ func TestCreateComment(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.CreateComment(postID, text, author)
+			posts, comment, err := mockDB.CreateComment(context.Background(), postID, c.parentID, text, author, posts.CommentOptions{BlockedBy: c.blockedBy})
 
 			if c.expectError {
 				assert.Error(t, err)
 				assert.Nil(t, posts)
+				assert.Nil(t, comment)
+				if c.expectedError != nil {
+					assert.ErrorIs(t, err, c.expectedError)
+				}
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, posts)
+				assert.Equal(t, text, comment.Body)
 			}
 		})
 	}
 }
 
+var commentPostData = bson.D{
+	{Key: "_id", Value: "1"},
+	{Key: "author", Value: bson.D{{Key: "username", Value: "User"}, {Key: "_id", Value: "2"}}},
+	{Key: "comments", Value: bson.A{
+		bson.D{
+			{Key: "_id", Value: "1"},
+			{Key: "body", Value: "comment"},
+			{Key: "author", Value: bson.D{{Key: "username", Value: "User"}, {Key: "_id", Value: "1"}}},
+		},
+		bson.D{
+			{Key: "_id", Value: "2"},
+			{Key: "body", Value: "deleted"},
+			{Key: "deleted", Value: bson.D{{Key: "by", Value: posts.DeletedByAuthor}, {Key: "at", Value: time.Now()}}},
+		},
+	}},
+}
+
 func TestDeleteComment(t *testing.T) {
 	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
 
 	postID := "1"
-	commentID := "1"
 
 	cases := []struct {
 		name        string
 		resp        []bson.D
-		expectError bool
+		commentID   string
+		userID      string
+		expectError error
 	}{
 		{
-			name:        "upddate error",
+			name:        "get post error",
 			resp:        nil,
-			expectError: true,
+			commentID:   "1",
+			userID:      "1",
+			expectError: errors.New("any"),
 		},
 		{
-			name: "get post error 2",
+			name: "no comment",
 			resp: []bson.D{
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
+				mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, commentPostData),
 			},
-			expectError: true,
+			commentID:   "3",
+			userID:      "1",
+			expectError: myerrors.ErrNoComment,
+		},
+		{
+			name: "already deleted",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, commentPostData),
+			},
+			commentID:   "2",
+			userID:      "1",
+			expectError: myerrors.ErrNoComment,
+		},
+		{
+			name: "not the author",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, commentPostData),
+			},
+			commentID:   "1",
+			userID:      "2",
+			expectError: errors.New("any"),
+		},
+		{
+			name: "update error",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, commentPostData),
+				mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}),
+			},
+			commentID:   "1",
+			userID:      "1",
+			expectError: errors.New("any"),
 		},
 		{
 			name: "success",
 			resp: []bson.D{
+				mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, commentPostData),
 				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, commentPostData),
 			},
-			expectError: false,
+			commentID: "1",
+			userID:    "1",
 		},
 	}
 
 	for _, c := range cases {
 		mt.Run(c.name, func(mt *mtest.T) {
-			mockCollection := mt.Coll
-			mockDB := NewPostMongoDB(mockCollection)
+			mockDB := NewPostMongoDB(mt.Coll)
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.DeleteComment(postID, commentID)
+			posts, err := mockDB.DeleteComment(context.Background(), postID, c.commentID, c.userID)
 
-			if c.expectError {
+			if c.expectError != nil {
 				assert.Error(t, err)
+				if errors.Is(c.expectError, myerrors.ErrNoComment) {
+					assert.ErrorIs(t, err, myerrors.ErrNoComment)
+				}
 				assert.Nil(t, posts)
 			} else {
 				assert.NoError(t, err)
@@ -447,6 +800,65 @@ func TestDeleteComment(t *testing.T) {
 	}
 }
 
+func TestRemoveComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
+			mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, commentPostData),
+		)
+		post, err := NewPostMongoDB(mt.Coll).RemoveComment(context.Background(), "1", "1")
+		assert.NoError(t, err)
+		assert.NotNil(t, post)
+	})
+
+	mt.Run("no comment", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 0}})
+		post, err := NewPostMongoDB(mt.Coll).RemoveComment(context.Background(), "1", "1")
+		assert.ErrorIs(t, err, myerrors.ErrNoComment)
+		assert.Nil(t, post)
+	})
+
+	mt.Run("update error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		post, err := NewPostMongoDB(mt.Coll).RemoveComment(context.Background(), "1", "1")
+		assert.Error(t, err)
+		assert.Nil(t, post)
+	})
+}
+
+func TestPurgeDeleted(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	before := time.Now()
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 2}},
+			bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 3}, {Key: "nModified", Value: 3}},
+		)
+		purged, err := NewPostMongoDB(mt.Coll).PurgeDeleted(context.Background(), before)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(5), purged)
+	})
+
+	mt.Run("delete error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		_, err := NewPostMongoDB(mt.Coll).PurgeDeleted(context.Background(), before)
+		assert.Error(t, err)
+	})
+
+	mt.Run("pull error", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 2}},
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}),
+		)
+		purged, err := NewPostMongoDB(mt.Coll).PurgeDeleted(context.Background(), before)
+		assert.Error(t, err)
+		assert.Equal(t, int64(2), purged)
+	})
+}
+
 func TestGetUpvotePercentage(t *testing.T) {
 	cases := []struct {
 		name     string
@@ -529,7 +941,7 @@ func TestUpdateUpvotePercentage(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			err := mockDB.updateUpvotePercentage(postID)
+			err := mockDB.updateUpvotePercentage(context.Background(), postID)
 
 			if c.expectError {
 				assert.Error(t, err)
@@ -599,7 +1011,7 @@ func TestUpdateScore(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			err := mockDB.updateScore(postID)
+			err := mockDB.updateScore(context.Background(), postID)
 
 			if c.expectError {
 				assert.Error(t, err)
@@ -610,17 +1022,25 @@ func TestUpdateScore(t *testing.T) {
 	}
 }
 
-var voteSuccess = []bson.D{
-	mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-	mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-	mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-	mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-	{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-	mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-	mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-	{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-	mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-	mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+var (
+	updateNone     = bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 0}}
+	updateMatched  = bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}}
+	updateModified = bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}, {Key: "nModified", Value: 1}}
+)
+
+func findPost() []bson.D {
+	return []bson.D{
+		mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
+		mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+	}
+}
+
+// withMetrics appends the responses updateMetrics consumes after a vote.
+func withMetrics(resp ...bson.D) []bson.D {
+	resp = append(resp, findPost()...)
+	resp = append(resp, updateMatched)
+	resp = append(resp, findPost()...)
+	return append(resp, updateMatched)
 }
 
 func TestVote(t *testing.T) {
@@ -631,9 +1051,10 @@ func TestVote(t *testing.T) {
 	vote := 1
 
 	cases := []struct {
-		name        string
-		resp        []bson.D
-		expectError bool
+		name          string
+		resp          []bson.D
+		expectedDelta int
+		expectError   bool
 	}{
 		{
 			name:        "upddate error",
@@ -641,24 +1062,29 @@ func TestVote(t *testing.T) {
 			expectError: true,
 		},
 		{
-			name: "upddate error 2",
-			resp: []bson.D{
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-			},
+			name:        "no post",
+			resp:        []bson.D{updateNone, updateNone},
 			expectError: true,
 		},
 		{
-			name: "update metrics error",
-			resp: []bson.D{
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-			},
+			name:        "update metrics error",
+			resp:        []bson.D{updateModified},
 			expectError: true,
 		},
 		{
-			name:        "success",
-			resp:        voteSuccess,
-			expectError: false,
+			name:          "new vote",
+			resp:          withMetrics(updateNone, updateMatched),
+			expectedDelta: vote,
+		},
+		{
+			name:          "flipped vote",
+			resp:          withMetrics(updateModified),
+			expectedDelta: 2 * vote,
+		},
+		{
+			name:          "same vote",
+			resp:          withMetrics(updateMatched),
+			expectedDelta: 0,
 		},
 	}
 
@@ -669,17 +1095,73 @@ func TestVote(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			err := mockDB.vote(postID, userID, vote)
+			delta, err := mockDB.vote(context.Background(), postID, userID, vote)
 
 			if c.expectError {
 				assert.Error(t, err)
 			} else {
 				assert.NoError(t, err)
+				assert.Equal(t, c.expectedDelta, delta)
 			}
 		})
 	}
 }
 
+// tombstoned returns postData deleted, or with its comment deleted.
+func tombstoned(comment bool) []bson.D {
+	doc := append(bson.D{}, postData...)
+	deleted := bson.E{Key: "deleted", Value: bson.D{{Key: "by", Value: "User"}, {Key: "at", Value: time.Now()}}}
+	if comment {
+		doc[len(doc)-2] = bson.E{Key: "comments", Value: bson.A{bson.D{{Key: "_id", Value: "1"}, deleted}}}
+	} else {
+		doc = append(doc, deleted)
+	}
+	return []bson.D{
+		mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, doc),
+		mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+	}
+}
+
+func TestVoteTombstoned(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("vote filters deleted posts", func(mt *mtest.T) {
+		mt.AddMockResponses(updateNone, updateNone)
+		_, _, err := NewPostMongoDB(mt.Coll).UpvotePost(context.Background(), "1", "1")
+		assert.ErrorIs(t, err, myerrors.ErrNoPost)
+
+		for _, started := range mt.GetAllStartedEvents() {
+			filter := started.Command.Lookup("updates", "0", "q")
+			assert.Equal(t, false, filter.Document().Lookup("deleted", "$exists").Boolean())
+		}
+	})
+
+	mt.Run("unvote deleted post", func(mt *mtest.T) {
+		mt.AddMockResponses(append(withMetrics(updateNone, updateNone), tombstoned(false)...)...)
+		post, _, err := NewPostMongoDB(mt.Coll).UnvotePost(context.Background(), "1", "1")
+		assert.ErrorIs(t, err, myerrors.ErrNoPost)
+		assert.Nil(t, post)
+	})
+
+	mt.Run("unvote comment on deleted post", func(mt *mtest.T) {
+		mt.AddMockResponses(append([]bson.D{updateNone, updateNone}, tombstoned(false)...)...)
+		post, _, err := NewPostMongoDB(mt.Coll).VoteComment(context.Background(), "1", "1", "1", 0)
+		assert.ErrorIs(t, err, myerrors.ErrNoPost)
+		assert.Nil(t, post)
+	})
+
+	mt.Run("unvote deleted comment", func(mt *mtest.T) {
+		mt.AddMockResponses(append([]bson.D{updateNone, updateNone}, tombstoned(true)...)...)
+		post, _, err := NewPostMongoDB(mt.Coll).VoteComment(context.Background(), "1", "1", "1", 0)
+		assert.ErrorIs(t, err, myerrors.ErrNoComment)
+		assert.Nil(t, post)
+
+		filter := mt.GetStartedEvent().Command.Lookup("updates", "0", "q")
+		assert.Equal(t, false, filter.Document().Lookup("deleted", "$exists").Boolean())
+		assert.Equal(t, false, filter.Document().Lookup("comments", "$elemMatch", "deleted", "$exists").Boolean())
+	})
+}
+
 func TestUpdateMetrics(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -720,7 +1202,7 @@ func TestUpdateMetrics(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			err := mockDB.updateMetrics(postID)
+			err := mockDB.updateMetrics(context.Background(), postID)
 
 			if c.expectError {
 				assert.Error(t, err)
@@ -739,42 +1221,18 @@ func TestUpvotePost(t *testing.T) {
 		expectError bool
 	}{
 		{
-			name: "vote error",
-			resp: []bson.D{
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-			},
+			name:        "vote error",
+			resp:        []bson.D{updateModified},
 			expectError: true,
 		},
 		{
-			name: "get post error",
-			resp: []bson.D{
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-			},
+			name:        "get post error",
+			resp:        withMetrics(updateModified),
 			expectError: true,
 		},
 		{
-			name: "get post error",
-			resp: []bson.D{
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-			},
+			name:        "success",
+			resp:        append(withMetrics(updateModified), findPost()...),
 			expectError: false,
 		},
 	}
@@ -789,7 +1247,7 @@ func TestUpvotePost(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.UpvotePost(postID, userID)
+			posts, delta, err := mockDB.UpvotePost(context.Background(), postID, userID)
 
 			if c.expectError {
 				assert.Error(t, err)
@@ -797,6 +1255,7 @@ func TestUpvotePost(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, posts)
+				assert.Equal(t, 2*LIKE, delta)
 			}
 		})
 	}
@@ -809,42 +1268,18 @@ func TestDownvotePost(t *testing.T) {
 		expectError bool
 	}{
 		{
-			name: "vote error",
-			resp: []bson.D{
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-			},
+			name:        "vote error",
+			resp:        []bson.D{updateModified},
 			expectError: true,
 		},
 		{
-			name: "get post error",
-			resp: []bson.D{
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-			},
+			name:        "get post error",
+			resp:        withMetrics(updateModified),
 			expectError: true,
 		},
 		{
-			name: "get post error",
-			resp: []bson.D{
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-			},
+			name:        "success",
+			resp:        append(withMetrics(updateNone, updateMatched), findPost()...),
 			expectError: false,
 		},
 	}
@@ -859,7 +1294,7 @@ func TestDownvotePost(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.DownvotePost(postID, userID)
+			posts, delta, err := mockDB.DownvotePost(context.Background(), postID, userID)
 
 			if c.expectError {
 				assert.Error(t, err)
@@ -867,6 +1302,7 @@ func TestDownvotePost(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, posts)
+				assert.Equal(t, DISLIKE, delta)
 			}
 		})
 	}
@@ -909,7 +1345,7 @@ func TestGetPostsByUser(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.GetPostsByUser(username)
+			posts, err := mockDB.GetPostsByUser(context.Background(), username, nil)
 			if c.expectedError {
 				assert.Error(t, err)
 				assert.Nil(t, posts)
@@ -921,11 +1357,37 @@ func TestGetPostsByUser(t *testing.T) {
 	}
 }
 
+func TestGetPostsByUserHiding(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("aggregates with hidden authors", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch))
+		posts, err := NewPostMongoDB(mt.Coll).GetPostsByUser(context.Background(), "User", []string{"3"})
+		assert.NoError(t, err)
+		assert.Empty(t, posts)
+
+		started := mt.GetStartedEvent()
+		assert.Equal(t, "aggregate", started.CommandName)
+		assert.Equal(t, `["3"]`, started.Command.Lookup("pipeline", "0", "$match", "author._id", "$nin").String())
+	})
+
+	mt.Run("saved posts by ids", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch))
+		_, err := NewPostMongoDB(mt.Coll).GetPostsByIDs(context.Background(), []string{"1"}, []string{"3"})
+		assert.NoError(t, err)
+
+		started := mt.GetStartedEvent()
+		assert.Equal(t, "aggregate", started.CommandName)
+		assert.Equal(t, `["3"]`, started.Command.Lookup("pipeline", "0", "$match", "author._id", "$nin").String())
+	})
+}
+
 func TestUnvotePost(t *testing.T) {
 	cases := []struct {
-		name        string
-		resp        []bson.D
-		expectError bool
+		name          string
+		resp          []bson.D
+		expectedDelta int
+		expectError   bool
 	}{
 		{
 			name:        "update error",
@@ -933,39 +1395,29 @@ func TestUnvotePost(t *testing.T) {
 			expectError: true,
 		},
 		{
-			name: "update metrics error",
-			resp: []bson.D{
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-			},
+			name:        "update metrics error",
+			resp:        []bson.D{updateModified},
 			expectError: true,
 		},
 		{
-			name: "get post 2",
-			resp: []bson.D{
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-			},
+			name:        "get post error",
+			resp:        withMetrics(updateModified),
 			expectError: true,
 		},
 		{
-			name: "success",
-			resp: []bson.D{
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-				{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}},
-				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, postData),
-				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
-			},
-			expectError: false,
+			name:          "removed upvote",
+			resp:          append(withMetrics(updateModified), findPost()...),
+			expectedDelta: -LIKE,
+		},
+		{
+			name:          "removed downvote",
+			resp:          append(withMetrics(updateNone, updateModified), findPost()...),
+			expectedDelta: -DISLIKE,
+		},
+		{
+			name:          "no vote",
+			resp:          append(withMetrics(updateNone, updateNone), findPost()...),
+			expectedDelta: 0,
 		},
 	}
 
@@ -979,7 +1431,7 @@ func TestUnvotePost(t *testing.T) {
 			for _, response := range c.resp {
 				mt.AddMockResponses(response)
 			}
-			posts, err := mockDB.UnvotePost(postID, userID)
+			posts, delta, err := mockDB.UnvotePost(context.Background(), postID, userID)
 
 			if c.expectError {
 				assert.Error(t, err)
@@ -987,7 +1439,684 @@ func TestUnvotePost(t *testing.T) {
 			} else {
 				assert.NoError(t, err)
 				assert.NotNil(t, posts)
+				assert.Equal(t, c.expectedDelta, delta)
+			}
+		})
+	}
+}
+
+func TestVoteComment(t *testing.T) {
+	cases := []struct {
+		name          string
+		vote          int
+		resp          []bson.D
+		expectedDelta int
+		expectedError error
+	}{
+		{
+			name:          "update error",
+			vote:          LIKE,
+			resp:          nil,
+			expectedError: errors.New("mock"),
+		},
+		{
+			name:          "no comment",
+			vote:          LIKE,
+			resp:          []bson.D{updateNone, updateNone},
+			expectedError: myerrors.ErrNoComment,
+		},
+		{
+			name:          "new vote",
+			vote:          LIKE,
+			resp:          append([]bson.D{updateNone, updateMatched, updateModified}, findPost()...),
+			expectedDelta: LIKE,
+		},
+		{
+			name:          "flipped vote",
+			vote:          DISLIKE,
+			resp:          append([]bson.D{updateModified, updateModified}, findPost()...),
+			expectedDelta: 2 * DISLIKE,
+		},
+		{
+			name:          "same vote",
+			vote:          LIKE,
+			resp:          append([]bson.D{updateMatched}, findPost()...),
+			expectedDelta: 0,
+		},
+		{
+			name:          "unvote",
+			vote:          0,
+			resp:          append([]bson.D{updateNone, updateModified, updateModified}, findPost()...),
+			expectedDelta: -DISLIKE,
+		},
+		{
+			name:          "get post error",
+			vote:          0,
+			resp:          []bson.D{updateNone, updateNone},
+			expectedError: errors.New("mock"),
+		},
+	}
+
+	postID := "1"
+	commentID := "1"
+	userID := "1"
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewPostMongoDB(mt.Coll)
+			for _, response := range c.resp {
+				mt.AddMockResponses(response)
+			}
+			post, delta, err := mockDB.VoteComment(context.Background(), postID, commentID, userID, c.vote)
+
+			if c.expectedError != nil {
+				assert.Error(t, err)
+				assert.Nil(t, post)
+				if errors.Is(c.expectedError, myerrors.ErrNoComment) {
+					assert.ErrorIs(t, err, myerrors.ErrNoComment)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.NotNil(t, post)
+				assert.Equal(t, c.expectedDelta, delta)
+			}
+		})
+	}
+}
+
+func TestGetCommentsByUser(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	username := "User"
+
+	mt.Run("aggregate error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		comments, err := mockDB.GetCommentsByUser(context.Background(), username, nil, 10, 0)
+		assert.Error(t, err)
+		assert.Nil(t, comments)
+	})
+
+	mt.Run("decode error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, bson.D{{Key: "comment", Value: 1}}))
+		comments, err := mockDB.GetCommentsByUser(context.Background(), username, nil, 10, 0)
+		assert.Error(t, err)
+		assert.Nil(t, comments)
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch,
+			bson.D{
+				{Key: "postId", Value: "1"},
+				{Key: "title", Value: "title"},
+				{Key: "category", Value: "music"},
+				{Key: "comment", Value: bson.D{{Key: "_id", Value: "2"}, {Key: "body", Value: "text"}}},
+			},
+		))
+		comments, err := mockDB.GetCommentsByUser(context.Background(), username, nil, 0, 5)
+		assert.NoError(t, err)
+		assert.Len(t, comments, 1)
+		assert.Equal(t, "1", comments[0].PostID)
+		assert.Equal(t, "text", comments[0].Comment.Body)
+	})
+
+	mt.Run("hides blocked authors", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch))
+		_, err := mockDB.GetCommentsByUser(context.Background(), username, []string{"3"}, 10, 0)
+		assert.NoError(t, err)
+
+		pipeline := mt.GetStartedEvent().Command.Lookup("pipeline")
+		assert.Equal(t, `["3"]`, pipeline.Array().Index(0).Value().Document().Lookup("$match", "author._id", "$nin").String())
+		assert.Equal(t, `["3"]`, pipeline.Array().Index(2).Value().Document().Lookup("$match", "comments.author._id", "$nin").String())
+	})
+
+	mt.Run("skips held comments", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch))
+		comments, err := mockDB.GetCommentsByUser(context.Background(), username, nil, 10, 0)
+		assert.NoError(t, err)
+		assert.Empty(t, comments)
+
+		match := mt.GetStartedEvent().Command.Lookup("pipeline", "2", "$match")
+		assert.Equal(t, true, match.Document().Lookup("comments.held", "$ne").Boolean())
+	})
+}
+
+func pollPost(closes time.Time) []bson.D {
+	post := bson.D{
+		{Key: "_id", Value: "1"},
+		{Key: "type", Value: posts.TypePoll},
+		{Key: "poll", Value: bson.D{
+			{Key: "options", Value: bson.A{
+				bson.D{{Key: "_id", Value: "0"}, {Key: "text", Value: "a"}},
+				bson.D{{Key: "_id", Value: "1"}, {Key: "text", Value: "b"}},
+			}},
+			{Key: "closes", Value: closes},
+			{Key: "votes", Value: bson.A{bson.D{{Key: "user", Value: "1"}, {Key: "option", Value: "1"}}}},
+		}},
+	}
+	return []bson.D{
+		mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, post),
+		mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+	}
+}
+
+func TestVotePoll(t *testing.T) {
+	now := time.Now()
+	open := pollPost(now.Add(time.Hour))
+
+	cases := []struct {
+		name          string
+		optionID      string
+		resp          []bson.D
+		expectError   bool
+		expectedError error
+	}{
+		{
+			name:        "update error",
+			optionID:    "1",
+			resp:        nil,
+			expectError: true,
+		},
+		{
+			name:     "changed vote",
+			optionID: "1",
+			resp:     append([]bson.D{updateModified}, open...),
+		},
+		{
+			name:     "new vote",
+			optionID: "1",
+			resp:     append([]bson.D{updateNone, updateMatched}, open...),
+		},
+		{
+			name:        "get post error",
+			optionID:    "1",
+			resp:        []bson.D{updateMatched},
+			expectError: true,
+		},
+		{
+			name:          "not a poll",
+			optionID:      "1",
+			resp:          append([]bson.D{updateNone, updateNone}, findPost()...),
+			expectError:   true,
+			expectedError: myerrors.ErrNotPoll,
+		},
+		{
+			name:          "closed",
+			optionID:      "1",
+			resp:          append([]bson.D{updateNone, updateNone}, pollPost(now.Add(-time.Hour))...),
+			expectError:   true,
+			expectedError: myerrors.ErrPollClosed,
+		},
+		{
+			name:          "no option",
+			optionID:      "7",
+			resp:          append([]bson.D{updateNone, updateNone}, open...),
+			expectError:   true,
+			expectedError: myerrors.ErrNoPollOption,
+		},
+	}
+
+	postID := "1"
+	userID := "1"
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewPostMongoDB(mt.Coll)
+			for _, response := range c.resp {
+				mt.AddMockResponses(response)
+			}
+			post, err := mockDB.VotePoll(context.Background(), postID, userID, c.optionID, now)
+
+			if c.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, post)
+				if c.expectedError != nil {
+					assert.ErrorIs(t, err, c.expectedError)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, post.Poll.Options, 2)
+				assert.Len(t, post.Poll.Votes, 1)
+			}
+		})
+	}
+}
+
+func TestVotePollUpdate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("changes only the voter's vote", func(mt *mtest.T) {
+		now := time.Now()
+		mt.AddMockResponses(append([]bson.D{updateModified}, pollPost(now.Add(time.Hour))...)...)
+		_, err := NewPostMongoDB(mt.Coll).VotePoll(context.Background(), "1", "5", "2", now)
+		assert.NoError(t, err)
+
+		started := mt.GetAllStartedEvents()[0]
+		assert.Equal(t, "update", started.CommandName)
+		update := started.Command.Lookup("updates", "0")
+		assert.Equal(t, "2", update.Document().Lookup("u", "$set", "poll.votes.$[v].option").StringValue())
+		assert.Equal(t, "5", update.Document().Lookup("arrayFilters", "0", "v.user").StringValue())
+		filter := update.Document().Lookup("q")
+		assert.Equal(t, "2", filter.Document().Lookup("poll.options", "$elemMatch", "_id").StringValue())
+		assert.Equal(t, "5", filter.Document().Lookup("poll.votes.user").StringValue())
+		_, err = filter.Document().LookupErr("poll.options._id")
+		assert.Error(t, err)
+	})
+}
+
+func draftPost(status string) bson.D {
+	return bson.D{
+		{Key: "_id", Value: "1"},
+		{Key: "title", Value: "title"},
+		{Key: "status", Value: status},
+		{Key: "author", Value: bson.D{{Key: "_id", Value: "2"}, {Key: "username", Value: "User"}}},
+	}
+}
+
+func findAndModify(doc interface{}) bson.D {
+	return bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: doc}}
+}
+
+func TestGetDrafts(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("find error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		_, err := mockDB.GetDrafts(context.Background(), "2")
+		assert.Error(t, err)
+	})
+
+	mt.Run("success", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, draftPost(posts.StatusDraft)),
+			mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+		)
+		drafts, err := mockDB.GetDrafts(context.Background(), "2")
+		assert.NoError(t, err)
+		assert.Len(t, drafts, 1)
+		assert.False(t, drafts[0].IsPublished())
+	})
+}
+
+func TestUpdateDraft(t *testing.T) {
+	published := []bson.D{
+		mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, draftPost("")),
+		mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+	}
+	cases := []struct {
+		name          string
+		userID        string
+		resp          []bson.D
+		expectError   bool
+		expectedError error
+	}{
+		{
+			name:   "success",
+			userID: "2",
+			resp:   []bson.D{findAndModify(draftPost(posts.StatusDraft))},
+		},
+		{
+			name:        "update error",
+			userID:      "2",
+			resp:        []bson.D{{{Key: "ok", Value: 0}}},
+			expectError: true,
+		},
+		{
+			name:          "no post",
+			userID:        "2",
+			resp:          []bson.D{findAndModify(nil), mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch)},
+			expectError:   true,
+			expectedError: myerrors.ErrNoPost,
+		},
+		{
+			name:          "already published",
+			userID:        "2",
+			resp:          append([]bson.D{findAndModify(nil)}, published...),
+			expectError:   true,
+			expectedError: myerrors.ErrNotDraft,
+		},
+		{
+			name:          "other author",
+			userID:        "3",
+			resp:          append([]bson.D{findAndModify(nil)}, published...),
+			expectError:   true,
+			expectedError: myerrors.ErrNoPost,
+		},
+		{
+			name:   "held",
+			userID: "2",
+			resp: []bson.D{
+				findAndModify(nil),
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, draftPost(posts.StatusHeld)),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+			expectError:   true,
+			expectedError: myerrors.ErrHeld,
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewPostMongoDB(mt.Coll)
+			mt.AddMockResponses(c.resp...)
+			post, err := mockDB.UpdateDraft(context.Background(), "1", c.userID, &posts.Post{Title: "title"})
+
+			if c.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, post)
+				if c.expectedError != nil {
+					assert.ErrorIs(t, err, c.expectedError)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, "title", post.Title)
+			}
+		})
+	}
+}
+
+func TestSchedulePost(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("schedule", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(draftPost(posts.StatusScheduled)))
+		at := time.Now().Add(time.Hour)
+		post, err := mockDB.SchedulePost(context.Background(), "1", "2", &at)
+		assert.NoError(t, err)
+		assert.Equal(t, posts.StatusScheduled, post.Status)
+	})
+
+	mt.Run("unschedule", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(draftPost(posts.StatusDraft)))
+		post, err := mockDB.SchedulePost(context.Background(), "1", "2", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, posts.StatusDraft, post.Status)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		_, err := mockDB.SchedulePost(context.Background(), "1", "2", nil)
+		assert.Error(t, err)
+	})
+}
+
+func TestPublishPost(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(draftPost("")))
+		post, err := mockDB.PublishPost(context.Background(), "1", "2", time.Now())
+		assert.NoError(t, err)
+		assert.True(t, post.IsPublished())
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		_, err := mockDB.PublishPost(context.Background(), "1", "2", time.Now())
+		assert.Error(t, err)
+	})
+}
+
+func TestPublishDue(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("due", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(draftPost("")))
+		post, err := mockDB.PublishDue(context.Background(), time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, "1", post.ID)
+	})
+
+	mt.Run("nothing due", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(nil))
+		post, err := mockDB.PublishDue(context.Background(), time.Now())
+		assert.NoError(t, err)
+		assert.Nil(t, post)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		_, err := mockDB.PublishDue(context.Background(), time.Now())
+		assert.Error(t, err)
+	})
+}
+
+func TestSetLocked(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(append(bson.D{{Key: "locked", Value: true}}, postData...)))
+		post, err := mockDB.SetLocked(context.Background(), "1", true)
+		assert.NoError(t, err)
+		assert.True(t, post.Locked)
+	})
+
+	mt.Run("no post", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(nil))
+		_, err := mockDB.SetLocked(context.Background(), "1", true)
+		assert.ErrorIs(t, err, myerrors.ErrNoPost)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		_, err := mockDB.SetLocked(context.Background(), "1", false)
+		assert.Error(t, err)
+	})
+}
+
+func TestSetPinned(t *testing.T) {
+	count := func(n int) []bson.D {
+		return []bson.D{mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, bson.D{{Key: "n", Value: n}})}
+	}
+	pinnedPost := append(bson.D{{Key: "pinned", Value: true}}, postData...)
+
+	cases := []struct {
+		name          string
+		pinned        bool
+		resp          []bson.D
+		expectError   bool
+		expectedError error
+		rolledBack    bool
+	}{
+		{
+			name:   "pin",
+			pinned: true,
+			resp:   append(append(append(findPost(), count(1)...), findAndModify(pinnedPost)), count(2)...),
+		},
+		{
+			name:          "lost race",
+			pinned:        true,
+			resp:          append(append(append(append(findPost(), count(1)...), findAndModify(pinnedPost)), count(3)...), findAndModify(postData)),
+			expectError:   true,
+			expectedError: myerrors.ErrPinLimit,
+			rolledBack:    true,
+		},
+		{
+			name:        "recount error",
+			pinned:      true,
+			resp:        append(append(append(findPost(), count(1)...), findAndModify(pinnedPost)), bson.D{{Key: "ok", Value: 0}}, findAndModify(postData)),
+			expectError: true,
+			rolledBack:  true,
+		},
+		{
+			name:          "limit reached",
+			pinned:        true,
+			resp:          append(findPost(), count(2)...),
+			expectError:   true,
+			expectedError: myerrors.ErrPinLimit,
+		},
+		{
+			name:   "already pinned",
+			pinned: true,
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "posts.post", mtest.FirstBatch, pinnedPost),
+				mtest.CreateCursorResponse(0, "posts.post", mtest.NextBatch),
+			},
+		},
+		{
+			name:          "no post",
+			pinned:        true,
+			resp:          []bson.D{mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch)},
+			expectError:   true,
+			expectedError: myerrors.ErrNoPost,
+		},
+		{
+			name:        "count error",
+			pinned:      true,
+			resp:        append(findPost(), bson.D{{Key: "ok", Value: 0}}),
+			expectError: true,
+		},
+		{
+			name:   "unpin",
+			pinned: false,
+			resp:   []bson.D{findAndModify(postData)},
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewPostMongoDB(mt.Coll)
+			mt.AddMockResponses(c.resp...)
+			post, err := mockDB.SetPinned(context.Background(), "1", c.pinned, posts.DefaultPinLimit)
+
+			if c.expectError {
+				assert.Error(t, err)
+				assert.Nil(t, post)
+				if c.expectedError != nil {
+					assert.ErrorIs(t, err, c.expectedError)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.pinned, post.Pinned)
+			}
+			if c.rolledBack {
+				started := mt.GetAllStartedEvents()
+				last := started[len(started)-1]
+				assert.Equal(t, "findAndModify", last.CommandName)
+				assert.Equal(t, false, last.Command.Lookup("update", "$set", "pinned").Boolean())
 			}
 		})
 	}
 }
+
+func TestSetContentFlags(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	flag := true
+
+	mt.Run("set nsfw", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(append(bson.D{{Key: "nsfw", Value: true}}, postData...)))
+		post, err := mockDB.SetContentFlags(context.Background(), "1", &flag, nil)
+		assert.NoError(t, err)
+		assert.True(t, post.NSFW)
+		assert.False(t, post.Spoiler)
+	})
+
+	mt.Run("no flags returns the post", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findPost()...)
+		post, err := mockDB.SetContentFlags(context.Background(), "1", nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "1", post.ID)
+	})
+
+	mt.Run("no post", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(nil))
+		_, err := mockDB.SetContentFlags(context.Background(), "1", nil, &flag)
+		assert.ErrorIs(t, err, myerrors.ErrNoPost)
+	})
+}
+
+func TestApprovePost(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(postData))
+		post, err := mockDB.ApprovePost(context.Background(), "1", time.Now())
+		assert.NoError(t, err)
+		assert.True(t, post.IsPublished())
+	})
+
+	mt.Run("not held", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(nil))
+		_, err := mockDB.ApprovePost(context.Background(), "1", time.Now())
+		assert.ErrorIs(t, err, myerrors.ErrNotHeld)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		_, err := mockDB.ApprovePost(context.Background(), "1", time.Now())
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, myerrors.ErrNotHeld)
+	})
+}
+
+func TestApproveComment(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(postData))
+		post, err := mockDB.ApproveComment(context.Background(), "1", "2")
+		assert.NoError(t, err)
+		assert.Equal(t, "1", post.ID)
+	})
+
+	mt.Run("not held", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(findAndModify(nil))
+		_, err := mockDB.ApproveComment(context.Background(), "1", "2")
+		assert.ErrorIs(t, err, myerrors.ErrNotHeld)
+	})
+}
+
+func TestLinkPosted(t *testing.T) {
+	count := func(n int) bson.D {
+		return mtest.CreateCursorResponse(0, "posts.post", mtest.FirstBatch, bson.D{{Key: "n", Value: n}})
+	}
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("posted", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(count(1))
+		posted, err := mockDB.LinkPosted(context.Background(), "music", "https://example.com")
+		assert.NoError(t, err)
+		assert.True(t, posted)
+	})
+
+	mt.Run("new", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(count(0))
+		posted, err := mockDB.LinkPosted(context.Background(), "music", "https://example.com")
+		assert.NoError(t, err)
+		assert.False(t, posted)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mockDB := NewPostMongoDB(mt.Coll)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 0}})
+		_, err := mockDB.LinkPosted(context.Background(), "music", "https://example.com")
+		assert.Error(t, err)
+	})
+}
@@ -5,11 +5,13 @@
 package repository
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
-	gomock "github.com/golang/mock/gomock"
 	posts "github.com/KonstantinGalanin/redditclone/internal/posts"
 	user "github.com/KonstantinGalanin/redditclone/internal/user"
+	gomock "github.com/golang/mock/gomock"
 )
 
 // MockPostRepo is a mock of PostRepo interface.
@@ -35,166 +37,440 @@ func (m *MockPostRepo) EXPECT() *MockPostRepoMockRecorder {
 	return m.recorder
 }
 
-// CreateComment mocks base method.
-func (m *MockPostRepo) CreateComment(postID, text string, author *user.User) (*posts.Post, error) {
+// ApproveComment mocks base method.
+func (m *MockPostRepo) ApproveComment(ctx context.Context, postID, commentID string) (*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateComment", postID, text, author)
+	ret := m.ctrl.Call(m, "ApproveComment", ctx, postID, commentID)
 	ret0, _ := ret[0].(*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// ApproveComment indicates an expected call of ApproveComment.
+func (mr *MockPostRepoMockRecorder) ApproveComment(ctx, postID, commentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApproveComment", reflect.TypeOf((*MockPostRepo)(nil).ApproveComment), ctx, postID, commentID)
+}
+
+// ApprovePost mocks base method.
+func (m *MockPostRepo) ApprovePost(ctx context.Context, postID string, now time.Time) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ApprovePost", ctx, postID, now)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ApprovePost indicates an expected call of ApprovePost.
+func (mr *MockPostRepoMockRecorder) ApprovePost(ctx, postID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ApprovePost", reflect.TypeOf((*MockPostRepo)(nil).ApprovePost), ctx, postID, now)
+}
+
+// CreateComment mocks base method.
+func (m *MockPostRepo) CreateComment(ctx context.Context, postID, parentID, text string, author *user.User, opts posts.CommentOptions) (*posts.Post, *posts.Comment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateComment", ctx, postID, parentID, text, author, opts)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(*posts.Comment)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // CreateComment indicates an expected call of CreateComment.
-func (mr *MockPostRepoMockRecorder) CreateComment(postID, text, author interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) CreateComment(ctx, postID, parentID, text, author, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockPostRepo)(nil).CreateComment), postID, text, author)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateComment", reflect.TypeOf((*MockPostRepo)(nil).CreateComment), ctx, postID, parentID, text, author, opts)
 }
 
 // CreatePost mocks base method.
-func (m *MockPostRepo) CreatePost(category, title, typePost, url, text string, author *user.User) (*posts.Post, error) {
+func (m *MockPostRepo) CreatePost(ctx context.Context, post *posts.Post, author *user.User) (*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePost", category, title, typePost, url, text, author)
+	ret := m.ctrl.Call(m, "CreatePost", ctx, post, author)
 	ret0, _ := ret[0].(*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // CreatePost indicates an expected call of CreatePost.
-func (mr *MockPostRepoMockRecorder) CreatePost(category, title, typePost, url, text, author interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) CreatePost(ctx, post, author interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockPostRepo)(nil).CreatePost), category, title, typePost, url, text, author)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePost", reflect.TypeOf((*MockPostRepo)(nil).CreatePost), ctx, post, author)
 }
 
 // DeleteComment mocks base method.
-func (m *MockPostRepo) DeleteComment(postID, commentID string) (*posts.Post, error) {
+func (m *MockPostRepo) DeleteComment(ctx context.Context, postID, commentID, userID string) (*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteComment", postID, commentID)
+	ret := m.ctrl.Call(m, "DeleteComment", ctx, postID, commentID, userID)
 	ret0, _ := ret[0].(*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // DeleteComment indicates an expected call of DeleteComment.
-func (mr *MockPostRepoMockRecorder) DeleteComment(postID, commentID interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) DeleteComment(ctx, postID, commentID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockPostRepo)(nil).DeleteComment), postID, commentID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteComment", reflect.TypeOf((*MockPostRepo)(nil).DeleteComment), ctx, postID, commentID, userID)
 }
 
 // DeletePost mocks base method.
-func (m *MockPostRepo) DeletePost(postID, userID string) error {
+func (m *MockPostRepo) DeletePost(ctx context.Context, postID, userID string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePost", postID, userID)
+	ret := m.ctrl.Call(m, "DeletePost", ctx, postID, userID)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePost indicates an expected call of DeletePost.
-func (mr *MockPostRepoMockRecorder) DeletePost(postID, userID interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) DeletePost(ctx, postID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockPostRepo)(nil).DeletePost), postID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePost", reflect.TypeOf((*MockPostRepo)(nil).DeletePost), ctx, postID, userID)
 }
 
 // DownvotePost mocks base method.
-func (m *MockPostRepo) DownvotePost(postID, userID string) (*posts.Post, error) {
+func (m *MockPostRepo) DownvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DownvotePost", postID, userID)
+	ret := m.ctrl.Call(m, "DownvotePost", ctx, postID, userID)
 	ret0, _ := ret[0].(*posts.Post)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
 }
 
 // DownvotePost indicates an expected call of DownvotePost.
-func (mr *MockPostRepoMockRecorder) DownvotePost(postID, userID interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) DownvotePost(ctx, postID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownvotePost", reflect.TypeOf((*MockPostRepo)(nil).DownvotePost), postID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownvotePost", reflect.TypeOf((*MockPostRepo)(nil).DownvotePost), ctx, postID, userID)
 }
 
 // GetAllPosts mocks base method.
-func (m *MockPostRepo) GetAllPosts() ([]*posts.Post, error) {
+func (m *MockPostRepo) GetAllPosts(ctx context.Context, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllPosts")
+	ret := m.ctrl.Call(m, "GetAllPosts", ctx, hidden, opts)
 	ret0, _ := ret[0].([]*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetAllPosts indicates an expected call of GetAllPosts.
-func (mr *MockPostRepoMockRecorder) GetAllPosts() *gomock.Call {
+func (mr *MockPostRepoMockRecorder) GetAllPosts(ctx, hidden, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostRepo)(nil).GetAllPosts), ctx, hidden, opts)
+}
+
+// GetCommentsByUser mocks base method.
+func (m *MockPostRepo) GetCommentsByUser(ctx context.Context, username string, hidden []string, limit, offset int) ([]*posts.UserComment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCommentsByUser", ctx, username, hidden, limit, offset)
+	ret0, _ := ret[0].([]*posts.UserComment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCommentsByUser indicates an expected call of GetCommentsByUser.
+func (mr *MockPostRepoMockRecorder) GetCommentsByUser(ctx, username, hidden, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCommentsByUser", reflect.TypeOf((*MockPostRepo)(nil).GetCommentsByUser), ctx, username, hidden, limit, offset)
+}
+
+// GetDrafts mocks base method.
+func (m *MockPostRepo) GetDrafts(ctx context.Context, userID string) ([]*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDrafts", ctx, userID)
+	ret0, _ := ret[0].([]*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDrafts indicates an expected call of GetDrafts.
+func (mr *MockPostRepoMockRecorder) GetDrafts(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllPosts", reflect.TypeOf((*MockPostRepo)(nil).GetAllPosts))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDrafts", reflect.TypeOf((*MockPostRepo)(nil).GetDrafts), ctx, userID)
 }
 
 // GetPost mocks base method.
-func (m *MockPostRepo) GetPost(postID string) (*posts.Post, error) {
+func (m *MockPostRepo) GetPost(ctx context.Context, postID string) (*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPost", postID)
+	ret := m.ctrl.Call(m, "GetPost", ctx, postID)
 	ret0, _ := ret[0].(*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPost indicates an expected call of GetPost.
-func (mr *MockPostRepoMockRecorder) GetPost(postID interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) GetPost(ctx, postID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockPostRepo)(nil).GetPost), postID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPost", reflect.TypeOf((*MockPostRepo)(nil).GetPost), ctx, postID)
+}
+
+// GetPostHiding mocks base method.
+func (m *MockPostRepo) GetPostHiding(ctx context.Context, postID string, hidden []string) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostHiding", ctx, postID, hidden)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostHiding indicates an expected call of GetPostHiding.
+func (mr *MockPostRepoMockRecorder) GetPostHiding(ctx, postID, hidden interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostHiding", reflect.TypeOf((*MockPostRepo)(nil).GetPostHiding), ctx, postID, hidden)
+}
+
+// GetPostsByCategories mocks base method.
+func (m *MockPostRepo) GetPostsByCategories(ctx context.Context, categories, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostsByCategories", ctx, categories, hidden, opts)
+	ret0, _ := ret[0].([]*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostsByCategories indicates an expected call of GetPostsByCategories.
+func (mr *MockPostRepoMockRecorder) GetPostsByCategories(ctx, categories, hidden, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsByCategories", reflect.TypeOf((*MockPostRepo)(nil).GetPostsByCategories), ctx, categories, hidden, opts)
 }
 
 // GetPostsByCategory mocks base method.
-func (m *MockPostRepo) GetPostsByCategory(category string) ([]*posts.Post, error) {
+func (m *MockPostRepo) GetPostsByCategory(ctx context.Context, category string, hidden []string, opts *posts.ListOptions) ([]*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPostsByCategory", category)
+	ret := m.ctrl.Call(m, "GetPostsByCategory", ctx, category, hidden, opts)
 	ret0, _ := ret[0].([]*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPostsByCategory indicates an expected call of GetPostsByCategory.
-func (mr *MockPostRepoMockRecorder) GetPostsByCategory(category interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) GetPostsByCategory(ctx, category, hidden, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsByCategory", reflect.TypeOf((*MockPostRepo)(nil).GetPostsByCategory), ctx, category, hidden, opts)
+}
+
+// GetPostsByIDs mocks base method.
+func (m *MockPostRepo) GetPostsByIDs(ctx context.Context, postIDs, hidden []string) ([]*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPostsByIDs", ctx, postIDs, hidden)
+	ret0, _ := ret[0].([]*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPostsByIDs indicates an expected call of GetPostsByIDs.
+func (mr *MockPostRepoMockRecorder) GetPostsByIDs(ctx, postIDs, hidden interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsByCategory", reflect.TypeOf((*MockPostRepo)(nil).GetPostsByCategory), category)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsByIDs", reflect.TypeOf((*MockPostRepo)(nil).GetPostsByIDs), ctx, postIDs, hidden)
 }
 
 // GetPostsByUser mocks base method.
-func (m *MockPostRepo) GetPostsByUser(username string) ([]*posts.Post, error) {
+func (m *MockPostRepo) GetPostsByUser(ctx context.Context, username string, hidden []string) ([]*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPostsByUser", username)
+	ret := m.ctrl.Call(m, "GetPostsByUser", ctx, username, hidden)
 	ret0, _ := ret[0].([]*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPostsByUser indicates an expected call of GetPostsByUser.
-func (mr *MockPostRepoMockRecorder) GetPostsByUser(username interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) GetPostsByUser(ctx, username, hidden interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsByUser", reflect.TypeOf((*MockPostRepo)(nil).GetPostsByUser), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostsByUser", reflect.TypeOf((*MockPostRepo)(nil).GetPostsByUser), ctx, username, hidden)
 }
 
-// UnvotePost mocks base method.
-func (m *MockPostRepo) UnvotePost(postID, userID string) (*posts.Post, error) {
+// PublishDue mocks base method.
+func (m *MockPostRepo) PublishDue(ctx context.Context, now time.Time) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishDue", ctx, now)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishDue indicates an expected call of PublishDue.
+func (mr *MockPostRepoMockRecorder) PublishDue(ctx, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishDue", reflect.TypeOf((*MockPostRepo)(nil).PublishDue), ctx, now)
+}
+
+// PublishPost mocks base method.
+func (m *MockPostRepo) PublishPost(ctx context.Context, postID, userID string, now time.Time) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPost", ctx, postID, userID, now)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishPost indicates an expected call of PublishPost.
+func (mr *MockPostRepoMockRecorder) PublishPost(ctx, postID, userID, now interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPost", reflect.TypeOf((*MockPostRepo)(nil).PublishPost), ctx, postID, userID, now)
+}
+
+// RemoveComment mocks base method.
+func (m *MockPostRepo) RemoveComment(ctx context.Context, postID, commentID string) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveComment", ctx, postID, commentID)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RemoveComment indicates an expected call of RemoveComment.
+func (mr *MockPostRepoMockRecorder) RemoveComment(ctx, postID, commentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveComment", reflect.TypeOf((*MockPostRepo)(nil).RemoveComment), ctx, postID, commentID)
+}
+
+// RemovePost mocks base method.
+func (m *MockPostRepo) RemovePost(ctx context.Context, postID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePost", ctx, postID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePost indicates an expected call of RemovePost.
+func (mr *MockPostRepoMockRecorder) RemovePost(ctx, postID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePost", reflect.TypeOf((*MockPostRepo)(nil).RemovePost), ctx, postID)
+}
+
+// SchedulePost mocks base method.
+func (m *MockPostRepo) SchedulePost(ctx context.Context, postID, userID string, publishAt *time.Time) (*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UnvotePost", postID, userID)
+	ret := m.ctrl.Call(m, "SchedulePost", ctx, postID, userID, publishAt)
 	ret0, _ := ret[0].(*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// SchedulePost indicates an expected call of SchedulePost.
+func (mr *MockPostRepoMockRecorder) SchedulePost(ctx, postID, userID, publishAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SchedulePost", reflect.TypeOf((*MockPostRepo)(nil).SchedulePost), ctx, postID, userID, publishAt)
+}
+
+// SetContentFlags mocks base method.
+func (m *MockPostRepo) SetContentFlags(ctx context.Context, postID string, nsfw, spoiler *bool) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetContentFlags", ctx, postID, nsfw, spoiler)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetContentFlags indicates an expected call of SetContentFlags.
+func (mr *MockPostRepoMockRecorder) SetContentFlags(ctx, postID, nsfw, spoiler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetContentFlags", reflect.TypeOf((*MockPostRepo)(nil).SetContentFlags), ctx, postID, nsfw, spoiler)
+}
+
+// SetLocked mocks base method.
+func (m *MockPostRepo) SetLocked(ctx context.Context, postID string, locked bool) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLocked", ctx, postID, locked)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLocked indicates an expected call of SetLocked.
+func (mr *MockPostRepoMockRecorder) SetLocked(ctx, postID, locked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLocked", reflect.TypeOf((*MockPostRepo)(nil).SetLocked), ctx, postID, locked)
+}
+
+// SetPinned mocks base method.
+func (m *MockPostRepo) SetPinned(ctx context.Context, postID string, pinned bool, limit int) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPinned", ctx, postID, pinned, limit)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetPinned indicates an expected call of SetPinned.
+func (mr *MockPostRepoMockRecorder) SetPinned(ctx, postID, pinned, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPinned", reflect.TypeOf((*MockPostRepo)(nil).SetPinned), ctx, postID, pinned, limit)
+}
+
+// UnvotePost mocks base method.
+func (m *MockPostRepo) UnvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UnvotePost", ctx, postID, userID)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // UnvotePost indicates an expected call of UnvotePost.
-func (mr *MockPostRepoMockRecorder) UnvotePost(postID, userID interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) UnvotePost(ctx, postID, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnvotePost", reflect.TypeOf((*MockPostRepo)(nil).UnvotePost), postID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnvotePost", reflect.TypeOf((*MockPostRepo)(nil).UnvotePost), ctx, postID, userID)
 }
 
-// UpvotePost mocks base method.
-func (m *MockPostRepo) UpvotePost(postID, userID string) (*posts.Post, error) {
+// UpdateDraft mocks base method.
+func (m *MockPostRepo) UpdateDraft(ctx context.Context, postID, userID string, post *posts.Post) (*posts.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpvotePost", postID, userID)
+	ret := m.ctrl.Call(m, "UpdateDraft", ctx, postID, userID, post)
 	ret0, _ := ret[0].(*posts.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// UpdateDraft indicates an expected call of UpdateDraft.
+func (mr *MockPostRepoMockRecorder) UpdateDraft(ctx, postID, userID, post interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDraft", reflect.TypeOf((*MockPostRepo)(nil).UpdateDraft), ctx, postID, userID, post)
+}
+
+// UpvotePost mocks base method.
+func (m *MockPostRepo) UpvotePost(ctx context.Context, postID, userID string) (*posts.Post, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpvotePost", ctx, postID, userID)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // UpvotePost indicates an expected call of UpvotePost.
-func (mr *MockPostRepoMockRecorder) UpvotePost(postID, userID interface{}) *gomock.Call {
+func (mr *MockPostRepoMockRecorder) UpvotePost(ctx, postID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpvotePost", reflect.TypeOf((*MockPostRepo)(nil).UpvotePost), ctx, postID, userID)
+}
+
+// VoteComment mocks base method.
+func (m *MockPostRepo) VoteComment(ctx context.Context, postID, commentID, userID string, vote int) (*posts.Post, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoteComment", ctx, postID, commentID, userID, vote)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// VoteComment indicates an expected call of VoteComment.
+func (mr *MockPostRepoMockRecorder) VoteComment(ctx, postID, commentID, userID, vote interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoteComment", reflect.TypeOf((*MockPostRepo)(nil).VoteComment), ctx, postID, commentID, userID, vote)
+}
+
+// VotePoll mocks base method.
+func (m *MockPostRepo) VotePoll(ctx context.Context, postID, userID, optionID string, now time.Time) (*posts.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VotePoll", ctx, postID, userID, optionID, now)
+	ret0, _ := ret[0].(*posts.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VotePoll indicates an expected call of VotePoll.
+func (mr *MockPostRepoMockRecorder) VotePoll(ctx, postID, userID, optionID, now interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpvotePost", reflect.TypeOf((*MockPostRepo)(nil).UpvotePost), postID, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VotePoll", reflect.TypeOf((*MockPostRepo)(nil).VotePoll), ctx, postID, userID, optionID, now)
 }
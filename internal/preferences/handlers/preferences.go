@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/preferences"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const unauthorizedMsg = "unauthorized"
+
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
+
+func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
+	newError := &ErrorMessage{
+		Message: errBody,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errStatus)
+	if err := json.NewEncoder(w).Encode(newError); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func WriteResponsePreferences(w http.ResponseWriter, prefs *preferences.Preferences, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(prefs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type PreferencesHandler struct {
+	PreferencesRepo preferences.PreferencesRepo
+	UserRepo        user.UserRepo
+}
+
+func (p *PreferencesHandler) getUserFromCtx(r *http.Request) (*user.User, error) {
+	ctx := r.Context()
+	sess, ok := ctx.Value("session").(*session.Session)
+	if !ok || sess == nil {
+		return nil, myerrors.ErrNoAuth
+	}
+
+	user, err := p.UserRepo.GetUserByUsername(r.Context(), sess.Username)
+	if err != nil {
+		return nil, fmt.Errorf("get user from sess: %w", err)
+	}
+	return user, nil
+}
+
+func (p *PreferencesHandler) Get(w http.ResponseWriter, r *http.Request) {
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("get preferences %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	prefs, err := p.PreferencesRepo.Get(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	WriteResponsePreferences(w, prefs, http.StatusOK)
+}
+
+// Update changes the preferences present in the body and keeps the rest.
+func (p *PreferencesHandler) Update(w http.ResponseWriter, r *http.Request) {
+	var data struct {
+		ShowNSFW     *bool `json:"showNsfw"`
+		BlurNSFW     *bool `json:"blurNsfw"`
+		BlurSpoilers *bool `json:"blurSpoilers"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := p.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("update preferences %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	prefs, err := p.PreferencesRepo.Get(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if data.ShowNSFW != nil {
+		prefs.ShowNSFW = *data.ShowNSFW
+	}
+	if data.BlurNSFW != nil {
+		prefs.BlurNSFW = *data.BlurNSFW
+	}
+	if data.BlurSpoilers != nil {
+		prefs.BlurSpoilers = *data.BlurSpoilers
+	}
+
+	if err = p.PreferencesRepo.Update(user.ID, prefs); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	WriteResponsePreferences(w, prefs, http.StatusOK)
+}
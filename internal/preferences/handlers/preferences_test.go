@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/preferences"
+	repositoryPreferences "github.com/KonstantinGalanin/redditclone/internal/preferences/repository"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+const username = "User"
+
+var expectedUser = &user.User{
+	Username: username,
+	Password: "password",
+	ID:       "1",
+}
+
+func newRequest(withSession bool, body string) *http.Request {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(http.MethodGet, "/", reader)
+	if withSession {
+		req = sessiontest.SignIn(req, username)
+	}
+	return req
+}
+
+func TestGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	preferencesRepo := repositoryPreferences.NewMockPreferencesRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &PreferencesHandler{
+		PreferencesRepo: preferencesRepo,
+		UserRepo:        userRepo,
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, ""),
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, ""),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				preferencesRepo.EXPECT().Get(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, ""),
+			expect: func() {
+				defaults := preferences.Default
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				preferencesRepo.EXPECT().Get(expectedUser.ID).Return(&defaults, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			service.Get(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	preferencesRepo := repositoryPreferences.NewMockPreferencesRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &PreferencesHandler{
+		PreferencesRepo: preferencesRepo,
+		UserRepo:        userRepo,
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+		expected   *preferences.Preferences
+	}{
+		{
+			name:       "bad body",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(true, "{"),
+		},
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, `{"showNsfw":true}`),
+		},
+		{
+			name:       "get error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, `{"showNsfw":true}`),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				preferencesRepo.EXPECT().Get(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "update error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, `{"showNsfw":true}`),
+			expect: func() {
+				defaults := preferences.Default
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				preferencesRepo.EXPECT().Get(expectedUser.ID).Return(&defaults, nil)
+				preferencesRepo.EXPECT().Update(expectedUser.ID, gomock.Any()).Return(errors.New("some error"))
+			},
+		},
+		{
+			name:       "partial update keeps the rest",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, `{"showNsfw":true,"blurSpoilers":false}`),
+			expect: func() {
+				defaults := preferences.Default
+				updated := &preferences.Preferences{ShowNSFW: true, BlurNSFW: true, BlurSpoilers: false}
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				preferencesRepo.EXPECT().Get(expectedUser.ID).Return(&defaults, nil)
+				preferencesRepo.EXPECT().Update(expectedUser.ID, updated).Return(nil)
+			},
+			expected: &preferences.Preferences{ShowNSFW: true, BlurNSFW: true, BlurSpoilers: false},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			service.Update(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+			if c.expected != nil {
+				var got preferences.Preferences
+				assert.NoError(t, json.NewDecoder(recorder.Body).Decode(&got))
+				assert.Equal(t, c.expected, &got)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package preferences
+
+// Preferences control how flagged posts are shown to a user.
+type Preferences struct {
+	// ShowNSFW includes NSFW posts in listings.
+	ShowNSFW bool `json:"showNsfw"`
+	// BlurNSFW and BlurSpoilers withhold the content of flagged posts until
+	// the user asks to reveal it.
+	BlurNSFW     bool `json:"blurNsfw"`
+	BlurSpoilers bool `json:"blurSpoilers"`
+}
+
+// Default applies to anonymous users and to users who never saved their
+// preferences.
+var Default = Preferences{
+	ShowNSFW:     false,
+	BlurNSFW:     true,
+	BlurSpoilers: true,
+}
+
+//go:generate mockgen -source=preferences.go -destination=repository/repo_mock.go -package=repository PreferencesRepo
+type PreferencesRepo interface {
+	Get(userID string) (*Preferences, error)
+	Update(userID string, prefs *Preferences) error
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/KonstantinGalanin/redditclone/internal/preferences"
+)
+
+type PreferencesMySQLRepo struct {
+	DB *sql.DB
+}
+
+func NewPreferencesMySQLRepo(db *sql.DB) *PreferencesMySQLRepo {
+	return &PreferencesMySQLRepo{
+		DB: db,
+	}
+}
+
+// Get returns the defaults for users that never saved their preferences.
+func (p *PreferencesMySQLRepo) Get(userID string) (*preferences.Preferences, error) {
+	prefs := &preferences.Preferences{}
+	row := p.DB.QueryRow(GetPreferences, userID)
+	err := row.Scan(&prefs.ShowNSFW, &prefs.BlurNSFW, &prefs.BlurSpoilers)
+	if errors.Is(err, sql.ErrNoRows) {
+		defaults := preferences.Default
+		return &defaults, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mysql get preferences: %w", err)
+	}
+	return prefs, nil
+}
+
+func (p *PreferencesMySQLRepo) Update(userID string, prefs *preferences.Preferences) error {
+	_, err := p.DB.Exec(UpdatePreferences, userID, prefs.ShowNSFW, prefs.BlurNSFW, prefs.BlurSpoilers)
+	if err != nil {
+		return fmt.Errorf("mysql update preferences: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/KonstantinGalanin/redditclone/internal/preferences"
+)
+
+const userID = "1"
+
+func TestNewPreferencesMySQLRepo(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPreferencesMySQLRepo(db)
+
+	assert.NotNil(t, repo)
+	assert.Equal(t, db, repo.DB)
+}
+
+func TestGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPreferencesMySQLRepo(db)
+	columns := []string{"show_nsfw", "blur_nsfw", "blur_spoilers"}
+
+	mock.
+		ExpectQuery("SELECT show_nsfw, blur_nsfw, blur_spoilers FROM user_preferences WHERE user_id = (.+);").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(true, false, true))
+	prefs, err := repo.Get(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, &preferences.Preferences{ShowNSFW: true, BlurNSFW: false, BlurSpoilers: true}, prefs)
+
+	mock.
+		ExpectQuery("SELECT (.+) FROM user_preferences").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(columns))
+	prefs, err = repo.Get(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, preferences.Default, *prefs)
+
+	mock.
+		ExpectQuery("SELECT (.+) FROM user_preferences").
+		WithArgs(userID).
+		WillReturnError(fmt.Errorf("query error"))
+	_, err = repo.Get(userID)
+	assert.EqualError(t, err, "mysql get preferences: query error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewPreferencesMySQLRepo(db)
+	prefs := &preferences.Preferences{ShowNSFW: true, BlurNSFW: true}
+
+	mock.
+		ExpectExec(`INSERT INTO user_preferences (.+) ON DUPLICATE KEY UPDATE show_nsfw = VALUES\(show_nsfw\)`).
+		WithArgs(userID, true, true, false).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Update(userID, prefs))
+
+	mock.
+		ExpectExec(`INSERT INTO user_preferences`).
+		WithArgs(userID, true, true, false).
+		WillReturnError(fmt.Errorf("exec error"))
+	assert.EqualError(t, repo.Update(userID, prefs), "mysql update preferences: exec error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
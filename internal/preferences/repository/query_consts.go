@@ -0,0 +1,7 @@
+package repository
+
+var (
+	GetPreferences    = "SELECT show_nsfw, blur_nsfw, blur_spoilers FROM user_preferences WHERE user_id = ?;"
+	UpdatePreferences = "INSERT INTO user_preferences (user_id, show_nsfw, blur_nsfw, blur_spoilers) VALUES (?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE show_nsfw = VALUES(show_nsfw), blur_nsfw = VALUES(blur_nsfw), blur_spoilers = VALUES(blur_spoilers);"
+)
@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: preferences.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	reflect "reflect"
+
+	preferences "github.com/KonstantinGalanin/redditclone/internal/preferences"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockPreferencesRepo is a mock of PreferencesRepo interface.
+type MockPreferencesRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockPreferencesRepoMockRecorder
+}
+
+// MockPreferencesRepoMockRecorder is the mock recorder for MockPreferencesRepo.
+type MockPreferencesRepoMockRecorder struct {
+	mock *MockPreferencesRepo
+}
+
+// NewMockPreferencesRepo creates a new mock instance.
+func NewMockPreferencesRepo(ctrl *gomock.Controller) *MockPreferencesRepo {
+	mock := &MockPreferencesRepo{ctrl: ctrl}
+	mock.recorder = &MockPreferencesRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPreferencesRepo) EXPECT() *MockPreferencesRepoMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockPreferencesRepo) Get(userID string) (*preferences.Preferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", userID)
+	ret0, _ := ret[0].(*preferences.Preferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockPreferencesRepoMockRecorder) Get(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockPreferencesRepo)(nil).Get), userID)
+}
+
+// Update mocks base method.
+func (m *MockPreferencesRepo) Update(userID string, prefs *preferences.Preferences) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", userID, prefs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockPreferencesRepoMockRecorder) Update(userID, prefs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPreferencesRepo)(nil).Update), userID, prefs)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/blocks"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/profiles"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	fieldUsername = "username"
+	queryLimit    = "limit"
+	queryOffset   = "offset"
+
+	DefaultCommentsLimit = 25
+	MaxCommentsLimit     = 100
+)
+
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
+
+func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
+	newError := &ErrorMessage{
+		Message: errBody,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errStatus)
+	if err := json.NewEncoder(w).Encode(newError); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func WriteErrorProfile(w http.ResponseWriter, err error) {
+	if errors.Is(err, myerrors.ErrNoUser) {
+		WriteErrorMsg(w, myerrors.ErrNoUser.Error(), http.StatusNotFound)
+	} else {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func WriteResponse(w http.ResponseWriter, body interface{}, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type ProfilesHandler struct {
+	UserRepo  user.UserRepo
+	StatsRepo profiles.StatsRepo
+	PostsRepo posts.PostRepo
+	BlockRepo blocks.BlockRepo
+}
+
+// hiddenAuthors returns the IDs of the users the caller blocked, or nil for
+// anonymous callers.
+func (h *ProfilesHandler) hiddenAuthors(r *http.Request) ([]string, error) {
+	sess, ok := r.Context().Value("session").(*session.Session)
+	if !ok || sess == nil || h.BlockRepo == nil {
+		return nil, nil
+	}
+
+	viewer, err := h.UserRepo.GetUserByUsername(r.Context(), sess.Username)
+	if err != nil {
+		return nil, fmt.Errorf("get user from sess: %w", err)
+	}
+	blocked, err := h.BlockRepo.GetBlocked(viewer.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get blocked users: %w", err)
+	}
+	return blocks.IDs(blocked), nil
+}
+
+func (h *ProfilesHandler) Profile(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)[fieldUsername]
+	if username == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyUsername.Error(), http.StatusBadRequest)
+		return
+	}
+
+	profileUser, err := h.UserRepo.GetUserByUsername(r.Context(), username)
+	if err != nil {
+		WriteErrorProfile(w, err)
+		return
+	}
+	stats, err := h.StatsRepo.Get(profileUser.ID)
+	if err != nil {
+		WriteErrorProfile(w, err)
+		return
+	}
+
+	profile := &profiles.Profile{
+		Username:       profileUser.Username,
+		Created:        profileUser.Created,
+		AccountAgeDays: int(time.Since(profileUser.Created).Hours() / 24),
+		Karma:          stats.PostKarma + stats.CommentKarma,
+		Stats:          *stats,
+	}
+	WriteResponse(w, profile, http.StatusOK)
+}
+
+// Comments lists the user's most recent comments, newest first. Comments by
+// or under posts of users the caller blocked are left out.
+func (h *ProfilesHandler) Comments(w http.ResponseWriter, r *http.Request) {
+	username := mux.Vars(r)[fieldUsername]
+	if username == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyUsername.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	limit, err := getNonNegative(query.Get(queryLimit))
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := getNonNegative(query.Get(queryOffset))
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if limit == 0 {
+		limit = DefaultCommentsLimit
+	}
+	if limit > MaxCommentsLimit {
+		limit = MaxCommentsLimit
+	}
+
+	hidden, err := h.hiddenAuthors(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	comments, err := h.PostsRepo.GetCommentsByUser(r.Context(), username, hidden, limit, offset)
+	if err != nil {
+		WriteErrorProfile(w, err)
+		return
+	}
+	for _, item := range comments {
+		item.Comment.Render()
+	}
+	WriteResponse(w, comments, http.StatusOK)
+}
+
+func getNonNegative(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, myerrors.ErrBadPagination
+	}
+	return n, nil
+}
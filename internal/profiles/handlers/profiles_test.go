@@ -0,0 +1,208 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/profiles"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+
+	repositoryBlocks "github.com/KonstantinGalanin/redditclone/internal/blocks/repository"
+	repositoryPosts "github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	repositoryProfiles "github.com/KonstantinGalanin/redditclone/internal/profiles/repository"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+const username = "User"
+
+var profileUser = &user.User{
+	ID:       "1",
+	Username: username,
+	Created:  time.Now().AddDate(0, 0, -10),
+}
+
+type mocks struct {
+	userRepo  *repositoryUser.MockUserRepo
+	statsRepo *repositoryProfiles.MockStatsRepo
+	postsRepo *repositoryPosts.MockPostRepo
+}
+
+func newMockService(ctrl *gomock.Controller) (*ProfilesHandler, *mocks) {
+	m := &mocks{
+		userRepo:  repositoryUser.NewMockUserRepo(ctrl),
+		statsRepo: repositoryProfiles.NewMockStatsRepo(ctrl),
+		postsRepo: repositoryPosts.NewMockPostRepo(ctrl),
+	}
+	return &ProfilesHandler{
+		UserRepo:  m.userRepo,
+		StatsRepo: m.statsRepo,
+		PostsRepo: m.postsRepo,
+	}, m
+}
+
+func newRequest(target string, vars map[string]string) *http.Request {
+	return mux.SetURLVars(httptest.NewRequest(http.MethodGet, target, nil), vars)
+}
+
+func TestProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+	vars := map[string]string{fieldUsername: username}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "empty username",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest("/", nil),
+		},
+		{
+			name:       "no user",
+			statusCode: http.StatusNotFound,
+			req:        newRequest("/", vars),
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(nil, fmt.Errorf("postgres get user: %w", myerrors.ErrNoUser))
+			},
+		},
+		{
+			name:       "stats error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest("/", vars),
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(profileUser, nil)
+				m.statsRepo.EXPECT().Get(profileUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        newRequest("/", vars),
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(profileUser, nil)
+				m.statsRepo.EXPECT().Get(profileUser.ID).Return(&profiles.Stats{PostKarma: 7, CommentKarma: 3, PostCount: 2, CommentCount: 4}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.Profile(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+
+			if c.statusCode == http.StatusOK {
+				profile := &profiles.Profile{}
+				assert.NoError(t, json.NewDecoder(recorder.Body).Decode(profile))
+				assert.Equal(t, username, profile.Username)
+				assert.Equal(t, 10, profile.AccountAgeDays)
+				assert.Equal(t, 10, profile.Karma)
+				assert.Equal(t, 4, profile.CommentCount)
+			}
+		})
+	}
+}
+
+func TestComments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+	vars := map[string]string{fieldUsername: username}
+	comments := []*posts.UserComment{{PostID: "1", Comment: &posts.Comment{ID: "2"}}}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "empty username",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest("/", nil),
+		},
+		{
+			name:       "bad limit",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest("/?limit=x", vars),
+		},
+		{
+			name:       "bad offset",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest("/?offset=-1", vars),
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest("/", vars),
+			expect: func() {
+				m.postsRepo.EXPECT().GetCommentsByUser(gomock.Any(), username, nil, DefaultCommentsLimit, 0).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "limit is capped",
+			statusCode: http.StatusOK,
+			req:        newRequest("/?limit=1000&offset=5", vars),
+			expect: func() {
+				m.postsRepo.EXPECT().GetCommentsByUser(gomock.Any(), username, nil, MaxCommentsLimit, 5).Return(comments, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if c.expect != nil {
+				c.expect()
+			}
+			recorder := httptest.NewRecorder()
+			service.Comments(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestCommentsHidesBlocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+	blockRepo := repositoryBlocks.NewMockBlockRepo(ctrl)
+	service.BlockRepo = blockRepo
+	viewer := &user.User{ID: "2", Username: "viewer"}
+	request := func() *http.Request {
+		return sessiontest.SignIn(newRequest("/", map[string]string{fieldUsername: username}), viewer.Username)
+	}
+
+	m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), viewer.Username).Return(viewer, nil).Times(2)
+	blockRepo.EXPECT().GetBlocked(viewer.ID).Return(nil, errors.New("some error"))
+	recorder := httptest.NewRecorder()
+	service.Comments(recorder, request())
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	blockRepo.EXPECT().GetBlocked(viewer.ID).Return([]*user.User{profileUser}, nil)
+	m.postsRepo.EXPECT().GetCommentsByUser(gomock.Any(), username, []string{profileUser.ID}, DefaultCommentsLimit, 0).Return([]*posts.UserComment{}, nil)
+	recorder = httptest.NewRecorder()
+	service.Comments(recorder, request())
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,65 @@
+package profiles
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/reports"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+// StatsListener applies every post event to the affected user's counters.
+type StatsListener struct {
+	Repo StatsRepo
+}
+
+func (l *StatsListener) Handle(ctx context.Context, event *events.Event) {
+	target, delta := l.delta(event)
+	if target == nil || target.ID == "" || delta == (Stats{}) {
+		return
+	}
+	if err := l.Repo.Add(target.ID, &delta); err != nil {
+		logrus.WithContext(ctx).WithError(err).WithField("type", event.Type).Error("update user stats failed")
+	}
+}
+
+func (l *StatsListener) delta(event *events.Event) (*user.User, Stats) {
+	switch event.Type {
+	case events.PostCreated:
+		if event.Post == nil {
+			return nil, Stats{}
+		}
+		return event.Actor, Stats{PostCount: 1, PostKarma: event.Post.Score}
+	case events.PostDeleted:
+		return event.Actor, Stats{PostCount: -1}
+	case events.CommentCreated:
+		return event.Actor, Stats{CommentCount: 1}
+	case events.CommentDeleted:
+		// A moderator removing the comment is the actor; the author is
+		// the target.
+		if event.Target != nil {
+			return event.Target, Stats{CommentCount: -1}
+		}
+		return event.Actor, Stats{CommentCount: -1}
+	case events.ModeratorAction:
+		// Removed comments are counted from their CommentDeleted event.
+		if event.Action != reports.ActionRemove || event.CommentID != "" {
+			return nil, Stats{}
+		}
+		return event.Target, Stats{PostCount: -1}
+	case events.ScoreChanged:
+		if event.CommentID != "" {
+			if event.Comment == nil {
+				return nil, Stats{}
+			}
+			return event.Comment.Author, Stats{CommentKarma: event.ScoreDelta}
+		}
+		if event.Post == nil {
+			return nil, Stats{}
+		}
+		return event.Post.Author, Stats{PostKarma: event.ScoreDelta}
+	}
+	return nil, Stats{}
+}
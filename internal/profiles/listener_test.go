@@ -0,0 +1,120 @@
+package profiles_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/profiles"
+	"github.com/KonstantinGalanin/redditclone/internal/reports"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+
+	repositoryProfiles "github.com/KonstantinGalanin/redditclone/internal/profiles/repository"
+)
+
+func TestStatsListener(t *testing.T) {
+	author := &user.User{ID: "1", Username: "author"}
+	voter := &user.User{ID: "2", Username: "voter"}
+	post := &posts.Post{ID: "p", Author: author, Score: 1}
+	comment := &posts.Comment{ID: "c", Author: author}
+
+	cases := []struct {
+		name   string
+		event  *events.Event
+		userID string
+		delta  *profiles.Stats
+	}{
+		{
+			name:   "post created",
+			event:  &events.Event{Type: events.PostCreated, Actor: author, Post: post},
+			userID: author.ID,
+			delta:  &profiles.Stats{PostCount: 1, PostKarma: 1},
+		},
+		{
+			name:   "post deleted",
+			event:  &events.Event{Type: events.PostDeleted, Actor: author},
+			userID: author.ID,
+			delta:  &profiles.Stats{PostCount: -1},
+		},
+		{
+			name:   "comment created",
+			event:  &events.Event{Type: events.CommentCreated, Actor: author, Post: post, Comment: comment},
+			userID: author.ID,
+			delta:  &profiles.Stats{CommentCount: 1},
+		},
+		{
+			name:   "comment deleted",
+			event:  &events.Event{Type: events.CommentDeleted, Actor: author, CommentID: "c"},
+			userID: author.ID,
+			delta:  &profiles.Stats{CommentCount: -1},
+		},
+		{
+			name:   "post vote",
+			event:  &events.Event{Type: events.ScoreChanged, Actor: voter, Post: post, ScoreDelta: -2},
+			userID: author.ID,
+			delta:  &profiles.Stats{PostKarma: -2},
+		},
+		{
+			name:   "comment vote",
+			event:  &events.Event{Type: events.ScoreChanged, Actor: voter, Post: post, CommentID: "c", Comment: comment, ScoreDelta: 1},
+			userID: author.ID,
+			delta:  &profiles.Stats{CommentKarma: 1},
+		},
+		{
+			name:   "removed comment",
+			event:  &events.Event{Type: events.CommentDeleted, Actor: voter, CommentID: "c", Target: author},
+			userID: author.ID,
+			delta:  &profiles.Stats{CommentCount: -1},
+		},
+		{
+			name:  "removed comment action",
+			event: &events.Event{Type: events.ModeratorAction, Action: reports.ActionRemove, CommentID: "c", Target: author},
+		},
+		{
+			name:   "removed post",
+			event:  &events.Event{Type: events.ModeratorAction, Action: reports.ActionRemove, Target: author},
+			userID: author.ID,
+			delta:  &profiles.Stats{PostCount: -1},
+		},
+		{
+			name:  "warning",
+			event: &events.Event{Type: events.ModeratorAction, Action: reports.ActionWarn, Target: author},
+		},
+		{
+			name:  "vote without change",
+			event: &events.Event{Type: events.ScoreChanged, Actor: voter, Post: post},
+		},
+		{
+			name:  "comment vote without comment",
+			event: &events.Event{Type: events.ScoreChanged, Actor: voter, CommentID: "c", ScoreDelta: 1},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := repositoryProfiles.NewMockStatsRepo(ctrl)
+			if c.delta != nil {
+				repo.EXPECT().Add(c.userID, c.delta).Return(nil)
+			}
+			listener := &profiles.StatsListener{Repo: repo}
+			listener.Handle(context.Background(), c.event)
+		})
+	}
+
+	t.Run("repo error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		repo := repositoryProfiles.NewMockStatsRepo(ctrl)
+		repo.EXPECT().Add(author.ID, gomock.Any()).Return(errors.New("some error"))
+		listener := &profiles.StatsListener{Repo: repo}
+		listener.Handle(context.Background(), &events.Event{Type: events.PostDeleted, Actor: author})
+	})
+}
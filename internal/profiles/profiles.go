@@ -0,0 +1,28 @@
+package profiles
+
+import (
+	"time"
+)
+
+// Stats are per-user counters kept up to date from post events rather than
+// recomputed from the posts.
+type Stats struct {
+	PostKarma    int `json:"postKarma"`
+	CommentKarma int `json:"commentKarma"`
+	PostCount    int `json:"postCount"`
+	CommentCount int `json:"commentCount"`
+}
+
+type Profile struct {
+	Username       string    `json:"username"`
+	Created        time.Time `json:"created"`
+	AccountAgeDays int       `json:"accountAgeDays"`
+	Karma          int       `json:"karma"`
+	Stats
+}
+
+//go:generate mockgen -source=profiles.go -destination=repository/repo_mock.go -package=repository StatsRepo
+type StatsRepo interface {
+	Add(userID string, delta *Stats) error
+	Get(userID string) (*Stats, error)
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/KonstantinGalanin/redditclone/internal/profiles"
+)
+
+type StatsMySQLRepo struct {
+	DB *sql.DB
+}
+
+func NewStatsMySQLRepo(db *sql.DB) *StatsMySQLRepo {
+	return &StatsMySQLRepo{
+		DB: db,
+	}
+}
+
+// Add applies delta to the user's counters in a single upsert, so concurrent
+// updates never lose increments.
+func (s *StatsMySQLRepo) Add(userID string, delta *profiles.Stats) error {
+	_, err := s.DB.Exec(AddStats, userID, delta.PostKarma, delta.CommentKarma, delta.PostCount, delta.CommentCount)
+	if err != nil {
+		return fmt.Errorf("mysql add user stats: %w", err)
+	}
+	return nil
+}
+
+// Get returns zero stats for users that have no activity yet.
+func (s *StatsMySQLRepo) Get(userID string) (*profiles.Stats, error) {
+	stats := &profiles.Stats{}
+	row := s.DB.QueryRow(GetStats, userID)
+	err := row.Scan(&stats.PostKarma, &stats.CommentKarma, &stats.PostCount, &stats.CommentCount)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("mysql get user stats: %w", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+
+	"github.com/KonstantinGalanin/redditclone/internal/profiles"
+)
+
+const userID = "1"
+
+func TestNewStatsMySQLRepo(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsMySQLRepo(db)
+
+	assert.NotNil(t, repo)
+	assert.Equal(t, db, repo.DB)
+}
+
+func TestAdd(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsMySQLRepo(db)
+	delta := &profiles.Stats{PostKarma: 2, CommentCount: -1}
+
+	mock.
+		ExpectExec(`INSERT INTO user_stats (.+) ON DUPLICATE KEY UPDATE post_karma = post_karma \+ VALUES\(post_karma\)`).
+		WithArgs(userID, 2, 0, 0, -1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Add(userID, delta))
+
+	mock.
+		ExpectExec(`INSERT INTO user_stats`).
+		WithArgs(userID, 2, 0, 0, -1).
+		WillReturnError(fmt.Errorf("exec error"))
+	assert.EqualError(t, repo.Add(userID, delta), "mysql add user stats: exec error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGet(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewStatsMySQLRepo(db)
+	columns := []string{"post_karma", "comment_karma", "post_count", "comment_count"}
+
+	mock.
+		ExpectQuery("SELECT post_karma, comment_karma, post_count, comment_count FROM user_stats WHERE user_id = (.+);").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(10, 3, 2, 5))
+	stats, err := repo.Get(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, &profiles.Stats{PostKarma: 10, CommentKarma: 3, PostCount: 2, CommentCount: 5}, stats)
+
+	mock.
+		ExpectQuery("SELECT (.+) FROM user_stats").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows(columns))
+	stats, err = repo.Get(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, &profiles.Stats{}, stats)
+
+	mock.
+		ExpectQuery("SELECT (.+) FROM user_stats").
+		WithArgs(userID).
+		WillReturnError(fmt.Errorf("query error"))
+	_, err = repo.Get(userID)
+	assert.EqualError(t, err, "mysql get user stats: query error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
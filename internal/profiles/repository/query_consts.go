@@ -0,0 +1,8 @@
+package repository
+
+var (
+	AddStats = "INSERT INTO user_stats (user_id, post_karma, comment_karma, post_count, comment_count) VALUES (?, ?, ?, ?, ?) " +
+		"ON DUPLICATE KEY UPDATE post_karma = post_karma + VALUES(post_karma), comment_karma = comment_karma + VALUES(comment_karma), " +
+		"post_count = post_count + VALUES(post_count), comment_count = comment_count + VALUES(comment_count);"
+	GetStats = "SELECT post_karma, comment_karma, post_count, comment_count FROM user_stats WHERE user_id = ?;"
+)
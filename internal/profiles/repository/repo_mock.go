@@ -0,0 +1,64 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: profiles.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	reflect "reflect"
+
+	profiles "github.com/KonstantinGalanin/redditclone/internal/profiles"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockStatsRepo is a mock of StatsRepo interface.
+type MockStatsRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsRepoMockRecorder
+}
+
+// MockStatsRepoMockRecorder is the mock recorder for MockStatsRepo.
+type MockStatsRepoMockRecorder struct {
+	mock *MockStatsRepo
+}
+
+// NewMockStatsRepo creates a new mock instance.
+func NewMockStatsRepo(ctrl *gomock.Controller) *MockStatsRepo {
+	mock := &MockStatsRepo{ctrl: ctrl}
+	mock.recorder = &MockStatsRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsRepo) EXPECT() *MockStatsRepoMockRecorder {
+	return m.recorder
+}
+
+// Add mocks base method.
+func (m *MockStatsRepo) Add(userID string, delta *profiles.Stats) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Add", userID, delta)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Add indicates an expected call of Add.
+func (mr *MockStatsRepoMockRecorder) Add(userID, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Add", reflect.TypeOf((*MockStatsRepo)(nil).Add), userID, delta)
+}
+
+// Get mocks base method.
+func (m *MockStatsRepo) Get(userID string) (*profiles.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", userID)
+	ret0, _ := ret[0].(*profiles.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStatsRepoMockRecorder) Get(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStatsRepo)(nil).Get), userID)
+}
@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/middleware"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/reports"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	unauthorizedMsg = "unauthorized"
+	fieldPostID     = "id"
+	fieldCommentID  = "commentID"
+	fieldReportID   = "reportID"
+	queryStatus     = "status"
+	queryLimit      = "limit"
+	queryOffset     = "offset"
+
+	DefaultSuspendDays = 7
+)
+
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
+
+func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
+	newError := &ErrorMessage{
+		Message: errBody,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errStatus)
+	if err := json.NewEncoder(w).Encode(newError); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func WriteErrorReport(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, myerrors.ErrNoPost):
+		WriteErrorMsg(w, myerrors.ErrNoPost.Error(), http.StatusNotFound)
+	case errors.Is(err, myerrors.ErrNoComment):
+		WriteErrorMsg(w, myerrors.ErrNoComment.Error(), http.StatusNotFound)
+	case errors.Is(err, myerrors.ErrNoReport):
+		WriteErrorMsg(w, myerrors.ErrNoReport.Error(), http.StatusNotFound)
+	case errors.Is(err, myerrors.ErrNoUser):
+		WriteErrorMsg(w, myerrors.ErrNoUser.Error(), http.StatusNotFound)
+	case errors.Is(err, myerrors.ErrAlreadyReported):
+		WriteErrorMsg(w, myerrors.ErrAlreadyReported.Error(), http.StatusConflict)
+	case errors.Is(err, myerrors.ErrNotHeld):
+		WriteErrorMsg(w, myerrors.ErrNotHeld.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func WriteResponse(w http.ResponseWriter, body interface{}, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type ReportsHandler struct {
+	ReportRepo reports.ReportRepo
+	PostsRepo  posts.PostRepo
+	UserRepo   user.UserRepo
+	Events     events.Publisher
+}
+
+func (h *ReportsHandler) getUserFromCtx(r *http.Request) (*user.User, error) {
+	ctx := r.Context()
+	sess, ok := ctx.Value("session").(*session.Session)
+	if !ok || sess == nil {
+		return nil, myerrors.ErrNoAuth
+	}
+
+	user, err := h.UserRepo.GetUserByUsername(r.Context(), sess.Username)
+	if err != nil {
+		return nil, fmt.Errorf("get user from sess: %w", err)
+	}
+	return user, nil
+}
+
+func (h *ReportsHandler) report(w http.ResponseWriter, r *http.Request) {
+	postID := mux.Vars(r)[fieldPostID]
+	if postID == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyPostID.Error(), http.StatusBadRequest)
+		return
+	}
+	commentID := mux.Vars(r)[fieldCommentID]
+
+	var data struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !reports.Reasons[data.Reason] {
+		WriteErrorMsg(w, myerrors.ErrBadReason.Error(), http.StatusBadRequest)
+		return
+	}
+
+	reporter, err := h.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("report %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	// Content the reporter cannot see is reported as missing, the way
+	// GetPost answers, so reports do not reveal drafts or held posts.
+	post, err := h.PostsRepo.GetPost(r.Context(), postID)
+	if err != nil {
+		WriteErrorReport(w, err)
+		return
+	}
+	if post.Deleted != nil || !post.VisibleTo(reporter.ID) {
+		WriteErrorReport(w, myerrors.ErrNoPost)
+		return
+	}
+	author := post.Author
+	if commentID != "" {
+		comment := findComment(post, commentID)
+		if comment == nil || comment.Deleted != nil || comment.Held {
+			WriteErrorReport(w, myerrors.ErrNoComment)
+			return
+		}
+		author = comment.Author
+	}
+
+	report, err := h.ReportRepo.Report(r.Context(), postID, commentID, author, reporter.ID, data.Reason)
+	if err != nil {
+		WriteErrorReport(w, err)
+		return
+	}
+	WriteResponse(w, report, http.StatusCreated)
+}
+
+func (h *ReportsHandler) ReportPost(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r)
+}
+
+func (h *ReportsHandler) ReportComment(w http.ResponseWriter, r *http.Request) {
+	h.report(w, r)
+}
+
+// Queue lists aggregated reports, most reported first. It defaults to the
+// open ones; ?status=dismissed or ?status=actioned shows handled items.
+func (h *ReportsHandler) Queue(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.RequireModerator(w, r, h.UserRepo); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	status := query.Get(queryStatus)
+	if status == "" {
+		status = reports.StatusOpen
+	}
+	limit, err := getNonNegative(query.Get(queryLimit))
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	offset, err := getNonNegative(query.Get(queryOffset))
+	if err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	queue, err := h.ReportRepo.GetQueue(r.Context(), status, limit, offset)
+	if err != nil {
+		WriteErrorReport(w, err)
+		return
+	}
+	WriteResponse(w, queue, http.StatusOK)
+}
+
+// GetReport returns one item's aggregated reports and its audit trail.
+func (h *ReportsHandler) GetReport(w http.ResponseWriter, r *http.Request) {
+	if _, ok := middleware.RequireModerator(w, r, h.UserRepo); !ok {
+		return
+	}
+
+	report, err := h.ReportRepo.GetReport(r.Context(), mux.Vars(r)[fieldReportID])
+	if err != nil {
+		WriteErrorReport(w, err)
+		return
+	}
+	WriteResponse(w, report, http.StatusOK)
+}
+
+// Act resolves a report. Every action is appended to the report's audit
+// trail, including dismissals.
+func (h *ReportsHandler) Act(w http.ResponseWriter, r *http.Request) {
+	moderator, ok := middleware.RequireModerator(w, r, h.UserRepo)
+	if !ok {
+		return
+	}
+
+	var data struct {
+		Action string `json:"action"`
+		Note   string `json:"note,omitempty"`
+		Days   int    `json:"days,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		WriteErrorMsg(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	report, err := h.ReportRepo.GetReport(r.Context(), mux.Vars(r)[fieldReportID])
+	if err != nil {
+		WriteErrorReport(w, err)
+		return
+	}
+
+	status := reports.StatusActioned
+	// changed announces the approved or removed content to listeners.
+	var changed *events.Event
+	switch data.Action {
+	case reports.ActionDismiss:
+		status = reports.StatusDismissed
+	case reports.ActionApprove:
+		status = reports.StatusDismissed
+		changed, err = h.approve(r.Context(), report)
+	case reports.ActionRemove:
+		if report.CommentID == "" {
+			err = h.PostsRepo.RemovePost(r.Context(), report.PostID)
+		} else {
+			changed, err = h.removeComment(r.Context(), report, moderator)
+		}
+	case reports.ActionWarn:
+	case reports.ActionSuspend:
+		days := data.Days
+		if days <= 0 {
+			days = DefaultSuspendDays
+		}
+		err = h.UserRepo.Suspend(r.Context(), report.Author.ID, time.Now().AddDate(0, 0, days))
+	default:
+		WriteErrorMsg(w, myerrors.ErrBadAction.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		WriteErrorReport(w, err)
+		return
+	}
+
+	report, err = h.ReportRepo.AddAction(r.Context(), report.ID, status, &reports.Action{
+		Type:      data.Action,
+		Moderator: moderator.Username,
+		Note:      data.Note,
+		Created:   time.Now(),
+	})
+	if err != nil {
+		WriteErrorReport(w, err)
+		return
+	}
+	if changed != nil && h.Events != nil {
+		h.Events.Publish(r.Context(), changed)
+	}
+	if status == reports.StatusActioned && h.Events != nil {
+		h.Events.Publish(r.Context(), &events.Event{
+			Type:      events.ModeratorAction,
+			PostID:    report.PostID,
+			CommentID: report.CommentID,
+			Actor:     moderator,
+			Action:    data.Action,
+			Target:    report.Author,
+		})
+	}
+	WriteResponse(w, report, http.StatusOK)
+}
+
+// approve publishes content the filter held for review and returns the
+// event announcing it, as if it had just been created.
+func (h *ReportsHandler) approve(ctx context.Context, report *reports.Report) (*events.Event, error) {
+	if report.CommentID == "" {
+		post, err := h.PostsRepo.ApprovePost(ctx, report.PostID, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		return &events.Event{Type: events.PostCreated, PostID: post.ID, Actor: post.Author, Post: post}, nil
+	}
+
+	post, err := h.PostsRepo.ApproveComment(ctx, report.PostID, report.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	comment := findComment(post, report.CommentID)
+	if comment == nil {
+		return nil, myerrors.ErrNoComment
+	}
+	return &events.Event{
+		Type:      events.CommentCreated,
+		PostID:    post.ID,
+		CommentID: report.CommentID,
+		Actor:     comment.Author,
+		Post:      post,
+		Comment:   comment,
+	}, nil
+}
+
+// removeComment tombstones a reported comment and returns the same event an
+// author's delete publishes, with the moderator as the actor and the author
+// as the target.
+func (h *ReportsHandler) removeComment(ctx context.Context, report *reports.Report, moderator *user.User) (*events.Event, error) {
+	post, err := h.PostsRepo.RemoveComment(ctx, report.PostID, report.CommentID)
+	if err != nil {
+		return nil, err
+	}
+	return &events.Event{
+		Type:      events.CommentDeleted,
+		PostID:    post.ID,
+		CommentID: report.CommentID,
+		Actor:     moderator,
+		Target:    report.Author,
+		Post:      post,
+		Comment:   findComment(post, report.CommentID),
+	}, nil
+}
+
+func getNonNegative(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return 0, myerrors.ErrBadPagination
+	}
+	return n, nil
+}
+
+func findComment(post *posts.Post, commentID string) *posts.Comment {
+	for _, comment := range post.Comments {
+		if comment.ID == commentID {
+			return comment
+		}
+	}
+	return nil
+}
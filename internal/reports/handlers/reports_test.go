@@ -0,0 +1,574 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/reports"
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+
+	repositoryPosts "github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+	repositoryReports "github.com/KonstantinGalanin/redditclone/internal/reports/repository"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+const (
+	username  = "User"
+	postID    = "1"
+	commentID = "2"
+)
+
+var (
+	expectedUser = &user.User{
+		Username: username,
+		ID:       "1",
+	}
+	moderatorUser = &user.User{
+		Username:  username,
+		ID:        "1",
+		Moderator: true,
+	}
+	author = &user.User{
+		Username: "author",
+		ID:       "2",
+	}
+	post = &posts.Post{
+		ID:       postID,
+		Author:   author,
+		Comments: []*posts.Comment{{ID: commentID, Author: author}},
+	}
+)
+
+func newRequest(body string, withSession bool, vars map[string]string) *http.Request {
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", reader)
+	if withSession {
+		req = sessiontest.SignIn(req, username)
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+type mocks struct {
+	reportRepo *repositoryReports.MockReportRepo
+	postsRepo  *repositoryPosts.MockPostRepo
+	userRepo   *repositoryUser.MockUserRepo
+}
+
+func newMockService(ctrl *gomock.Controller) (*ReportsHandler, *mocks) {
+	m := &mocks{
+		reportRepo: repositoryReports.NewMockReportRepo(ctrl),
+		postsRepo:  repositoryPosts.NewMockPostRepo(ctrl),
+		userRepo:   repositoryUser.NewMockUserRepo(ctrl),
+	}
+	return &ReportsHandler{
+		ReportRepo: m.reportRepo,
+		PostsRepo:  m.postsRepo,
+		UserRepo:   m.userRepo,
+	}, m
+}
+
+func TestWriteErrorReport(t *testing.T) {
+	cases := []struct {
+		err        error
+		statusCode int
+	}{
+		{myerrors.ErrNoPost, http.StatusNotFound},
+		{myerrors.ErrNoComment, http.StatusNotFound},
+		{myerrors.ErrNoReport, http.StatusNotFound},
+		{myerrors.ErrNoUser, http.StatusNotFound},
+		{myerrors.ErrAlreadyReported, http.StatusConflict},
+		{errors.New("some error"), http.StatusInternalServerError},
+	}
+	for _, c := range cases {
+		recorder := httptest.NewRecorder()
+		WriteErrorReport(recorder, c.err)
+		assert.Equal(t, c.statusCode, recorder.Code)
+	}
+}
+
+func TestReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+	postVars := map[string]string{fieldPostID: postID}
+	commentVars := map[string]string{fieldPostID: postID, fieldCommentID: commentID}
+	spam := `{"reason":"spam"}`
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+		handler    http.HandlerFunc
+	}{
+		{
+			name:       "empty post id",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(spam, true, nil),
+			handler:    service.ReportPost,
+		},
+		{
+			name:       "bad body",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest("{", true, postVars),
+			handler:    service.ReportPost,
+		},
+		{
+			name:       "bad reason",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(`{"reason":"boring"}`, true, postVars),
+			handler:    service.ReportPost,
+		},
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(spam, false, postVars),
+			handler:    service.ReportPost,
+		},
+		{
+			name:       "no post",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(spam, true, postVars),
+			handler:    service.ReportPost,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(nil, myerrors.ErrNoPost)
+			},
+		},
+		{
+			name:       "no comment",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(spam, true, map[string]string{fieldPostID: postID, fieldCommentID: "missing"}),
+			handler:    service.ReportComment,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(post, nil)
+			},
+		},
+		{
+			name:       "deleted post",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(spam, true, postVars),
+			handler:    service.ReportPost,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(&posts.Post{ID: postID, Author: author, Deleted: &posts.Tombstone{}}, nil)
+			},
+		},
+		{
+			name:       "someone else's draft",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(spam, true, postVars),
+			handler:    service.ReportPost,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(&posts.Post{ID: postID, Author: author, Status: posts.StatusDraft}, nil)
+			},
+		},
+		{
+			name:       "held post",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(spam, true, postVars),
+			handler:    service.ReportPost,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(&posts.Post{ID: postID, Author: author, Status: posts.StatusHeld}, nil)
+			},
+		},
+		{
+			name:       "held comment",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(spam, true, commentVars),
+			handler:    service.ReportComment,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(&posts.Post{
+					ID:       postID,
+					Author:   author,
+					Comments: []*posts.Comment{{ID: commentID, Author: author, Held: true}},
+				}, nil)
+			},
+		},
+		{
+			name:       "deleted comment",
+			statusCode: http.StatusNotFound,
+			req:        newRequest(spam, true, commentVars),
+			handler:    service.ReportComment,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(&posts.Post{
+					ID:       postID,
+					Author:   author,
+					Comments: []*posts.Comment{{ID: commentID, Author: author, Deleted: &posts.Tombstone{}}},
+				}, nil)
+			},
+		},
+		{
+			name:       "already reported",
+			statusCode: http.StatusConflict,
+			req:        newRequest(spam, true, postVars),
+			handler:    service.ReportPost,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(post, nil)
+				m.reportRepo.EXPECT().Report(gomock.Any(), postID, "", author, expectedUser.ID, reports.ReasonSpam).Return(nil, myerrors.ErrAlreadyReported)
+			},
+		},
+		{
+			name:       "report comment success",
+			statusCode: http.StatusCreated,
+			req:        newRequest(spam, true, commentVars),
+			handler:    service.ReportComment,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				m.postsRepo.EXPECT().GetPost(gomock.Any(), postID).Return(post, nil)
+				m.reportRepo.EXPECT().Report(gomock.Any(), postID, commentID, author, expectedUser.ID, reports.ReasonSpam).Return(&reports.Report{}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			c.handler(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestQueue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+
+	withQuery := func(query string) *http.Request {
+		return sessiontest.SignIn(httptest.NewRequest(http.MethodGet, "/"+query, nil), username)
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        httptest.NewRequest(http.MethodGet, "/", nil),
+		},
+		{
+			name:       "not a moderator",
+			statusCode: http.StatusForbidden,
+			req:        withQuery(""),
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+			},
+		},
+		{
+			name:       "bad limit",
+			statusCode: http.StatusBadRequest,
+			req:        withQuery("?limit=x"),
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+			},
+		},
+		{
+			name:       "bad offset",
+			statusCode: http.StatusBadRequest,
+			req:        withQuery("?offset=-3"),
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+			},
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        withQuery(""),
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetQueue(gomock.Any(), reports.StatusOpen, 0, 0).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        withQuery("?status=dismissed&limit=5&offset=10"),
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetQueue(gomock.Any(), reports.StatusDismissed, 5, 10).Return([]*reports.Report{}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			service.Queue(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestGetReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+	vars := map[string]string{fieldReportID: postID}
+
+	t.Run("not a moderator", func(t *testing.T) {
+		m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+		recorder := httptest.NewRecorder()
+		service.GetReport(recorder, newRequest("", true, vars))
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+	})
+
+	t.Run("no report", func(t *testing.T) {
+		m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+		m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(nil, myerrors.ErrNoReport)
+		recorder := httptest.NewRecorder()
+		service.GetReport(recorder, newRequest("", true, vars))
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+		m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(&reports.Report{ID: postID}, nil)
+		recorder := httptest.NewRecorder()
+		service.GetReport(recorder, newRequest("", true, vars))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestAct(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+	bus := events.NewBus()
+	var actions []string
+	var deleted []*events.Event
+	bus.Subscribe(events.ListenerFunc(func(_ context.Context, event *events.Event) {
+		assert.Equal(t, author, event.Target)
+		if event.Type == events.CommentDeleted {
+			deleted = append(deleted, event)
+			return
+		}
+		assert.Equal(t, events.ModeratorAction, event.Type)
+		actions = append(actions, event.Action)
+	}))
+	service.Events = bus
+	vars := map[string]string{fieldReportID: postID}
+	postReport := &reports.Report{ID: postID, PostID: postID, Author: author}
+	commentReport := &reports.Report{ID: reports.ItemID(postID, commentID), PostID: postID, CommentID: commentID, Author: author}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		body       string
+		expect     func()
+	}{
+		{
+			name:       "bad body",
+			statusCode: http.StatusBadRequest,
+			body:       "{",
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+			},
+		},
+		{
+			name:       "no report",
+			statusCode: http.StatusNotFound,
+			body:       `{"action":"dismiss"}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(nil, myerrors.ErrNoReport)
+			},
+		},
+		{
+			name:       "bad action",
+			statusCode: http.StatusBadRequest,
+			body:       `{"action":"ban forever"}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(postReport, nil)
+			},
+		},
+		{
+			name:       "dismiss",
+			statusCode: http.StatusOK,
+			body:       `{"action":"dismiss","note":"fine"}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(postReport, nil)
+				m.reportRepo.EXPECT().AddAction(gomock.Any(), postID, reports.StatusDismissed, gomock.Any()).Return(postReport, nil)
+			},
+		},
+		{
+			name:       "remove post error",
+			statusCode: http.StatusNotFound,
+			body:       `{"action":"remove"}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(postReport, nil)
+				m.postsRepo.EXPECT().RemovePost(gomock.Any(), postID).Return(myerrors.ErrNoPost)
+			},
+		},
+		{
+			name:       "remove comment",
+			statusCode: http.StatusOK,
+			body:       `{"action":"remove"}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(commentReport, nil)
+				m.postsRepo.EXPECT().RemoveComment(gomock.Any(), postID, commentID).Return(post, nil)
+				m.reportRepo.EXPECT().AddAction(gomock.Any(), commentReport.ID, reports.StatusActioned, gomock.Any()).Return(commentReport, nil)
+			},
+		},
+		{
+			name:       "warn",
+			statusCode: http.StatusOK,
+			body:       `{"action":"warn"}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(postReport, nil)
+				m.reportRepo.EXPECT().AddAction(gomock.Any(), postID, reports.StatusActioned, gomock.Any()).Return(postReport, nil)
+			},
+		},
+		{
+			name:       "suspend",
+			statusCode: http.StatusOK,
+			body:       `{"action":"suspend","days":3}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(postReport, nil)
+				m.userRepo.EXPECT().Suspend(gomock.Any(), author.ID, gomock.Any()).Return(nil)
+				m.reportRepo.EXPECT().AddAction(gomock.Any(), postID, reports.StatusActioned, gomock.Any()).Return(postReport, nil)
+			},
+		},
+		{
+			name:       "add action error",
+			statusCode: http.StatusInternalServerError,
+			body:       `{"action":"warn"}`,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(postReport, nil)
+				m.reportRepo.EXPECT().AddAction(gomock.Any(), postID, reports.StatusActioned, gomock.Any()).Return(nil, errors.New("some error"))
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			service.Act(recorder, newRequest(c.body, true, vars))
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+
+	assert.Equal(t, []string{reports.ActionRemove, reports.ActionWarn, reports.ActionSuspend}, actions)
+	if assert.Len(t, deleted, 1) {
+		assert.Equal(t, commentID, deleted[0].CommentID)
+		assert.Equal(t, moderatorUser, deleted[0].Actor)
+		assert.Equal(t, commentID, deleted[0].Comment.ID)
+	}
+}
+
+func TestActApprove(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, m := newMockService(ctrl)
+	bus := events.NewBus()
+	var published []*events.Event
+	bus.Subscribe(events.ListenerFunc(func(_ context.Context, event *events.Event) {
+		published = append(published, event)
+	}))
+	service.Events = bus
+	vars := map[string]string{fieldReportID: postID}
+	postReport := &reports.Report{ID: postID, PostID: postID, Author: author}
+	commentReport := &reports.Report{ID: reports.ItemID(postID, commentID), PostID: postID, CommentID: commentID, Author: author}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		event      string
+	}{
+		{
+			name:       "not held",
+			statusCode: http.StatusConflict,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(postReport, nil)
+				m.postsRepo.EXPECT().ApprovePost(gomock.Any(), postID, gomock.Any()).Return(nil, myerrors.ErrNotHeld)
+			},
+		},
+		{
+			name:       "post",
+			statusCode: http.StatusOK,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(postReport, nil)
+				m.postsRepo.EXPECT().ApprovePost(gomock.Any(), postID, gomock.Any()).Return(post, nil)
+				m.reportRepo.EXPECT().AddAction(gomock.Any(), postID, reports.StatusDismissed, gomock.Any()).Return(postReport, nil)
+			},
+			event: events.PostCreated,
+		},
+		{
+			name:       "comment",
+			statusCode: http.StatusOK,
+			expect: func() {
+				m.userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(moderatorUser, nil)
+				m.reportRepo.EXPECT().GetReport(gomock.Any(), postID).Return(commentReport, nil)
+				m.postsRepo.EXPECT().ApproveComment(gomock.Any(), postID, commentID).Return(post, nil)
+				m.reportRepo.EXPECT().AddAction(gomock.Any(), commentReport.ID, reports.StatusDismissed, gomock.Any()).Return(commentReport, nil)
+			},
+			event: events.CommentCreated,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			published = nil
+			recorder := httptest.NewRecorder()
+			c.expect()
+			service.Act(recorder, newRequest(`{"action":"approve"}`, true, vars))
+			assert.Equal(t, c.statusCode, recorder.Code)
+			if c.event == "" {
+				assert.Empty(t, published)
+				return
+			}
+			assert.Len(t, published, 1)
+			assert.Equal(t, c.event, published[0].Type)
+			assert.Equal(t, author, published[0].Actor)
+		})
+	}
+}
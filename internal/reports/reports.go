@@ -0,0 +1,80 @@
+package reports
+
+import (
+	"context"
+	"time"
+
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	ReasonSpam           = "spam"
+	ReasonAbuse          = "abuse"
+	ReasonHarassment     = "harassment"
+	ReasonMisinformation = "misinformation"
+	ReasonOther          = "other"
+
+	// ReasonFilter is only filed by the content filter, under FilterReporter,
+	// for content it held for review. Users cannot pick it.
+	ReasonFilter   = "filter"
+	FilterReporter = "filter"
+
+	StatusOpen      = "open"
+	StatusDismissed = "dismissed"
+	StatusActioned  = "actioned"
+
+	ActionDismiss = "dismiss"
+	ActionRemove  = "remove"
+	ActionWarn    = "warn"
+	ActionSuspend = "suspend"
+	ActionApprove = "approve"
+)
+
+var Reasons = map[string]bool{
+	ReasonSpam:           true,
+	ReasonAbuse:          true,
+	ReasonHarassment:     true,
+	ReasonMisinformation: true,
+	ReasonOther:          true,
+}
+
+// Report aggregates every report filed against one post or comment, together
+// with the moderation actions taken on it.
+type Report struct {
+	ID        string         `json:"id" bson:"_id"`
+	PostID    string         `json:"postId" bson:"post"`
+	CommentID string         `json:"commentId,omitempty" bson:"comment,omitempty"`
+	Author    *user.User     `json:"author" bson:"author"`
+	Reasons   map[string]int `json:"reasons" bson:"reasons"`
+	Reporters []string       `json:"-" bson:"reporters"`
+	Count     int            `json:"count" bson:"count"`
+	Status    string         `json:"status" bson:"status"`
+	Created   time.Time      `json:"created" bson:"created"`
+	Updated   time.Time      `json:"updated" bson:"updated"`
+	Actions   []*Action      `json:"actions" bson:"actions"`
+	Filter    []string       `json:"filter,omitempty" bson:"filter,omitempty"`
+	Excerpt   string         `json:"excerpt,omitempty" bson:"excerpt,omitempty"`
+}
+
+type Action struct {
+	Type      string    `json:"type" bson:"type"`
+	Moderator string    `json:"moderator" bson:"moderator"`
+	Note      string    `json:"note,omitempty" bson:"note,omitempty"`
+	Created   time.Time `json:"created" bson:"created"`
+}
+
+func ItemID(postID, commentID string) string {
+	if commentID == "" {
+		return postID
+	}
+	return postID + ":" + commentID
+}
+
+//go:generate mockgen -source=reports.go -destination=repository/repo_mock.go -package=repository ReportRepo
+type ReportRepo interface {
+	Report(ctx context.Context, postID, commentID string, author *user.User, reporterID, reason string) (*Report, error)
+	Hold(ctx context.Context, postID, commentID string, author *user.User, reasons []string, excerpt string) (*Report, error)
+	GetQueue(ctx context.Context, status string, limit, offset int) ([]*Report, error)
+	GetReport(ctx context.Context, reportID string) (*Report, error)
+	AddAction(ctx context.Context, reportID, status string, action *Action) (*Report, error)
+}
@@ -0,0 +1,155 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/reports"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	TimeoutVal = 10
+)
+
+type ReportMongoDB struct {
+	db *mongo.Collection
+}
+
+func NewReportMongoDB(db *mongo.Collection) *ReportMongoDB {
+	return &ReportMongoDB{
+		db: db,
+	}
+}
+
+func (rp *ReportMongoDB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, TimeoutVal*time.Second)
+}
+
+// Report files a report against an item. Each user counts once per item: a
+// repeated report does not match the filter, so the upsert collides with the
+// existing document and is rejected as a duplicate. A new report reopens an
+// item that moderators have already handled.
+func (rp *ReportMongoDB) Report(ctx context.Context, postID, commentID string, author *user.User, reporterID, reason string) (*reports.Report, error) {
+	report, err := rp.file(ctx, postID, commentID, author, reporterID, reason, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("mongodb report: %w", err)
+	}
+	return report, nil
+}
+
+// Hold files the report of the content filter for an item it held back. It
+// counts as one report by FilterReporter and keeps the filter's reasons and
+// an excerpt, since moderators cannot see held content anywhere else.
+func (rp *ReportMongoDB) Hold(ctx context.Context, postID, commentID string, author *user.User, reasons []string, excerpt string) (*reports.Report, error) {
+	set := bson.M{"filter": reasons, "excerpt": excerpt}
+	report, err := rp.file(ctx, postID, commentID, author, reports.FilterReporter, reports.ReasonFilter, set)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb hold: %w", err)
+	}
+	return report, nil
+}
+
+// file upserts the item's report and counts the reporter in. set carries
+// extra fields to store with it.
+func (rp *ReportMongoDB) file(ctx context.Context, postID, commentID string, author *user.User, reporterID, reason string, set bson.M) (*reports.Report, error) {
+	now := time.Now()
+	insert := bson.M{
+		"post":    postID,
+		"author":  author,
+		"created": now,
+		"actions": []*reports.Action{},
+	}
+	if commentID != "" {
+		insert["comment"] = commentID
+	}
+
+	set["status"] = reports.StatusOpen
+	set["updated"] = now
+
+	filter := bson.M{
+		"_id":       reports.ItemID(postID, commentID),
+		"reporters": bson.M{"$ne": reporterID},
+	}
+	update := bson.M{
+		"$setOnInsert": insert,
+		"$set":         set,
+		"$inc":         bson.M{"count": 1, "reasons." + reason: 1},
+		"$push":        bson.M{"reporters": reporterID},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var report *reports.Report
+	ctx, cancel := rp.withTimeout(ctx)
+	defer cancel()
+	err := rp.db.FindOneAndUpdate(ctx, filter, update, opts).Decode(&report)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, myerrors.ErrAlreadyReported
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+func (rp *ReportMongoDB) GetQueue(ctx context.Context, status string, limit, offset int) ([]*reports.Report, error) {
+	queue := []*reports.Report{}
+	filter := bson.M{"status": status}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "count", Value: -1}, {Key: "updated", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	ctx, cancel := rp.withTimeout(ctx)
+	defer cancel()
+	c, err := rp.db.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get report queue: %w", err)
+	}
+	if err = c.All(ctx, &queue); err != nil {
+		return nil, fmt.Errorf("mongodb get report queue: %w", err)
+	}
+	return queue, nil
+}
+
+func (rp *ReportMongoDB) GetReport(ctx context.Context, reportID string) (*reports.Report, error) {
+	var report *reports.Report
+	ctx, cancel := rp.withTimeout(ctx)
+	defer cancel()
+	err := rp.db.FindOne(ctx, bson.M{"_id": reportID}).Decode(&report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("mongodb get report: %w", myerrors.ErrNoReport)
+		}
+		return nil, fmt.Errorf("mongodb get report: %w", err)
+	}
+	return report, nil
+}
+
+func (rp *ReportMongoDB) AddAction(ctx context.Context, reportID, status string, action *reports.Action) (*reports.Report, error) {
+	filter := bson.M{"_id": reportID}
+	update := bson.M{
+		"$set":  bson.M{"status": status, "updated": action.Created},
+		"$push": bson.M{"actions": action},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var report *reports.Report
+	ctx, cancel := rp.withTimeout(ctx)
+	defer cancel()
+	err := rp.db.FindOneAndUpdate(ctx, filter, update, opts).Decode(&report)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, fmt.Errorf("mongodb add report action: %w", myerrors.ErrNoReport)
+		}
+		return nil, fmt.Errorf("mongodb add report action: %w", err)
+	}
+	return report, nil
+}
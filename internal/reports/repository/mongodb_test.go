@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/reports"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	postID     = "1"
+	commentID  = "2"
+	reporterID = "3"
+)
+
+var author = &user.User{
+	Username: "User",
+	ID:       "4",
+}
+
+var reportData = bson.D{
+	{Key: "_id", Value: postID},
+	{Key: "post", Value: postID},
+	{Key: "author", Value: bson.D{{Key: "username", Value: "User"}, {Key: "_id", Value: "4"}}},
+	{Key: "reasons", Value: bson.D{{Key: reports.ReasonSpam, Value: 2}}},
+	{Key: "reporters", Value: bson.A{reporterID, "5"}},
+	{Key: "count", Value: 2},
+	{Key: "status", Value: reports.StatusOpen},
+	{Key: "created", Value: time.Now()},
+	{Key: "updated", Value: time.Now()},
+	{Key: "actions", Value: bson.A{}},
+}
+
+func TestNewReportMongoDB(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("test", func(mt *mtest.T) {
+		assert.NotNil(t, NewReportMongoDB(mt.Coll))
+	})
+}
+
+func TestReport(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: reportData}})
+		report, err := NewReportMongoDB(mt.Coll).Report(context.Background(), postID, "", author, reporterID, reports.ReasonSpam)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, report.Count)
+		assert.Equal(t, 2, report.Reasons[reports.ReasonSpam])
+	})
+
+	mt.Run("already reported", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11000, Message: "duplicate key error"}))
+		report, err := NewReportMongoDB(mt.Coll).Report(context.Background(), postID, commentID, author, reporterID, reports.ReasonSpam)
+		assert.ErrorIs(t, err, myerrors.ErrAlreadyReported)
+		assert.Nil(t, report)
+	})
+
+	mt.Run("update error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		report, err := NewReportMongoDB(mt.Coll).Report(context.Background(), postID, "", author, reporterID, reports.ReasonSpam)
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, myerrors.ErrAlreadyReported)
+		assert.Nil(t, report)
+	})
+}
+
+func TestHold(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		held := append(bson.D{
+			{Key: "filter", Value: bson.A{"blocked word"}},
+			{Key: "excerpt", Value: "spam"},
+		}, reportData...)
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: held}})
+		report, err := NewReportMongoDB(mt.Coll).Hold(context.Background(), postID, "", author, []string{"blocked word"}, "spam")
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"blocked word"}, report.Filter)
+		assert.Equal(t, "spam", report.Excerpt)
+	})
+
+	mt.Run("error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		_, err := NewReportMongoDB(mt.Coll).Hold(context.Background(), postID, commentID, author, nil, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestGetQueue(t *testing.T) {
+	cases := []struct {
+		name          string
+		resp          []bson.D
+		expectedError bool
+	}{
+		{
+			name:          "find error",
+			resp:          nil,
+			expectedError: true,
+		},
+		{
+			name: "cursor error",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "reddit.reports", mtest.FirstBatch, bson.D{{Key: "count", Value: "many"}}),
+				mtest.CreateCursorResponse(0, "reddit.reports", mtest.NextBatch),
+			},
+			expectedError: true,
+		},
+		{
+			name: "success",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "reddit.reports", mtest.FirstBatch, reportData),
+				mtest.CreateCursorResponse(0, "reddit.reports", mtest.NextBatch),
+			},
+			expectedError: false,
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewReportMongoDB(mt.Coll)
+			for _, response := range c.resp {
+				mt.AddMockResponses(response)
+			}
+			queue, err := mockDB.GetQueue(context.Background(), reports.StatusOpen, 10, 0)
+			if c.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, queue)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, queue, 1)
+			}
+		})
+	}
+}
+
+func TestGetReport(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, "reddit.reports", mtest.FirstBatch, reportData),
+			mtest.CreateCursorResponse(0, "reddit.reports", mtest.NextBatch),
+		)
+		report, err := NewReportMongoDB(mt.Coll).GetReport(context.Background(), postID)
+		assert.NoError(t, err)
+		assert.Equal(t, postID, report.PostID)
+	})
+
+	mt.Run("no report", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, "reddit.reports", mtest.FirstBatch),
+		)
+		report, err := NewReportMongoDB(mt.Coll).GetReport(context.Background(), postID)
+		assert.ErrorIs(t, err, myerrors.ErrNoReport)
+		assert.Nil(t, report)
+	})
+
+	mt.Run("find error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		report, err := NewReportMongoDB(mt.Coll).GetReport(context.Background(), postID)
+		assert.Error(t, err)
+		assert.Nil(t, report)
+	})
+}
+
+func TestAddAction(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	action := &reports.Action{
+		Type:      reports.ActionWarn,
+		Moderator: "moderator",
+		Created:   time.Now(),
+	}
+
+	mt.Run("success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: reportData}})
+		report, err := NewReportMongoDB(mt.Coll).AddAction(context.Background(), postID, reports.StatusActioned, action)
+		assert.NoError(t, err)
+		assert.NotNil(t, report)
+	})
+
+	mt.Run("no report", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "value", Value: nil}})
+		report, err := NewReportMongoDB(mt.Coll).AddAction(context.Background(), postID, reports.StatusActioned, action)
+		assert.ErrorIs(t, err, myerrors.ErrNoReport)
+		assert.Nil(t, report)
+	})
+
+	mt.Run("update error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		report, err := NewReportMongoDB(mt.Coll).AddAction(context.Background(), postID, reports.StatusActioned, action)
+		assert.Error(t, err)
+		assert.Nil(t, report)
+	})
+}
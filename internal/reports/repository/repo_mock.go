@@ -0,0 +1,112 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: reports.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	reports "github.com/KonstantinGalanin/redditclone/internal/reports"
+	user "github.com/KonstantinGalanin/redditclone/internal/user"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockReportRepo is a mock of ReportRepo interface.
+type MockReportRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportRepoMockRecorder
+}
+
+// MockReportRepoMockRecorder is the mock recorder for MockReportRepo.
+type MockReportRepoMockRecorder struct {
+	mock *MockReportRepo
+}
+
+// NewMockReportRepo creates a new mock instance.
+func NewMockReportRepo(ctrl *gomock.Controller) *MockReportRepo {
+	mock := &MockReportRepo{ctrl: ctrl}
+	mock.recorder = &MockReportRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportRepo) EXPECT() *MockReportRepoMockRecorder {
+	return m.recorder
+}
+
+// AddAction mocks base method.
+func (m *MockReportRepo) AddAction(ctx context.Context, reportID, status string, action *reports.Action) (*reports.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddAction", ctx, reportID, status, action)
+	ret0, _ := ret[0].(*reports.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddAction indicates an expected call of AddAction.
+func (mr *MockReportRepoMockRecorder) AddAction(ctx, reportID, status, action interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAction", reflect.TypeOf((*MockReportRepo)(nil).AddAction), ctx, reportID, status, action)
+}
+
+// GetQueue mocks base method.
+func (m *MockReportRepo) GetQueue(ctx context.Context, status string, limit, offset int) ([]*reports.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQueue", ctx, status, limit, offset)
+	ret0, _ := ret[0].([]*reports.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQueue indicates an expected call of GetQueue.
+func (mr *MockReportRepoMockRecorder) GetQueue(ctx, status, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQueue", reflect.TypeOf((*MockReportRepo)(nil).GetQueue), ctx, status, limit, offset)
+}
+
+// GetReport mocks base method.
+func (m *MockReportRepo) GetReport(ctx context.Context, reportID string) (*reports.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReport", ctx, reportID)
+	ret0, _ := ret[0].(*reports.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReport indicates an expected call of GetReport.
+func (mr *MockReportRepoMockRecorder) GetReport(ctx, reportID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReport", reflect.TypeOf((*MockReportRepo)(nil).GetReport), ctx, reportID)
+}
+
+// Hold mocks base method.
+func (m *MockReportRepo) Hold(ctx context.Context, postID, commentID string, author *user.User, reasons []string, excerpt string) (*reports.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Hold", ctx, postID, commentID, author, reasons, excerpt)
+	ret0, _ := ret[0].(*reports.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Hold indicates an expected call of Hold.
+func (mr *MockReportRepoMockRecorder) Hold(ctx, postID, commentID, author, reasons, excerpt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hold", reflect.TypeOf((*MockReportRepo)(nil).Hold), ctx, postID, commentID, author, reasons, excerpt)
+}
+
+// Report mocks base method.
+func (m *MockReportRepo) Report(ctx context.Context, postID, commentID string, author *user.User, reporterID, reason string) (*reports.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Report", ctx, postID, commentID, author, reporterID, reason)
+	ret0, _ := ret[0].(*reports.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Report indicates an expected call of Report.
+func (mr *MockReportRepoMockRecorder) Report(ctx, postID, commentID, author, reporterID, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Report", reflect.TypeOf((*MockReportRepo)(nil).Report), ctx, postID, commentID, author, reporterID, reason)
+}
@@ -0,0 +1,67 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultPeriod   = 30 * 24 * time.Hour
+	DefaultInterval = time.Hour
+)
+
+type Purger interface {
+	PurgeDeleted(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Job periodically hard-deletes content that has been tombstoned for longer
+// than Period.
+type Job struct {
+	Purger   Purger
+	Period   time.Duration
+	Interval time.Duration
+}
+
+func NewJob(purger Purger, period, interval time.Duration) *Job {
+	if period <= 0 {
+		period = DefaultPeriod
+	}
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Job{
+		Purger:   purger,
+		Period:   period,
+		Interval: interval,
+	}
+}
+
+func (j *Job) RunOnce(ctx context.Context, now time.Time) (int64, error) {
+	return j.Purger.PurgeDeleted(ctx, now.Add(-j.Period))
+}
+
+// Run purges once on start and then every Interval until ctx is done.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := j.RunOnce(ctx, time.Now())
+		if err != nil {
+			logrus.WithError(err).Error("retention purge failed")
+		} else if purged > 0 {
+			logrus.WithFields(logrus.Fields{
+				"type":   "RETENTION",
+				"purged": purged,
+			}).Info("purged deleted content")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
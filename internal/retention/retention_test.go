@@ -0,0 +1,75 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePurger struct {
+	mu     sync.Mutex
+	before []time.Time
+	err    error
+}
+
+func (f *fakePurger) PurgeDeleted(ctx context.Context, before time.Time) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.before = append(f.before, before)
+	return int64(len(f.before)), f.err
+}
+
+func (f *fakePurger) calls() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.before)
+}
+
+func TestNewJobDefaults(t *testing.T) {
+	job := NewJob(&fakePurger{}, 0, 0)
+	assert.Equal(t, DefaultPeriod, job.Period)
+	assert.Equal(t, DefaultInterval, job.Interval)
+
+	job = NewJob(&fakePurger{}, time.Hour, time.Minute)
+	assert.Equal(t, time.Hour, job.Period)
+	assert.Equal(t, time.Minute, job.Interval)
+}
+
+func TestRunOnce(t *testing.T) {
+	purger := &fakePurger{}
+	job := NewJob(purger, 24*time.Hour, time.Minute)
+	now := time.Now()
+
+	purged, err := job.RunOnce(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), purged)
+	assert.Equal(t, now.Add(-24*time.Hour), purger.before[0])
+
+	purger.err = errors.New("some error")
+	_, err = job.RunOnce(context.Background(), now)
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	purger := &fakePurger{err: errors.New("some error")}
+	job := NewJob(purger, time.Hour, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		job.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return purger.calls() >= 2 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job did not stop after cancel")
+	}
+}
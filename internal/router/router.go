@@ -1,57 +1,147 @@
 package router
 
 import (
-	"html/template"
 	"net/http"
 
+	"github.com/KonstantinGalanin/redditclone/internal/metrics"
 	"github.com/KonstantinGalanin/redditclone/internal/middleware"
 	"github.com/KonstantinGalanin/redditclone/internal/session"
 	"github.com/gorilla/mux"
 
+	blobsHandlers "github.com/KonstantinGalanin/redditclone/internal/blobs/handlers"
+	blocksHandlers "github.com/KonstantinGalanin/redditclone/internal/blocks/handlers"
+	feedsHandlers "github.com/KonstantinGalanin/redditclone/internal/feeds/handlers"
+	healthHandlers "github.com/KonstantinGalanin/redditclone/internal/health/handlers"
+	liveHandlers "github.com/KonstantinGalanin/redditclone/internal/live/handlers"
+	messagesHandlers "github.com/KonstantinGalanin/redditclone/internal/messages/handlers"
+	modlogHandlers "github.com/KonstantinGalanin/redditclone/internal/modlog/handlers"
+	notificationsHandlers "github.com/KonstantinGalanin/redditclone/internal/notifications/handlers"
 	postsHandlers "github.com/KonstantinGalanin/redditclone/internal/posts/handlers"
+	preferencesHandlers "github.com/KonstantinGalanin/redditclone/internal/preferences/handlers"
+	profilesHandlers "github.com/KonstantinGalanin/redditclone/internal/profiles/handlers"
+	reportsHandlers "github.com/KonstantinGalanin/redditclone/internal/reports/handlers"
+	shellHandlers "github.com/KonstantinGalanin/redditclone/internal/shell/handlers"
+	subscriptionsHandlers "github.com/KonstantinGalanin/redditclone/internal/subscriptions/handlers"
 	userHandlers "github.com/KonstantinGalanin/redditclone/internal/user/handlers"
 )
 
-func renderStatic(w http.ResponseWriter, r *http.Request) {
-	tmpl := template.Must(template.ParseFiles("static/html/index.html"))
-	if err := tmpl.Execute(w, nil); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
-}
-
 func NewRouter(
 	userHandler userHandlers.UserHandler,
 	postsHandler postsHandlers.PostsHandler,
+	subscriptionsHandler subscriptionsHandlers.SubscriptionsHandler,
+	reportsHandler reportsHandlers.ReportsHandler,
+	notificationsHandler notificationsHandlers.NotificationsHandler,
+	liveHandler liveHandlers.LiveHandler,
+	profilesHandler profilesHandlers.ProfilesHandler,
+	mediaHandler blobsHandlers.MediaHandler,
+	moderationHandler modlogHandlers.ModerationHandler,
+	preferencesHandler preferencesHandlers.PreferencesHandler,
+	blocksHandler blocksHandlers.BlocksHandler,
+	messagesHandler messagesHandlers.MessagesHandler,
+	feedsHandler feedsHandlers.FeedsHandler,
+	shellHandler shellHandlers.ShellHandler,
+	healthHandler *healthHandlers.HealthHandler,
 	sessionManager session.SessionManager,
 
 ) http.Handler {
 	publicRouter := mux.NewRouter()
+	// privateRouter rejects anonymous callers. optionalRouter serves reads
+	// that work anonymously and are personalized when a session is present,
+	// like the saved flags on posts.
 	privateRouter := publicRouter.NewRoute().Subrouter()
+	optionalRouter := publicRouter.NewRoute().Subrouter()
 
 	staticHandler := http.StripPrefix("/static/", http.FileServer(http.Dir("./static/")))
 	publicRouter.PathPrefix("/static/").Handler(staticHandler)
 
+	publicRouter.HandleFunc("/healthz", healthHandler.Live).Methods(http.MethodGet, http.MethodHead)
+	publicRouter.HandleFunc("/readyz", healthHandler.Ready).Methods(http.MethodGet, http.MethodHead)
+	publicRouter.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
+
 	publicRouter.HandleFunc("/api/register", userHandler.Signup).Methods(http.MethodPost)
 	publicRouter.HandleFunc("/api/login", userHandler.Login).Methods(http.MethodPost)
 
 	privateRouter.HandleFunc("/api/posts", postsHandler.CreatePost).Methods(http.MethodPost)
-	publicRouter.HandleFunc("/api/posts/", postsHandler.GetAll).Methods(http.MethodGet)
-	publicRouter.HandleFunc("/api/posts/{category}", postsHandler.GetByCategory).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/posts/image", postsHandler.CreateImagePost).Methods(http.MethodPost)
+	optionalRouter.HandleFunc("/api/posts/", postsHandler.GetAll).Methods(http.MethodGet)
+	optionalRouter.HandleFunc("/api/posts/{category}", postsHandler.GetByCategory).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/feed", postsHandler.Feed).Methods(http.MethodGet)
 
-	publicRouter.HandleFunc("/api/post/{id}", postsHandler.GetPost).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/subscriptions", subscriptionsHandler.List).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/subscriptions/{category}", subscriptionsHandler.Subscribe).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/subscriptions/{category}", subscriptionsHandler.Unsubscribe).Methods(http.MethodDelete)
+
+	optionalRouter.HandleFunc("/api/post/{id}", postsHandler.GetPost).Methods(http.MethodGet)
 	privateRouter.HandleFunc("/api/post/{id}", postsHandler.CreateComment).Methods(http.MethodPost)
 	privateRouter.HandleFunc("/api/post/{id}", postsHandler.DeletePost).Methods(http.MethodDelete)
 	privateRouter.HandleFunc("/api/post/{id}/{commentID}", postsHandler.DeleteComment).Methods(http.MethodDelete)
 	privateRouter.HandleFunc("/api/post/{id}/upvote", postsHandler.UpvotePost).Methods(http.MethodGet)
 	privateRouter.HandleFunc("/api/post/{id}/unvote", postsHandler.UnvotePost).Methods(http.MethodGet)
 	privateRouter.HandleFunc("/api/post/{id}/downvote", postsHandler.DownvotePost).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/post/{id}/{commentID}/upvote", postsHandler.UpvoteComment).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/post/{id}/{commentID}/unvote", postsHandler.UnvoteComment).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/post/{id}/{commentID}/downvote", postsHandler.DownvoteComment).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/post/{id}/poll/vote", postsHandler.VotePoll).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/draft", postsHandler.UpdateDraft).Methods(http.MethodPut)
+	privateRouter.HandleFunc("/api/post/{id}/publish", postsHandler.PublishPost).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/unschedule", postsHandler.UnschedulePost).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/flags", postsHandler.SetFlags).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/save", postsHandler.SavePost).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/unsave", postsHandler.UnsavePost).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/{commentID}/save", postsHandler.SaveComment).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/{commentID}/unsave", postsHandler.UnsaveComment).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/report", reportsHandler.ReportPost).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/post/{id}/{commentID}/report", reportsHandler.ReportComment).Methods(http.MethodPost)
+
+	privateRouter.HandleFunc("/api/mod/reports", reportsHandler.Queue).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/mod/reports/{reportID}", reportsHandler.GetReport).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/mod/reports/{reportID}", reportsHandler.Act).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/mod/post/{id}/lock", moderationHandler.Lock).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/mod/post/{id}/unlock", moderationHandler.Unlock).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/mod/post/{id}/pin", moderationHandler.Pin).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/mod/post/{id}/unpin", moderationHandler.Unpin).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/mod/log", moderationHandler.Log).Methods(http.MethodGet)
+
+	privateRouter.HandleFunc("/api/notifications", notificationsHandler.List).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/notifications/read", notificationsHandler.MarkAllRead).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/notifications/{notificationID}/read", notificationsHandler.MarkRead).Methods(http.MethodPost)
+
+	privateRouter.HandleFunc("/api/messages", messagesHandler.List).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/messages/{username}", messagesHandler.Conversation).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/messages/{username}", messagesHandler.Send).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/messages/{username}/read", messagesHandler.MarkRead).Methods(http.MethodPost)
+
+	privateRouter.HandleFunc("/api/user/me/saved", postsHandler.Saved).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/user/me/drafts", postsHandler.Drafts).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/user/me/preferences", preferencesHandler.Get).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/user/me/preferences", preferencesHandler.Update).Methods(http.MethodPut)
+	privateRouter.HandleFunc("/api/user/me/blocked", blocksHandler.List).Methods(http.MethodGet)
+	optionalRouter.HandleFunc("/api/user/{username}", postsHandler.PostsByUser).Methods(http.MethodGet)
+	privateRouter.HandleFunc("/api/user/{username}/block", blocksHandler.Block).Methods(http.MethodPost)
+	privateRouter.HandleFunc("/api/user/{username}/block", blocksHandler.Unblock).Methods(http.MethodDelete)
+
+	publicRouter.HandleFunc("/api/user/{username}/profile", profilesHandler.Profile).Methods(http.MethodGet)
+	optionalRouter.HandleFunc("/api/user/{username}/comments", profilesHandler.Comments).Methods(http.MethodGet)
+
+	publicRouter.HandleFunc("/api/post/{id}/stream", liveHandler.PostStream).Methods(http.MethodGet)
+	publicRouter.HandleFunc("/api/posts/{category}/stream", liveHandler.CategoryStream).Methods(http.MethodGet)
+
+	publicRouter.HandleFunc("/media/{key:.+}", mediaHandler.Serve).Methods(http.MethodGet, http.MethodHead)
 
-	publicRouter.HandleFunc("/api/user/{username}", postsHandler.PostsByUser).Methods(http.MethodGet)
+	publicRouter.HandleFunc("/feeds/all.{format:atom|rss}", feedsHandler.All).Methods(http.MethodGet, http.MethodHead)
+	publicRouter.HandleFunc("/feeds/category/{category}.{format:atom|rss}", feedsHandler.Category).Methods(http.MethodGet, http.MethodHead)
+	publicRouter.HandleFunc("/feeds/user/{username}.{format:atom|rss}", feedsHandler.User).Methods(http.MethodGet, http.MethodHead)
 
-	publicRouter.PathPrefix("/").HandlerFunc(renderStatic).Methods(http.MethodGet)
+	publicRouter.HandleFunc("/a/{category}/{id}", shellHandler.Post).Methods(http.MethodGet)
+	publicRouter.PathPrefix("/").HandlerFunc(shellHandler.Index).Methods(http.MethodGet)
 
+	publicRouter.Use(middleware.RequestID)
+	publicRouter.Use(middleware.Tracing)
 	publicRouter.Use(middleware.AccessLog)
+	publicRouter.Use(middleware.Metrics)
 	privateRouter.Use(middleware.Session(sessionManager))
+	privateRouter.Use(middleware.RequireSession)
+	optionalRouter.Use(middleware.Session(sessionManager))
 	publicRouter.Use(middleware.Panic)
 
 	return publicRouter
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/KonstantinGalanin/redditclone/internal/saved"
+)
+
+const (
+	TimeoutVal = 10
+)
+
+type SavedMongoDB struct {
+	db *mongo.Collection
+}
+
+func NewSavedMongoDB(db *mongo.Collection) *SavedMongoDB {
+	return &SavedMongoDB{
+		db: db,
+	}
+}
+
+func (s *SavedMongoDB) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, TimeoutVal*time.Second)
+}
+
+// itemID makes saving idempotent: a user has at most one entry per target.
+func itemID(userID, postID, commentID string) string {
+	return userID + ":" + postID + ":" + commentID
+}
+
+func (s *SavedMongoDB) Save(ctx context.Context, userID, postID, commentID string) error {
+	item := bson.M{
+		"user":    userID,
+		"post":    postID,
+		"created": time.Now(),
+	}
+	if commentID != "" {
+		item["comment"] = commentID
+	}
+
+	filter := bson.M{"_id": itemID(userID, postID, commentID)}
+	update := bson.M{"$setOnInsert": item}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if _, err := s.db.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true)); err != nil {
+		return fmt.Errorf("mongodb save item: %w", err)
+	}
+	return nil
+}
+
+func (s *SavedMongoDB) Unsave(ctx context.Context, userID, postID, commentID string) error {
+	filter := bson.M{"_id": itemID(userID, postID, commentID)}
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	if _, err := s.db.DeleteOne(ctx, filter); err != nil {
+		return fmt.Errorf("mongodb unsave item: %w", err)
+	}
+	return nil
+}
+
+func (s *SavedMongoDB) GetSaved(ctx context.Context, userID string, limit, offset int) ([]*saved.Item, error) {
+	items := []*saved.Item{}
+	filter := bson.M{"user": userID}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created", Value: -1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	c, err := s.db.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get saved: %w", err)
+	}
+	if err = c.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("mongodb get saved: %w", err)
+	}
+
+	return items, nil
+}
+
+func (s *SavedMongoDB) GetSavedPostIDs(ctx context.Context, userID string, postIDs []string) (map[string]bool, error) {
+	items := []*saved.Item{}
+	filter := bson.M{
+		"user":    userID,
+		"post":    bson.M{"$in": postIDs},
+		"comment": bson.M{"$exists": false},
+	}
+
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	c, err := s.db.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("mongodb get saved post ids: %w", err)
+	}
+	if err = c.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("mongodb get saved post ids: %w", err)
+	}
+
+	savedIDs := make(map[string]bool, len(items))
+	for _, item := range items {
+		savedIDs[item.PostID] = true
+	}
+	return savedIDs, nil
+}
@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+const (
+	userID    = "1"
+	postID    = "2"
+	commentID = "3"
+)
+
+var itemData = bson.D{
+	{Key: "_id", Value: itemID(userID, postID, "")},
+	{Key: "user", Value: userID},
+	{Key: "post", Value: postID},
+	{Key: "created", Value: time.Now()},
+}
+
+func TestNewSavedMongoDB(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	mt.Run("test", func(mt *mtest.T) {
+		assert.NotNil(t, NewSavedMongoDB(mt.Coll))
+	})
+}
+
+func TestItemID(t *testing.T) {
+	assert.Equal(t, "1:2:", itemID(userID, postID, ""))
+	assert.Equal(t, "1:2:3", itemID(userID, postID, commentID))
+}
+
+func TestSaveUnsave(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("save success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 0}})
+		assert.NoError(t, NewSavedMongoDB(mt.Coll).Save(context.Background(), userID, postID, commentID))
+	})
+
+	mt.Run("save error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		assert.Error(t, NewSavedMongoDB(mt.Coll).Save(context.Background(), userID, postID, ""))
+	})
+
+	mt.Run("unsave success", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "acknowledged", Value: true}, {Key: "n", Value: 1}})
+		assert.NoError(t, NewSavedMongoDB(mt.Coll).Unsave(context.Background(), userID, postID, ""))
+	})
+
+	mt.Run("save cancelled", func(mt *mtest.T) {
+		mt.AddMockResponses(bson.D{{Key: "ok", Value: 1}, {Key: "n", Value: 1}, {Key: "nModified", Value: 0}})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assert.ErrorIs(t, NewSavedMongoDB(mt.Coll).Save(ctx, userID, postID, ""), context.Canceled)
+	})
+
+	mt.Run("unsave error", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "error"}))
+		assert.Error(t, NewSavedMongoDB(mt.Coll).Unsave(context.Background(), userID, postID, ""))
+	})
+}
+
+func TestGetSaved(t *testing.T) {
+	cases := []struct {
+		name          string
+		resp          []bson.D
+		expectedError bool
+	}{
+		{
+			name:          "find error",
+			resp:          nil,
+			expectedError: true,
+		},
+		{
+			name: "cursor error",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "reddit.saved", mtest.FirstBatch, bson.D{{Key: "post", Value: 1}}),
+				mtest.CreateCursorResponse(0, "reddit.saved", mtest.NextBatch),
+			},
+			expectedError: true,
+		},
+		{
+			name: "success",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "reddit.saved", mtest.FirstBatch, itemData),
+				mtest.CreateCursorResponse(0, "reddit.saved", mtest.NextBatch),
+			},
+			expectedError: false,
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewSavedMongoDB(mt.Coll)
+			for _, response := range c.resp {
+				mt.AddMockResponses(response)
+			}
+			items, err := mockDB.GetSaved(context.Background(), userID, 10, 0)
+			if c.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, items)
+			} else {
+				assert.NoError(t, err)
+				assert.Len(t, items, 1)
+				assert.Equal(t, postID, items[0].PostID)
+			}
+		})
+	}
+}
+
+func TestGetSavedPostIDs(t *testing.T) {
+	cases := []struct {
+		name          string
+		resp          []bson.D
+		expectedError bool
+	}{
+		{
+			name:          "find error",
+			resp:          nil,
+			expectedError: true,
+		},
+		{
+			name: "cursor error",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "reddit.saved", mtest.FirstBatch, bson.D{{Key: "post", Value: 1}}),
+				mtest.CreateCursorResponse(0, "reddit.saved", mtest.NextBatch),
+			},
+			expectedError: true,
+		},
+		{
+			name: "success",
+			resp: []bson.D{
+				mtest.CreateCursorResponse(1, "reddit.saved", mtest.FirstBatch, itemData),
+				mtest.CreateCursorResponse(0, "reddit.saved", mtest.NextBatch),
+			},
+			expectedError: false,
+		},
+	}
+
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	for _, c := range cases {
+		mt.Run(c.name, func(mt *mtest.T) {
+			mockDB := NewSavedMongoDB(mt.Coll)
+			for _, response := range c.resp {
+				mt.AddMockResponses(response)
+			}
+			ids, err := mockDB.GetSavedPostIDs(context.Background(), userID, []string{postID, "other"})
+			if c.expectedError {
+				assert.Error(t, err)
+				assert.Nil(t, ids)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, map[string]bool{postID: true}, ids)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: saved.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	context "context"
+	reflect "reflect"
+
+	saved "github.com/KonstantinGalanin/redditclone/internal/saved"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSavedRepo is a mock of SavedRepo interface.
+type MockSavedRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockSavedRepoMockRecorder
+}
+
+// MockSavedRepoMockRecorder is the mock recorder for MockSavedRepo.
+type MockSavedRepoMockRecorder struct {
+	mock *MockSavedRepo
+}
+
+// NewMockSavedRepo creates a new mock instance.
+func NewMockSavedRepo(ctrl *gomock.Controller) *MockSavedRepo {
+	mock := &MockSavedRepo{ctrl: ctrl}
+	mock.recorder = &MockSavedRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSavedRepo) EXPECT() *MockSavedRepoMockRecorder {
+	return m.recorder
+}
+
+// GetSaved mocks base method.
+func (m *MockSavedRepo) GetSaved(ctx context.Context, userID string, limit, offset int) ([]*saved.Item, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSaved", ctx, userID, limit, offset)
+	ret0, _ := ret[0].([]*saved.Item)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSaved indicates an expected call of GetSaved.
+func (mr *MockSavedRepoMockRecorder) GetSaved(ctx, userID, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSaved", reflect.TypeOf((*MockSavedRepo)(nil).GetSaved), ctx, userID, limit, offset)
+}
+
+// GetSavedPostIDs mocks base method.
+func (m *MockSavedRepo) GetSavedPostIDs(ctx context.Context, userID string, postIDs []string) (map[string]bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSavedPostIDs", ctx, userID, postIDs)
+	ret0, _ := ret[0].(map[string]bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSavedPostIDs indicates an expected call of GetSavedPostIDs.
+func (mr *MockSavedRepoMockRecorder) GetSavedPostIDs(ctx, userID, postIDs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSavedPostIDs", reflect.TypeOf((*MockSavedRepo)(nil).GetSavedPostIDs), ctx, userID, postIDs)
+}
+
+// Save mocks base method.
+func (m *MockSavedRepo) Save(ctx context.Context, userID, postID, commentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, userID, postID, commentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockSavedRepoMockRecorder) Save(ctx, userID, postID, commentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockSavedRepo)(nil).Save), ctx, userID, postID, commentID)
+}
+
+// Unsave mocks base method.
+func (m *MockSavedRepo) Unsave(ctx context.Context, userID, postID, commentID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsave", ctx, userID, postID, commentID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsave indicates an expected call of Unsave.
+func (mr *MockSavedRepoMockRecorder) Unsave(ctx, userID, postID, commentID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsave", reflect.TypeOf((*MockSavedRepo)(nil).Unsave), ctx, userID, postID, commentID)
+}
@@ -0,0 +1,27 @@
+package saved
+
+import (
+	"context"
+	"time"
+
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+type Item struct {
+	ID        string         `json:"-" bson:"_id"`
+	UserID    string         `json:"-" bson:"user"`
+	PostID    string         `json:"postId" bson:"post"`
+	CommentID string         `json:"commentId,omitempty" bson:"comment,omitempty"`
+	Created   time.Time      `json:"created" bson:"created"`
+	Removed   bool           `json:"removed" bson:"-"`
+	Post      *posts.Post    `json:"post,omitempty" bson:"-"`
+	Comment   *posts.Comment `json:"comment,omitempty" bson:"-"`
+}
+
+//go:generate mockgen -source=saved.go -destination=repository/repo_mock.go -package=repository SavedRepo
+type SavedRepo interface {
+	Save(ctx context.Context, userID, postID, commentID string) error
+	Unsave(ctx context.Context, userID, postID, commentID string) error
+	GetSaved(ctx context.Context, userID string, limit, offset int) ([]*Item, error)
+	GetSavedPostIDs(ctx context.Context, userID string, postIDs []string) (map[string]bool, error)
+}
@@ -0,0 +1,81 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+const DefaultInterval = 30 * time.Second
+
+type Publisher interface {
+	PublishDue(ctx context.Context, now time.Time) (*posts.Post, error)
+}
+
+// Job periodically publishes scheduled posts whose time has come. Publishing
+// is claimed atomically in the repository, so every server instance can run
+// its own job.
+type Job struct {
+	Posts    Publisher
+	Events   events.Publisher
+	Interval time.Duration
+}
+
+func NewJob(publisher Publisher, bus events.Publisher, interval time.Duration) *Job {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	return &Job{
+		Posts:    publisher,
+		Events:   bus,
+		Interval: interval,
+	}
+}
+
+// RunOnce publishes every post that is due at now and returns how many were
+// published.
+func (j *Job) RunOnce(ctx context.Context, now time.Time) (int, error) {
+	published := 0
+	for {
+		post, err := j.Posts.PublishDue(ctx, now)
+		if err != nil {
+			return published, err
+		}
+		if post == nil {
+			return published, nil
+		}
+		published++
+		if j.Events != nil {
+			j.Events.Publish(ctx, &events.Event{Type: events.PostCreated, PostID: post.ID, Actor: post.Author, Post: post})
+		}
+	}
+}
+
+// Run publishes once on start and then every Interval until ctx is done.
+func (j *Job) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.Interval)
+	defer ticker.Stop()
+
+	for {
+		published, err := j.RunOnce(ctx, time.Now())
+		if err != nil {
+			logrus.WithError(err).Error("publish scheduled posts failed")
+		}
+		if published > 0 {
+			logrus.WithFields(logrus.Fields{
+				"type":      "SCHEDULER",
+				"published": published,
+			}).Info("published scheduled posts")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/events"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+// fakePublisher hands out due posts once, like the atomic claim in the
+// repository.
+type fakePublisher struct {
+	mu    sync.Mutex
+	due   []*posts.Post
+	calls int
+	err   error
+}
+
+func (f *fakePublisher) PublishDue(ctx context.Context, now time.Time) (*posts.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if len(f.due) == 0 {
+		return nil, nil
+	}
+	post := f.due[0]
+	f.due = f.due[1:]
+	return post, nil
+}
+
+func (f *fakePublisher) callCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls
+}
+
+type recorder struct {
+	mu     sync.Mutex
+	events []*events.Event
+}
+
+func (r *recorder) Publish(ctx context.Context, event *events.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func TestNewJobDefaults(t *testing.T) {
+	job := NewJob(&fakePublisher{}, nil, 0)
+	assert.Equal(t, DefaultInterval, job.Interval)
+
+	job = NewJob(&fakePublisher{}, nil, time.Minute)
+	assert.Equal(t, time.Minute, job.Interval)
+}
+
+func TestRunOnce(t *testing.T) {
+	author := &user.User{ID: "1", Username: "User"}
+	publisher := &fakePublisher{due: []*posts.Post{
+		{ID: "1", Author: author},
+		{ID: "2", Author: author},
+	}}
+	bus := &recorder{}
+	job := NewJob(publisher, bus, time.Minute)
+
+	published, err := job.RunOnce(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, published)
+	assert.Len(t, bus.events, 2)
+	assert.Equal(t, events.PostCreated, bus.events[0].Type)
+	assert.Equal(t, "1", bus.events[0].PostID)
+	assert.Equal(t, author, bus.events[0].Actor)
+
+	published, err = job.RunOnce(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+
+	publisher.err = errors.New("some error")
+	_, err = job.RunOnce(context.Background(), time.Now())
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	publisher := &fakePublisher{err: errors.New("some error")}
+	job := NewJob(publisher, nil, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		job.Run(ctx)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return publisher.callCount() >= 2 }, time.Second, time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("job did not stop after cancel")
+	}
+}
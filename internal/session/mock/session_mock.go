@@ -5,6 +5,7 @@
 package mock
 
 import (
+	context "context"
 	reflect "reflect"
 
 	session "github.com/KonstantinGalanin/redditclone/internal/session"
@@ -35,45 +36,45 @@ func (m *MockSessionManager) EXPECT() *MockSessionManagerMockRecorder {
 }
 
 // Check mocks base method.
-func (m *MockSessionManager) Check(in *session.SessionID) (*session.Session, error) {
+func (m *MockSessionManager) Check(ctx context.Context, in *session.SessionID) (*session.Session, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Check", in)
+	ret := m.ctrl.Call(m, "Check", ctx, in)
 	ret0, _ := ret[0].(*session.Session)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Check indicates an expected call of Check.
-func (mr *MockSessionManagerMockRecorder) Check(in interface{}) *gomock.Call {
+func (mr *MockSessionManagerMockRecorder) Check(ctx, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockSessionManager)(nil).Check), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockSessionManager)(nil).Check), ctx, in)
 }
 
 // Create mocks base method.
-func (m *MockSessionManager) Create(in *session.Session) (*session.SessionID, error) {
+func (m *MockSessionManager) Create(ctx context.Context, in *session.Session) (*session.SessionID, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", in)
+	ret := m.ctrl.Call(m, "Create", ctx, in)
 	ret0, _ := ret[0].(*session.SessionID)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockSessionManagerMockRecorder) Create(in interface{}) *gomock.Call {
+func (mr *MockSessionManagerMockRecorder) Create(ctx, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionManager)(nil).Create), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockSessionManager)(nil).Create), ctx, in)
 }
 
 // Delete mocks base method.
-func (m *MockSessionManager) Delete(in *session.SessionID) error {
+func (m *MockSessionManager) Delete(ctx context.Context, in *session.SessionID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", in)
+	ret := m.ctrl.Call(m, "Delete", ctx, in)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockSessionManagerMockRecorder) Delete(in interface{}) *gomock.Call {
+func (mr *MockSessionManagerMockRecorder) Delete(ctx, in interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessionManager)(nil).Delete), in)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockSessionManager)(nil).Delete), ctx, in)
 }
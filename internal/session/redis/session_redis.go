@@ -1,27 +1,138 @@
 package repository
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/KonstantinGalanin/redditclone/internal/metrics"
 	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
 	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/tracing"
 	"github.com/gomodule/redigo/redis"
 	"github.com/google/uuid"
 )
 
+const (
+	DefaultPoolSize    = 16
+	DefaultIdleTimeout = 4 * time.Minute
+	DefaultDialTimeout = 2 * time.Second
+	DefaultIOTimeout   = 2 * time.Second
+	DefaultHealthCheck = 10 * time.Second
+	sessionTTL         = 86400
+	sessionKeyPrefix   = "session:"
+	dbSystem           = "redis"
+)
+
+// PoolOptions tune the connection pool. Zero values take the defaults.
+type PoolOptions struct {
+	// Size caps the connections open at once; callers wait for a free one.
+	Size         int
+	IdleTimeout  time.Duration
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+	// HealthCheck is how long a connection may sit idle before it is
+	// pinged on borrow, so ones broken by a Redis restart are replaced.
+	HealthCheck time.Duration
+}
+
+// NewPool returns a pool dialing the redis:// address in url.
+func NewPool(url string, opts PoolOptions) *redis.Pool {
+	if opts.Size <= 0 {
+		opts.Size = DefaultPoolSize
+	}
+	if opts.IdleTimeout <= 0 {
+		opts.IdleTimeout = DefaultIdleTimeout
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = DefaultDialTimeout
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = DefaultIOTimeout
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = DefaultIOTimeout
+	}
+	if opts.HealthCheck <= 0 {
+		opts.HealthCheck = DefaultHealthCheck
+	}
+
+	return &redis.Pool{
+		MaxActive:   opts.Size,
+		MaxIdle:     opts.Size,
+		IdleTimeout: opts.IdleTimeout,
+		Wait:        true,
+		Dial: func() (redis.Conn, error) {
+			return redis.DialURL(url,
+				redis.DialConnectTimeout(opts.DialTimeout),
+				redis.DialReadTimeout(opts.ReadTimeout),
+				redis.DialWriteTimeout(opts.WriteTimeout),
+			)
+		},
+		TestOnBorrow: func(conn redis.Conn, lastUsed time.Time) error {
+			if time.Since(lastUsed) < opts.HealthCheck {
+				return nil
+			}
+			_, err := conn.Do("PING")
+			return err
+		},
+	}
+}
+
+// Ping checks that the pool can reach Redis.
+func Ping(ctx context.Context, pool *redis.Pool) error {
+	conn, err := pool.GetContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	_, err = redis.DoContext(conn, ctx, "PING")
+	return err
+}
 
+// SessionManagerRedis takes a connection from the pool for every command,
+// since a redigo connection must not be shared between goroutines.
 type SessionManagerRedis struct {
-	redisConn redis.Conn
+	pool *redis.Pool
 }
 
-func NewSessionManagerRedis(conn redis.Conn) *SessionManagerRedis {
+func NewSessionManagerRedis(pool *redis.Pool) *SessionManagerRedis {
 	return &SessionManagerRedis{
-		redisConn: conn,
+		pool: pool,
+	}
+}
+
+// do runs a command, retrying once on a fresh connection when the first
+// one turns out to be broken. Session commands are idempotent, so the retry
+// is safe even if the first attempt reached Redis.
+func (sm *SessionManagerRedis) do(ctx context.Context, command string, args ...interface{}) (interface{}, error) {
+	var (
+		reply interface{}
+		err   error
+	)
+	for attempt := 0; attempt < 2; attempt++ {
+		var conn redis.Conn
+		conn, err = sm.pool.GetContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		reply, err = redis.DoContext(conn, ctx, command, args...)
+		broken := conn.Err() != nil
+		conn.Close()
+		if !broken {
+			break
+		}
 	}
+	return reply, err
 }
 
-func (sm *SessionManagerRedis) Create(in *session.Session) (*session.SessionID, error) {
+func (sm *SessionManagerRedis) Create(ctx context.Context, in *session.Session) (_ *session.SessionID, err error) {
+	ctx, span := tracing.StartClient(ctx, dbSystem, "Create")
+	defer func() { tracing.End(span, err) }()
+
 	id := session.SessionID{
 		ID: uuid.New().String(),
 	}
@@ -29,8 +140,8 @@ func (sm *SessionManagerRedis) Create(in *session.Session) (*session.SessionID,
 	if err != nil {
 		return nil, fmt.Errorf("create session %w", err)
 	}
-	mkey := "session:" + id.ID
-	result, err := redis.String(sm.redisConn.Do("SET", mkey, dataSerialized, "EX", 86400))
+	mkey := sessionKeyPrefix + id.ID
+	result, err := redis.String(sm.do(ctx, "SET", mkey, dataSerialized, "EX", sessionTTL))
 	if err != nil {
 		return nil, fmt.Errorf("create session %w", err)
 	}
@@ -41,9 +152,20 @@ func (sm *SessionManagerRedis) Create(in *session.Session) (*session.SessionID,
 	return &id, nil
 }
 
-func (sm *SessionManagerRedis) Check(in *session.SessionID) (*session.Session, error) {
-	mkey := "session:" + in.ID
-	data, err := redis.Bytes(sm.redisConn.Do("GET", mkey))
+func (sm *SessionManagerRedis) Check(ctx context.Context, in *session.SessionID) (_ *session.Session, err error) {
+	ctx, span := tracing.StartClient(ctx, dbSystem, "Check")
+	defer func() { tracing.End(span, err) }()
+
+	mkey := sessionKeyPrefix + in.ID
+	data, err := redis.Bytes(sm.do(ctx, "GET", mkey))
+	switch {
+	case errors.Is(err, redis.ErrNil):
+		metrics.SessionLookups.WithLabelValues(metrics.SessionMiss).Inc()
+	case err != nil:
+		metrics.SessionLookups.WithLabelValues(metrics.SessionError).Inc()
+	default:
+		metrics.SessionLookups.WithLabelValues(metrics.SessionHit).Inc()
+	}
 	if err != nil {
 		return nil, fmt.Errorf("cant unpack session data: %w", err)
 	}
@@ -56,9 +178,12 @@ func (sm *SessionManagerRedis) Check(in *session.SessionID) (*session.Session, e
 	return sess, nil
 }
 
-func (sm *SessionManagerRedis) Delete(in *session.SessionID) error {
-	mkey := "session:" + in.ID
-	_, err := redis.Int(sm.redisConn.Do("DEL", mkey))
+func (sm *SessionManagerRedis) Delete(ctx context.Context, in *session.SessionID) (err error) {
+	ctx, span := tracing.StartClient(ctx, dbSystem, "Delete")
+	defer func() { tracing.End(span, err) }()
+
+	mkey := sessionKeyPrefix + in.ID
+	_, err = redis.Int(sm.do(ctx, "DEL", mkey))
 	if err != nil {
 		return fmt.Errorf("deleting session: %w", err)
 	}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gomodule/redigo/redis"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonstantinGalanin/redditclone/internal/metrics"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+)
+
+func newManager(t *testing.T, server *miniredis.Miniredis, opts PoolOptions) *SessionManagerRedis {
+	pool := NewPool("redis://"+server.Addr()+"/0", opts)
+	t.Cleanup(func() { pool.Close() })
+	return NewSessionManagerRedis(pool)
+}
+
+func TestSessionManagerRedis(t *testing.T) {
+	server := miniredis.RunT(t)
+	sm := newManager(t, server, PoolOptions{})
+
+	id, err := sm.Create(context.Background(), &session.Session{Username: "alice"})
+	require.NoError(t, err)
+	assert.True(t, server.Exists(sessionKeyPrefix+id.ID))
+	assert.Equal(t, time.Duration(sessionTTL)*time.Second, server.TTL(sessionKeyPrefix+id.ID))
+
+	hits := testutil.ToFloat64(metrics.SessionLookups.WithLabelValues(metrics.SessionHit))
+	misses := testutil.ToFloat64(metrics.SessionLookups.WithLabelValues(metrics.SessionMiss))
+
+	sess, err := sm.Check(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", sess.Username)
+
+	require.NoError(t, sm.Delete(context.Background(), id))
+	_, err = sm.Check(context.Background(), id)
+	assert.True(t, errors.Is(err, redis.ErrNil))
+
+	assert.Equal(t, hits+1, testutil.ToFloat64(metrics.SessionLookups.WithLabelValues(metrics.SessionHit)))
+	assert.Equal(t, misses+1, testutil.ToFloat64(metrics.SessionLookups.WithLabelValues(metrics.SessionMiss)))
+}
+
+func TestPing(t *testing.T) {
+	server := miniredis.RunT(t)
+	pool := NewPool("redis://"+server.Addr()+"/0", PoolOptions{})
+	defer pool.Close()
+
+	assert.NoError(t, Ping(context.Background(), pool))
+	server.Close()
+	assert.Error(t, Ping(context.Background(), pool))
+}
+
+func TestSessionManagerRedisBadData(t *testing.T) {
+	server := miniredis.RunT(t)
+	sm := newManager(t, server, PoolOptions{})
+	require.NoError(t, server.Set(sessionKeyPrefix+"1", "not json"))
+
+	_, err := sm.Check(context.Background(), &session.SessionID{ID: "1"})
+	assert.ErrorContains(t, err, "unmarshal session key")
+}
+
+// Every goroutine must read back its own session. With a shared connection
+// the replies of concurrent commands get mixed up.
+func TestSessionManagerRedisConcurrent(t *testing.T) {
+	server := miniredis.RunT(t)
+	sm := newManager(t, server, PoolOptions{Size: 4})
+
+	const workers, rounds = 32, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			username := fmt.Sprintf("user%d", w)
+			for i := 0; i < rounds; i++ {
+				id, err := sm.Create(context.Background(), &session.Session{Username: username})
+				if err != nil {
+					errs <- err
+					return
+				}
+				sess, err := sm.Check(context.Background(), id)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if sess.Username != username {
+					errs <- fmt.Errorf("worker %d got session of %s", w, sess.Username)
+					return
+				}
+				if err = sm.Delete(context.Background(), id); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	assert.Empty(t, server.Keys())
+}
+
+func TestSessionManagerRedisReconnects(t *testing.T) {
+	server := miniredis.RunT(t)
+	sm := newManager(t, server, PoolOptions{Size: 1, HealthCheck: time.Hour})
+
+	id, err := sm.Create(context.Background(), &session.Session{Username: "alice"})
+	require.NoError(t, err)
+
+	// The pooled connection dies with the server and is not health checked
+	// yet, so the first command after the restart hits it.
+	server.Close()
+	require.NoError(t, server.Restart())
+
+	sess, err := sm.Check(context.Background(), id)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", sess.Username)
+}
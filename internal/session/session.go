@@ -1,5 +1,7 @@
 package session
 
+import "context"
+
 type Session struct {
 	Username string `json:"username"`
 }
@@ -10,7 +12,7 @@ type SessionID struct {
 
 //go:generate mockgen -source=session.go -destination=mock/session_mock.go -package=mock Session
 type SessionManager interface {
-	Create(in *Session) (*SessionID, error)
-	Check(in *SessionID) (*Session, error)
-	Delete(in *SessionID) error
+	Create(ctx context.Context, in *Session) (*SessionID, error)
+	Check(ctx context.Context, in *SessionID) (*Session, error)
+	Delete(ctx context.Context, in *SessionID) error
 }
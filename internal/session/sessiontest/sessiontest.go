@@ -0,0 +1,15 @@
+// Package sessiontest signs requests in for handler tests.
+package sessiontest
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+)
+
+// SignIn returns a copy of r carrying a session for username, the way the
+// Session middleware leaves it for handlers.
+func SignIn(r *http.Request, username string) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), "session", &session.Session{Username: username}))
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/shell"
+)
+
+const fieldID = "id"
+
+type ShellHandler struct {
+	PostsRepo posts.PostRepo
+	// BaseURL is the public address of the site without a trailing slash,
+	// e.g. https://example.com. When empty, links are relative to the site.
+	BaseURL string
+}
+
+// Index serves the shell page of the web client, which routes on its own.
+func (h *ShellHandler) Index(w http.ResponseWriter, r *http.Request) {
+	h.render(w, nil)
+}
+
+// Post serves the shell page with preview tags for the post, so links
+// shared in chats unfurl. A post that cannot be shown gets the plain page
+// and the client reports the error.
+func (h *ShellHandler) Post(w http.ResponseWriter, r *http.Request) {
+	post, err := h.PostsRepo.GetPost(r.Context(), mux.Vars(r)[fieldID])
+	if err != nil {
+		if !errors.Is(err, myerrors.ErrNoPost) {
+			logrus.WithContext(r.Context()).WithError(err).Error("get post for page preview failed")
+		}
+		h.render(w, nil)
+		return
+	}
+	if post.Deleted != nil || !post.IsPublished() {
+		h.render(w, nil)
+		return
+	}
+	h.render(w, shell.PostMeta(post, h.BaseURL))
+}
+
+func (h *ShellHandler) render(w http.ResponseWriter, meta *shell.Meta) {
+	var page bytes.Buffer
+	if err := shell.Render(&page, meta); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(page.Bytes())
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/internal/posts/repository"
+)
+
+func newRequest(id string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/a/music/"+id, nil)
+	return mux.SetURLVars(req, map[string]string{"category": "music", fieldID: id})
+}
+
+func TestIndex(t *testing.T) {
+	handler := &ShellHandler{}
+	w := httptest.NewRecorder()
+	handler.Index(w, httptest.NewRequest(http.MethodGet, "/u/alice", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Body.String(), `<div id="root"></div>`)
+	assert.NotContains(t, w.Body.String(), "og:title")
+}
+
+func TestPostRelative(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockPostRepo(ctrl)
+	repo.EXPECT().GetPost(gomock.Any(), "1").Return(&posts.Post{ID: "1", Category: "music", Title: "Hello"}, nil)
+	handler := &ShellHandler{PostsRepo: repo}
+
+	req := newRequest("1")
+	req.Host = "evil.example"
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w := httptest.NewRecorder()
+	handler.Post(w, req)
+	assert.Contains(t, w.Body.String(), `<meta property="og:url" content="/a/music/1">`)
+	assert.NotContains(t, w.Body.String(), "evil.example")
+}
+
+func TestPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	repo := repository.NewMockPostRepo(ctrl)
+	handler := &ShellHandler{PostsRepo: repo, BaseURL: "https://example.com"}
+	post := &posts.Post{ID: "1", Category: "music", Title: "Hello", Text: "world"}
+
+	cases := []struct {
+		name    string
+		expect  func()
+		preview bool
+	}{
+		{
+			name: "published",
+			expect: func() {
+				repo.EXPECT().GetPost(gomock.Any(), "1").Return(post, nil)
+			},
+			preview: true,
+		},
+		{
+			name: "draft",
+			expect: func() {
+				repo.EXPECT().GetPost(gomock.Any(), "1").Return(&posts.Post{ID: "1", Title: "Hello", Status: posts.StatusDraft}, nil)
+			},
+		},
+		{
+			name: "deleted",
+			expect: func() {
+				repo.EXPECT().GetPost(gomock.Any(), "1").Return(&posts.Post{ID: "1", Title: "Hello", Deleted: &posts.Tombstone{At: time.Now()}}, nil)
+			},
+		},
+		{
+			name: "not found",
+			expect: func() {
+				repo.EXPECT().GetPost(gomock.Any(), "1").Return(nil, myerrors.ErrNoPost)
+			},
+		},
+		{
+			name: "repo error",
+			expect: func() {
+				repo.EXPECT().GetPost(gomock.Any(), "1").Return(nil, errors.New("db error"))
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.expect()
+			w := httptest.NewRecorder()
+			handler.Post(w, newRequest("1"))
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), `<div id="root"></div>`)
+			if !tc.preview {
+				assert.NotContains(t, w.Body.String(), "og:title")
+				return
+			}
+			assert.Contains(t, w.Body.String(), `<meta property="og:title" content="Hello">`)
+			assert.Contains(t, w.Body.String(), `<meta property="og:url" content="https://example.com/a/music/1">`)
+			assert.Contains(t, w.Body.String(), `<meta property="og:description" content="world">`)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package shell
+
+import (
+	"html/template"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+	"github.com/KonstantinGalanin/redditclone/static"
+)
+
+const maxDescription = 200
+
+var index = template.Must(template.New("index").Parse(static.IndexHTML))
+
+// Meta describes a page for link previews. URLs are absolute.
+type Meta struct {
+	Title       string
+	Description string
+	URL         string
+	Image       string
+}
+
+// Render writes the shell page of the web client. With nil meta the page
+// carries no preview tags.
+func Render(w io.Writer, meta *Meta) error {
+	return index.Execute(w, meta)
+}
+
+// PostMeta describes a post page. NSFW and spoiler posts get a title only,
+// so the preview does not give away what the flag hides.
+func PostMeta(post *posts.Post, baseURL string) *Meta {
+	meta := &Meta{
+		Title: post.Title,
+		URL:   baseURL + "/a/" + post.Category + "/" + post.ID,
+	}
+	if post.NSFW || post.Spoiler {
+		return meta
+	}
+
+	switch {
+	case post.Image != nil:
+		meta.Image = baseURL + posts.MediaPath + post.Image.Key
+		meta.Description = excerpt(post.Text)
+	case post.URL != "":
+		meta.Description = post.URL
+	case post.Poll != nil:
+		options := make([]string, 0, len(post.Poll.Options))
+		for _, option := range post.Poll.Options {
+			options = append(options, option.Text)
+		}
+		meta.Description = excerpt(strings.Join(options, " / "))
+	default:
+		meta.Description = excerpt(post.Text)
+	}
+	return meta
+}
+
+// excerpt collapses whitespace and cuts the text to maxDescription runes.
+func excerpt(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= maxDescription {
+		return text
+	}
+	runes := []rune(text)
+	return strings.TrimSpace(string(runes[:maxDescription-1])) + "…"
+}
@@ -0,0 +1,90 @@
+package shell
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/KonstantinGalanin/redditclone/internal/posts"
+)
+
+const base = "https://example.com"
+
+func TestRender(t *testing.T) {
+	var page bytes.Buffer
+	require.NoError(t, Render(&page, nil))
+	assert.Contains(t, page.String(), "<title>asperitas</title>")
+	assert.NotContains(t, page.String(), "og:title")
+	assert.Contains(t, page.String(), `<div id="root"></div>`)
+
+	page.Reset()
+	require.NoError(t, Render(&page, &Meta{
+		Title:       `"Fish" & <chips>`,
+		Description: "desc",
+		URL:         base + "/a/funny/1",
+		Image:       base + "/media/images/1.png",
+	}))
+	out := page.String()
+	assert.Contains(t, out, "<title>&#34;Fish&#34; &amp; &lt;chips&gt; - asperitas</title>")
+	assert.Contains(t, out, `<meta property="og:title" content="&#34;Fish&#34; &amp; &lt;chips&gt;">`)
+	assert.Contains(t, out, `<meta property="og:url" content="https://example.com/a/funny/1">`)
+	assert.Contains(t, out, `<meta property="og:image" content="https://example.com/media/images/1.png">`)
+	assert.Contains(t, out, `<meta name="twitter:card" content="summary_large_image">`)
+}
+
+func TestPostMeta(t *testing.T) {
+	cases := []struct {
+		name   string
+		post   *posts.Post
+		expect *Meta
+	}{
+		{
+			name:   "text",
+			post:   &posts.Post{ID: "1", Category: "funny", Title: "t", Text: "line one\n\n  line two"},
+			expect: &Meta{Title: "t", URL: base + "/a/funny/1", Description: "line one line two"},
+		},
+		{
+			name:   "link",
+			post:   &posts.Post{ID: "1", Category: "news", Title: "t", URL: "https://go.dev"},
+			expect: &Meta{Title: "t", URL: base + "/a/news/1", Description: "https://go.dev"},
+		},
+		{
+			name:   "image",
+			post:   &posts.Post{ID: "1", Category: "funny", Title: "t", Image: &posts.Image{Key: "images/1.png"}},
+			expect: &Meta{Title: "t", URL: base + "/a/funny/1", Image: base + "/media/images/1.png"},
+		},
+		{
+			name: "poll",
+			post: &posts.Post{ID: "1", Category: "funny", Title: "t", Poll: &posts.Poll{
+				Options: []*posts.PollOption{{Text: "yes"}, {Text: "no"}},
+			}},
+			expect: &Meta{Title: "t", URL: base + "/a/funny/1", Description: "yes / no"},
+		},
+		{
+			name:   "spoiler",
+			post:   &posts.Post{ID: "1", Category: "funny", Title: "t", Text: "secret", Spoiler: true},
+			expect: &Meta{Title: "t", URL: base + "/a/funny/1"},
+		},
+		{
+			name:   "nsfw",
+			post:   &posts.Post{ID: "1", Category: "funny", Title: "t", NSFW: true, Image: &posts.Image{Key: "images/1.png"}},
+			expect: &Meta{Title: "t", URL: base + "/a/funny/1"},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expect, PostMeta(tc.post, base))
+		})
+	}
+}
+
+func TestExcerpt(t *testing.T) {
+	long := strings.Repeat("ж", maxDescription+10)
+	got := excerpt(long)
+	assert.Equal(t, maxDescription, len([]rune(got)))
+	assert.True(t, strings.HasSuffix(got, "…"))
+	assert.Equal(t, "short", excerpt(" short "))
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
+	"github.com/KonstantinGalanin/redditclone/internal/session"
+	"github.com/KonstantinGalanin/redditclone/internal/subscriptions"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	unauthorizedMsg = "unauthorized"
+	fieldCategory   = "category"
+)
+
+type ErrorMessage struct {
+	Message string `json:"message"`
+}
+
+func WriteErrorMsg(w http.ResponseWriter, errBody string, errStatus int) {
+	newError := &ErrorMessage{
+		Message: errBody,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(errStatus)
+	if err := json.NewEncoder(w).Encode(newError); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+}
+
+func WriteResponseCategories(w http.ResponseWriter, categories []string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(categories); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+type SubscriptionsHandler struct {
+	SubscriptionRepo subscriptions.SubscriptionRepo
+	UserRepo         user.UserRepo
+}
+
+func (s *SubscriptionsHandler) getUserFromCtx(r *http.Request) (*user.User, error) {
+	ctx := r.Context()
+	sess, ok := ctx.Value("session").(*session.Session)
+	if !ok || sess == nil {
+		return nil, myerrors.ErrNoAuth
+	}
+
+	user, err := s.UserRepo.GetUserByUsername(r.Context(), sess.Username)
+	if err != nil {
+		return nil, fmt.Errorf("get user from sess: %w", err)
+	}
+	return user, nil
+}
+
+func (s *SubscriptionsHandler) List(w http.ResponseWriter, r *http.Request) {
+	user, err := s.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("list subscriptions %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	categories, err := s.SubscriptionRepo.GetCategories(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	WriteResponseCategories(w, categories, http.StatusOK)
+}
+
+func (s *SubscriptionsHandler) Subscribe(w http.ResponseWriter, r *http.Request) {
+	category := mux.Vars(r)[fieldCategory]
+	if category == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyCategory.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("subscribe %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err = s.SubscriptionRepo.Subscribe(user.ID, category); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := s.SubscriptionRepo.GetCategories(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	WriteResponseCategories(w, categories, http.StatusOK)
+}
+
+func (s *SubscriptionsHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	category := mux.Vars(r)[fieldCategory]
+	if category == "" {
+		WriteErrorMsg(w, myerrors.ErrEmptyCategory.Error(), http.StatusBadRequest)
+		return
+	}
+
+	user, err := s.getUserFromCtx(r)
+	if err != nil {
+		WriteErrorMsg(w, fmt.Errorf("unsubscribe %s", unauthorizedMsg).Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if err = s.SubscriptionRepo.Unsubscribe(user.ID, category); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	categories, err := s.SubscriptionRepo.GetCategories(user.ID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	WriteResponseCategories(w, categories, http.StatusOK)
+}
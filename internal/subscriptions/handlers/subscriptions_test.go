@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/KonstantinGalanin/redditclone/internal/session/sessiontest"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+
+	repositorySubscriptions "github.com/KonstantinGalanin/redditclone/internal/subscriptions/repository"
+	repositoryUser "github.com/KonstantinGalanin/redditclone/internal/user/repository"
+)
+
+const (
+	username = "User"
+	category = "music"
+)
+
+var expectedUser = &user.User{
+	Username: username,
+	Password: "password",
+	ID:       "1",
+}
+
+func newRequest(withSession bool, vars map[string]string) *http.Request {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if withSession {
+		req = sessiontest.SignIn(req, username)
+	}
+	return mux.SetURLVars(req, vars)
+}
+
+func TestList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	subscriptionRepo := repositorySubscriptions.NewMockSubscriptionRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &SubscriptionsHandler{
+		SubscriptionRepo: subscriptionRepo,
+		UserRepo:         userRepo,
+	}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+	}{
+		{
+			name:       "no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, nil),
+		},
+		{
+			name:       "repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, nil),
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return([]string{category}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			service.List(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestSubscribeUnsubscribe(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	subscriptionRepo := repositorySubscriptions.NewMockSubscriptionRepo(ctrl)
+	userRepo := repositoryUser.NewMockUserRepo(ctrl)
+	service := &SubscriptionsHandler{
+		SubscriptionRepo: subscriptionRepo,
+		UserRepo:         userRepo,
+	}
+	vars := map[string]string{fieldCategory: category}
+
+	cases := []struct {
+		name       string
+		statusCode int
+		expect     func()
+		req        *http.Request
+		handler    http.HandlerFunc
+	}{
+		{
+			name:       "subscribe empty category",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(true, nil),
+			handler:    service.Subscribe,
+		},
+		{
+			name:       "subscribe no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, vars),
+			handler:    service.Subscribe,
+		},
+		{
+			name:       "subscribe repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, vars),
+			handler:    service.Subscribe,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().Subscribe(expectedUser.ID, category).Return(errors.New("some error"))
+			},
+		},
+		{
+			name:       "subscribe list error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, vars),
+			handler:    service.Subscribe,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().Subscribe(expectedUser.ID, category).Return(nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return(nil, errors.New("some error"))
+			},
+		},
+		{
+			name:       "subscribe success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, vars),
+			handler:    service.Subscribe,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().Subscribe(expectedUser.ID, category).Return(nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return([]string{category}, nil)
+			},
+		},
+		{
+			name:       "unsubscribe empty category",
+			statusCode: http.StatusBadRequest,
+			req:        newRequest(true, nil),
+			handler:    service.Unsubscribe,
+		},
+		{
+			name:       "unsubscribe no session",
+			statusCode: http.StatusUnauthorized,
+			req:        newRequest(false, vars),
+			handler:    service.Unsubscribe,
+		},
+		{
+			name:       "unsubscribe repo error",
+			statusCode: http.StatusInternalServerError,
+			req:        newRequest(true, vars),
+			handler:    service.Unsubscribe,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().Unsubscribe(expectedUser.ID, category).Return(errors.New("some error"))
+			},
+		},
+		{
+			name:       "unsubscribe success",
+			statusCode: http.StatusOK,
+			req:        newRequest(true, vars),
+			handler:    service.Unsubscribe,
+			expect: func() {
+				userRepo.EXPECT().GetUserByUsername(gomock.Any(), username).Return(expectedUser, nil)
+				subscriptionRepo.EXPECT().Unsubscribe(expectedUser.ID, category).Return(nil)
+				subscriptionRepo.EXPECT().GetCategories(expectedUser.ID).Return([]string{}, nil)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			if c.expect != nil {
+				c.expect()
+			}
+			c.handler(recorder, c.req)
+			assert.Equal(t, c.statusCode, recorder.Code)
+		})
+	}
+}
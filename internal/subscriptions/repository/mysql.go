@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type SubscriptionMySQLRepo struct {
+	DB *sql.DB
+}
+
+func NewSubscriptionMySQLRepo(db *sql.DB) *SubscriptionMySQLRepo {
+	return &SubscriptionMySQLRepo{
+		DB: db,
+	}
+}
+
+func (s *SubscriptionMySQLRepo) Subscribe(userID, category string) error {
+	if _, err := s.DB.Exec(Subscribe, userID, category); err != nil {
+		return fmt.Errorf("mysql subscribe: %w", err)
+	}
+	return nil
+}
+
+func (s *SubscriptionMySQLRepo) Unsubscribe(userID, category string) error {
+	if _, err := s.DB.Exec(Unsubscribe, userID, category); err != nil {
+		return fmt.Errorf("mysql unsubscribe: %w", err)
+	}
+	return nil
+}
+
+func (s *SubscriptionMySQLRepo) GetCategories(userID string) ([]string, error) {
+	rows, err := s.DB.Query(GetCategories, userID)
+	if err != nil {
+		return nil, fmt.Errorf("mysql get subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []string{}
+	for rows.Next() {
+		var category string
+		if err = rows.Scan(&category); err != nil {
+			return nil, fmt.Errorf("mysql get subscriptions: %w", err)
+		}
+		categories = append(categories, category)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("mysql get subscriptions: %w", err)
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	sqlmock "gopkg.in/DATA-DOG/go-sqlmock.v1"
+)
+
+const (
+	userID   = "1"
+	category = "music"
+)
+
+func TestNewSubscriptionMySQLRepo(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSubscriptionMySQLRepo(db)
+
+	assert.NotNil(t, repo)
+	assert.Equal(t, db, repo.DB)
+}
+
+func TestSubscribe(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSubscriptionMySQLRepo(db)
+
+	mock.
+		ExpectExec("INSERT IGNORE INTO subscriptions").
+		WithArgs(userID, category).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	assert.NoError(t, repo.Subscribe(userID, category))
+
+	mock.
+		ExpectExec("INSERT IGNORE INTO subscriptions").
+		WithArgs(userID, category).
+		WillReturnError(fmt.Errorf("exec error"))
+	assert.EqualError(t, repo.Subscribe(userID, category), "mysql subscribe: exec error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnsubscribe(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSubscriptionMySQLRepo(db)
+
+	mock.
+		ExpectExec("DELETE FROM subscriptions").
+		WithArgs(userID, category).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Unsubscribe(userID, category))
+
+	mock.
+		ExpectExec("DELETE FROM subscriptions").
+		WithArgs(userID, category).
+		WillReturnError(fmt.Errorf("exec error"))
+	assert.EqualError(t, repo.Unsubscribe(userID, category), "mysql unsubscribe: exec error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCategories(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewSubscriptionMySQLRepo(db)
+
+	rows := sqlmock.NewRows([]string{"category"}).AddRow("music").AddRow("news")
+	mock.
+		ExpectQuery("SELECT category FROM subscriptions WHERE user_id = (.+)").
+		WithArgs(userID).
+		WillReturnRows(rows)
+	categories, err := repo.GetCategories(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"music", "news"}, categories)
+
+	mock.
+		ExpectQuery("SELECT category FROM subscriptions WHERE user_id = (.+)").
+		WithArgs(userID).
+		WillReturnError(fmt.Errorf("query error"))
+	categories, err = repo.GetCategories(userID)
+	assert.EqualError(t, err, "mysql get subscriptions: query error")
+	assert.Nil(t, categories)
+
+	rows = sqlmock.NewRows([]string{"category", "extra"}).AddRow("music", "extra")
+	mock.
+		ExpectQuery("SELECT category FROM subscriptions WHERE user_id = (.+)").
+		WithArgs(userID).
+		WillReturnRows(rows)
+	categories, err = repo.GetCategories(userID)
+	assert.Error(t, err)
+	assert.Nil(t, categories)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
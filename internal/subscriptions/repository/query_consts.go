@@ -0,0 +1,7 @@
+package repository
+
+var (
+	Subscribe     = "INSERT IGNORE INTO subscriptions (user_id, category) VALUES (?, ?);"
+	Unsubscribe   = "DELETE FROM subscriptions WHERE user_id = ? AND category = ?;"
+	GetCategories = "SELECT category FROM subscriptions WHERE user_id = ? ORDER BY category;"
+)
@@ -0,0 +1,77 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: subscriptions.go
+
+// Package repository is a generated GoMock package.
+package repository
+
+import (
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockSubscriptionRepo is a mock of SubscriptionRepo interface.
+type MockSubscriptionRepo struct {
+	ctrl     *gomock.Controller
+	recorder *MockSubscriptionRepoMockRecorder
+}
+
+// MockSubscriptionRepoMockRecorder is the mock recorder for MockSubscriptionRepo.
+type MockSubscriptionRepoMockRecorder struct {
+	mock *MockSubscriptionRepo
+}
+
+// NewMockSubscriptionRepo creates a new mock instance.
+func NewMockSubscriptionRepo(ctrl *gomock.Controller) *MockSubscriptionRepo {
+	mock := &MockSubscriptionRepo{ctrl: ctrl}
+	mock.recorder = &MockSubscriptionRepoMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSubscriptionRepo) EXPECT() *MockSubscriptionRepoMockRecorder {
+	return m.recorder
+}
+
+// GetCategories mocks base method.
+func (m *MockSubscriptionRepo) GetCategories(userID string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", userID)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockSubscriptionRepoMockRecorder) GetCategories(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockSubscriptionRepo)(nil).GetCategories), userID)
+}
+
+// Subscribe mocks base method.
+func (m *MockSubscriptionRepo) Subscribe(userID, category string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", userID, category)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockSubscriptionRepoMockRecorder) Subscribe(userID, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockSubscriptionRepo)(nil).Subscribe), userID, category)
+}
+
+// Unsubscribe mocks base method.
+func (m *MockSubscriptionRepo) Unsubscribe(userID, category string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsubscribe", userID, category)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsubscribe indicates an expected call of Unsubscribe.
+func (mr *MockSubscriptionRepoMockRecorder) Unsubscribe(userID, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsubscribe", reflect.TypeOf((*MockSubscriptionRepo)(nil).Unsubscribe), userID, category)
+}
@@ -0,0 +1,8 @@
+package subscriptions
+
+//go:generate mockgen -source=subscriptions.go -destination=repository/repo_mock.go -package=repository SubscriptionRepo
+type SubscriptionRepo interface {
+	Subscribe(userID, category string) error
+	Unsubscribe(userID, category string) error
+	GetCategories(userID string) ([]string, error)
+}
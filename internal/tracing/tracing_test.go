@@ -0,0 +1,115 @@
+package tracing_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/KonstantinGalanin/redditclone/internal/middleware"
+	"github.com/KonstantinGalanin/redditclone/internal/tracing"
+)
+
+func record(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { provider.Shutdown(context.Background()) })
+	return recorder
+}
+
+func TestRequestSpans(t *testing.T) {
+	recorder := record(t)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/api/post/{id}", func(w http.ResponseWriter, r *http.Request) {
+		_, span := tracing.StartClient(r.Context(), "mongodb", "GetPost")
+		tracing.End(span, errors.New("no post"))
+		http.Error(w, "no post", http.StatusNotFound)
+	})
+	router.Use(middleware.Tracing)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/post/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	client, server := spans[0], spans[1]
+
+	assert.Equal(t, "GET /api/post/{id}", server.Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Equal(t, codes.Unset, server.Status().Code)
+
+	assert.Equal(t, "mongodb.GetPost", client.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), client.Parent().SpanID())
+	assert.Equal(t, codes.Error, client.Status().Code)
+}
+
+func TestServerErrorStatus(t *testing.T) {
+	recorder := record(t)
+
+	router := mux.NewRouter()
+	router.HandleFunc("/boom", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	})
+	router.Use(middleware.Tracing)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.False(t, spans[0].Parent().IsValid())
+}
+
+func TestLogHook(t *testing.T) {
+	record(t)
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.AddHook(tracing.LogHook{})
+
+	ctx, span := tracing.StartClient(context.Background(), "redis", "Check")
+	logger.WithContext(ctx).Info("inside")
+	span.End()
+	assert.Contains(t, out.String(), "trace_id="+span.SpanContext().TraceID().String())
+	assert.Contains(t, out.String(), "span_id="+span.SpanContext().SpanID().String())
+
+	out.Reset()
+	logger.WithContext(context.Background()).Info("outside")
+	logger.Info("no context")
+	assert.NotContains(t, out.String(), "trace_id")
+}
+
+func TestSetup(t *testing.T) {
+	var out bytes.Buffer
+	shutdown, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    tracing.ExporterStdout,
+		SampleRatio: 1,
+		Output:      &out,
+	})
+	require.NoError(t, err)
+
+	_, span := tracing.StartClient(context.Background(), "mysql", "Login")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	assert.Contains(t, out.String(), `"Name":"mysql.Login"`)
+	assert.Contains(t, out.String(), tracing.ServiceName)
+
+	_, err = tracing.Setup(context.Background(), tracing.Options{Exporter: "zipkin"})
+	assert.ErrorContains(t, err, `unknown tracing exporter "zipkin"`)
+}
@@ -111,7 +111,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userItem, err := h.UserRepo.Login(data.Username, data.Password)
+	userItem, err := h.UserRepo.Login(r.Context(), data.Username, data.Password)
 	if errors.Is(err, myerrors.ErrNoUser) {
 		WriteLoginError(w, myerrors.ErrNoUser.Error())
 		return
@@ -127,7 +127,7 @@ func (h *UserHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sess, err := h.SessionManager.Create(&session.Session{
+	sess, err := h.SessionManager.Create(r.Context(), &session.Session{
 		Username: data.Username,
 	})
 	if err != nil {
@@ -158,7 +158,7 @@ func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userItem, err := h.UserRepo.Signup(data.Username, data.Password)
+	userItem, err := h.UserRepo.Signup(r.Context(), data.Username, data.Password)
 	if errors.Is(err, myerrors.ErrUserExist) {
 		WriteSignupError(w, "body", UsernameField, data.Username, myerrors.ErrUserExist.Error())
 		return
@@ -170,7 +170,7 @@ func (h *UserHandler) Signup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sess, err := h.SessionManager.Create(&session.Session{
+	sess, err := h.SessionManager.Create(r.Context(), &session.Session{
 		Username: data.Username,
 	})
 	if err != nil {
@@ -155,7 +155,7 @@ func TestLogin(t *testing.T) {
 		{
 			name: "no user",
 			userExpect: func() {
-				userRepo.EXPECT().Login(username, password).Return(&user.User{ID: id, Username: username, Password: password}, myerrors.ErrNoUser)
+				userRepo.EXPECT().Login(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, myerrors.ErrNoUser)
 			},
 			req:        httptest.NewRequest("POST", "/login", bytes.NewReader(body)),
 			statusCode: http.StatusUnauthorized,
@@ -163,7 +163,7 @@ func TestLogin(t *testing.T) {
 		{
 			name: "no user",
 			userExpect: func() {
-				userRepo.EXPECT().Login(username, password).Return(&user.User{ID: id, Username: username, Password: password}, myerrors.ErrBadPass)
+				userRepo.EXPECT().Login(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, myerrors.ErrBadPass)
 			},
 			req:        httptest.NewRequest("POST", "/login", bytes.NewReader(body)),
 			statusCode: http.StatusUnauthorized,
@@ -171,10 +171,10 @@ func TestLogin(t *testing.T) {
 		{
 			name: "no user",
 			userExpect: func() {
-				userRepo.EXPECT().Login(username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
+				userRepo.EXPECT().Login(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
 			},
 			sessionExpect: func() {
-				sessionManager.EXPECT().Create(&session.Session{
+				sessionManager.EXPECT().Create(gomock.Any(), &session.Session{
 					Username: username,
 				}).Return(&session.SessionID{
 					ID: "1",
@@ -186,10 +186,10 @@ func TestLogin(t *testing.T) {
 		{
 			name: "success",
 			userExpect: func() {
-				userRepo.EXPECT().Login(username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
+				userRepo.EXPECT().Login(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
 			},
 			sessionExpect: func() {
-				sessionManager.EXPECT().Create(&session.Session{
+				sessionManager.EXPECT().Create(gomock.Any(), &session.Session{
 					Username: username,
 				}).Return(&session.SessionID{
 					ID: "1",
@@ -253,7 +253,7 @@ func TestLogin(t *testing.T) {
 			SessionManager: sessionManagerJWT,
 			JwtService:     jwtManagerJWT,
 		}
-		userRepoJWT.EXPECT().Login(username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
+		userRepoJWT.EXPECT().Login(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
 		jwtManagerJWT.EXPECT().CreateToken(&user.User{ID: id, Username: username, Password: password}).Return([]byte(""), errors.New("some error"))
 
 		response := &mockResponseWriter{
@@ -270,8 +270,8 @@ func TestLogin(t *testing.T) {
 		response := &mockResponseWriter{
 			HeaderMap: make(http.Header),
 		}
-		userRepo.EXPECT().Login(username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
-		sessionManager.EXPECT().Create(&session.Session{
+		userRepo.EXPECT().Login(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
+		sessionManager.EXPECT().Create(gomock.Any(), &session.Session{
 			Username: username,
 		}).Return(&session.SessionID{
 			ID: "1",
@@ -315,7 +315,7 @@ func TestSignup(t *testing.T) {
 		{
 			name: "no user",
 			userExpect: func() {
-				userRepo.EXPECT().Signup(username, password).Return(&user.User{ID: id, Username: username, Password: password}, myerrors.ErrUserExist)
+				userRepo.EXPECT().Signup(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, myerrors.ErrUserExist)
 			},
 			req:        httptest.NewRequest("POST", "/signup", bytes.NewReader(body)),
 			statusCode: http.StatusUnprocessableEntity,
@@ -323,10 +323,10 @@ func TestSignup(t *testing.T) {
 		{
 			name: "session error",
 			userExpect: func() {
-				userRepo.EXPECT().Signup(username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
+				userRepo.EXPECT().Signup(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
 			},
 			sessionExpect: func() {
-				sessionManager.EXPECT().Create(&session.Session{
+				sessionManager.EXPECT().Create(gomock.Any(), &session.Session{
 					Username: username,
 				}).Return(&session.SessionID{
 					ID: "1",
@@ -338,10 +338,10 @@ func TestSignup(t *testing.T) {
 		{
 			name: "success",
 			userExpect: func() {
-				userRepo.EXPECT().Signup(username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
+				userRepo.EXPECT().Signup(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
 			},
 			sessionExpect: func() {
-				sessionManager.EXPECT().Create(&session.Session{
+				sessionManager.EXPECT().Create(gomock.Any(), &session.Session{
 					Username: username,
 				}).Return(&session.SessionID{
 					ID: "1",
@@ -384,8 +384,8 @@ func TestSignup(t *testing.T) {
 	})
 
 	t.Run("write error", func(t *testing.T) {
-		userRepo.EXPECT().Signup(username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
-		sessionManager.EXPECT().Create(&session.Session{
+		userRepo.EXPECT().Signup(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
+		sessionManager.EXPECT().Create(gomock.Any(), &session.Session{
 			Username: username,
 		}).Return(&session.SessionID{
 			ID: "1",
@@ -422,7 +422,7 @@ func TestSignup(t *testing.T) {
 			SessionManager: sessionManager,
 			JwtService:     jwtManager,
 		}
-		userRepo.EXPECT().Signup(username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
+		userRepo.EXPECT().Signup(gomock.Any(), username, password).Return(&user.User{ID: id, Username: username, Password: password}, nil)
 		jwtManager.EXPECT().CreateToken(&user.User{ID: id, Username: username, Password: password}).Return([]byte(""), errors.New("some error"))
 
 		response := &mockResponseWriter{
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/KonstantinGalanin/redditclone/internal/metrics"
+	"github.com/KonstantinGalanin/redditclone/internal/tracing"
+	"github.com/KonstantinGalanin/redditclone/internal/user"
+)
+
+const (
+	metricsName = "users_mysql"
+	dbSystem    = "mysql"
+)
+
+// InstrumentedUserPostgresRepo records the latency and errors of every
+// operation of the wrapped repository and traces it in a child span of the
+// caller.
+type InstrumentedUserPostgresRepo struct {
+	*UserPostgresRepo
+}
+
+func NewInstrumentedUserPostgresRepo(repo *UserPostgresRepo) *InstrumentedUserPostgresRepo {
+	return &InstrumentedUserPostgresRepo{UserPostgresRepo: repo}
+}
+
+// instrument starts the span of an operation. done records its latency and
+// error and ends the span.
+func instrument(ctx context.Context, operation string) (context.Context, func(err error)) {
+	start := time.Now()
+	ctx, span := tracing.StartClient(ctx, dbSystem, operation)
+	return ctx, func(err error) {
+		metrics.ObserveRepo(metricsName, operation, start, err)
+		tracing.End(span, err)
+	}
+}
+
+func (m *InstrumentedUserPostgresRepo) Login(ctx context.Context, username, password string) (*user.User, error) {
+	ctx, done := instrument(ctx, "Login")
+	found, err := m.UserPostgresRepo.Login(ctx, username, password)
+	done(err)
+	return found, err
+}
+
+func (m *InstrumentedUserPostgresRepo) Signup(ctx context.Context, username, password string) (*user.User, error) {
+	ctx, done := instrument(ctx, "Signup")
+	created, err := m.UserPostgresRepo.Signup(ctx, username, password)
+	done(err)
+	return created, err
+}
+
+func (m *InstrumentedUserPostgresRepo) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
+	ctx, done := instrument(ctx, "GetUserByUsername")
+	found, err := m.UserPostgresRepo.GetUserByUsername(ctx, username)
+	done(err)
+	return found, err
+}
+
+func (m *InstrumentedUserPostgresRepo) GetUsersByUsernames(ctx context.Context, usernames []string) ([]*user.User, error) {
+	ctx, done := instrument(ctx, "GetUsersByUsernames")
+	found, err := m.UserPostgresRepo.GetUsersByUsernames(ctx, usernames)
+	done(err)
+	return found, err
+}
+
+func (m *InstrumentedUserPostgresRepo) Suspend(ctx context.Context, userID string, until time.Time) error {
+	ctx, done := instrument(ctx, "Suspend")
+	err := m.UserPostgresRepo.Suspend(ctx, userID, until)
+	done(err)
+	return err
+}
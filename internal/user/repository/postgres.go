@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
 	"github.com/KonstantinGalanin/redditclone/internal/user"
@@ -20,9 +23,9 @@ func NewUserPostgresRepo(db *sql.DB) *UserPostgresRepo {
 	}
 }
 
-func isUserExists(db *sql.DB, username string) error {
+func isUserExists(ctx context.Context, db *sql.DB, username string) error {
 	var exists bool
-	row := db.QueryRow(CheckExists, username)
+	row := db.QueryRowContext(ctx, CheckExists, username)
 	err := row.Scan(&exists)
 	if exists {
 		return myerrors.ErrUserExist
@@ -30,8 +33,8 @@ func isUserExists(db *sql.DB, username string) error {
 	return err
 }
 
-func (u *UserPostgresRepo) Login(username, password string) (*user.User, error) {
-	user, err := u.GetUserByUsername(username)
+func (u *UserPostgresRepo) Login(ctx context.Context, username, password string) (*user.User, error) {
+	user, err := u.GetUserByUsername(ctx, username)
 	if err != nil {
 		return nil, fmt.Errorf("postgres login user: %w", err)
 	}
@@ -43,8 +46,8 @@ func (u *UserPostgresRepo) Login(username, password string) (*user.User, error)
 	return user, nil
 }
 
-func (u *UserPostgresRepo) Signup(username, password string) (*user.User, error) {
-	err := isUserExists(u.DB, username)
+func (u *UserPostgresRepo) Signup(ctx context.Context, username, password string) (*user.User, error) {
+	err := isUserExists(ctx, u.DB, username)
 	if err != nil {
 		return nil, fmt.Errorf("postgres signup user: %w", err)
 	}
@@ -53,26 +56,79 @@ func (u *UserPostgresRepo) Signup(username, password string) (*user.User, error)
 		ID:       uuid.New().String(),
 		Password: password,
 		Username: username,
+		Created:  time.Now(),
 	}
 
-	_, err = u.DB.Exec(CreateUser, user.ID, user.Username, user.Password)
+	_, err = u.DB.ExecContext(ctx, CreateUser, user.ID, user.Username, user.Password, user.Created)
 	if err != nil {
 		return nil, fmt.Errorf("postgres signup user: %w", err)
 	}
 	return user, nil
 }
 
-func (u *UserPostgresRepo) GetUserByUsername(username string) (*user.User, error) {
+func (u *UserPostgresRepo) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
 	user := &user.User{}
+	var suspendedUntil sql.NullTime
 
-	row := u.DB.QueryRow(GetUser, username)
-	err := row.Scan(&user.ID, &user.Username, &user.Password)
+	row := u.DB.QueryRowContext(ctx, GetUser, username)
+	err := row.Scan(&user.ID, &user.Username, &user.Password, &user.Moderator, &suspendedUntil, &user.Created)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, fmt.Errorf("postgres get user: %w", myerrors.ErrNoUser)
 		}
 		return nil, fmt.Errorf("postgres get user: %w", err)
 	}
+	user.SuspendedUntil = suspendedUntil.Time
 
 	return user, nil
 }
+
+// GetUsersByUsernames looks up several users in one query. Unknown names are
+// skipped, so fewer users than names may come back.
+func (u *UserPostgresRepo) GetUsersByUsernames(ctx context.Context, usernames []string) ([]*user.User, error) {
+	users := []*user.User{}
+	if len(usernames) == 0 {
+		return users, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(usernames)), ", ")
+	args := make([]interface{}, 0, len(usernames))
+	for _, username := range usernames {
+		args = append(args, username)
+	}
+	rows, err := u.DB.QueryContext(ctx, fmt.Sprintf(GetUsers, placeholders), args...)
+	if err != nil {
+		return nil, fmt.Errorf("postgres get users: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		found := &user.User{}
+		var suspendedUntil sql.NullTime
+		err = rows.Scan(&found.ID, &found.Username, &found.Password, &found.Moderator, &suspendedUntil, &found.Created)
+		if err != nil {
+			return nil, fmt.Errorf("postgres get users: %w", err)
+		}
+		found.SuspendedUntil = suspendedUntil.Time
+		users = append(users, found)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("postgres get users: %w", err)
+	}
+	return users, nil
+}
+
+func (u *UserPostgresRepo) Suspend(ctx context.Context, userID string, until time.Time) error {
+	res, err := u.DB.ExecContext(ctx, SuspendUser, until, userID)
+	if err != nil {
+		return fmt.Errorf("postgres suspend user: %w", err)
+	}
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("postgres suspend user: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("postgres suspend user: %w", myerrors.ErrNoUser)
+	}
+	return nil
+}
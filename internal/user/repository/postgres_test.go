@@ -1,8 +1,10 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/KonstantinGalanin/redditclone/internal/myerrors"
 	"github.com/KonstantinGalanin/redditclone/internal/user"
@@ -16,6 +18,8 @@ const (
 	password = "password"
 )
 
+var created = time.Date(2024, time.January, 2, 3, 4, 5, 0, time.UTC)
+
 func TestNewUserPostgresRepo(t *testing.T) {
 	db, _, err := sqlmock.New()
 	assert.NoError(t, err)
@@ -32,21 +36,22 @@ func TestGetUserByUsernameSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "username", "password"})
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "moderator", "suspended_until", "created"})
 	expect := []*user.User{
 		{
 			ID:       id,
 			Username: username,
 			Password: password,
+			Created:  created,
 		},
 	}
 
 	for _, user := range expect {
-		rows = rows.AddRow(user.ID, user.Username, user.Password)
+		rows = rows.AddRow(user.ID, user.Username, user.Password, user.Moderator, nil, user.Created)
 	}
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = (.+);").
+		ExpectQuery("SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username = (.+);").
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -54,7 +59,7 @@ func TestGetUserByUsernameSuccess(t *testing.T) {
 		DB: db,
 	}
 
-	getUser, err := repo.GetUserByUsername(username)
+	getUser, err := repo.GetUserByUsername(context.Background(), username)
 	assert.NoError(t, err)
 	assert.Equal(t, getUser, expect[0])
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -65,10 +70,10 @@ func TestGetUserByUsernameErrorNoUser(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "username", "password"})
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "moderator", "suspended_until", "created"})
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = (.+);").
+		ExpectQuery("SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username = (.+);").
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -76,7 +81,7 @@ func TestGetUserByUsernameErrorNoUser(t *testing.T) {
 		DB: db,
 	}
 
-	getUser, err := repo.GetUserByUsername(username)
+	getUser, err := repo.GetUserByUsername(context.Background(), username)
 	assert.Error(t, err, "postgres get user: user not found")
 	assert.Nil(t, getUser)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -88,7 +93,7 @@ func TestGetUserByUsernameError(t *testing.T) {
 	defer db.Close()
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = (.+);").
+		ExpectQuery("SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username = (.+);").
 		WithArgs(username).
 		WillReturnError(fmt.Errorf("scan error"))
 
@@ -96,31 +101,62 @@ func TestGetUserByUsernameError(t *testing.T) {
 		DB: db,
 	}
 
-	_, err = repo.GetUserByUsername(username)
+	_, err = repo.GetUserByUsername(context.Background(), username)
 	assert.Error(t, err, "postgres get user: scan error")
 	assert.EqualError(t, err, "postgres get user: scan error")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetUsersByUsernames(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	repo := &UserPostgresRepo{DB: db}
+
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "moderator", "suspended_until", "created"}).
+		AddRow(id, username, password, true, nil, created)
+	mock.
+		ExpectQuery(`SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username IN \(\?, \?\);`).
+		WithArgs(username, "nobody").
+		WillReturnRows(rows)
+
+	users, err := repo.GetUsersByUsernames(context.Background(), []string{username, "nobody"})
+	assert.NoError(t, err)
+	assert.Equal(t, []*user.User{{ID: id, Username: username, Password: password, Moderator: true, Created: created}}, users)
+
+	mock.
+		ExpectQuery("SELECT (.+) FROM users WHERE username IN").
+		WithArgs(username).
+		WillReturnError(fmt.Errorf("db error"))
+	_, err = repo.GetUsersByUsernames(context.Background(), []string{username})
+	assert.EqualError(t, err, "postgres get users: db error")
+
+	users, err = repo.GetUsersByUsernames(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Empty(t, users)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestLoginSuccess(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	assert.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "username", "password"})
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "moderator", "suspended_until", "created"})
 	expect := []*user.User{
 		{
 			ID:       id,
 			Username: username,
 			Password: password,
+			Created:  created,
 		},
 	}
 	for _, user := range expect {
-		rows = rows.AddRow(user.ID, user.Username, user.Password)
+		rows = rows.AddRow(user.ID, user.Username, user.Password, user.Moderator, nil, user.Created)
 	}
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = (.+);").
+		ExpectQuery("SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username = (.+);").
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -128,7 +164,7 @@ func TestLoginSuccess(t *testing.T) {
 		DB: db,
 	}
 
-	getUser, err := repo.Login(username, password)
+	getUser, err := repo.Login(context.Background(), username, password)
 	fmt.Println()
 	assert.NoError(t, err)
 	assert.Equal(t, getUser, expect[0])
@@ -140,21 +176,22 @@ func TestLoginInvalidPass(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "username", "password"})
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "moderator", "suspended_until", "created"})
 
 	expect := []*user.User{
 		{
 			ID:       id,
 			Username: username,
 			Password: password,
+			Created:  created,
 		},
 	}
 	for _, user := range expect {
-		rows = rows.AddRow(user.ID, user.Username, user.Password)
+		rows = rows.AddRow(user.ID, user.Username, user.Password, user.Moderator, nil, user.Created)
 	}
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = (.+);").
+		ExpectQuery("SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username = (.+);").
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -162,7 +199,7 @@ func TestLoginInvalidPass(t *testing.T) {
 		DB: db,
 	}
 
-	_, err = repo.Login(username, "Invalid Password")
+	_, err = repo.Login(context.Background(), username, "Invalid Password")
 	fmt.Println()
 	assert.EqualError(t, err, myerrors.ErrBadPass.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -173,10 +210,10 @@ func TestLoginError(t *testing.T) {
 	assert.NoError(t, err)
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "username", "password"})
+	rows := sqlmock.NewRows([]string{"id", "username", "password", "moderator", "suspended_until", "created"})
 
 	mock.
-		ExpectQuery("SELECT id, username, password FROM users WHERE username = (.+);").
+		ExpectQuery("SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username = (.+);").
 		WithArgs(username).
 		WillReturnRows(rows)
 
@@ -184,7 +221,7 @@ func TestLoginError(t *testing.T) {
 		DB: db,
 	}
 
-	_, err = repo.Login(username, password)
+	_, err = repo.Login(context.Background(), username, password)
 	assert.EqualError(t, err, "postgres login user: postgres get user: user not found")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -205,7 +242,7 @@ func TestIsUserExists(t *testing.T) { //
 		DB: db,
 	}
 
-	err = isUserExists(repo.DB, username)
+	err = isUserExists(context.Background(), repo.DB, username)
 	assert.ErrorIs(t, err, myerrors.ErrUserExist)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -228,7 +265,7 @@ func TestIsUserNotExists(t *testing.T) {
 		DB: db,
 	}
 
-	err = isUserExists(repo.DB, username)
+	err = isUserExists(context.Background(), repo.DB, username)
 	assert.Error(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -244,15 +281,15 @@ func TestSignupSuccess(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	mock.
-		ExpectExec(`INSERT INTO users \(id, username, password\) VALUES \((.+), (.+), (.+)\);`).
-		WithArgs(sqlmock.AnyArg(), username, password).
+		ExpectExec(`INSERT INTO users \(id, username, password, created\) VALUES \((.+), (.+), (.+), (.+)\);`).
+		WithArgs(sqlmock.AnyArg(), username, password, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	repo := &UserPostgresRepo{
 		DB: db,
 	}
 
-	user, err := repo.Signup(username, password)
+	user, err := repo.Signup(context.Background(), username, password)
 	assert.NoError(t, err)
 	// assert.ErrorIs(t, err, myerrors.ErrUserExist)
 	assert.Equal(t, user.Username, username)
@@ -271,15 +308,15 @@ func TestSignupError(t *testing.T) {
 		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
 
 	mock.
-		ExpectExec(`INSERT INTO users \(id, username, password\) VALUES \((.+), (.+), (.+)\);`).
-		WithArgs(sqlmock.AnyArg(), username, password).
+		ExpectExec(`INSERT INTO users \(id, username, password, created\) VALUES \((.+), (.+), (.+), (.+)\);`).
+		WithArgs(sqlmock.AnyArg(), username, password, sqlmock.AnyArg()).
 		WillReturnError(fmt.Errorf("create user error"))
 
 	repo := &UserPostgresRepo{
 		DB: db,
 	}
 
-	_, err = repo.Signup(username, password)
+	_, err = repo.Signup(context.Background(), username, password)
 	assert.EqualError(t, err, "postgres signup user: create user error")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -298,7 +335,36 @@ func TestSignupNoUser(t *testing.T) {
 		DB: db,
 	}
 
-	_, err = repo.Signup(username, password)
+	_, err = repo.Signup(context.Background(), username, password)
 	assert.EqualError(t, err, "postgres signup user: user already exists")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestSuspend(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	repo := NewUserPostgresRepo(db)
+	until := time.Now().Add(24 * time.Hour)
+
+	mock.
+		ExpectExec("UPDATE users SET suspended_until = (.+) WHERE id = (.+);").
+		WithArgs(until, id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Suspend(context.Background(), id, until))
+
+	mock.
+		ExpectExec("UPDATE users SET suspended_until = (.+) WHERE id = (.+);").
+		WithArgs(until, id).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Suspend(context.Background(), id, until), myerrors.ErrNoUser)
+
+	mock.
+		ExpectExec("UPDATE users SET suspended_until = (.+) WHERE id = (.+);").
+		WithArgs(until, id).
+		WillReturnError(fmt.Errorf("exec error"))
+	assert.EqualError(t, repo.Suspend(context.Background(), id, until), "postgres suspend user: exec error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
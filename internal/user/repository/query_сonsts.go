@@ -2,6 +2,9 @@ package repository
 
 var (
 	CheckExists = "SELECT EXISTS(SELECT 1 FROM users WHERE username = ?);"
-	CreateUser  = "INSERT INTO users (id, username, password) VALUES (?, ?, ?);"
-	GetUser     = "SELECT id, username, password FROM users WHERE username = ?;"
+	CreateUser  = "INSERT INTO users (id, username, password, created) VALUES (?, ?, ?, ?);"
+	GetUser     = "SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username = ?;"
+	// GetUsers takes the placeholders of the IN list.
+	GetUsers    = "SELECT id, username, password, moderator, suspended_until, created FROM users WHERE username IN (%s);"
+	SuspendUser = "UPDATE users SET suspended_until = ? WHERE id = ?;"
 )
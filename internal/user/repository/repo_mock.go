@@ -5,9 +5,11 @@
 package repository
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
-	"github.com/KonstantinGalanin/redditclone/internal/user"
+	user "github.com/KonstantinGalanin/redditclone/internal/user"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -35,46 +37,75 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 }
 
 // GetUserByUsername mocks base method.
-func (m *MockUserRepo) GetUserByUsername(username string) (*user.User, error) {
+func (m *MockUserRepo) GetUserByUsername(ctx context.Context, username string) (*user.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByUsername", username)
+	ret := m.ctrl.Call(m, "GetUserByUsername", ctx, username)
 	ret0, _ := ret[0].(*user.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserByUsername indicates an expected call of GetUserByUsername.
-func (mr *MockUserRepoMockRecorder) GetUserByUsername(username interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) GetUserByUsername(ctx, username interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUserRepo)(nil).GetUserByUsername), username)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByUsername", reflect.TypeOf((*MockUserRepo)(nil).GetUserByUsername), ctx, username)
+}
+
+// GetUsersByUsernames mocks base method.
+func (m *MockUserRepo) GetUsersByUsernames(ctx context.Context, usernames []string) ([]*user.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUsersByUsernames", ctx, usernames)
+	ret0, _ := ret[0].([]*user.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUsersByUsernames indicates an expected call of GetUsersByUsernames.
+func (mr *MockUserRepoMockRecorder) GetUsersByUsernames(ctx, usernames interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUsersByUsernames", reflect.TypeOf((*MockUserRepo)(nil).GetUsersByUsernames), ctx, usernames)
 }
 
 // Login mocks base method.
-func (m *MockUserRepo) Login(username, password string) (*user.User, error) {
+func (m *MockUserRepo) Login(ctx context.Context, username, password string) (*user.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Login", username, password)
+	ret := m.ctrl.Call(m, "Login", ctx, username, password)
 	ret0, _ := ret[0].(*user.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Login indicates an expected call of Login.
-func (mr *MockUserRepoMockRecorder) Login(username, password interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) Login(ctx, username, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserRepo)(nil).Login), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockUserRepo)(nil).Login), ctx, username, password)
 }
 
 // Signup mocks base method.
-func (m *MockUserRepo) Signup(username, password string) (*user.User, error) {
+func (m *MockUserRepo) Signup(ctx context.Context, username, password string) (*user.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Signup", username, password)
+	ret := m.ctrl.Call(m, "Signup", ctx, username, password)
 	ret0, _ := ret[0].(*user.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Signup indicates an expected call of Signup.
-func (mr *MockUserRepoMockRecorder) Signup(username, password interface{}) *gomock.Call {
+func (mr *MockUserRepoMockRecorder) Signup(ctx, username, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signup", reflect.TypeOf((*MockUserRepo)(nil).Signup), ctx, username, password)
+}
+
+// Suspend mocks base method.
+func (m *MockUserRepo) Suspend(ctx context.Context, userID string, until time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suspend", ctx, userID, until)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Suspend indicates an expected call of Suspend.
+func (mr *MockUserRepoMockRecorder) Suspend(ctx, userID, until interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Signup", reflect.TypeOf((*MockUserRepo)(nil).Signup), username, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suspend", reflect.TypeOf((*MockUserRepo)(nil).Suspend), ctx, userID, until)
 }
@@ -1,14 +1,47 @@
 package user
 
+import (
+	"context"
+	"regexp"
+	"time"
+)
+
+var mentionRe = regexp.MustCompile(`(?:^|[^a-zA-Z0-9])@([a-zA-Z0-9]+)`)
+
 type User struct {
-	Username string `json:"username" bson:"username"`
-	Password string `bson:"password"`
-	ID       string `json:"id" bson:"_id"`
+	Username       string    `json:"username" bson:"username"`
+	Password       string    `bson:"password"`
+	ID             string    `json:"id" bson:"_id"`
+	Moderator      bool      `json:"-" bson:"-"`
+	SuspendedUntil time.Time `json:"-" bson:"-"`
+	Created        time.Time `json:"-" bson:"-"`
+}
+
+func (u *User) IsSuspended(now time.Time) bool {
+	return u.SuspendedUntil.After(now)
+}
+
+// Mentions returns the usernames referenced as @username in text, each once,
+// in order of appearance. E-mail addresses are not mentions.
+func Mentions(text string) []string {
+	var usernames []string
+	seen := map[string]bool{}
+	for _, match := range mentionRe.FindAllStringSubmatch(text, -1) {
+		username := match[1]
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
 }
 
 //go:generate mockgen -source=user.go -destination=repository/repo_mock.go -package=repository ItemRepo
 type UserRepo interface {
-	Signup(username, password string) (*User, error)
-	Login(username, password string) (*User, error)
-	GetUserByUsername(username string) (*User, error)
+	Signup(ctx context.Context, username, password string) (*User, error)
+	Login(ctx context.Context, username, password string) (*User, error)
+	GetUserByUsername(ctx context.Context, username string) (*User, error)
+	GetUsersByUsernames(ctx context.Context, usernames []string) ([]*User, error)
+	Suspend(ctx context.Context, userID string, until time.Time) error
 }
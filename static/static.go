@@ -0,0 +1,8 @@
+// Package static embeds the shell page of the web client, so the server
+// does not depend on the working directory to render it.
+package static
+
+import _ "embed"
+
+//go:embed html/index.html
+var IndexHTML string